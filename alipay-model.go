@@ -0,0 +1,140 @@
+package payment
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// Alipay product codes
+const (
+	AlipayProductCodeFastInstantTradePay = "FAST_INSTANT_TRADE_PAY"
+	AlipayProductCodeFaceToFacePayment   = "FACE_TO_FACE_PAYMENT"
+)
+
+// Alipay trade statuses
+// Doc: https://opendocs.alipay.com/open/194/103296
+const (
+	AlipayTradeStatusWaitBuyerPay = "WAIT_BUYER_PAY"
+	AlipayTradeStatusClosed       = "TRADE_CLOSED"
+	AlipayTradeStatusSuccess      = "TRADE_SUCCESS"
+	AlipayTradeStatusFinished     = "TRADE_FINISHED"
+)
+
+// AlipayError is a gateway response with a code other than 10000
+// Doc: https://opendocs.alipay.com/common/02km9f
+type AlipayError struct {
+	Response *http.Response `json:"-"`
+	Code     string         `json:"code"`
+	Msg      string         `json:"msg"`
+	SubCode  string         `json:"sub_code,omitempty"`
+	SubMsg   string         `json:"sub_msg,omitempty"`
+}
+
+func (e *AlipayError) Error() string {
+	return fmt.Sprintf("alipay: %s %s: %s %s", e.Code, e.Msg, e.SubCode, e.SubMsg)
+}
+
+// Is reports whether the error belongs to the target error category
+func (e *AlipayError) Is(target error) bool {
+	switch target {
+	case ErrUnauthorized:
+		return e.Code == "40001" || e.Code == "40006"
+	case ErrNotFound:
+		return e.SubCode == "ACQ.TRADE_NOT_EXIST"
+	case ErrValidation:
+		return e.Code == "40002"
+	}
+	if e.Response != nil {
+		return statusErrorIs(e.Response.StatusCode, target)
+	}
+	return false
+}
+
+// AlipayGoodsDetail struct
+type AlipayGoodsDetail struct {
+	GoodsID   string `json:"goods_id"`
+	GoodsName string `json:"goods_name"`
+	Quantity  int    `json:"quantity"`
+	Price     string `json:"price"`
+}
+
+// AlipayTradePagePay is the biz_content of alipay.trade.page.pay
+// Doc: https://opendocs.alipay.com/open/028r8t
+type AlipayTradePagePay struct {
+	OutTradeNo     string              `json:"out_trade_no"`
+	TotalAmount    string              `json:"total_amount"` // In CNY, e.g. "88.88"
+	Subject        string              `json:"subject"`
+	ProductCode    string              `json:"product_code"`
+	Body           string              `json:"body,omitempty"`
+	TimeoutExpress string              `json:"timeout_express,omitempty"` // e.g. "90m"
+	GoodsDetail    []AlipayGoodsDetail `json:"goods_detail,omitempty"`
+	ReturnURL      string              `json:"-"` // Sent as the return_url parameter
+}
+
+// AlipayTradePrecreate is the biz_content of alipay.trade.precreate
+type AlipayTradePrecreate struct {
+	OutTradeNo     string              `json:"out_trade_no"`
+	TotalAmount    string              `json:"total_amount"`
+	Subject        string              `json:"subject"`
+	Body           string              `json:"body,omitempty"`
+	TimeoutExpress string              `json:"timeout_express,omitempty"`
+	GoodsDetail    []AlipayGoodsDetail `json:"goods_detail,omitempty"`
+}
+
+// AlipayTradePrecreateResponse struct
+type AlipayTradePrecreateResponse struct {
+	OutTradeNo string `json:"out_trade_no"`
+	QRCode     string `json:"qr_code"`
+}
+
+// AlipayTradeQuery identifies a trade by OutTradeNo or TradeNo
+type AlipayTradeQuery struct {
+	OutTradeNo string `json:"out_trade_no,omitempty"`
+	TradeNo    string `json:"trade_no,omitempty"`
+}
+
+// AlipayTradeQueryResponse struct
+type AlipayTradeQueryResponse struct {
+	TradeNo        string `json:"trade_no"`
+	OutTradeNo     string `json:"out_trade_no"`
+	BuyerLogonID   string `json:"buyer_logon_id"`
+	TradeStatus    string `json:"trade_status"`
+	TotalAmount    string `json:"total_amount"`
+	BuyerPayAmount string `json:"buyer_pay_amount,omitempty"`
+	SendPayDate    string `json:"send_pay_date,omitempty"`
+}
+
+// AlipayTradeRefund is the biz_content of alipay.trade.refund
+type AlipayTradeRefund struct {
+	OutTradeNo   string `json:"out_trade_no,omitempty"`
+	TradeNo      string `json:"trade_no,omitempty"`
+	RefundAmount string `json:"refund_amount"`
+	RefundReason string `json:"refund_reason,omitempty"`
+	OutRequestNo string `json:"out_request_no,omitempty"`
+}
+
+// AlipayTradeRefundResponse struct
+type AlipayTradeRefundResponse struct {
+	TradeNo      string `json:"trade_no"`
+	OutTradeNo   string `json:"out_trade_no"`
+	BuyerLogonID string `json:"buyer_logon_id"`
+	FundChange   string `json:"fund_change"` // Y when the refund moved funds
+	RefundFee    string `json:"refund_fee"`
+}
+
+// AlipayNotification is an asynchronous notification, the other parameters are in Params
+type AlipayNotification struct {
+	NotifyID    string
+	NotifyTime  string
+	AppID       string
+	TradeNo     string
+	OutTradeNo  string
+	TradeStatus string
+	TotalAmount string
+	BuyerID     string
+	GmtPayment  string
+	RefundFee   string
+	OutBizNo    string
+	Params      url.Values
+}
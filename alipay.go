@@ -0,0 +1,359 @@
+package payment
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// AlipayAPIBaseSandBox is the sandbox gateway of the Alipay Open Platform
+	AlipayAPIBaseSandBox = "https://openapi-sandbox.dl.alipaydev.com/gateway.do"
+
+	// AlipayAPIBaseLive is the live gateway of the Alipay Open Platform
+	AlipayAPIBaseLive = "https://openapi.alipay.com/gateway.do"
+
+	// alipayCodeSuccess is the code of a successful gateway response
+	alipayCodeSuccess = "10000"
+)
+
+var (
+	// ErrInvalidAlipayConfig is returned when an Alipay client is created without AppID, PrivateKey, AlipayPublicKey or APIBase
+	ErrInvalidAlipayConfig = errors.New("alipay: AppID, PrivateKey, AlipayPublicKey and APIBase are required to create a client")
+
+	// ErrAlipaySignature is returned when a response or a notification is not signed by Alipay
+	ErrAlipaySignature = errors.New("alipay: invalid signature")
+)
+
+// alipayLocation is the time zone of the timestamp parameter
+var alipayLocation = time.FixedZone("CST", 8*60*60)
+
+// IAlipay is the Alipay client interface
+type IAlipay interface {
+	PagePayURL(trade AlipayTradePagePay) (string, error)
+	TradePrecreate(ctx context.Context, trade AlipayTradePrecreate) (*AlipayTradePrecreateResponse, error)
+	TradeQuery(ctx context.Context, query AlipayTradeQuery) (*AlipayTradeQueryResponse, error)
+	TradeRefund(ctx context.Context, refund AlipayTradeRefund) (*AlipayTradeRefundResponse, error)
+	TradeClose(ctx context.Context, query AlipayTradeQuery) error
+	VerifyNotification(req *http.Request) (*AlipayNotification, error)
+}
+
+// AlipayClient represents an Alipay Open Platform client
+type AlipayClient struct {
+	Client          *http.Client
+	AppID           string
+	APIBase         string
+	NotifyURL       string
+	privateKey      *rsa.PrivateKey
+	alipayPublicKey *rsa.PublicKey
+}
+
+// NewAlipayClient returns an Alipay client for config.
+// The keys are PEM encoded or raw base64, as downloaded from the Alipay console
+func NewAlipayClient(config *Alipay) (IAlipay, error) {
+	if config == nil || config.AppID == "" || config.PrivateKey == "" || config.AlipayPublicKey == "" || config.APIBase == "" {
+		return nil, ErrInvalidAlipayConfig
+	}
+
+	privateKey, err := parseRSAPrivateKey(config.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("alipay: private key: %w", err)
+	}
+	publicKey, err := parseRSAPublicKey(config.AlipayPublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("alipay: public key: %w", err)
+	}
+
+	return &AlipayClient{
+		Client:          &http.Client{},
+		AppID:           config.AppID,
+		APIBase:         config.APIBase,
+		NotifyURL:       config.NotifyURL,
+		privateKey:      privateKey,
+		alipayPublicKey: publicKey,
+	}, nil
+}
+
+// newAlipay returns an Alipay client, or nil when config is invalid
+func newAlipay(config *Alipay) IAlipay {
+	client, err := NewAlipayClient(config)
+	if err != nil {
+		log.Println("Unable to init Alipay client: ", err)
+		return nil
+	}
+
+	return client
+}
+
+// parseRSAPrivateKey parses a PKCS#1 or PKCS#8 RSA private key, PEM encoded or raw base64
+func parseRSAPrivateKey(key string) (*rsa.PrivateKey, error) {
+	der, err := decodeKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if privateKey, err := x509.ParsePKCS1PrivateKey(der); err == nil {
+		return privateKey, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, err
+	}
+	privateKey, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("not an RSA key")
+	}
+
+	return privateKey, nil
+}
+
+// parseRSAPublicKey parses a PKIX RSA public key, PEM encoded or raw base64
+func parseRSAPublicKey(key string) (*rsa.PublicKey, error) {
+	der, err := decodeKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return nil, err
+	}
+	publicKey, ok := parsed.(*rsa.PublicKey)
+	if !ok {
+		return nil, errors.New("not an RSA key")
+	}
+
+	return publicKey, nil
+}
+
+// decodeKey returns the DER bytes of a PEM encoded or raw base64 key
+func decodeKey(key string) ([]byte, error) {
+	if block, _ := pem.Decode([]byte(key)); block != nil {
+		return block.Bytes, nil
+	}
+	return base64.StdEncoding.DecodeString(strings.TrimSpace(key))
+}
+
+// alipaySignContent returns the sorted "key=value" pairs of params joined by "&",
+// skipping the empty values and the excluded keys
+func alipaySignContent(params url.Values, exclude ...string) string {
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		if params.Get(key) == "" {
+			continue
+		}
+		excluded := false
+		for _, e := range exclude {
+			excluded = excluded || key == e
+		}
+		if !excluded {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, len(keys))
+	for i, key := range keys {
+		pairs[i] = key + "=" + params.Get(key)
+	}
+	return strings.Join(pairs, "&")
+}
+
+// verify checks the base64 RSA2 signature of content with the Alipay public key
+func (c *AlipayClient) verify(content []byte, signature string) error {
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return ErrAlipaySignature
+	}
+	hashed := sha256.Sum256(content)
+	if rsa.VerifyPKCS1v15(c.alipayPublicKey, crypto.SHA256, hashed[:], sig) != nil {
+		return ErrAlipaySignature
+	}
+	return nil
+}
+
+// signedParams returns the common parameters of method with bizContent, signed
+func (c *AlipayClient) signedParams(method string, bizContent interface{}, notifyURL string) (url.Values, error) {
+	content, err := json.Marshal(bizContent)
+	if err != nil {
+		return nil, err
+	}
+
+	params := url.Values{
+		"app_id":      {c.AppID},
+		"method":      {method},
+		"format":      {"JSON"},
+		"charset":     {"utf-8"},
+		"sign_type":   {"RSA2"},
+		"timestamp":   {time.Now().In(alipayLocation).Format("2006-01-02 15:04:05")},
+		"version":     {"1.0"},
+		"biz_content": {string(content)},
+	}
+	if notifyURL != "" {
+		params.Set("notify_url", notifyURL)
+	}
+
+	hashed := sha256.Sum256([]byte(alipaySignContent(params)))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, c.privateKey, crypto.SHA256, hashed[:])
+	if err != nil {
+		return nil, err
+	}
+	params.Set("sign", base64.StdEncoding.EncodeToString(signature))
+
+	return params, nil
+}
+
+// execute calls method on the gateway, verifies the signature of the response and decodes it into v.
+// A response with a code other than 10000 is returned as an AlipayError
+func (c *AlipayClient) execute(ctx context.Context, method string, bizContent, v interface{}) error {
+	params, err := c.signedParams(method, bizContent, c.NotifyURL)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.APIBase, strings.NewReader(params.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded;charset=utf-8")
+
+	raw := map[string]json.RawMessage{}
+	err = sendJSON(c.Client, req, &raw, func(resp *http.Response, body []byte) error {
+		return &AlipayError{Response: resp, Code: fmt.Sprint(resp.StatusCode), Msg: string(body)}
+	})
+	if err != nil {
+		return err
+	}
+
+	node := raw[strings.Replace(method, ".", "_", -1)+"_response"]
+	if node == nil {
+		if errNode := raw["error_response"]; errNode != nil {
+			node = errNode
+		} else {
+			return fmt.Errorf("alipay: no response for %s", method)
+		}
+	}
+
+	status := &AlipayError{}
+	if err = json.Unmarshal(node, status); err != nil {
+		return err
+	}
+	if status.Code != alipayCodeSuccess {
+		return status
+	}
+
+	var signature string
+	json.Unmarshal(raw["sign"], &signature)
+	if err = c.verify(node, signature); err != nil {
+		return err
+	}
+
+	if v == nil {
+		return nil
+	}
+	return json.Unmarshal(node, v)
+}
+
+// PagePayURL returns the signed URL of the Alipay cashier to redirect the buyer to.
+// No request is made, the result is sent to ReturnURL and to the notification URL of the client
+// Method: alipay.trade.page.pay
+func (c *AlipayClient) PagePayURL(trade AlipayTradePagePay) (string, error) {
+	if trade.ProductCode == "" {
+		trade.ProductCode = AlipayProductCodeFastInstantTradePay
+	}
+	returnURL := trade.ReturnURL
+	trade.ReturnURL = ""
+
+	params, err := c.signedParams("alipay.trade.page.pay", trade, c.NotifyURL)
+	if err != nil {
+		return "", err
+	}
+	if returnURL != "" {
+		params.Set("return_url", returnURL)
+		hashed := sha256.Sum256([]byte(alipaySignContent(params, "sign")))
+		signature, err := rsa.SignPKCS1v15(rand.Reader, c.privateKey, crypto.SHA256, hashed[:])
+		if err != nil {
+			return "", err
+		}
+		params.Set("sign", base64.StdEncoding.EncodeToString(signature))
+	}
+
+	return c.APIBase + "?" + params.Encode(), nil
+}
+
+// TradePrecreate creates a trade paid by scanning the returned QR code
+// Method: alipay.trade.precreate
+func (c *AlipayClient) TradePrecreate(ctx context.Context, trade AlipayTradePrecreate) (*AlipayTradePrecreateResponse, error) {
+	response := &AlipayTradePrecreateResponse{}
+	err := c.execute(ctx, "alipay.trade.precreate", trade, response)
+	return response, err
+}
+
+// TradeQuery returns the status of a trade, by OutTradeNo or TradeNo
+// Method: alipay.trade.query
+func (c *AlipayClient) TradeQuery(ctx context.Context, query AlipayTradeQuery) (*AlipayTradeQueryResponse, error) {
+	response := &AlipayTradeQueryResponse{}
+	err := c.execute(ctx, "alipay.trade.query", query, response)
+	return response, err
+}
+
+// TradeRefund refunds a trade, partially when RefundAmount is less than the trade amount.
+// Set OutRequestNo to make several partial refunds of the same trade
+// Method: alipay.trade.refund
+func (c *AlipayClient) TradeRefund(ctx context.Context, refund AlipayTradeRefund) (*AlipayTradeRefundResponse, error) {
+	response := &AlipayTradeRefundResponse{}
+	err := c.execute(ctx, "alipay.trade.refund", refund, response)
+	return response, err
+}
+
+// TradeClose closes an unpaid trade
+// Method: alipay.trade.close
+func (c *AlipayClient) TradeClose(ctx context.Context, query AlipayTradeQuery) error {
+	return c.execute(ctx, "alipay.trade.close", query, nil)
+}
+
+// VerifyNotification checks the signature of an asynchronous notification and decodes it.
+// Answer "success" to the notification once it is processed, Alipay retries otherwise
+// Doc: https://opendocs.alipay.com/common/02mse7
+func (c *AlipayClient) VerifyNotification(req *http.Request) (*AlipayNotification, error) {
+	if err := req.ParseForm(); err != nil {
+		return nil, err
+	}
+	params := req.PostForm
+
+	if err := c.verify([]byte(alipaySignContent(params, "sign", "sign_type")), params.Get("sign")); err != nil {
+		return nil, err
+	}
+	if params.Get("app_id") != c.AppID {
+		return nil, ErrAlipaySignature
+	}
+
+	return &AlipayNotification{
+		NotifyID:    params.Get("notify_id"),
+		NotifyTime:  params.Get("notify_time"),
+		AppID:       params.Get("app_id"),
+		TradeNo:     params.Get("trade_no"),
+		OutTradeNo:  params.Get("out_trade_no"),
+		TradeStatus: params.Get("trade_status"),
+		TotalAmount: params.Get("total_amount"),
+		BuyerID:     params.Get("buyer_id"),
+		GmtPayment:  params.Get("gmt_payment"),
+		RefundFee:   params.Get("refund_fee"),
+		OutBizNo:    params.Get("out_biz_no"),
+		Params:      params,
+	}, nil
+}
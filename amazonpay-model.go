@@ -0,0 +1,286 @@
+package payment
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Amazon Pay regions
+const (
+	AmazonPayRegionNA = "na"
+	AmazonPayRegionEU = "eu"
+	AmazonPayRegionJP = "jp"
+)
+
+// Amazon Pay payment intents
+const (
+	AmazonPayPaymentIntentConfirm              = "Confirm"
+	AmazonPayPaymentIntentAuthorize            = "Authorize"
+	AmazonPayPaymentIntentAuthorizeWithCapture = "AuthorizeWithCapture"
+)
+
+// Amazon Pay object states
+const (
+	AmazonPayStateOpen                   = "Open"
+	AmazonPayStateCompleted              = "Completed"
+	AmazonPayStateCanceled               = "Canceled"
+	AmazonPayStateAuthorizationInitiated = "AuthorizationInitiated"
+	AmazonPayStateAuthorized             = "Authorized"
+	AmazonPayStateCaptureInitiated       = "CaptureInitiated"
+	AmazonPayStateCaptured               = "Captured"
+	AmazonPayStateDeclined               = "Declined"
+	AmazonPayStateChargeable             = "Chargeable"
+	AmazonPayStateNonChargeable          = "NonChargeable"
+	AmazonPayStateClosed                 = "Closed"
+	AmazonPayStateRefundInitiated        = "RefundInitiated"
+	AmazonPayStateRefunded               = "Refunded"
+)
+
+// Amazon SNS message types
+const (
+	AmazonSNSTypeNotification             = "Notification"
+	AmazonSNSTypeSubscriptionConfirmation = "SubscriptionConfirmation"
+	AmazonSNSTypeUnsubscribeConfirmation  = "UnsubscribeConfirmation"
+)
+
+// AmazonPayError is returned for a non 2xx response
+// Doc: https://developer.amazon.com/docs/amazon-pay-api-v2/error-handling.html
+type AmazonPayError struct {
+	Response   *http.Response `json:"-"`
+	ReasonCode string         `json:"reasonCode"`
+	Message    string         `json:"message"`
+}
+
+func (e *AmazonPayError) Error() string {
+	return fmt.Sprintf("%v %v: %d %s %s", e.Response.Request.Method, e.Response.Request.URL, e.Response.StatusCode, e.ReasonCode, e.Message)
+}
+
+// Is reports whether the error belongs to the target error category
+func (e *AmazonPayError) Is(target error) bool {
+	switch target {
+	case ErrUnauthorized:
+		if e.ReasonCode == "UnauthorizedAccess" || e.ReasonCode == "InvalidRequestSignature" || e.ReasonCode == "InvalidAuthentication" {
+			return true
+		}
+	case ErrNotFound:
+		if e.ReasonCode == "ResourceNotFound" {
+			return true
+		}
+	case ErrValidation:
+		if e.ReasonCode == "InvalidParameterValue" || e.ReasonCode == "MissingParameterValue" || e.ReasonCode == "InvalidRequestFormat" {
+			return true
+		}
+	case ErrRateLimited:
+		if e.ReasonCode == "TooManyRequests" {
+			return true
+		}
+	}
+	return statusErrorIs(e.Response.StatusCode, target)
+}
+
+// AmazonPayPrice is an amount, e.g. "14.00"
+type AmazonPayPrice struct {
+	Amount       string `json:"amount"`
+	CurrencyCode string `json:"currencyCode"`
+}
+
+// AmazonPayStatusDetails struct
+type AmazonPayStatusDetails struct {
+	State                string `json:"state"`
+	ReasonCode           string `json:"reasonCode,omitempty"`
+	ReasonDescription    string `json:"reasonDescription,omitempty"`
+	LastUpdatedTimestamp string `json:"lastUpdatedTimestamp,omitempty"`
+}
+
+// AmazonPayAddress struct
+type AmazonPayAddress struct {
+	Name          string `json:"name"`
+	AddressLine1  string `json:"addressLine1"`
+	AddressLine2  string `json:"addressLine2,omitempty"`
+	AddressLine3  string `json:"addressLine3,omitempty"`
+	City          string `json:"city"`
+	County        string `json:"county,omitempty"`
+	District      string `json:"district,omitempty"`
+	StateOrRegion string `json:"stateOrRegion"`
+	PostalCode    string `json:"postalCode"`
+	CountryCode   string `json:"countryCode"`
+	PhoneNumber   string `json:"phoneNumber,omitempty"`
+}
+
+// AmazonPayBuyer struct
+type AmazonPayBuyer struct {
+	BuyerID     string `json:"buyerId"`
+	Name        string `json:"name"`
+	Email       string `json:"email"`
+	PhoneNumber string `json:"phoneNumber,omitempty"`
+}
+
+// AmazonPayMerchantMetadata struct
+type AmazonPayMerchantMetadata struct {
+	MerchantReferenceID string `json:"merchantReferenceId,omitempty"`
+	MerchantStoreName   string `json:"merchantStoreName,omitempty"`
+	NoteToBuyer         string `json:"noteToBuyer,omitempty"`
+	CustomInformation   string `json:"customInformation,omitempty"`
+}
+
+// AmazonPayWebCheckoutDetails struct
+type AmazonPayWebCheckoutDetails struct {
+	CheckoutReviewReturnURL string `json:"checkoutReviewReturnUrl,omitempty"`
+	CheckoutResultReturnURL string `json:"checkoutResultReturnUrl,omitempty"`
+	CheckoutMode            string `json:"checkoutMode,omitempty"`
+	AmazonPayRedirectURL    string `json:"amazonPayRedirectUrl,omitempty"`
+}
+
+// AmazonPayPaymentDetails struct
+type AmazonPayPaymentDetails struct {
+	PaymentIntent                 string          `json:"paymentIntent,omitempty"`
+	CanHandlePendingAuthorization bool            `json:"canHandlePendingAuthorization,omitempty"`
+	ChargeAmount                  *AmazonPayPrice `json:"chargeAmount,omitempty"`
+	TotalOrderAmount              *AmazonPayPrice `json:"totalOrderAmount,omitempty"`
+	PresentmentCurrency           string          `json:"presentmentCurrency,omitempty"`
+	SoftDescriptor                string          `json:"softDescriptor,omitempty"`
+}
+
+// AmazonPayCheckoutSessionRequest is the payload of the create and update checkout session calls
+type AmazonPayCheckoutSessionRequest struct {
+	WebCheckoutDetails   *AmazonPayWebCheckoutDetails `json:"webCheckoutDetails,omitempty"`
+	StoreID              string                       `json:"storeId,omitempty"`
+	Scopes               []string                     `json:"scopes,omitempty"`
+	ChargePermissionType string                       `json:"chargePermissionType,omitempty"`
+	PaymentDetails       *AmazonPayPaymentDetails     `json:"paymentDetails,omitempty"`
+	MerchantMetadata     *AmazonPayMerchantMetadata   `json:"merchantMetadata,omitempty"`
+	PlatformID           string                       `json:"platformId,omitempty"`
+}
+
+// AmazonPayCheckoutSession struct
+type AmazonPayCheckoutSession struct {
+	CheckoutSessionID   string                      `json:"checkoutSessionId"`
+	WebCheckoutDetails  AmazonPayWebCheckoutDetails `json:"webCheckoutDetails"`
+	ProductType         string                      `json:"productType,omitempty"`
+	PaymentDetails      AmazonPayPaymentDetails     `json:"paymentDetails"`
+	MerchantMetadata    AmazonPayMerchantMetadata   `json:"merchantMetadata"`
+	Buyer               *AmazonPayBuyer             `json:"buyer,omitempty"`
+	ShippingAddress     *AmazonPayAddress           `json:"shippingAddress,omitempty"`
+	BillingAddress      *AmazonPayAddress           `json:"billingAddress,omitempty"`
+	StatusDetails       AmazonPayStatusDetails      `json:"statusDetails"`
+	ChargePermissionID  string                      `json:"chargePermissionId,omitempty"`
+	ChargeID            string                      `json:"chargeId,omitempty"`
+	CreationTimestamp   string                      `json:"creationTimestamp"`
+	ExpirationTimestamp string                      `json:"expirationTimestamp,omitempty"`
+	ReleaseEnvironment  string                      `json:"releaseEnvironment"`
+}
+
+// AmazonPayChargePermission struct
+type AmazonPayChargePermission struct {
+	ChargePermissionID string                    `json:"chargePermissionId"`
+	Buyer              *AmazonPayBuyer           `json:"buyer,omitempty"`
+	ShippingAddress    *AmazonPayAddress         `json:"shippingAddress,omitempty"`
+	BillingAddress     *AmazonPayAddress         `json:"billingAddress,omitempty"`
+	StatusDetails      AmazonPayStatusDetails    `json:"statusDetails"`
+	MerchantMetadata   AmazonPayMerchantMetadata `json:"merchantMetadata"`
+	Limits             struct {
+		AmountLimit   AmazonPayPrice `json:"amountLimit"`
+		AmountBalance AmazonPayPrice `json:"amountBalance"`
+	} `json:"limits"`
+	PresentmentCurrency string `json:"presentmentCurrency"`
+	CreationTimestamp   string `json:"creationTimestamp"`
+	ExpirationTimestamp string `json:"expirationTimestamp,omitempty"`
+	ReleaseEnvironment  string `json:"releaseEnvironment"`
+}
+
+// AmazonPayChargeRequest struct
+type AmazonPayChargeRequest struct {
+	ChargePermissionID            string                     `json:"chargePermissionId"`
+	ChargeAmount                  AmazonPayPrice             `json:"chargeAmount"`
+	CaptureNow                    bool                       `json:"captureNow,omitempty"`
+	SoftDescriptor                string                     `json:"softDescriptor,omitempty"`
+	CanHandlePendingAuthorization bool                       `json:"canHandlePendingAuthorization,omitempty"`
+	MerchantMetadata              *AmazonPayMerchantMetadata `json:"merchantMetadata,omitempty"`
+}
+
+// AmazonPayCharge struct
+type AmazonPayCharge struct {
+	ChargeID            string                 `json:"chargeId"`
+	ChargePermissionID  string                 `json:"chargePermissionId"`
+	ChargeAmount        AmazonPayPrice         `json:"chargeAmount"`
+	CaptureAmount       *AmazonPayPrice        `json:"captureAmount,omitempty"`
+	RefundedAmount      *AmazonPayPrice        `json:"refundedAmount,omitempty"`
+	ConvertedAmount     *AmazonPayPrice        `json:"convertedAmount,omitempty"`
+	ConversionRate      string                 `json:"conversionRate,omitempty"`
+	SoftDescriptor      string                 `json:"softDescriptor,omitempty"`
+	StatusDetails       AmazonPayStatusDetails `json:"statusDetails"`
+	CreationTimestamp   string                 `json:"creationTimestamp"`
+	ExpirationTimestamp string                 `json:"expirationTimestamp,omitempty"`
+	ReleaseEnvironment  string                 `json:"releaseEnvironment"`
+}
+
+// AmazonPayRefundRequest struct
+type AmazonPayRefundRequest struct {
+	ChargeID       string         `json:"chargeId"`
+	RefundAmount   AmazonPayPrice `json:"refundAmount"`
+	SoftDescriptor string         `json:"softDescriptor,omitempty"`
+}
+
+// AmazonPayRefund struct
+type AmazonPayRefund struct {
+	RefundID           string                 `json:"refundId"`
+	ChargeID           string                 `json:"chargeId"`
+	RefundAmount       AmazonPayPrice         `json:"refundAmount"`
+	SoftDescriptor     string                 `json:"softDescriptor,omitempty"`
+	StatusDetails      AmazonPayStatusDetails `json:"statusDetails"`
+	CreationTimestamp  string                 `json:"creationTimestamp"`
+	ReleaseEnvironment string                 `json:"releaseEnvironment"`
+}
+
+// AmazonPaySNSMessage is a message posted by Amazon SNS to the IPN endpoint
+type AmazonPaySNSMessage struct {
+	Type             string `json:"Type"`
+	MessageID        string `json:"MessageId"`
+	Token            string `json:"Token,omitempty"`
+	TopicArn         string `json:"TopicArn"`
+	Subject          string `json:"Subject,omitempty"`
+	Message          string `json:"Message"`
+	Timestamp        string `json:"Timestamp"`
+	SignatureVersion string `json:"SignatureVersion"`
+	Signature        string `json:"Signature"`
+	SigningCertURL   string `json:"SigningCertURL"`
+	SubscribeURL     string `json:"SubscribeURL,omitempty"`
+	UnsubscribeURL   string `json:"UnsubscribeURL,omitempty"`
+}
+
+// stringToSign returns the content of which SNS signed the message
+func (m *AmazonPaySNSMessage) stringToSign() string {
+	var fields []string
+	if m.Type == AmazonSNSTypeNotification {
+		fields = []string{"Message", m.Message, "MessageId", m.MessageID}
+		if m.Subject != "" {
+			fields = append(fields, "Subject", m.Subject)
+		}
+		fields = append(fields, "Timestamp", m.Timestamp, "TopicArn", m.TopicArn, "Type", m.Type)
+	} else {
+		fields = []string{"Message", m.Message, "MessageId", m.MessageID, "SubscribeURL", m.SubscribeURL,
+			"Timestamp", m.Timestamp, "Token", m.Token, "TopicArn", m.TopicArn, "Type", m.Type}
+	}
+	return strings.Join(fields, "\n") + "\n"
+}
+
+// Notification decodes the Amazon Pay notification of a Notification message
+func (m *AmazonPaySNSMessage) Notification() (*AmazonPayNotification, error) {
+	notification := &AmazonPayNotification{}
+	err := json.Unmarshal([]byte(m.Message), notification)
+	return notification, err
+}
+
+// AmazonPayNotification tells that an object changed state, get the object to know its new state
+// Doc: https://developer.amazon.com/docs/amazon-pay-checkout/setting-up-ipn.html
+type AmazonPayNotification struct {
+	MerchantID          string `json:"MerchantID"`
+	ObjectType          string `json:"ObjectType"` // CHARGE, REFUND or CHARGE_PERMISSION
+	ObjectID            string `json:"ObjectId"`
+	ChargePermissionID  string `json:"ChargePermissionId"`
+	NotificationType    string `json:"NotificationType"`
+	NotificationID      string `json:"NotificationId"`
+	NotificationVersion string `json:"NotificationVersion"`
+}
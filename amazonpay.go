@@ -0,0 +1,413 @@
+package payment
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// AmazonPayAPIBaseSandBoxNA is the sandbox API of the North America region
+	AmazonPayAPIBaseSandBoxNA = "https://pay-api.amazon.com/sandbox/v2"
+
+	// AmazonPayAPIBaseLiveNA is the live API of the North America region
+	AmazonPayAPIBaseLiveNA = "https://pay-api.amazon.com/live/v2"
+
+	// AmazonPayAPIBaseSandBoxEU is the sandbox API of the Europe region
+	AmazonPayAPIBaseSandBoxEU = "https://pay-api.amazon.eu/sandbox/v2"
+
+	// AmazonPayAPIBaseLiveEU is the live API of the Europe region
+	AmazonPayAPIBaseLiveEU = "https://pay-api.amazon.eu/live/v2"
+
+	// AmazonPayAPIBaseSandBoxJP is the sandbox API of the Japan region
+	AmazonPayAPIBaseSandBoxJP = "https://pay-api.amazon.jp/sandbox/v2"
+
+	// AmazonPayAPIBaseLiveJP is the live API of the Japan region
+	AmazonPayAPIBaseLiveJP = "https://pay-api.amazon.jp/live/v2"
+
+	// amazonPaySigningAlgorithm is the algorithm of the request and button signatures
+	amazonPaySigningAlgorithm = "AMZN-PAY-RSASSA-PSS-V2"
+)
+
+var (
+	// ErrInvalidAmazonPayConfig is returned when an Amazon Pay client is created without PublicKeyID, PrivateKey or APIBase
+	ErrInvalidAmazonPayConfig = errors.New("amazonpay: PublicKeyID, PrivateKey and APIBase are required to create a client")
+
+	// ErrAmazonPaySNSSignature is returned when an SNS message is not signed by Amazon SNS
+	ErrAmazonPaySNSSignature = errors.New("amazonpay: invalid SNS message signature")
+)
+
+// amazonSNSCertHost matches the hosts allowed to serve SNS signing certificates
+var amazonSNSCertHost = regexp.MustCompile(`^sns\.[a-z0-9-]+\.amazonaws\.com(\.cn)?$`)
+
+// IAmazonPay is the Amazon Pay client interface
+type IAmazonPay interface {
+	GenerateButtonSignature(payload interface{}) (string, error)
+	CreateCheckoutSession(ctx context.Context, session AmazonPayCheckoutSessionRequest, idempotencyKey string) (*AmazonPayCheckoutSession, error)
+	GetCheckoutSession(ctx context.Context, checkoutSessionID string) (*AmazonPayCheckoutSession, error)
+	UpdateCheckoutSession(ctx context.Context, checkoutSessionID string, session AmazonPayCheckoutSessionRequest) (*AmazonPayCheckoutSession, error)
+	CompleteCheckoutSession(ctx context.Context, checkoutSessionID string, chargeAmount AmazonPayPrice) (*AmazonPayCheckoutSession, error)
+	GetChargePermission(ctx context.Context, chargePermissionID string) (*AmazonPayChargePermission, error)
+	UpdateChargePermission(ctx context.Context, chargePermissionID string, merchantMetadata AmazonPayMerchantMetadata) (*AmazonPayChargePermission, error)
+	CloseChargePermission(ctx context.Context, chargePermissionID, closureReason string, cancelPendingCharges bool) (*AmazonPayChargePermission, error)
+	CreateCharge(ctx context.Context, charge AmazonPayChargeRequest, idempotencyKey string) (*AmazonPayCharge, error)
+	GetCharge(ctx context.Context, chargeID string) (*AmazonPayCharge, error)
+	CaptureCharge(ctx context.Context, chargeID string, captureAmount AmazonPayPrice, idempotencyKey string) (*AmazonPayCharge, error)
+	CancelCharge(ctx context.Context, chargeID, cancellationReason string) (*AmazonPayCharge, error)
+	CreateRefund(ctx context.Context, refund AmazonPayRefundRequest, idempotencyKey string) (*AmazonPayRefund, error)
+	GetRefund(ctx context.Context, refundID string) (*AmazonPayRefund, error)
+	VerifySNSMessage(ctx context.Context, req *http.Request) (*AmazonPaySNSMessage, error)
+}
+
+// AmazonPayClient represents an Amazon Pay API v2 client
+type AmazonPayClient struct {
+	Client       *http.Client
+	PublicKeyID  string
+	Region       string
+	APIBase      string
+	privateKey   *rsa.PrivateKey
+	certsMutex   sync.Mutex
+	certificates map[string]*x509.Certificate
+	snsCertHost  *regexp.Regexp
+}
+
+// NewAmazonPayClient returns an Amazon Pay client for config
+func NewAmazonPayClient(config *AmazonPay) (IAmazonPay, error) {
+	if config == nil || config.PublicKeyID == "" || config.PrivateKey == "" || config.APIBase == "" {
+		return nil, ErrInvalidAmazonPayConfig
+	}
+
+	privateKey, err := parseRSAPrivateKey(config.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("amazonpay: private key: %w", err)
+	}
+	region := config.Region
+	if region == "" {
+		region = AmazonPayRegionNA
+	}
+
+	return &AmazonPayClient{
+		Client:       &http.Client{},
+		PublicKeyID:  config.PublicKeyID,
+		Region:       region,
+		APIBase:      strings.TrimSuffix(config.APIBase, "/"),
+		privateKey:   privateKey,
+		certificates: map[string]*x509.Certificate{},
+		snsCertHost:  amazonSNSCertHost,
+	}, nil
+}
+
+// newAmazonPay returns an Amazon Pay client, or nil when config is invalid
+func newAmazonPay(config *AmazonPay) IAmazonPay {
+	client, err := NewAmazonPayClient(config)
+	if err != nil {
+		log.Println("Unable to init Amazon Pay client: ", err)
+		return nil
+	}
+
+	return client
+}
+
+// sign returns the base64 RSASSA-PSS signature of the string to sign of a hashed content
+func (c *AmazonPayClient) sign(content []byte) (string, error) {
+	contentHash := sha256.Sum256(content)
+	stringToSign := amazonPaySigningAlgorithm + "\n" + hex.EncodeToString(contentHash[:])
+
+	hashed := sha256.Sum256([]byte(stringToSign))
+	signature, err := rsa.SignPSS(rand.Reader, c.privateKey, crypto.SHA256, hashed[:], &rsa.PSSOptions{SaltLength: 32})
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(signature), nil
+}
+
+// GenerateButtonSignature returns the signature of the checkout session payload given to the Amazon Pay button
+// Doc: https://developer.amazon.com/docs/amazon-pay-checkout/add-the-amazon-pay-button.html#3-sign-the-payload
+func (c *AmazonPayClient) GenerateButtonSignature(payload interface{}) (string, error) {
+	content, ok := payload.(string)
+	if !ok {
+		b, err := json.Marshal(payload)
+		if err != nil {
+			return "", err
+		}
+		content = string(b)
+	}
+	return c.sign([]byte(content))
+}
+
+// send signs and sends a request to path and decodes the response into v
+// Doc: https://developer.amazon.com/docs/amazon-pay-api-v2/signing-requests.html
+func (c *AmazonPayClient) send(ctx context.Context, method, path string, payload, v interface{}, idempotencyKey string) error {
+	var body []byte
+	if payload != nil {
+		var err error
+		if body, err = json.Marshal(payload); err != nil {
+			return err
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.APIBase+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Amz-Pay-Date", time.Now().UTC().Format("20060102T150405Z"))
+	req.Header.Set("X-Amz-Pay-Host", req.URL.Host)
+	req.Header.Set("X-Amz-Pay-Region", c.Region)
+	if idempotencyKey != "" {
+		req.Header.Set("X-Amz-Pay-Idempotency-Key", idempotencyKey)
+	}
+
+	signedHeaders := make([]string, 0, len(req.Header))
+	for name := range req.Header {
+		signedHeaders = append(signedHeaders, strings.ToLower(name))
+	}
+	sort.Strings(signedHeaders)
+
+	var canonical strings.Builder
+	canonical.WriteString(method + "\n" + req.URL.EscapedPath() + "\n" + req.URL.Query().Encode() + "\n")
+	for _, name := range signedHeaders {
+		canonical.WriteString(name + ":" + strings.TrimSpace(req.Header.Get(name)) + "\n")
+	}
+	payloadHash := sha256.Sum256(body)
+	canonical.WriteString("\n" + strings.Join(signedHeaders, ";") + "\n" + hex.EncodeToString(payloadHash[:]))
+
+	signature, err := c.sign([]byte(canonical.String()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("%s PublicKeyId=%s, SignedHeaders=%s, Signature=%s",
+		amazonPaySigningAlgorithm, c.PublicKeyID, strings.Join(signedHeaders, ";"), signature))
+
+	return sendJSON(c.Client, req, v, func(resp *http.Response, body []byte) error {
+		e := &AmazonPayError{Response: resp}
+		json.Unmarshal(body, e)
+		return e
+	})
+}
+
+// newAmazonPayIdempotencyKey returns idempotencyKey, or a generated key when it is empty
+func newAmazonPayIdempotencyKey(idempotencyKey string) string {
+	if idempotencyKey == "" {
+		return NewIdempotencyKey()
+	}
+	return idempotencyKey
+}
+
+// CreateCheckoutSession creates a checkout session, generating an idempotency key when empty
+// Endpoint: POST /checkoutSessions
+func (c *AmazonPayClient) CreateCheckoutSession(ctx context.Context, session AmazonPayCheckoutSessionRequest, idempotencyKey string) (*AmazonPayCheckoutSession, error) {
+	response := &AmazonPayCheckoutSession{}
+	err := c.send(ctx, http.MethodPost, "/checkoutSessions", session, response, newAmazonPayIdempotencyKey(idempotencyKey))
+	return response, err
+}
+
+// GetCheckoutSession returns a checkout session, with the buyer and the selected addresses
+// Endpoint: GET /checkoutSessions/{checkoutSessionId}
+func (c *AmazonPayClient) GetCheckoutSession(ctx context.Context, checkoutSessionID string) (*AmazonPayCheckoutSession, error) {
+	response := &AmazonPayCheckoutSession{}
+	err := c.send(ctx, http.MethodGet, "/checkoutSessions/"+url.PathEscape(checkoutSessionID), nil, response, "")
+	return response, err
+}
+
+// UpdateCheckoutSession sets the payment details and the return URLs of a checkout session,
+// after which the buyer is redirected to WebCheckoutDetails.AmazonPayRedirectURL
+// Endpoint: PATCH /checkoutSessions/{checkoutSessionId}
+func (c *AmazonPayClient) UpdateCheckoutSession(ctx context.Context, checkoutSessionID string, session AmazonPayCheckoutSessionRequest) (*AmazonPayCheckoutSession, error) {
+	response := &AmazonPayCheckoutSession{}
+	err := c.send(ctx, http.MethodPatch, "/checkoutSessions/"+url.PathEscape(checkoutSessionID), session, response, "")
+	return response, err
+}
+
+// CompleteCheckoutSession confirms a checkout session when the buyer is back on the result return URL,
+// chargeAmount must match the amount of the session
+// Endpoint: POST /checkoutSessions/{checkoutSessionId}/complete
+func (c *AmazonPayClient) CompleteCheckoutSession(ctx context.Context, checkoutSessionID string, chargeAmount AmazonPayPrice) (*AmazonPayCheckoutSession, error) {
+	response := &AmazonPayCheckoutSession{}
+	payload := map[string]AmazonPayPrice{"chargeAmount": chargeAmount}
+	err := c.send(ctx, http.MethodPost, "/checkoutSessions/"+url.PathEscape(checkoutSessionID)+"/complete", payload, response, "")
+	return response, err
+}
+
+// GetChargePermission returns a charge permission
+// Endpoint: GET /chargePermissions/{chargePermissionId}
+func (c *AmazonPayClient) GetChargePermission(ctx context.Context, chargePermissionID string) (*AmazonPayChargePermission, error) {
+	response := &AmazonPayChargePermission{}
+	err := c.send(ctx, http.MethodGet, "/chargePermissions/"+url.PathEscape(chargePermissionID), nil, response, "")
+	return response, err
+}
+
+// UpdateChargePermission updates the merchant metadata of a charge permission
+// Endpoint: PATCH /chargePermissions/{chargePermissionId}
+func (c *AmazonPayClient) UpdateChargePermission(ctx context.Context, chargePermissionID string, merchantMetadata AmazonPayMerchantMetadata) (*AmazonPayChargePermission, error) {
+	response := &AmazonPayChargePermission{}
+	payload := map[string]AmazonPayMerchantMetadata{"merchantMetadata": merchantMetadata}
+	err := c.send(ctx, http.MethodPatch, "/chargePermissions/"+url.PathEscape(chargePermissionID), payload, response, "")
+	return response, err
+}
+
+// CloseChargePermission closes a charge permission, so that no more charges are made against it
+// Endpoint: DELETE /chargePermissions/{chargePermissionId}/close
+func (c *AmazonPayClient) CloseChargePermission(ctx context.Context, chargePermissionID, closureReason string, cancelPendingCharges bool) (*AmazonPayChargePermission, error) {
+	response := &AmazonPayChargePermission{}
+	payload := map[string]interface{}{"closureReason": closureReason, "cancelPendingCharges": cancelPendingCharges}
+	err := c.send(ctx, http.MethodDelete, "/chargePermissions/"+url.PathEscape(chargePermissionID)+"/close", payload, response, "")
+	return response, err
+}
+
+// CreateCharge authorizes, and captures when CaptureNow is set, an amount against a charge permission
+// Endpoint: POST /charges
+func (c *AmazonPayClient) CreateCharge(ctx context.Context, charge AmazonPayChargeRequest, idempotencyKey string) (*AmazonPayCharge, error) {
+	response := &AmazonPayCharge{}
+	err := c.send(ctx, http.MethodPost, "/charges", charge, response, newAmazonPayIdempotencyKey(idempotencyKey))
+	return response, err
+}
+
+// GetCharge returns a charge
+// Endpoint: GET /charges/{chargeId}
+func (c *AmazonPayClient) GetCharge(ctx context.Context, chargeID string) (*AmazonPayCharge, error) {
+	response := &AmazonPayCharge{}
+	err := c.send(ctx, http.MethodGet, "/charges/"+url.PathEscape(chargeID), nil, response, "")
+	return response, err
+}
+
+// CaptureCharge captures an authorized charge
+// Endpoint: POST /charges/{chargeId}/capture
+func (c *AmazonPayClient) CaptureCharge(ctx context.Context, chargeID string, captureAmount AmazonPayPrice, idempotencyKey string) (*AmazonPayCharge, error) {
+	response := &AmazonPayCharge{}
+	payload := map[string]AmazonPayPrice{"captureAmount": captureAmount}
+	err := c.send(ctx, http.MethodPost, "/charges/"+url.PathEscape(chargeID)+"/capture", payload, response, newAmazonPayIdempotencyKey(idempotencyKey))
+	return response, err
+}
+
+// CancelCharge cancels an uncaptured charge
+// Endpoint: DELETE /charges/{chargeId}/cancel
+func (c *AmazonPayClient) CancelCharge(ctx context.Context, chargeID, cancellationReason string) (*AmazonPayCharge, error) {
+	response := &AmazonPayCharge{}
+	payload := map[string]string{"cancellationReason": cancellationReason}
+	err := c.send(ctx, http.MethodDelete, "/charges/"+url.PathEscape(chargeID)+"/cancel", payload, response, "")
+	return response, err
+}
+
+// CreateRefund refunds a captured charge, partially when RefundAmount is less than the captured amount
+// Endpoint: POST /refunds
+func (c *AmazonPayClient) CreateRefund(ctx context.Context, refund AmazonPayRefundRequest, idempotencyKey string) (*AmazonPayRefund, error) {
+	response := &AmazonPayRefund{}
+	err := c.send(ctx, http.MethodPost, "/refunds", refund, response, newAmazonPayIdempotencyKey(idempotencyKey))
+	return response, err
+}
+
+// GetRefund returns a refund
+// Endpoint: GET /refunds/{refundId}
+func (c *AmazonPayClient) GetRefund(ctx context.Context, refundID string) (*AmazonPayRefund, error) {
+	response := &AmazonPayRefund{}
+	err := c.send(ctx, http.MethodGet, "/refunds/"+url.PathEscape(refundID), nil, response, "")
+	return response, err
+}
+
+// snsCertificate returns the SNS signing certificate at certURL, which must be served over HTTPS by Amazon SNS
+func (c *AmazonPayClient) snsCertificate(ctx context.Context, certURL string) (*x509.Certificate, error) {
+	u, err := url.Parse(certURL)
+	if err != nil || u.Scheme != "https" || !c.snsCertHost.MatchString(u.Hostname()) || !strings.HasSuffix(u.Path, ".pem") {
+		return nil, ErrAmazonPaySNSSignature
+	}
+
+	c.certsMutex.Lock()
+	certificate := c.certificates[certURL]
+	c.certsMutex.Unlock()
+	if certificate != nil {
+		return certificate, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, certURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := doRequest(c.Client, req, func(resp *http.Response, body []byte) error {
+		return &AmazonPayError{Response: resp, Message: string(body)}
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(body)
+	if block == nil {
+		return nil, ErrAmazonPaySNSSignature
+	}
+	if certificate, err = x509.ParseCertificate(block.Bytes); err != nil {
+		return nil, err
+	}
+
+	c.certsMutex.Lock()
+	c.certificates[certURL] = certificate
+	c.certsMutex.Unlock()
+
+	return certificate, nil
+}
+
+// VerifySNSMessage checks the signature of an Amazon SNS message posted to the IPN endpoint and decodes it.
+// Confirm a SubscriptionConfirmation by getting SubscribeURL, and decode the Message of a Notification
+// with AmazonPaySNSMessage.Notification
+// Doc: https://docs.aws.amazon.com/sns/latest/dg/sns-verify-signature-of-message.html
+func (c *AmazonPayClient) VerifySNSMessage(ctx context.Context, req *http.Request) (*AmazonPaySNSMessage, error) {
+	message := &AmazonPaySNSMessage{}
+	if err := json.NewDecoder(req.Body).Decode(message); err != nil {
+		return nil, err
+	}
+
+	certificate, err := c.snsCertificate(ctx, message.SigningCertURL)
+	if err != nil {
+		return nil, err
+	}
+	publicKey, ok := certificate.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return nil, ErrAmazonPaySNSSignature
+	}
+	signature, err := base64.StdEncoding.DecodeString(message.Signature)
+	if err != nil {
+		return nil, ErrAmazonPaySNSSignature
+	}
+
+	content := []byte(message.stringToSign())
+	switch message.SignatureVersion {
+	case "1":
+		hashed := sha1.Sum(content)
+		err = rsa.VerifyPKCS1v15(publicKey, crypto.SHA1, hashed[:], signature)
+	case "2":
+		hashed := sha256.Sum256(content)
+		err = rsa.VerifyPKCS1v15(publicKey, crypto.SHA256, hashed[:], signature)
+	default:
+		err = ErrAmazonPaySNSSignature
+	}
+	if err != nil {
+		return nil, ErrAmazonPaySNSSignature
+	}
+
+	return message, nil
+}
@@ -0,0 +1,130 @@
+package payment
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Apple Pay payment data types
+const (
+	ApplePayPaymentDataType3DSecure = "3DSecure"
+	ApplePayPaymentDataTypeEMV      = "EMV"
+)
+
+// ApplePayError is returned for a non 2xx response of a merchant validation
+type ApplePayError struct {
+	Response      *http.Response `json:"-"`
+	StatusMessage string         `json:"statusMessage"`
+}
+
+func (e *ApplePayError) Error() string {
+	return fmt.Sprintf("%v %v: %d %s", e.Response.Request.Method, e.Response.Request.URL, e.Response.StatusCode, e.StatusMessage)
+}
+
+// Is reports whether the error belongs to the target error category
+func (e *ApplePayError) Is(target error) bool {
+	return statusErrorIs(e.Response.StatusCode, target)
+}
+
+// ApplePayMerchantSessionRequest struct
+type ApplePayMerchantSessionRequest struct {
+	MerchantIdentifier string `json:"merchantIdentifier"`
+	DisplayName        string `json:"displayName"`
+	Initiative         string `json:"initiative"`
+	InitiativeContext  string `json:"initiativeContext"`
+}
+
+// ApplePayPaymentToken is the token of an ApplePayPayment, as received by onpaymentauthorized
+type ApplePayPaymentToken struct {
+	PaymentData           ApplePayEncryptedPaymentData `json:"paymentData"`
+	PaymentMethod         ApplePayPaymentMethod        `json:"paymentMethod"`
+	TransactionIdentifier string                       `json:"transactionIdentifier"`
+}
+
+// ApplePayEncryptedPaymentData struct
+type ApplePayEncryptedPaymentData struct {
+	Version   string `json:"version"`
+	Data      string `json:"data"`
+	Signature string `json:"signature"`
+	Header    struct {
+		EphemeralPublicKey string `json:"ephemeralPublicKey"`
+		PublicKeyHash      string `json:"publicKeyHash"`
+		TransactionID      string `json:"transactionId"`
+		ApplicationData    string `json:"applicationData,omitempty"`
+	} `json:"header"`
+}
+
+// ApplePayPaymentMethod struct
+type ApplePayPaymentMethod struct {
+	DisplayName string `json:"displayName"` // e.g. "Visa 0492"
+	Network     string `json:"network"`     // e.g. "Visa", "MasterCard", "AmEx" or "Discover"
+	Type        string `json:"type"`        // debit, credit, prepaid or store
+}
+
+// ApplePayTokenData is the decrypted payment data of a token
+type ApplePayTokenData struct {
+	ApplicationPrimaryAccountNumber string `json:"applicationPrimaryAccountNumber"`
+	ApplicationExpirationDate       string `json:"applicationExpirationDate"` // YYMMDD
+	CurrencyCode                    string `json:"currencyCode"`              // ISO 4217 numeric code
+	TransactionAmount               int64  `json:"transactionAmount"`         // In the minor unit of the currency
+	CardholderName                  string `json:"cardholderName,omitempty"`
+	DeviceManufacturerIdentifier    string `json:"deviceManufacturerIdentifier"`
+	PaymentDataType                 string `json:"paymentDataType"`
+	PaymentData                     struct {
+		OnlinePaymentCryptogram string `json:"onlinePaymentCryptogram,omitempty"`
+		ECIIndicator            string `json:"eciIndicator,omitempty"`
+		EMVData                 string `json:"emvData,omitempty"`
+		EncryptedPINData        string `json:"encryptedPINData,omitempty"`
+	} `json:"paymentData"`
+}
+
+// ApplePayDecryptedPayment is a decrypted token with the payment method it was made with
+type ApplePayDecryptedPayment struct {
+	ApplePayTokenData
+	PaymentMethod ApplePayPaymentMethod
+}
+
+// applePayNetworkBrands maps the Apple Pay networks to the PayPal card brands
+var applePayNetworkBrands = map[string]string{
+	"visa":          "VISA",
+	"mastercard":    "MASTERCARD",
+	"amex":          "AMEX",
+	"discover":      "DISCOVER",
+	"jcb":           "JCB",
+	"maestro":       "MAESTRO",
+	"chinaunionpay": "CHINA_UNION_PAY",
+}
+
+// PayPalPaymentSource returns the apple_pay payment source of a PayPal order paying with the decrypted token.
+// currency is the ISO 4217 alphabetic code of CurrencyCode, e.g. "USD" for "840"
+func (p *ApplePayDecryptedPayment) PayPalPaymentSource(currency string) *PaymentSource {
+	expiry := p.ApplicationExpirationDate
+	if len(expiry) >= 4 {
+		expiry = "20" + expiry[:2] + "-" + expiry[2:4]
+	}
+
+	return &PaymentSource{
+		ApplePay: &PaymentSourceApplePay{
+			Name: p.CardholderName,
+			DecryptedToken: &ApplePayDecryptedToken{
+				TransactionAmount: MoneyAmount{Currency: currency, Minor: p.TransactionAmount}.Money(),
+				TokenizedCard: &TokenizedCard{
+					Name:   p.CardholderName,
+					Number: p.ApplicationPrimaryAccountNumber,
+					Expiry: expiry,
+					Type:   strings.ToUpper(p.PaymentMethod.Type),
+					Brand:  applePayNetworkBrands[strings.ToLower(p.PaymentMethod.Network)],
+				},
+				DeviceManufacturerID: p.DeviceManufacturerIdentifier,
+				PaymentDataType:      strings.ToUpper(p.PaymentDataType),
+				PaymentData: &ApplePayPaymentData{
+					Cryptogram:   p.PaymentData.OnlinePaymentCryptogram,
+					ECIIndicator: p.PaymentData.ECIIndicator,
+					EMVData:      p.PaymentData.EMVData,
+					Pin:          p.PaymentData.EncryptedPINData,
+				},
+			},
+		},
+	}
+}
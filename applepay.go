@@ -0,0 +1,414 @@
+package payment
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"time"
+)
+
+const (
+	// ApplePayTokenMaxAge is how old the signature of a payment token may be by default
+	ApplePayTokenMaxAge = 5 * time.Minute
+
+	// applePayVersionEC is the only supported payment token version
+	applePayVersionEC = "EC_v1"
+)
+
+var (
+	// ErrInvalidApplePayConfig is returned when an Apple Pay client is created without MerchantID
+	// or with a merchant identity certificate or payment processing key that cannot be parsed
+	ErrInvalidApplePayConfig = errors.New("applepay: MerchantID is required and the certificates and keys must be PEM encoded")
+
+	// ErrApplePayValidationURL is returned when the validation URL of a merchant validation is not an Apple Pay server
+	ErrApplePayValidationURL = errors.New("applepay: the validation URL is not an Apple Pay server")
+
+	// ErrApplePayMerchantIdentityRequired is returned by ValidateMerchant without a merchant identity certificate
+	ErrApplePayMerchantIdentityRequired = errors.New("applepay: a merchant identity certificate is required to validate the merchant")
+
+	// ErrApplePayProcessingKeyRequired is returned by DecryptToken without a payment processing key or Apple root certificate
+	ErrApplePayProcessingKeyRequired = errors.New("applepay: a payment processing key and the Apple root certificate are required to decrypt tokens")
+
+	// ErrApplePayTokenSignature is returned when a payment token is not signed by Apple, or was signed too long ago
+	ErrApplePayTokenSignature = errors.New("applepay: invalid payment token signature")
+
+	// ErrApplePayKeyMismatch is returned when a payment token is encrypted for another payment processing key
+	ErrApplePayKeyMismatch = errors.New("applepay: payment token encrypted for another payment processing key")
+)
+
+var (
+	// applePayValidationHost matches the hosts of the merchant validation URLs
+	applePayValidationHost = regexp.MustCompile(`^apple-pay-gateway(-[a-z0-9-]+)?\.apple\.com$`)
+
+	oidApplePayLeaf         = asn1.ObjectIdentifier{1, 2, 840, 113635, 100, 6, 29}
+	oidApplePayIntermediate = asn1.ObjectIdentifier{1, 2, 840, 113635, 100, 6, 2, 14}
+	oidPKCS7SignedData      = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 2}
+	oidPKCS9MessageDigest   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 4}
+	oidPKCS9SigningTime     = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 5}
+)
+
+// IApplePay is the Apple Pay client interface
+type IApplePay interface {
+	ValidateMerchant(ctx context.Context, validationURL string) (json.RawMessage, error)
+	DecryptToken(token ApplePayPaymentToken) (*ApplePayDecryptedPayment, error)
+}
+
+// ApplePayClient validates Apple Pay on the web merchant sessions and decrypts payment tokens
+type ApplePayClient struct {
+	Client         *http.Client
+	MerchantID     string
+	DomainName     string
+	DisplayName    string
+	TokenMaxAge    time.Duration
+	hasIdentity    bool
+	processingKey  *ecdsa.PrivateKey
+	roots          *x509.CertPool
+	validationHost *regexp.Regexp
+}
+
+// NewApplePayClient returns an Apple Pay client for config.
+// The merchant identity certificate is only needed by ValidateMerchant,
+// the payment processing key and the Apple root certificate by DecryptToken
+func NewApplePayClient(config *ApplePay) (IApplePay, error) {
+	if config == nil || config.MerchantID == "" {
+		return nil, ErrInvalidApplePayConfig
+	}
+
+	c := &ApplePayClient{
+		Client:         &http.Client{},
+		MerchantID:     config.MerchantID,
+		DomainName:     config.DomainName,
+		DisplayName:    config.DisplayName,
+		TokenMaxAge:    ApplePayTokenMaxAge,
+		validationHost: applePayValidationHost,
+	}
+
+	if config.MerchantIdentityCertificate != "" {
+		certificate, err := tls.X509KeyPair([]byte(config.MerchantIdentityCertificate), []byte(config.MerchantIdentityKey))
+		if err != nil {
+			return nil, fmt.Errorf("%w: merchant identity: %v", ErrInvalidApplePayConfig, err)
+		}
+		c.Client.Transport = &http.Transport{TLSClientConfig: &tls.Config{Certificates: []tls.Certificate{certificate}}}
+		c.hasIdentity = true
+	}
+
+	if config.PaymentProcessingKey != "" {
+		key, err := parseECPrivateKey(config.PaymentProcessingKey)
+		if err != nil {
+			return nil, fmt.Errorf("%w: payment processing key: %v", ErrInvalidApplePayConfig, err)
+		}
+		c.processingKey = key
+	}
+
+	if config.RootCertificate != "" {
+		c.roots = x509.NewCertPool()
+		if !c.roots.AppendCertsFromPEM([]byte(config.RootCertificate)) {
+			return nil, fmt.Errorf("%w: root certificate", ErrInvalidApplePayConfig)
+		}
+	}
+
+	return c, nil
+}
+
+// newApplePay returns an Apple Pay client, or nil when config is invalid
+func newApplePay(config *ApplePay) IApplePay {
+	client, err := NewApplePayClient(config)
+	if err != nil {
+		log.Println("Unable to init Apple Pay client: ", err)
+		return nil
+	}
+
+	return client
+}
+
+// parseECPrivateKey parses a SEC 1 or PKCS#8 EC private key, PEM encoded or raw base64
+func parseECPrivateKey(key string) (*ecdsa.PrivateKey, error) {
+	der, err := decodeKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if privateKey, err := x509.ParseECPrivateKey(der); err == nil {
+		return privateKey, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, err
+	}
+	privateKey, ok := parsed.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("not an EC key")
+	}
+
+	return privateKey, nil
+}
+
+// ValidateMerchant requests a merchant session from the validation URL of the onvalidatemerchant event,
+// over TLS authenticated by the merchant identity certificate.
+// Hand the session as is to ApplePaySession.completeMerchantValidation
+// Doc: https://developer.apple.com/documentation/apple_pay_on_the_web/apple_pay_js_api/requesting_an_apple_pay_payment_session
+func (c *ApplePayClient) ValidateMerchant(ctx context.Context, validationURL string) (json.RawMessage, error) {
+	if !c.hasIdentity {
+		return nil, ErrApplePayMerchantIdentityRequired
+	}
+	u, err := url.Parse(validationURL)
+	if err != nil || u.Scheme != "https" || !c.validationHost.MatchString(u.Hostname()) {
+		return nil, ErrApplePayValidationURL
+	}
+
+	payload := ApplePayMerchantSessionRequest{
+		MerchantIdentifier: c.MerchantID,
+		DisplayName:        c.DisplayName,
+		Initiative:         "web",
+		InitiativeContext:  c.DomainName,
+	}
+	req, err := newJSONRequest(ctx, http.MethodPost, validationURL, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	var session json.RawMessage
+	err = sendJSON(c.Client, req, &session, func(resp *http.Response, body []byte) error {
+		e := &ApplePayError{Response: resp}
+		json.Unmarshal(body, e)
+		return e
+	})
+	return session, err
+}
+
+// DecryptToken verifies the signature of an EC_v1 payment token and decrypts its payment data
+// Doc: https://developer.apple.com/documentation/passkit/apple_pay/payment_token_format_reference
+func (c *ApplePayClient) DecryptToken(token ApplePayPaymentToken) (*ApplePayDecryptedPayment, error) {
+	if c.processingKey == nil || c.roots == nil {
+		return nil, ErrApplePayProcessingKeyRequired
+	}
+	paymentData := token.PaymentData
+	if paymentData.Version != applePayVersionEC {
+		return nil, fmt.Errorf("applepay: unsupported payment token version %q", paymentData.Version)
+	}
+
+	ephemeralKey, err := base64.StdEncoding.DecodeString(paymentData.Header.EphemeralPublicKey)
+	if err != nil {
+		return nil, err
+	}
+	data, err := base64.StdEncoding.DecodeString(paymentData.Data)
+	if err != nil {
+		return nil, err
+	}
+	transactionID, err := hex.DecodeString(paymentData.Header.TransactionID)
+	if err != nil {
+		return nil, err
+	}
+	applicationData, err := hex.DecodeString(paymentData.Header.ApplicationData)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := base64.StdEncoding.DecodeString(paymentData.Signature)
+	if err != nil {
+		return nil, ErrApplePayTokenSignature
+	}
+
+	signed := bytes.Join([][]byte{ephemeralKey, data, transactionID, applicationData}, nil)
+	if err = c.verifySignature(signature, signed); err != nil {
+		return nil, err
+	}
+
+	publicKeyDER, err := x509.MarshalPKIXPublicKey(&c.processingKey.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	publicKeyHash := sha256.Sum256(publicKeyDER)
+	if base64.StdEncoding.EncodeToString(publicKeyHash[:]) != paymentData.Header.PublicKeyHash {
+		return nil, ErrApplePayKeyMismatch
+	}
+
+	plaintext, err := c.decrypt(ephemeralKey, data)
+	if err != nil {
+		return nil, err
+	}
+
+	payment := &ApplePayDecryptedPayment{PaymentMethod: token.PaymentMethod}
+	if err = json.Unmarshal(plaintext, &payment.ApplePayTokenData); err != nil {
+		return nil, err
+	}
+	return payment, nil
+}
+
+// decrypt derives the symmetric key from the ephemeral public key and opens the AES-256-GCM payment data
+func (c *ApplePayClient) decrypt(ephemeralKey, data []byte) ([]byte, error) {
+	parsed, err := x509.ParsePKIXPublicKey(ephemeralKey)
+	if err != nil {
+		return nil, err
+	}
+	ephemeral, ok := parsed.(*ecdsa.PublicKey)
+	if !ok || ephemeral.Curve != elliptic.P256() {
+		return nil, errors.New("applepay: the ephemeral public key is not a P-256 key")
+	}
+
+	x, _ := ephemeral.Curve.ScalarMult(ephemeral.X, ephemeral.Y, c.processingKey.D.Bytes())
+	sharedSecret := make([]byte, 32)
+	x.FillBytes(sharedSecret)
+
+	block, err := aes.NewCipher(applePaySymmetricKey(sharedSecret, c.MerchantID))
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCMWithNonceSize(block, 16)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := gcm.Open(nil, make([]byte, 16), data, nil)
+	if err != nil {
+		return nil, fmt.Errorf("applepay: decrypt payment data: %w", err)
+	}
+	return plaintext, nil
+}
+
+// applePaySymmetricKey is the NIST SP 800-56A single step KDF of the shared secret,
+// of which the party V info is the SHA-256 of the merchant identifier
+func applePaySymmetricKey(sharedSecret []byte, merchantID string) []byte {
+	merchantIDHash := sha256.Sum256([]byte(merchantID))
+
+	h := sha256.New()
+	h.Write([]byte{0, 0, 0, 1})
+	h.Write(sharedSecret)
+	h.Write([]byte("\x0did-aes256-GCMApple"))
+	h.Write(merchantIDHash[:])
+	return h.Sum(nil)
+}
+
+// pkcs7ContentInfo is a CMS ContentInfo
+type pkcs7ContentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue `asn1:"explicit,tag:0"`
+}
+
+// pkcs7SignedData is a CMS SignedData with a detached content
+type pkcs7SignedData struct {
+	Version          int
+	DigestAlgorithms asn1.RawValue
+	EncapContentInfo struct {
+		ContentType asn1.ObjectIdentifier
+		Content     asn1.RawValue `asn1:"optional,explicit,tag:0"`
+	}
+	Certificates asn1.RawValue     `asn1:"optional,tag:0"`
+	CRLs         asn1.RawValue     `asn1:"optional,tag:1"`
+	SignerInfos  []pkcs7SignerInfo `asn1:"set"`
+}
+
+// pkcs7SignerInfo is a CMS SignerInfo
+type pkcs7SignerInfo struct {
+	Version            int
+	SID                asn1.RawValue
+	DigestAlgorithm    pkix.AlgorithmIdentifier
+	SignedAttrs        asn1.RawValue `asn1:"optional,tag:0"`
+	SignatureAlgorithm pkix.AlgorithmIdentifier
+	Signature          []byte
+	UnsignedAttrs      asn1.RawValue `asn1:"optional,tag:1"`
+}
+
+// pkcs7Attribute is a CMS Attribute
+type pkcs7Attribute struct {
+	Type   asn1.ObjectIdentifier
+	Values asn1.RawValue
+}
+
+// verifySignature checks the detached PKCS#7 signature of a payment token: the leaf and intermediate
+// certificates must chain to the Apple root, the signed attributes must carry the digest of content and
+// a signing time within TokenMaxAge, and be signed by the leaf certificate
+func (c *ApplePayClient) verifySignature(signature, content []byte) error {
+	var contentInfo pkcs7ContentInfo
+	if _, err := asn1.Unmarshal(signature, &contentInfo); err != nil || !contentInfo.ContentType.Equal(oidPKCS7SignedData) {
+		return ErrApplePayTokenSignature
+	}
+	var signedData pkcs7SignedData
+	if _, err := asn1.Unmarshal(contentInfo.Content.Bytes, &signedData); err != nil || len(signedData.SignerInfos) != 1 {
+		return ErrApplePayTokenSignature
+	}
+
+	certificates, err := x509.ParseCertificates(signedData.Certificates.Bytes)
+	if err != nil {
+		return ErrApplePayTokenSignature
+	}
+	var leaf, intermediate *x509.Certificate
+	for _, certificate := range certificates {
+		for _, extension := range certificate.Extensions {
+			switch {
+			case extension.Id.Equal(oidApplePayLeaf):
+				leaf = certificate
+			case extension.Id.Equal(oidApplePayIntermediate):
+				intermediate = certificate
+			}
+		}
+	}
+	if leaf == nil || intermediate == nil {
+		return ErrApplePayTokenSignature
+	}
+
+	signerInfo := signedData.SignerInfos[0]
+	var messageDigest []byte
+	var signingTime time.Time
+	rest := signerInfo.SignedAttrs.Bytes
+	for len(rest) > 0 {
+		var attribute pkcs7Attribute
+		if rest, err = asn1.Unmarshal(rest, &attribute); err != nil {
+			return ErrApplePayTokenSignature
+		}
+		switch {
+		case attribute.Type.Equal(oidPKCS9MessageDigest):
+			asn1.Unmarshal(attribute.Values.Bytes, &messageDigest)
+		case attribute.Type.Equal(oidPKCS9SigningTime):
+			asn1.Unmarshal(attribute.Values.Bytes, &signingTime)
+		}
+	}
+
+	digest := sha256.Sum256(content)
+	if !bytes.Equal(messageDigest, digest[:]) {
+		return ErrApplePayTokenSignature
+	}
+	if signingTime.IsZero() || time.Since(signingTime) > c.TokenMaxAge || time.Until(signingTime) > c.TokenMaxAge {
+		return ErrApplePayTokenSignature
+	}
+
+	intermediates := x509.NewCertPool()
+	intermediates.AddCert(intermediate)
+	_, err = leaf.Verify(x509.VerifyOptions{
+		Roots:         c.roots,
+		Intermediates: intermediates,
+		CurrentTime:   signingTime,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	if err != nil {
+		return ErrApplePayTokenSignature
+	}
+
+	publicKey, ok := leaf.PublicKey.(*ecdsa.PublicKey)
+	if !ok {
+		return ErrApplePayTokenSignature
+	}
+	// The signed attributes are signed with their SET OF tag instead of the implicit [0] tag
+	signedAttrs := append([]byte{0x31}, signerInfo.SignedAttrs.FullBytes[1:]...)
+	hashed := sha256.Sum256(signedAttrs)
+	if !ecdsa.VerifyASN1(publicKey, hashed[:], signerInfo.Signature) {
+		return ErrApplePayTokenSignature
+	}
+
+	return nil
+}
@@ -0,0 +1,119 @@
+package payment
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// BitPay invoice statuses
+// Doc: https://developer.bitpay.com/reference/invoice-states
+const (
+	BitPayInvoiceStatusNew       = "new"
+	BitPayInvoiceStatusPaid      = "paid"
+	BitPayInvoiceStatusConfirmed = "confirmed"
+	BitPayInvoiceStatusComplete  = "complete"
+	BitPayInvoiceStatusExpired   = "expired"
+	BitPayInvoiceStatusInvalid   = "invalid"
+)
+
+// BitPayError is the error returned by the BitPay API
+type BitPayError struct {
+	Response *http.Response `json:"-"`
+	Status   string         `json:"status"`
+	Code     string         `json:"code"`
+	Message  string         `json:"error"`
+}
+
+func (e *BitPayError) Error() string {
+	status := 0
+	if e.Response != nil {
+		status = e.Response.StatusCode
+	}
+	return fmt.Sprintf("bitpay: %d %s: %s", status, e.Code, e.Message)
+}
+
+// Is reports whether the error belongs to the target error category
+func (e *BitPayError) Is(target error) bool {
+	if e.Response == nil {
+		return false
+	}
+	return statusErrorIs(e.Response.StatusCode, target)
+}
+
+// BitPayBuyer struct
+type BitPayBuyer struct {
+	Name   string `json:"name,omitempty"`
+	Email  string `json:"email,omitempty"`
+	Notify bool   `json:"notify,omitempty"`
+}
+
+// BitPayInvoiceRequest struct, Token is set by the client
+// Doc: https://developer.bitpay.com/reference/create-an-invoice
+type BitPayInvoiceRequest struct {
+	Token                 string       `json:"token"`
+	Price                 float64      `json:"price"`
+	Currency              string       `json:"currency"`
+	OrderID               string       `json:"orderId,omitempty"`
+	ItemDesc              string       `json:"itemDesc,omitempty"`
+	NotificationURL       string       `json:"notificationURL,omitempty"`
+	RedirectURL           string       `json:"redirectURL,omitempty"`
+	CloseURL              string       `json:"closeURL,omitempty"`
+	PosData               string       `json:"posData,omitempty"`
+	TransactionSpeed      string       `json:"transactionSpeed,omitempty"` // high, medium or low
+	ExtendedNotifications bool         `json:"extendedNotifications,omitempty"`
+	Buyer                 *BitPayBuyer `json:"buyer,omitempty"`
+}
+
+// BitPayInvoice struct
+type BitPayInvoice struct {
+	ID                  string      `json:"id"`
+	URL                 string      `json:"url"`
+	Status              string      `json:"status"`
+	Price               float64     `json:"price"`
+	Currency            string      `json:"currency"`
+	OrderID             string      `json:"orderId,omitempty"`
+	PosData             string      `json:"posData,omitempty"`
+	InvoiceTime         int64       `json:"invoiceTime"`     // Unix time in milliseconds
+	ExpirationTime      int64       `json:"expirationTime"`  // Unix time in milliseconds
+	ExceptionStatus     interface{} `json:"exceptionStatus"` // false, or paidPartial/paidOver
+	TransactionCurrency string      `json:"transactionCurrency,omitempty"`
+	AmountPaid          int64       `json:"amountPaid,omitempty"` // In the smallest unit of TransactionCurrency
+}
+
+// BitPayRefundRequest struct, Token is set by the client
+// Doc: https://developer.bitpay.com/reference/create-a-refund-request
+type BitPayRefundRequest struct {
+	Token              string  `json:"token"`
+	InvoiceID          string  `json:"invoiceId"`
+	Amount             float64 `json:"amount"`
+	Preview            bool    `json:"preview,omitempty"`
+	Immediate          bool    `json:"immediate,omitempty"`
+	BuyerPaysRefundFee bool    `json:"buyerPaysRefundFee,omitempty"`
+	Reference          string  `json:"reference,omitempty"`
+}
+
+// BitPayRefund struct
+type BitPayRefund struct {
+	ID          string   `json:"id"`
+	Invoice     string   `json:"invoice"`
+	Status      string   `json:"status"` // preview, created, pending, canceled, success or failure
+	Amount      float64  `json:"amount"`
+	Currency    string   `json:"currency"`
+	RequestDate JSONTime `json:"requestDate"`
+	Reference   string   `json:"reference,omitempty"`
+}
+
+// BitPayIPN is an instant payment notification
+type BitPayIPN struct {
+	Event struct {
+		Code int    `json:"code"`
+		Name string `json:"name"` // e.g. invoice_paidInFull, invoice_confirmed, invoice_expired
+	} `json:"event"`
+	Data struct {
+		ID      string  `json:"id"`
+		URL     string  `json:"url"`
+		Status  string  `json:"status"`
+		Price   float64 `json:"price"`
+		OrderID string  `json:"orderId,omitempty"`
+	} `json:"data"`
+}
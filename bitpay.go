@@ -0,0 +1,213 @@
+package payment
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+)
+
+const (
+	// BitPayAPIBaseTest points to the test version of the BitPay API
+	BitPayAPIBaseTest = "https://test.bitpay.com"
+
+	// BitPayAPIBaseLive points to the live version of the BitPay API
+	BitPayAPIBaseLive = "https://bitpay.com"
+
+	// bitPayAPIVersion is sent in the X-Accept-Version header
+	bitPayAPIVersion = "2.0.0"
+)
+
+var (
+	// ErrInvalidBitPayConfig is returned when a BitPay client is created without POSToken or APIBase
+	ErrInvalidBitPayConfig = errors.New("bitpay: POSToken and APIBase are required to create a client")
+
+	// ErrBitPaySignerRequired is returned by the merchant facade calls when the client has no MerchantToken or Signer
+	ErrBitPaySignerRequired = errors.New("bitpay: MerchantToken and Signer are required for merchant facade calls")
+
+	// ErrBitPayIPNMismatch is returned by HandleIPN when the notification does not match the invoice
+	ErrBitPayIPNMismatch = errors.New("bitpay: notification does not match the invoice")
+)
+
+// BitPaySigner signs the merchant facade requests with the private key paired with MerchantToken.
+// BitPay uses ECDSA over secp256k1, which the standard library does not provide,
+// so the signature is delegated to an implementation of your choice
+// Doc: https://developer.bitpay.com/reference/concepts
+type BitPaySigner interface {
+	// PublicKey returns the compressed public key, hex encoded, sent in the X-Identity header
+	PublicKey() string
+	// Sign returns the hex encoded DER signature of the SHA-256 hash of message
+	Sign(message []byte) (string, error)
+}
+
+// IBitPay is the BitPay client interface
+type IBitPay interface {
+	CreateInvoice(ctx context.Context, invoice BitPayInvoiceRequest) (*BitPayInvoice, error)
+	GetInvoice(ctx context.Context, invoiceID string) (*BitPayInvoice, error)
+	WaitForInvoice(ctx context.Context, invoiceID string, pollOptions PollOptions) (*BitPayInvoice, error)
+	CreateRefund(ctx context.Context, refund BitPayRefundRequest) (*BitPayRefund, error)
+	GetRefund(ctx context.Context, refundID string) (*BitPayRefund, error)
+	HandleIPN(req *http.Request) (*BitPayIPN, *BitPayInvoice, error)
+}
+
+// BitPayClient represents a BitPay API client
+type BitPayClient struct {
+	Client        *http.Client
+	APIBase       string
+	POSToken      string
+	MerchantToken string
+	Signer        BitPaySigner
+}
+
+// NewBitPayClient returns a BitPay client for config.
+// APIBase is a base API URL, for testing you can use BitPayAPIBaseTest.
+// Refunds need config.MerchantToken and signer, which may otherwise be nil
+func NewBitPayClient(config *BitPay, signer BitPaySigner) (IBitPay, error) {
+	if config == nil || config.POSToken == "" || config.APIBase == "" {
+		return nil, ErrInvalidBitPayConfig
+	}
+
+	return &BitPayClient{
+		Client:        &http.Client{},
+		APIBase:       config.APIBase,
+		POSToken:      config.POSToken,
+		MerchantToken: config.MerchantToken,
+		Signer:        signer,
+	}, nil
+}
+
+// newBitPay returns a BitPay client without merchant facade, or nil when config is invalid
+func newBitPay(config *BitPay) IBitPay {
+	client, err := NewBitPayClient(config, nil)
+	if err != nil {
+		log.Println("Unable to init BitPay client: ", err)
+		return nil
+	}
+
+	return client
+}
+
+// newBitPayError decodes the error returned by the BitPay API
+func newBitPayError(resp *http.Response, body []byte) error {
+	errResp := &BitPayError{Response: resp}
+	json.Unmarshal(body, errResp)
+	return errResp
+}
+
+// send makes a request and decodes the data of the response into v.
+// Requests are signed when signed is true
+func (c *BitPayClient) send(ctx context.Context, method, path string, payload, v interface{}, signed bool) error {
+	req, err := newJSONRequest(ctx, method, c.APIBase+path, payload)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Accept-Version", bitPayAPIVersion)
+
+	if signed {
+		message := []byte(req.URL.String())
+		if req.GetBody != nil {
+			body, _ := req.GetBody()
+			buf := &bytes.Buffer{}
+			buf.ReadFrom(body)
+			message = append(message, buf.Bytes()...)
+		}
+		signature, err := c.Signer.Sign(message)
+		if err != nil {
+			return fmt.Errorf("bitpay: sign request: %w", err)
+		}
+		req.Header.Set("X-Identity", c.Signer.PublicKey())
+		req.Header.Set("X-Signature", signature)
+	}
+
+	response := &struct {
+		Data interface{} `json:"data"`
+	}{Data: v}
+	return sendJSON(c.Client, req, response, newBitPayError)
+}
+
+// CreateInvoice creates an invoice with the POS facade, the buyer pays it at URL
+// Endpoint: POST /invoices
+func (c *BitPayClient) CreateInvoice(ctx context.Context, invoice BitPayInvoiceRequest) (*BitPayInvoice, error) {
+	invoice.Token = c.POSToken
+	response := &BitPayInvoice{}
+	err := c.send(ctx, http.MethodPost, "/invoices", invoice, response, false)
+	return response, err
+}
+
+// GetInvoice returns an invoice by ID with the POS facade
+// Endpoint: GET /invoices/ID
+func (c *BitPayClient) GetInvoice(ctx context.Context, invoiceID string) (*BitPayInvoice, error) {
+	response := &BitPayInvoice{}
+	err := c.send(ctx, http.MethodGet, "/invoices/"+url.PathEscape(invoiceID), nil, response, false)
+	return response, err
+}
+
+// WaitForInvoice polls an invoice until it is confirmed, complete, expired or invalid
+func (c *BitPayClient) WaitForInvoice(ctx context.Context, invoiceID string, pollOptions PollOptions) (*BitPayInvoice, error) {
+	for {
+		invoice, err := c.GetInvoice(ctx, invoiceID)
+		if err != nil {
+			return invoice, err
+		}
+
+		switch invoice.Status {
+		case BitPayInvoiceStatusConfirmed, BitPayInvoiceStatusComplete, BitPayInvoiceStatusExpired, BitPayInvoiceStatusInvalid:
+			return invoice, nil
+		}
+
+		if err = pollOptions.wait(ctx); err != nil {
+			return invoice, err
+		}
+	}
+}
+
+// CreateRefund refunds a paid invoice, it needs the merchant facade
+// Endpoint: POST /refunds
+func (c *BitPayClient) CreateRefund(ctx context.Context, refund BitPayRefundRequest) (*BitPayRefund, error) {
+	if c.MerchantToken == "" || c.Signer == nil {
+		return nil, ErrBitPaySignerRequired
+	}
+
+	refund.Token = c.MerchantToken
+	response := &BitPayRefund{}
+	err := c.send(ctx, http.MethodPost, "/refunds", refund, response, true)
+	return response, err
+}
+
+// GetRefund returns a refund by ID, it needs the merchant facade
+// Endpoint: GET /refunds/ID
+func (c *BitPayClient) GetRefund(ctx context.Context, refundID string) (*BitPayRefund, error) {
+	if c.MerchantToken == "" || c.Signer == nil {
+		return nil, ErrBitPaySignerRequired
+	}
+
+	response := &BitPayRefund{}
+	path := "/refunds/" + url.PathEscape(refundID) + "?token=" + url.QueryEscape(c.MerchantToken)
+	err := c.send(ctx, http.MethodGet, path, nil, response, true)
+	return response, err
+}
+
+// HandleIPN decodes an instant payment notification and returns it with the invoice it is about.
+// IPNs are not signed, so the invoice is fetched from the API and its status must match the notification:
+// act on the returned invoice rather than on the notification
+// Doc: https://developer.bitpay.com/reference/instant-payment-notifications
+func (c *BitPayClient) HandleIPN(req *http.Request) (*BitPayIPN, *BitPayInvoice, error) {
+	ipn := &BitPayIPN{}
+	if err := json.NewDecoder(req.Body).Decode(ipn); err != nil {
+		return nil, nil, fmt.Errorf("bitpay: decode notification: %w", err)
+	}
+
+	invoice, err := c.GetInvoice(req.Context(), ipn.Data.ID)
+	if err != nil {
+		return ipn, nil, err
+	}
+	if ipn.Data.Status != "" && ipn.Data.Status != invoice.Status {
+		return ipn, invoice, ErrBitPayIPNMismatch
+	}
+
+	return ipn, invoice, nil
+}
@@ -0,0 +1,162 @@
+package payment
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// Coinbase Commerce pricing types
+const (
+	CoinbasePricingTypeFixedPrice = "fixed_price"
+	CoinbasePricingTypeNoPrice    = "no_price"
+)
+
+// Coinbase Commerce charge statuses, as found in the charge timeline
+const (
+	CoinbaseChargeStatusNew        = "NEW"
+	CoinbaseChargeStatusPending    = "PENDING"
+	CoinbaseChargeStatusCompleted  = "COMPLETED"
+	CoinbaseChargeStatusExpired    = "EXPIRED"
+	CoinbaseChargeStatusUnresolved = "UNRESOLVED"
+	CoinbaseChargeStatusResolved   = "RESOLVED"
+	CoinbaseChargeStatusCanceled   = "CANCELED"
+)
+
+// Coinbase Commerce webhook event types
+const (
+	CoinbaseEventChargeCreated   = "charge:created"
+	CoinbaseEventChargeConfirmed = "charge:confirmed"
+	CoinbaseEventChargeFailed    = "charge:failed"
+	CoinbaseEventChargeDelayed   = "charge:delayed"
+	CoinbaseEventChargePending   = "charge:pending"
+	CoinbaseEventChargeResolved  = "charge:resolved"
+)
+
+// CoinbaseError is the error returned by the Coinbase APIs
+type CoinbaseError struct {
+	Response *http.Response `json:"-"`
+	Type     string         `json:"type"`
+	Message  string         `json:"message"`
+}
+
+func (e *CoinbaseError) Error() string {
+	status := 0
+	if e.Response != nil {
+		status = e.Response.StatusCode
+	}
+	return fmt.Sprintf("coinbase commerce: %d %s: %s", status, e.Type, e.Message)
+}
+
+// Is reports whether the error belongs to the target error category
+func (e *CoinbaseError) Is(target error) bool {
+	if e.Response == nil {
+		return false
+	}
+	return statusErrorIs(e.Response.StatusCode, target)
+}
+
+// CoinbaseMoney is an amount in a fiat or crypto currency
+type CoinbaseMoney struct {
+	Amount   string `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+// CoinbaseChargeRequest struct
+// Doc: https://docs.cloud.coinbase.com/commerce/reference/createcharge
+type CoinbaseChargeRequest struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	PricingType string            `json:"pricing_type"`
+	LocalPrice  *CoinbaseMoney    `json:"local_price,omitempty"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+	RedirectURL string            `json:"redirect_url,omitempty"`
+	CancelURL   string            `json:"cancel_url,omitempty"`
+}
+
+// CoinbaseTimelineEntry is a status change of a charge
+type CoinbaseTimelineEntry struct {
+	Time    JSONTime `json:"time"`
+	Status  string   `json:"status"`
+	Context string   `json:"context,omitempty"` // e.g. UNDERPAID, OVERPAID, DELAYED
+}
+
+// CoinbasePayment is a payment made to a charge on a blockchain
+type CoinbasePayment struct {
+	Network       string `json:"network"`
+	TransactionID string `json:"transaction_id"`
+	Status        string `json:"status"`
+	Value         struct {
+		Local  CoinbaseMoney `json:"local"`
+		Crypto CoinbaseMoney `json:"crypto"`
+	} `json:"value"`
+}
+
+// CoinbaseCharge struct
+type CoinbaseCharge struct {
+	ID          string                   `json:"id"`
+	Code        string                   `json:"code"`
+	Name        string                   `json:"name"`
+	Description string                   `json:"description"`
+	HostedURL   string                   `json:"hosted_url"`
+	CreatedAt   JSONTime                 `json:"created_at"`
+	ExpiresAt   JSONTime                 `json:"expires_at"`
+	ConfirmedAt *JSONTime                `json:"confirmed_at,omitempty"`
+	PricingType string                   `json:"pricing_type"`
+	Pricing     map[string]CoinbaseMoney `json:"pricing,omitempty"` // keyed by local, bitcoin, ethereum...
+	Addresses   map[string]string        `json:"addresses,omitempty"`
+	Timeline    []CoinbaseTimelineEntry  `json:"timeline"`
+	Payments    []CoinbasePayment        `json:"payments"`
+	Metadata    map[string]string        `json:"metadata,omitempty"`
+}
+
+// Status returns the last status of the charge timeline
+func (c *CoinbaseCharge) Status() string {
+	if len(c.Timeline) == 0 {
+		return ""
+	}
+	return c.Timeline[len(c.Timeline)-1].Status
+}
+
+// CoinbaseCheckoutRequest struct
+type CoinbaseCheckoutRequest struct {
+	Name          string         `json:"name"`
+	Description   string         `json:"description"`
+	PricingType   string         `json:"pricing_type"`
+	LocalPrice    *CoinbaseMoney `json:"local_price,omitempty"`
+	RequestedInfo []string       `json:"requested_info,omitempty"` // name and/or email
+}
+
+// CoinbaseCheckout struct
+type CoinbaseCheckout struct {
+	ID            string         `json:"id"`
+	Name          string         `json:"name"`
+	Description   string         `json:"description"`
+	PricingType   string         `json:"pricing_type"`
+	LocalPrice    *CoinbaseMoney `json:"local_price,omitempty"`
+	RequestedInfo []string       `json:"requested_info,omitempty"`
+}
+
+// CoinbaseExchangeRates are the rates of Currency, keyed by the other currencies
+type CoinbaseExchangeRates struct {
+	Currency string            `json:"currency"`
+	Rates    map[string]string `json:"rates"`
+}
+
+// CoinbaseWebhookEvent is the event of a webhook, Data holds the charge for charge events
+type CoinbaseWebhookEvent struct {
+	ID         string          `json:"id"`
+	Type       string          `json:"type"`
+	APIVersion string          `json:"api_version"`
+	CreatedAt  JSONTime        `json:"created_at"`
+	Data       json.RawMessage `json:"data"`
+}
+
+// Charge decodes the charge of a charge event
+func (e *CoinbaseWebhookEvent) Charge() (*CoinbaseCharge, error) {
+	charge := &CoinbaseCharge{}
+	if err := json.Unmarshal(e.Data, charge); err != nil {
+		return nil, err
+	}
+	return charge, nil
+}
@@ -0,0 +1,231 @@
+package payment
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+)
+
+const (
+	// CoinbaseCommerceAPIBase is the base URL of the Coinbase Commerce API
+	CoinbaseCommerceAPIBase = "https://api.commerce.coinbase.com"
+
+	// CoinbaseExchangeRatesAPIBase is the base URL of the public Coinbase API serving exchange rates
+	CoinbaseExchangeRatesAPIBase = "https://api.coinbase.com"
+
+	// coinbaseCommerceAPIVersion is sent in the X-CC-Version header
+	coinbaseCommerceAPIVersion = "2018-03-22"
+)
+
+var (
+	// ErrInvalidCoinbaseCommerceConfig is returned when a Coinbase Commerce client is created without APIKey
+	ErrInvalidCoinbaseCommerceConfig = errors.New("coinbase commerce: APIKey is required to create a client")
+
+	// ErrCoinbaseWebhookSignature is returned by VerifyCoinbaseWebhook when the signature does not match
+	ErrCoinbaseWebhookSignature = errors.New("coinbase commerce: invalid webhook signature")
+)
+
+// ICoinbaseCommerce is the Coinbase Commerce client interface
+type ICoinbaseCommerce interface {
+	CreateCharge(ctx context.Context, charge CoinbaseChargeRequest) (*CoinbaseCharge, error)
+	GetCharge(ctx context.Context, codeOrID string) (*CoinbaseCharge, error)
+	CancelCharge(ctx context.Context, codeOrID string) (*CoinbaseCharge, error)
+	ResolveCharge(ctx context.Context, codeOrID string) (*CoinbaseCharge, error)
+	CreateCheckout(ctx context.Context, checkout CoinbaseCheckoutRequest) (*CoinbaseCheckout, error)
+	GetCheckout(ctx context.Context, checkoutID string) (*CoinbaseCheckout, error)
+	UpdateCheckout(ctx context.Context, checkoutID string, checkout CoinbaseCheckoutRequest) (*CoinbaseCheckout, error)
+	DeleteCheckout(ctx context.Context, checkoutID string) error
+	ListCheckouts(ctx context.Context) ([]CoinbaseCheckout, error)
+	GetExchangeRates(ctx context.Context, currency string) (*CoinbaseExchangeRates, error)
+}
+
+// CoinbaseCommerceClient represents a Coinbase Commerce API client
+type CoinbaseCommerceClient struct {
+	Client               *http.Client
+	APIKey               string
+	APIBase              string
+	ExchangeRatesAPIBase string
+}
+
+// NewCoinbaseCommerceClient returns a Coinbase Commerce client for config.
+// APIBase defaults to CoinbaseCommerceAPIBase and ExchangeRatesAPIBase to CoinbaseExchangeRatesAPIBase
+func NewCoinbaseCommerceClient(config *CoinbaseCommerce) (ICoinbaseCommerce, error) {
+	if config == nil || config.APIKey == "" {
+		return nil, ErrInvalidCoinbaseCommerceConfig
+	}
+
+	client := &CoinbaseCommerceClient{
+		Client:               &http.Client{},
+		APIKey:               config.APIKey,
+		APIBase:              config.APIBase,
+		ExchangeRatesAPIBase: config.ExchangeRatesAPIBase,
+	}
+	if client.APIBase == "" {
+		client.APIBase = CoinbaseCommerceAPIBase
+	}
+	if client.ExchangeRatesAPIBase == "" {
+		client.ExchangeRatesAPIBase = CoinbaseExchangeRatesAPIBase
+	}
+
+	return client, nil
+}
+
+// newCoinbaseCommerce returns a Coinbase Commerce client, or nil when config is invalid
+func newCoinbaseCommerce(config *CoinbaseCommerce) ICoinbaseCommerce {
+	client, err := NewCoinbaseCommerceClient(config)
+	if err != nil {
+		log.Println("Unable to init Coinbase Commerce client: ", err)
+		return nil
+	}
+
+	return client
+}
+
+// newCoinbaseError decodes the error returned by the Coinbase APIs
+func newCoinbaseError(resp *http.Response, body []byte) error {
+	errResp := &CoinbaseError{Response: resp}
+	var aux struct {
+		Error *CoinbaseError `json:"error"`
+	}
+	aux.Error = errResp
+	json.Unmarshal(body, &aux)
+	return errResp
+}
+
+// send makes an authenticated request to the Commerce API and decodes the data of the response into v
+func (c *CoinbaseCommerceClient) send(ctx context.Context, method, path string, payload, v interface{}) error {
+	req, err := newJSONRequest(ctx, method, c.APIBase+path, payload)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-CC-Api-Key", c.APIKey)
+	req.Header.Set("X-CC-Version", coinbaseCommerceAPIVersion)
+
+	if v == nil {
+		return sendJSON(c.Client, req, nil, newCoinbaseError)
+	}
+	response := &struct {
+		Data interface{} `json:"data"`
+	}{Data: v}
+	return sendJSON(c.Client, req, response, newCoinbaseError)
+}
+
+// CreateCharge creates a charge, the customer pays it at HostedURL
+// Endpoint: POST /charges
+func (c *CoinbaseCommerceClient) CreateCharge(ctx context.Context, charge CoinbaseChargeRequest) (*CoinbaseCharge, error) {
+	response := &CoinbaseCharge{}
+	err := c.send(ctx, http.MethodPost, "/charges", charge, response)
+	return response, err
+}
+
+// GetCharge returns a charge by code or ID
+// Endpoint: GET /charges/CODE
+func (c *CoinbaseCommerceClient) GetCharge(ctx context.Context, codeOrID string) (*CoinbaseCharge, error) {
+	response := &CoinbaseCharge{}
+	err := c.send(ctx, http.MethodGet, "/charges/"+url.PathEscape(codeOrID), nil, response)
+	return response, err
+}
+
+// CancelCharge cancels a charge that has not been paid yet
+// Endpoint: POST /charges/CODE/cancel
+func (c *CoinbaseCommerceClient) CancelCharge(ctx context.Context, codeOrID string) (*CoinbaseCharge, error) {
+	response := &CoinbaseCharge{}
+	err := c.send(ctx, http.MethodPost, "/charges/"+url.PathEscape(codeOrID)+"/cancel", nil, response)
+	return response, err
+}
+
+// ResolveCharge marks an underpaid, overpaid or delayed charge as resolved
+// Endpoint: POST /charges/CODE/resolve
+func (c *CoinbaseCommerceClient) ResolveCharge(ctx context.Context, codeOrID string) (*CoinbaseCharge, error) {
+	response := &CoinbaseCharge{}
+	err := c.send(ctx, http.MethodPost, "/charges/"+url.PathEscape(codeOrID)+"/resolve", nil, response)
+	return response, err
+}
+
+// CreateCheckout creates a checkout, a reusable page creating a charge for each customer
+// Endpoint: POST /checkouts
+func (c *CoinbaseCommerceClient) CreateCheckout(ctx context.Context, checkout CoinbaseCheckoutRequest) (*CoinbaseCheckout, error) {
+	response := &CoinbaseCheckout{}
+	err := c.send(ctx, http.MethodPost, "/checkouts", checkout, response)
+	return response, err
+}
+
+// GetCheckout returns a checkout by ID
+// Endpoint: GET /checkouts/ID
+func (c *CoinbaseCommerceClient) GetCheckout(ctx context.Context, checkoutID string) (*CoinbaseCheckout, error) {
+	response := &CoinbaseCheckout{}
+	err := c.send(ctx, http.MethodGet, "/checkouts/"+url.PathEscape(checkoutID), nil, response)
+	return response, err
+}
+
+// UpdateCheckout replaces a checkout
+// Endpoint: PUT /checkouts/ID
+func (c *CoinbaseCommerceClient) UpdateCheckout(ctx context.Context, checkoutID string, checkout CoinbaseCheckoutRequest) (*CoinbaseCheckout, error) {
+	response := &CoinbaseCheckout{}
+	err := c.send(ctx, http.MethodPut, "/checkouts/"+url.PathEscape(checkoutID), checkout, response)
+	return response, err
+}
+
+// DeleteCheckout deletes a checkout
+// Endpoint: DELETE /checkouts/ID
+func (c *CoinbaseCommerceClient) DeleteCheckout(ctx context.Context, checkoutID string) error {
+	return c.send(ctx, http.MethodDelete, "/checkouts/"+url.PathEscape(checkoutID), nil, nil)
+}
+
+// ListCheckouts returns the checkouts of the account
+// Endpoint: GET /checkouts
+func (c *CoinbaseCommerceClient) ListCheckouts(ctx context.Context) ([]CoinbaseCheckout, error) {
+	var response []CoinbaseCheckout
+	err := c.send(ctx, http.MethodGet, "/checkouts", nil, &response)
+	return response, err
+}
+
+// GetExchangeRates returns the exchange rates of currency, e.g. BTC, against every other currency.
+// It uses the public Coinbase API, the API key is not sent
+// Endpoint: GET https://api.coinbase.com/v2/exchange-rates
+func (c *CoinbaseCommerceClient) GetExchangeRates(ctx context.Context, currency string) (*CoinbaseExchangeRates, error) {
+	req, err := newJSONRequest(ctx, http.MethodGet, c.ExchangeRatesAPIBase+"/v2/exchange-rates?currency="+url.QueryEscape(currency), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &struct {
+		Data CoinbaseExchangeRates `json:"data"`
+	}{}
+	err = sendJSON(c.Client, req, response, newCoinbaseError)
+	return &response.Data, err
+}
+
+// VerifyCoinbaseWebhook checks the X-CC-Webhook-Signature header of a webhook against the shared secret
+// of the webhook subscription and returns the decoded event
+// Doc: https://docs.cloud.coinbase.com/commerce/docs/webhooks-security
+func VerifyCoinbaseWebhook(req *http.Request, sharedSecret string) (*CoinbaseWebhookEvent, error) {
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	mac := hmac.New(sha256.New, []byte(sharedSecret))
+	mac.Write(body)
+	signature, err := hex.DecodeString(req.Header.Get("X-CC-Webhook-Signature"))
+	if err != nil || !hmac.Equal(signature, mac.Sum(nil)) {
+		return nil, ErrCoinbaseWebhookSignature
+	}
+
+	payload := &struct {
+		Event CoinbaseWebhookEvent `json:"event"`
+	}{}
+	if err = json.Unmarshal(body, payload); err != nil {
+		return nil, fmt.Errorf("coinbase commerce: decode webhook: %w", err)
+	}
+
+	return &payload.Event, nil
+}
@@ -0,0 +1,178 @@
+package payment
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// Dwolla customer types
+const (
+	DwollaCustomerTypePersonal    = "personal"
+	DwollaCustomerTypeBusiness    = "business"
+	DwollaCustomerTypeReceiveOnly = "receive-only"
+	DwollaCustomerTypeUnverified  = "unverified"
+)
+
+// Dwolla transfer statuses
+const (
+	DwollaTransferStatusPending   = "pending"
+	DwollaTransferStatusProcessed = "processed"
+	DwollaTransferStatusFailed    = "failed"
+	DwollaTransferStatusCancelled = "cancelled"
+)
+
+// DwollaError is the error returned by the Dwolla API
+// Doc: https://developers.dwolla.com/docs/balance/api-reference/api-fundamentals/errors
+type DwollaError struct {
+	Response *http.Response `json:"-"`
+	Code     string         `json:"code"`
+	Message  string         `json:"message"`
+	Embedded struct {
+		Errors []DwollaErrorDetail `json:"errors"`
+	} `json:"_embedded"`
+}
+
+// DwollaErrorDetail is an invalid field of a ValidationError
+type DwollaErrorDetail struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Path    string `json:"path"`
+}
+
+func (e *DwollaError) Error() string {
+	status := 0
+	if e.Response != nil {
+		status = e.Response.StatusCode
+	}
+	return fmt.Sprintf("dwolla: %d %s: %s, %+v", status, e.Code, e.Message, e.Embedded.Errors)
+}
+
+// Is reports whether the error belongs to the target error category
+func (e *DwollaError) Is(target error) bool {
+	if e.Code == "ValidationError" && target == ErrValidation {
+		return true
+	}
+	if e.Response == nil {
+		return false
+	}
+	return statusErrorIs(e.Response.StatusCode, target)
+}
+
+// DwollaToken is the response of the token endpoint
+type DwollaToken struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+// DwollaLink struct
+type DwollaLink struct {
+	Href string `json:"href"`
+}
+
+// DwollaCustomerRequest struct
+// Doc: https://developers.dwolla.com/docs/balance/api-reference/customers/create-a-customer
+type DwollaCustomerRequest struct {
+	FirstName     string `json:"firstName"`
+	LastName      string `json:"lastName"`
+	Email         string `json:"email"`
+	Type          string `json:"type,omitempty"`
+	IPAddress     string `json:"ipAddress,omitempty"`
+	BusinessName  string `json:"businessName,omitempty"`
+	Address1      string `json:"address1,omitempty"`
+	Address2      string `json:"address2,omitempty"`
+	City          string `json:"city,omitempty"`
+	State         string `json:"state,omitempty"`
+	PostalCode    string `json:"postalCode,omitempty"`
+	DateOfBirth   string `json:"dateOfBirth,omitempty"` // YYYY-MM-DD
+	SSN           string `json:"ssn,omitempty"`
+	CorrelationID string `json:"correlationId,omitempty"`
+}
+
+// DwollaCustomer struct
+type DwollaCustomer struct {
+	ID        string                `json:"id"`
+	FirstName string                `json:"firstName"`
+	LastName  string                `json:"lastName"`
+	Email     string                `json:"email"`
+	Type      string                `json:"type"`
+	Status    string                `json:"status"`
+	Created   JSONTime              `json:"created"`
+	Links     map[string]DwollaLink `json:"_links"`
+}
+
+// DwollaFundingSourceRequest struct, set either the bank account details or PlaidToken
+type DwollaFundingSourceRequest struct {
+	RoutingNumber   string `json:"routingNumber,omitempty"`
+	AccountNumber   string `json:"accountNumber,omitempty"`
+	BankAccountType string `json:"bankAccountType,omitempty"` // checking or savings
+	Name            string `json:"name"`
+	PlaidToken      string `json:"plaidToken,omitempty"` // Plaid processor token
+}
+
+// DwollaFundingSource struct
+type DwollaFundingSource struct {
+	ID              string                `json:"id"`
+	Status          string                `json:"status"` // unverified or verified
+	Type            string                `json:"type"`
+	BankAccountType string                `json:"bankAccountType,omitempty"`
+	Name            string                `json:"name"`
+	BankName        string                `json:"bankName,omitempty"`
+	Removed         bool                  `json:"removed"`
+	Created         JSONTime              `json:"created"`
+	Links           map[string]DwollaLink `json:"_links"`
+}
+
+// DwollaAmount struct
+type DwollaAmount struct {
+	Currency string `json:"currency"`
+	Value    string `json:"value"`
+}
+
+// DwollaTransferRequest struct, see NewDwollaTransfer
+// Doc: https://developers.dwolla.com/docs/balance/api-reference/transfers/initiate-a-transfer
+type DwollaTransferRequest struct {
+	Links         map[string]DwollaLink `json:"_links"`
+	Amount        DwollaAmount          `json:"amount"`
+	Metadata      map[string]string     `json:"metadata,omitempty"`
+	CorrelationID string                `json:"correlationId,omitempty"`
+}
+
+// NewDwollaTransfer returns a transfer of amount between the funding sources at sourceURL and destinationURL
+func NewDwollaTransfer(sourceURL, destinationURL string, amount MoneyAmount) DwollaTransferRequest {
+	return DwollaTransferRequest{
+		Links: map[string]DwollaLink{
+			"source":      {Href: sourceURL},
+			"destination": {Href: destinationURL},
+		},
+		Amount: DwollaAmount{Currency: amount.Currency, Value: amount.String()},
+	}
+}
+
+// DwollaTransfer struct
+type DwollaTransfer struct {
+	ID            string                `json:"id"`
+	Status        string                `json:"status"`
+	Amount        DwollaAmount          `json:"amount"`
+	Created       JSONTime              `json:"created"`
+	Metadata      map[string]string     `json:"metadata,omitempty"`
+	CorrelationID string                `json:"correlationId,omitempty"`
+	Links         map[string]DwollaLink `json:"_links"`
+}
+
+// DwollaWebhookSubscription struct
+type DwollaWebhookSubscription struct {
+	ID      string   `json:"id"`
+	URL     string   `json:"url"`
+	Paused  bool     `json:"paused"`
+	Created JSONTime `json:"created"`
+}
+
+// DwollaWebhookEvent is the payload of a webhook
+type DwollaWebhookEvent struct {
+	ID         string                `json:"id"`
+	ResourceID string                `json:"resourceId"`
+	Topic      string                `json:"topic"` // e.g. customer_transfer_completed
+	Timestamp  JSONTime              `json:"timestamp"`
+	Links      map[string]DwollaLink `json:"_links"`
+}
@@ -0,0 +1,299 @@
+package payment
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DwollaAPIBaseSandBox points to the sandbox version of the Dwolla API
+	DwollaAPIBaseSandBox = "https://api-sandbox.dwolla.com"
+
+	// DwollaAPIBaseLive points to the live version of the Dwolla API
+	DwollaAPIBaseLive = "https://api.dwolla.com"
+
+	// dwollaMediaType is the content type of every Dwolla request and response
+	dwollaMediaType = "application/vnd.dwolla.v1.hal+json"
+)
+
+var (
+	// ErrInvalidDwollaConfig is returned when a Dwolla client is created without Key, Secret or APIBase
+	ErrInvalidDwollaConfig = errors.New("dwolla: Key, Secret and APIBase are required to create a client")
+
+	// ErrDwollaWebhookSignature is returned by VerifyDwollaWebhook when the signature does not match
+	ErrDwollaWebhookSignature = errors.New("dwolla: invalid webhook signature")
+)
+
+// IDwolla is the Dwolla ACH client interface
+type IDwolla interface {
+	GetAccessToken(ctx context.Context) (*DwollaToken, error)
+	CreateCustomer(ctx context.Context, customer DwollaCustomerRequest) (string, error)
+	GetCustomer(ctx context.Context, customerID string) (*DwollaCustomer, error)
+	CreateFundingSource(ctx context.Context, customerID string, fundingSource DwollaFundingSourceRequest) (string, error)
+	ListFundingSources(ctx context.Context, customerID string) ([]DwollaFundingSource, error)
+	CreateTransfer(ctx context.Context, transfer DwollaTransferRequest, idempotencyKey string) (string, error)
+	GetTransfer(ctx context.Context, transferID string) (*DwollaTransfer, error)
+	CancelTransfer(ctx context.Context, transferID string) (*DwollaTransfer, error)
+	CreateWebhookSubscription(ctx context.Context, webhookURL, secret string) (string, error)
+	ListWebhookSubscriptions(ctx context.Context) ([]DwollaWebhookSubscription, error)
+	DeleteWebhookSubscription(ctx context.Context, subscriptionID string) error
+}
+
+// DwollaClient represents a Dwolla v2 API client
+type DwollaClient struct {
+	sync.Mutex
+	Client         *http.Client
+	Key            string
+	Secret         string
+	APIBase        string
+	Token          *DwollaToken
+	tokenExpiresAt time.Time
+}
+
+// NewDwollaClient returns a Dwolla client for config.
+// APIBase is a base API URL, for testing you can use DwollaAPIBaseSandBox
+func NewDwollaClient(config *Dwolla) (IDwolla, error) {
+	if config == nil || config.Key == "" || config.Secret == "" || config.APIBase == "" {
+		return nil, ErrInvalidDwollaConfig
+	}
+
+	return &DwollaClient{Client: &http.Client{}, Key: config.Key, Secret: config.Secret, APIBase: config.APIBase}, nil
+}
+
+// newDwolla returns a Dwolla client, or nil when config is invalid
+func newDwolla(config *Dwolla) IDwolla {
+	client, err := NewDwollaClient(config)
+	if err != nil {
+		log.Println("Unable to init Dwolla client: ", err)
+		return nil
+	}
+
+	return client
+}
+
+// newDwollaError decodes the error returned by the Dwolla API
+func newDwollaError(resp *http.Response, body []byte) error {
+	errResp := &DwollaError{Response: resp}
+	json.Unmarshal(body, errResp)
+	return errResp
+}
+
+// DwollaResourceID returns the ID of the resource at location, e.g. the URL returned by CreateCustomer
+func DwollaResourceID(location string) string {
+	return location[strings.LastIndex(location, "/")+1:]
+}
+
+// GetAccessToken requests an application access token, used by every other call.
+// It is requested automatically when missing or about to expire
+// Endpoint: POST /token
+func (c *DwollaClient) GetAccessToken(ctx context.Context) (*DwollaToken, error) {
+	form := url.Values{"grant_type": {"client_credentials"}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.APIBase+"/token", strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.SetBasicAuth(c.Key, c.Secret)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	token := &DwollaToken{}
+	if err = sendJSON(c.Client, req, token, newDwollaError); err != nil {
+		return nil, err
+	}
+
+	c.Lock()
+	c.Token = token
+	c.tokenExpiresAt = time.Now().Add(time.Duration(token.ExpiresIn) * time.Second)
+	c.Unlock()
+
+	return token, nil
+}
+
+// newRequest builds an authenticated request, refreshing the access token when needed
+func (c *DwollaClient) newRequest(ctx context.Context, method, path string, payload interface{}) (*http.Request, error) {
+	c.Lock()
+	token := c.Token
+	expired := token == nil || time.Until(c.tokenExpiresAt) < RequestNewTokenBeforeExpiresIn
+	c.Unlock()
+
+	if expired {
+		var err error
+		if token, err = c.GetAccessToken(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	req, err := newJSONRequest(ctx, method, c.APIBase+path, payload)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token.AccessToken)
+	req.Header.Set("Accept", dwollaMediaType)
+	if payload != nil {
+		req.Header.Set("Content-Type", dwollaMediaType)
+	}
+
+	return req, nil
+}
+
+// send makes an authenticated request and decodes the response into v
+func (c *DwollaClient) send(ctx context.Context, method, path string, payload, v interface{}) error {
+	req, err := c.newRequest(ctx, method, path, payload)
+	if err != nil {
+		return err
+	}
+
+	return sendJSON(c.Client, req, v, newDwollaError)
+}
+
+// create makes an authenticated POST request and returns the URL of the created resource.
+// Dwolla answers 201 Created with the URL in the Location header and no body
+func (c *DwollaClient) create(req *http.Request) (string, error) {
+	resp, err := doRequest(c.Client, req, newDwollaError)
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+
+	return resp.Header.Get("Location"), nil
+}
+
+// CreateCustomer creates a customer and returns its URL, see DwollaResourceID
+// Endpoint: POST /customers
+func (c *DwollaClient) CreateCustomer(ctx context.Context, customer DwollaCustomerRequest) (string, error) {
+	req, err := c.newRequest(ctx, http.MethodPost, "/customers", customer)
+	if err != nil {
+		return "", err
+	}
+
+	return c.create(req)
+}
+
+// GetCustomer returns a customer by ID
+// Endpoint: GET /customers/ID
+func (c *DwollaClient) GetCustomer(ctx context.Context, customerID string) (*DwollaCustomer, error) {
+	response := &DwollaCustomer{}
+	err := c.send(ctx, http.MethodGet, "/customers/"+url.PathEscape(customerID), nil, response)
+	return response, err
+}
+
+// CreateFundingSource attaches a bank account to a customer and returns its URL.
+// Set PlaidToken to a Plaid processor token to attach an account verified through Plaid
+// instead of the routing and account numbers
+// Endpoint: POST /customers/ID/funding-sources
+func (c *DwollaClient) CreateFundingSource(ctx context.Context, customerID string, fundingSource DwollaFundingSourceRequest) (string, error) {
+	req, err := c.newRequest(ctx, http.MethodPost, "/customers/"+url.PathEscape(customerID)+"/funding-sources", fundingSource)
+	if err != nil {
+		return "", err
+	}
+
+	return c.create(req)
+}
+
+// ListFundingSources returns the funding sources of a customer
+// Endpoint: GET /customers/ID/funding-sources
+func (c *DwollaClient) ListFundingSources(ctx context.Context, customerID string) ([]DwollaFundingSource, error) {
+	response := &struct {
+		Embedded struct {
+			FundingSources []DwollaFundingSource `json:"funding-sources"`
+		} `json:"_embedded"`
+	}{}
+	err := c.send(ctx, http.MethodGet, "/customers/"+url.PathEscape(customerID)+"/funding-sources", nil, response)
+	return response.Embedded.FundingSources, err
+}
+
+// CreateTransfer initiates a transfer between two funding sources and returns its URL.
+// idempotencyKey, when set, makes retries safe
+// Endpoint: POST /transfers
+func (c *DwollaClient) CreateTransfer(ctx context.Context, transfer DwollaTransferRequest, idempotencyKey string) (string, error) {
+	req, err := c.newRequest(ctx, http.MethodPost, "/transfers", transfer)
+	if err != nil {
+		return "", err
+	}
+	if idempotencyKey != "" {
+		req.Header.Set("Idempotency-Key", idempotencyKey)
+	}
+
+	return c.create(req)
+}
+
+// GetTransfer returns a transfer by ID
+// Endpoint: GET /transfers/ID
+func (c *DwollaClient) GetTransfer(ctx context.Context, transferID string) (*DwollaTransfer, error) {
+	response := &DwollaTransfer{}
+	err := c.send(ctx, http.MethodGet, "/transfers/"+url.PathEscape(transferID), nil, response)
+	return response, err
+}
+
+// CancelTransfer cancels a pending transfer
+// Endpoint: POST /transfers/ID
+func (c *DwollaClient) CancelTransfer(ctx context.Context, transferID string) (*DwollaTransfer, error) {
+	response := &DwollaTransfer{}
+	err := c.send(ctx, http.MethodPost, "/transfers/"+url.PathEscape(transferID), map[string]string{"status": DwollaTransferStatusCancelled}, response)
+	return response, err
+}
+
+// CreateWebhookSubscription subscribes webhookURL to the events of the account and returns the subscription URL.
+// secret signs the webhooks, see VerifyDwollaWebhook
+// Endpoint: POST /webhook-subscriptions
+func (c *DwollaClient) CreateWebhookSubscription(ctx context.Context, webhookURL, secret string) (string, error) {
+	req, err := c.newRequest(ctx, http.MethodPost, "/webhook-subscriptions", map[string]string{"url": webhookURL, "secret": secret})
+	if err != nil {
+		return "", err
+	}
+
+	return c.create(req)
+}
+
+// ListWebhookSubscriptions returns the webhook subscriptions of the account
+// Endpoint: GET /webhook-subscriptions
+func (c *DwollaClient) ListWebhookSubscriptions(ctx context.Context) ([]DwollaWebhookSubscription, error) {
+	response := &struct {
+		Embedded struct {
+			WebhookSubscriptions []DwollaWebhookSubscription `json:"webhook-subscriptions"`
+		} `json:"_embedded"`
+	}{}
+	err := c.send(ctx, http.MethodGet, "/webhook-subscriptions", nil, response)
+	return response.Embedded.WebhookSubscriptions, err
+}
+
+// DeleteWebhookSubscription deletes a webhook subscription
+// Endpoint: DELETE /webhook-subscriptions/ID
+func (c *DwollaClient) DeleteWebhookSubscription(ctx context.Context, subscriptionID string) error {
+	return c.send(ctx, http.MethodDelete, "/webhook-subscriptions/"+url.PathEscape(subscriptionID), nil, nil)
+}
+
+// VerifyDwollaWebhook checks the X-Request-Signature-SHA-256 header of a webhook against secret
+// and returns the decoded event
+// Doc: https://developers.dwolla.com/docs/balance/webhooks/process-validate
+func VerifyDwollaWebhook(req *http.Request, secret string) (*DwollaWebhookEvent, error) {
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	signature, err := hex.DecodeString(req.Header.Get("X-Request-Signature-SHA-256"))
+	if err != nil || !hmac.Equal(signature, mac.Sum(nil)) {
+		return nil, ErrDwollaWebhookSignature
+	}
+
+	event := &DwollaWebhookEvent{}
+	if err = json.Unmarshal(body, event); err != nil {
+		return nil, fmt.Errorf("dwolla: decode webhook: %w", err)
+	}
+
+	return event, nil
+}
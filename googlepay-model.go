@@ -0,0 +1,77 @@
+package payment
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// Google Pay authentication methods
+const (
+	GooglePayAuthMethodPANOnly       = "PAN_ONLY"
+	GooglePayAuthMethodCryptogram3DS = "CRYPTOGRAM_3DS"
+)
+
+// GooglePayError is returned for a non 2xx response when fetching the root signing keys
+type GooglePayError struct {
+	Response *http.Response
+	Message  string
+}
+
+func (e *GooglePayError) Error() string {
+	return fmt.Sprintf("%v %v: %d %s", e.Response.Request.Method, e.Response.Request.URL, e.Response.StatusCode, e.Message)
+}
+
+// Is reports whether the error belongs to the target error category
+func (e *GooglePayError) Is(target error) bool {
+	return statusErrorIs(e.Response.StatusCode, target)
+}
+
+// GooglePayRootKeys are the root signing keys served at the root keys URL
+type GooglePayRootKeys struct {
+	Keys []struct {
+		KeyValue        string `json:"keyValue"`
+		ProtocolVersion string `json:"protocolVersion"`
+		KeyExpiration   string `json:"keyExpiration,omitempty"`
+	} `json:"keys"`
+}
+
+// GooglePayPaymentMethodToken is the token of the PaymentData of the Google Pay API
+// Doc: https://developers.google.com/pay/api/web/guides/resources/payment-data-cryptography#payment-method-token-structure
+type GooglePayPaymentMethodToken struct {
+	ProtocolVersion        string `json:"protocolVersion"`
+	Signature              string `json:"signature"`
+	IntermediateSigningKey struct {
+		SignedKey  string   `json:"signedKey"`
+		Signatures []string `json:"signatures"`
+	} `json:"intermediateSigningKey"`
+	SignedMessage string `json:"signedMessage"`
+}
+
+// GooglePaySignedKey is the intermediate signing key of a token
+type GooglePaySignedKey struct {
+	KeyValue      string `json:"keyValue"`
+	KeyExpiration string `json:"keyExpiration"` // Milliseconds since epoch
+}
+
+// GooglePaySignedMessage is the encrypted message of a token
+type GooglePaySignedMessage struct {
+	EncryptedMessage   string `json:"encryptedMessage"`
+	EphemeralPublicKey string `json:"ephemeralPublicKey"`
+	Tag                string `json:"tag"`
+}
+
+// GooglePayPaymentData is the decrypted message of a token
+type GooglePayPaymentData struct {
+	GatewayMerchantID    string `json:"gatewayMerchantId,omitempty"`
+	MessageExpiration    string `json:"messageExpiration"` // Milliseconds since epoch
+	MessageID            string `json:"messageId"`
+	PaymentMethod        string `json:"paymentMethod"`
+	PaymentMethodDetails struct {
+		Pan             string `json:"pan"`
+		ExpirationMonth int    `json:"expirationMonth"`
+		ExpirationYear  int    `json:"expirationYear"`
+		AuthMethod      string `json:"authMethod"`
+		Cryptogram      string `json:"cryptogram,omitempty"`
+		ECIIndicator    string `json:"eciIndicator,omitempty"`
+	} `json:"paymentMethodDetails"`
+}
@@ -0,0 +1,352 @@
+package payment
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// GooglePayRootKeysURLTest serves the root signing keys of the TEST environment
+	GooglePayRootKeysURLTest = "https://payments.developers.google.com/paymentmethodtoken/test/keys.json"
+
+	// GooglePayRootKeysURLProduction serves the root signing keys of the PRODUCTION environment
+	GooglePayRootKeysURLProduction = "https://payments.developers.google.com/paymentmethodtoken/keys.json"
+
+	// googlePayProtocolVersion is the only supported protocol version
+	googlePayProtocolVersion = "ECv2"
+
+	// googlePayRootKeysTTL is how long the root signing keys are cached
+	googlePayRootKeysTTL = time.Hour
+)
+
+var (
+	// ErrInvalidGooglePayConfig is returned when a Google Pay decryptor is created without RecipientID or PrivateKeys,
+	// or with a private key that cannot be parsed
+	ErrInvalidGooglePayConfig = errors.New("googlepay: RecipientID and PrivateKeys are required, the private keys must be EC keys")
+
+	// ErrGooglePaySignature is returned when a payment method token is not signed by Google for the recipient
+	ErrGooglePaySignature = errors.New("googlepay: invalid payment method token signature")
+
+	// ErrGooglePayExpired is returned when the intermediate signing key or the message of a token has expired
+	ErrGooglePayExpired = errors.New("googlepay: payment method token expired")
+
+	// ErrGooglePayDecrypt is returned when no private key decrypts a payment method token
+	ErrGooglePayDecrypt = errors.New("googlepay: unable to decrypt payment method token")
+)
+
+// IGooglePayDecryptor is the Google Pay decryptor interface
+type IGooglePayDecryptor interface {
+	Decrypt(ctx context.Context, token string) (*GooglePayPaymentData, error)
+}
+
+// GooglePayDecryptor verifies and decrypts the ECv2 payment method tokens of merchants decrypting Google Pay themselves
+type GooglePayDecryptor struct {
+	sync.Mutex
+	Client            *http.Client
+	RecipientID       string
+	RootKeysURL       string
+	privateKeys       []*ecdsa.PrivateKey
+	rootKeys          []*ecdsa.PublicKey
+	rootKeysFetchedAt time.Time
+}
+
+// NewGooglePayDecryptor returns a Google Pay decryptor for config.
+// The root signing keys are fetched from RootKeysURL, the production keys by default
+func NewGooglePayDecryptor(config *GooglePay) (IGooglePayDecryptor, error) {
+	if config == nil || config.RecipientID == "" || len(config.PrivateKeys) == 0 {
+		return nil, ErrInvalidGooglePayConfig
+	}
+
+	privateKeys := make([]*ecdsa.PrivateKey, len(config.PrivateKeys))
+	for i, key := range config.PrivateKeys {
+		privateKey, err := parseECPrivateKey(key)
+		if err != nil {
+			return nil, fmt.Errorf("%w: private key %d: %v", ErrInvalidGooglePayConfig, i, err)
+		}
+		privateKeys[i] = privateKey
+	}
+	rootKeysURL := config.RootKeysURL
+	if rootKeysURL == "" {
+		rootKeysURL = GooglePayRootKeysURLProduction
+	}
+
+	return &GooglePayDecryptor{
+		Client:      &http.Client{},
+		RecipientID: config.RecipientID,
+		RootKeysURL: rootKeysURL,
+		privateKeys: privateKeys,
+	}, nil
+}
+
+// newGooglePay returns a Google Pay decryptor, or nil when config is invalid
+func newGooglePay(config *GooglePay) IGooglePayDecryptor {
+	decryptor, err := NewGooglePayDecryptor(config)
+	if err != nil {
+		log.Println("Unable to init Google Pay decryptor: ", err)
+		return nil
+	}
+
+	return decryptor
+}
+
+// googlePaySignedString returns the length prefixed concatenation of parts which Google signs
+func googlePaySignedString(parts ...string) []byte {
+	var b []byte
+	length := make([]byte, 4)
+	for _, part := range parts {
+		binary.LittleEndian.PutUint32(length, uint32(len(part)))
+		b = append(append(b, length...), part...)
+	}
+	return b
+}
+
+// parseGooglePayKey parses a base64 PKIX EC public key
+func parseGooglePayKey(keyValue string) (*ecdsa.PublicKey, error) {
+	der, err := base64.StdEncoding.DecodeString(keyValue)
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return nil, err
+	}
+	key, ok := parsed.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, errors.New("googlepay: not an EC key")
+	}
+	return key, nil
+}
+
+// googlePayExpired reports whether an expiration in milliseconds since epoch is in the past
+func googlePayExpired(expiration string) bool {
+	ms, err := strconv.ParseInt(expiration, 10, 64)
+	return err != nil || time.Now().After(time.Unix(0, ms*int64(time.Millisecond)))
+}
+
+// fetchRootKeys returns the unexpired ECv2 root signing keys, fetched again once cached for an hour
+func (d *GooglePayDecryptor) fetchRootKeys(ctx context.Context) ([]*ecdsa.PublicKey, error) {
+	d.Lock()
+	defer d.Unlock()
+	if d.rootKeys != nil && time.Since(d.rootKeysFetchedAt) < googlePayRootKeysTTL {
+		return d.rootKeys, nil
+	}
+
+	req, err := newJSONRequest(ctx, http.MethodGet, d.RootKeysURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	response := &GooglePayRootKeys{}
+	err = sendJSON(d.Client, req, response, func(resp *http.Response, body []byte) error {
+		return &GooglePayError{Response: resp, Message: string(body)}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []*ecdsa.PublicKey
+	for _, rootKey := range response.Keys {
+		if rootKey.ProtocolVersion != googlePayProtocolVersion || (rootKey.KeyExpiration != "" && googlePayExpired(rootKey.KeyExpiration)) {
+			continue
+		}
+		key, err := parseGooglePayKey(rootKey.KeyValue)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	d.rootKeys, d.rootKeysFetchedAt = keys, time.Now()
+	return keys, nil
+}
+
+// verify checks the signature chain of a token and returns its signed message
+// Doc: https://developers.google.com/pay/api/web/guides/resources/payment-data-cryptography#verify-signature
+func (d *GooglePayDecryptor) verify(ctx context.Context, token *GooglePayPaymentMethodToken) (*GooglePaySignedMessage, error) {
+	if token.ProtocolVersion != googlePayProtocolVersion {
+		return nil, fmt.Errorf("googlepay: unsupported protocol version %q", token.ProtocolVersion)
+	}
+	rootKeys, err := d.fetchRootKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	signedKey := googlePaySignedString("Google", googlePayProtocolVersion, token.IntermediateSigningKey.SignedKey)
+	if !googlePayVerifyAny(rootKeys, signedKey, token.IntermediateSigningKey.Signatures) {
+		return nil, ErrGooglePaySignature
+	}
+
+	intermediate := &GooglePaySignedKey{}
+	if err = json.Unmarshal([]byte(token.IntermediateSigningKey.SignedKey), intermediate); err != nil {
+		return nil, err
+	}
+	if googlePayExpired(intermediate.KeyExpiration) {
+		return nil, ErrGooglePayExpired
+	}
+	intermediateKey, err := parseGooglePayKey(intermediate.KeyValue)
+	if err != nil {
+		return nil, err
+	}
+
+	signedMessage := googlePaySignedString("Google", d.RecipientID, googlePayProtocolVersion, token.SignedMessage)
+	if !googlePayVerifyAny([]*ecdsa.PublicKey{intermediateKey}, signedMessage, []string{token.Signature}) {
+		return nil, ErrGooglePaySignature
+	}
+
+	message := &GooglePaySignedMessage{}
+	err = json.Unmarshal([]byte(token.SignedMessage), message)
+	return message, err
+}
+
+// googlePayVerifyAny reports whether one of the base64 ECDSA-SHA256 signatures of content is made by one of keys
+func googlePayVerifyAny(keys []*ecdsa.PublicKey, content []byte, signatures []string) bool {
+	hashed := sha256.Sum256(content)
+	for _, signature := range signatures {
+		sig, err := base64.StdEncoding.DecodeString(signature)
+		if err != nil {
+			continue
+		}
+		for _, key := range keys {
+			if ecdsa.VerifyASN1(key, hashed[:], sig) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// googlePayHKDF is the HKDF-SHA256 of ikm with a zero salt and the "Google" info, 64 bytes long
+func googlePayHKDF(ikm []byte) []byte {
+	extract := hmac.New(sha256.New, make([]byte, sha256.Size))
+	extract.Write(ikm)
+	prk := extract.Sum(nil)
+
+	var okm, previous []byte
+	for i := byte(1); len(okm) < 64; i++ {
+		expand := hmac.New(sha256.New, prk)
+		expand.Write(previous)
+		expand.Write([]byte("Google"))
+		expand.Write([]byte{i})
+		previous = expand.Sum(nil)
+		okm = append(okm, previous...)
+	}
+	return okm[:64]
+}
+
+// googlePayDecrypt opens the encrypted message with key, or returns nil when its tag does not match
+// Doc: https://developers.google.com/pay/api/web/guides/resources/payment-data-cryptography#decrypt-token
+func googlePayDecrypt(key *ecdsa.PrivateKey, message *GooglePaySignedMessage) ([]byte, error) {
+	ephemeralPublicKey, err := base64.StdEncoding.DecodeString(message.EphemeralPublicKey)
+	if err != nil {
+		return nil, err
+	}
+	encryptedMessage, err := base64.StdEncoding.DecodeString(message.EncryptedMessage)
+	if err != nil {
+		return nil, err
+	}
+	tag, err := base64.StdEncoding.DecodeString(message.Tag)
+	if err != nil {
+		return nil, err
+	}
+
+	x, y := elliptic.Unmarshal(elliptic.P256(), ephemeralPublicKey)
+	if x == nil {
+		return nil, errors.New("googlepay: invalid ephemeral public key")
+	}
+	sharedX, _ := elliptic.P256().ScalarMult(x, y, key.D.Bytes())
+	sharedSecret := make([]byte, 32)
+	sharedX.FillBytes(sharedSecret)
+
+	keys := googlePayHKDF(append(append([]byte{}, ephemeralPublicKey...), sharedSecret...))
+	mac := hmac.New(sha256.New, keys[32:])
+	mac.Write(encryptedMessage)
+	if !hmac.Equal(mac.Sum(nil), tag) {
+		return nil, nil
+	}
+
+	block, err := aes.NewCipher(keys[:32])
+	if err != nil {
+		return nil, err
+	}
+	plaintext := make([]byte, len(encryptedMessage))
+	cipher.NewCTR(block, make([]byte, aes.BlockSize)).XORKeyStream(plaintext, encryptedMessage)
+	return plaintext, nil
+}
+
+// Decrypt verifies and decrypts the token of PaymentData.paymentMethodData.tokenizationData.token
+func (d *GooglePayDecryptor) Decrypt(ctx context.Context, token string) (*GooglePayPaymentData, error) {
+	paymentMethodToken := &GooglePayPaymentMethodToken{}
+	if err := json.Unmarshal([]byte(token), paymentMethodToken); err != nil {
+		return nil, err
+	}
+	message, err := d.verify(ctx, paymentMethodToken)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, key := range d.privateKeys {
+		plaintext, err := googlePayDecrypt(key, message)
+		if err != nil {
+			return nil, err
+		}
+		if plaintext == nil {
+			continue
+		}
+
+		data := &GooglePayPaymentData{}
+		if err = json.Unmarshal(plaintext, data); err != nil {
+			return nil, err
+		}
+		if googlePayExpired(data.MessageExpiration) {
+			return nil, ErrGooglePayExpired
+		}
+		return data, nil
+	}
+
+	return nil, ErrGooglePayDecrypt
+}
+
+// Card returns the card payment source of an Orders v2 order paying with the decrypted PAN.
+// PayPal needs the cryptogram of a CRYPTOGRAM_3DS token, see PayPalPaymentSource
+func (p *GooglePayPaymentData) Card() *PaymentSourceCard {
+	return &PaymentSourceCard{
+		Number: p.PaymentMethodDetails.Pan,
+		Expiry: fmt.Sprintf("%04d-%02d", p.PaymentMethodDetails.ExpirationYear, p.PaymentMethodDetails.ExpirationMonth),
+	}
+}
+
+// PayPalPaymentSource returns the google_pay payment source of an Orders v2 order paying with the decrypted token
+func (p *GooglePayPaymentData) PayPalPaymentSource() *PaymentSource {
+	card := p.Card()
+	return &PaymentSource{
+		GooglePay: &PaymentSourceGooglePay{
+			DecryptedToken: &GooglePayDecryptedToken{
+				MessageID:         p.MessageID,
+				MessageExpiration: p.MessageExpiration,
+				PaymentMethod:     p.PaymentMethod,
+				Card: &TokenizedCard{
+					Number: card.Number,
+					Expiry: card.Expiry,
+				},
+				AuthenticationMethod: p.PaymentMethodDetails.AuthMethod,
+				Cryptogram:           p.PaymentMethodDetails.Cryptogram,
+				ECIIndicator:         p.PaymentMethodDetails.ECIIndicator,
+			},
+		},
+	}
+}
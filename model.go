@@ -2,7 +2,22 @@ package payment
 
 // Config model
 type Config struct {
-	PayPal PayPal `json:"paypal,omitempty"`
+	PayPal           PayPal           `json:"paypal,omitempty"`
+	Mollie           Mollie           `json:"mollie,omitempty"`
+	Payoneer         Payoneer         `json:"payoneer,omitempty"`
+	Dwolla           Dwolla           `json:"dwolla,omitempty"`
+	CoinbaseCommerce CoinbaseCommerce `json:"coinbaseCommerce,omitempty"`
+	BitPay           BitPay           `json:"bitpay,omitempty"`
+	Alipay           Alipay           `json:"alipay,omitempty"`
+	WeChatPay        WeChatPay        `json:"wechatPay,omitempty"`
+	VNPay            VNPay            `json:"vnpay,omitempty"`
+	MoMo             MoMo             `json:"momo,omitempty"`
+	ZaloPay          ZaloPay          `json:"zalopay,omitempty"`
+	AmazonPay        AmazonPay        `json:"amazonPay,omitempty"`
+	ApplePay         ApplePay         `json:"applePay,omitempty"`
+	GooglePay        GooglePay        `json:"googlePay,omitempty"`
+	Paddle           Paddle           `json:"paddle,omitempty"`
+	TwoCheckout      TwoCheckout      `json:"twoCheckout,omitempty"`
 }
 
 // Paypal model for Paypal connection config
@@ -11,3 +26,126 @@ type PayPal struct {
 	SecretID string `json:"secretID"`
 	APIBase  string `json:"apiBase"`
 }
+
+// Mollie model for Mollie connection config
+type Mollie struct {
+	APIKey  string `json:"apiKey"`
+	APIBase string `json:"apiBase,omitempty"` // Defaults to MollieAPIBase
+}
+
+// Payoneer model for Payoneer connection config
+type Payoneer struct {
+	ClientID  string `json:"clientID"`
+	SecretID  string `json:"secretID"`
+	ProgramID string `json:"programID"`
+	APIBase   string `json:"apiBase"`
+	AuthBase  string `json:"authBase,omitempty"` // Defaults to the authorization server of APIBase
+}
+
+// Dwolla model for Dwolla connection config
+type Dwolla struct {
+	Key     string `json:"key"`
+	Secret  string `json:"secret"`
+	APIBase string `json:"apiBase"`
+}
+
+// CoinbaseCommerce model for Coinbase Commerce connection config
+type CoinbaseCommerce struct {
+	APIKey               string `json:"apiKey"`
+	APIBase              string `json:"apiBase,omitempty"`              // Defaults to CoinbaseCommerceAPIBase
+	ExchangeRatesAPIBase string `json:"exchangeRatesAPIBase,omitempty"` // Defaults to CoinbaseExchangeRatesAPIBase
+}
+
+// BitPay model for BitPay connection config
+type BitPay struct {
+	APIBase       string `json:"apiBase"`
+	POSToken      string `json:"posToken"`
+	MerchantToken string `json:"merchantToken,omitempty"` // Needed for refunds, along with a BitPaySigner
+}
+
+// Alipay model for Alipay Open Platform connection config
+type Alipay struct {
+	AppID           string `json:"appID"`
+	PrivateKey      string `json:"privateKey"`      // Application private key, signs the requests
+	AlipayPublicKey string `json:"alipayPublicKey"` // Alipay public key, verifies the responses and notifications
+	APIBase         string `json:"apiBase"`
+	NotifyURL       string `json:"notifyURL,omitempty"`
+}
+
+// WeChatPay model for WeChat Pay v3 connection config
+type WeChatPay struct {
+	MchID      string `json:"mchID"`
+	AppID      string `json:"appID"`
+	SerialNo   string `json:"serialNo"`   // Serial number of the merchant API certificate
+	PrivateKey string `json:"privateKey"` // Private key of the merchant API certificate, signs the requests
+	APIv3Key   string `json:"apiV3Key"`   // Decrypts the platform certificates and the callbacks
+	APIBase    string `json:"apiBase"`
+	NotifyURL  string `json:"notifyURL,omitempty"`
+}
+
+// VNPay model for VNPay gateway connection config
+type VNPay struct {
+	TmnCode    string `json:"tmnCode"`
+	HashSecret string `json:"hashSecret"`
+	PaymentURL string `json:"paymentURL"`
+	APIURL     string `json:"apiURL"`
+	ReturnURL  string `json:"returnURL,omitempty"`
+}
+
+// MoMo model for MoMo payment gateway connection config
+type MoMo struct {
+	PartnerCode string `json:"partnerCode"`
+	AccessKey   string `json:"accessKey"`
+	SecretKey   string `json:"secretKey"`
+	APIBase     string `json:"apiBase"`
+	RedirectURL string `json:"redirectURL,omitempty"`
+	IPNURL      string `json:"ipnURL,omitempty"`
+}
+
+// ZaloPay model for ZaloPay open API connection config
+type ZaloPay struct {
+	AppID       string `json:"appID"`
+	Key1        string `json:"key1"` // Signs the requests
+	Key2        string `json:"key2"` // Verifies the callbacks
+	APIBase     string `json:"apiBase"`
+	CallbackURL string `json:"callbackURL,omitempty"`
+}
+
+// AmazonPay model for Amazon Pay API v2 connection config
+type AmazonPay struct {
+	PublicKeyID string `json:"publicKeyID"`
+	PrivateKey  string `json:"privateKey"` // Private key of PublicKeyID, signs the requests
+	Region      string `json:"region"`     // na, eu or jp, defaults to na
+	APIBase     string `json:"apiBase"`
+}
+
+// ApplePay model for Apple Pay on the web config
+type ApplePay struct {
+	MerchantID                  string `json:"merchantID"`
+	DomainName                  string `json:"domainName"`
+	DisplayName                 string `json:"displayName"`
+	MerchantIdentityCertificate string `json:"merchantIdentityCertificate,omitempty"` // PEM, authenticates the merchant validation
+	MerchantIdentityKey         string `json:"merchantIdentityKey,omitempty"`         // PEM
+	PaymentProcessingKey        string `json:"paymentProcessingKey,omitempty"`        // PEM EC key, decrypts the payment tokens
+	RootCertificate             string `json:"rootCertificate,omitempty"`             // PEM of Apple Root CA - G3, verifies the payment tokens
+}
+
+// GooglePay model for Google Pay token decryption config
+type GooglePay struct {
+	RecipientID string   `json:"recipientID"`           // "merchant:<merchant ID>", or "gateway:<gateway ID>" for gateways
+	PrivateKeys []string `json:"privateKeys"`           // PEM or base64 EC keys, several while rotating keys
+	RootKeysURL string   `json:"rootKeysURL,omitempty"` // Defaults to GooglePayRootKeysURLProduction
+}
+
+// Paddle model for Paddle Billing connection config
+type Paddle struct {
+	APIKey  string `json:"apiKey"`
+	APIBase string `json:"apiBase,omitempty"` // Defaults to PaddleAPIBaseLive
+}
+
+// TwoCheckout model for 2Checkout connection config
+type TwoCheckout struct {
+	MerchantCode string `json:"merchantCode"`
+	SecretKey    string `json:"secretKey"`
+	APIBase      string `json:"apiBase,omitempty"` // Defaults to TwoCheckoutAPIBase
+}
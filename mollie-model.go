@@ -0,0 +1,264 @@
+package payment
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// ErrMollieWebhookMissingID is returned by HandleWebhook when the call has no payment ID
+var ErrMollieWebhookMissingID = errors.New("mollie: webhook without id")
+
+// Mollie payment methods
+// Doc: https://docs.mollie.com/reference/v2/methods-api/list-methods
+const (
+	MollieMethodIDEAL        = "ideal"
+	MollieMethodBancontact   = "bancontact"
+	MollieMethodCreditCard   = "creditcard"
+	MollieMethodSofort       = "sofort"
+	MollieMethodBankTransfer = "banktransfer"
+	MollieMethodPayPal       = "paypal"
+	MollieMethodKBC          = "kbc"
+	MollieMethodBelfius      = "belfius"
+	MollieMethodEPS          = "eps"
+	MollieMethodGiropay      = "giropay"
+	MollieMethodDirectDebit  = "directdebit"
+)
+
+// Mollie payment statuses
+// Doc: https://docs.mollie.com/payments/status-changes
+const (
+	MolliePaymentStatusOpen       = "open"
+	MolliePaymentStatusCanceled   = "canceled"
+	MolliePaymentStatusPending    = "pending"
+	MolliePaymentStatusAuthorized = "authorized"
+	MolliePaymentStatusExpired    = "expired"
+	MolliePaymentStatusFailed     = "failed"
+	MolliePaymentStatusPaid       = "paid"
+)
+
+// Mollie sequence types, first and recurring are used to set up a mandate for subscriptions
+const (
+	MollieSequenceTypeOneOff    = "oneoff"
+	MollieSequenceTypeFirst     = "first"
+	MollieSequenceTypeRecurring = "recurring"
+)
+
+// MollieError is the error returned by the Mollie API
+// Doc: https://docs.mollie.com/overview/handling-errors
+type MollieError struct {
+	Response *http.Response `json:"-"`
+	Status   int            `json:"status"`
+	Title    string         `json:"title"`
+	Detail   string         `json:"detail"`
+	Field    string         `json:"field,omitempty"`
+}
+
+func (e *MollieError) Error() string {
+	msg := fmt.Sprintf("mollie: %d %s: %s", e.Status, e.Title, e.Detail)
+	if e.Field != "" {
+		msg += " (field " + e.Field + ")"
+	}
+	return msg
+}
+
+// Is reports whether the error belongs to the target error category
+func (e *MollieError) Is(target error) bool {
+	return statusErrorIs(e.Status, target)
+}
+
+// MollieAmount is an amount, Value must have the number of decimals of the currency, e.g. "10.00"
+type MollieAmount struct {
+	Currency string `json:"currency"`
+	Value    string `json:"value"`
+}
+
+// MollieLink struct
+type MollieLink struct {
+	Href string `json:"href"`
+	Type string `json:"type"`
+}
+
+// MollieLinks are the HAL links of a Mollie resource
+type MollieLinks struct {
+	Self          *MollieLink `json:"self,omitempty"`
+	Checkout      *MollieLink `json:"checkout,omitempty"`
+	Dashboard     *MollieLink `json:"dashboard,omitempty"`
+	Documentation *MollieLink `json:"documentation,omitempty"`
+	Next          *MollieLink `json:"next,omitempty"`
+	Previous      *MollieLink `json:"previous,omitempty"`
+}
+
+// MolliePaymentRequest struct
+// Doc: https://docs.mollie.com/reference/v2/payments-api/create-payment
+type MolliePaymentRequest struct {
+	Amount       MollieAmount           `json:"amount"`
+	Description  string                 `json:"description"`
+	RedirectURL  string                 `json:"redirectUrl,omitempty"`
+	CancelURL    string                 `json:"cancelUrl,omitempty"`
+	WebhookURL   string                 `json:"webhookUrl,omitempty"`
+	Locale       string                 `json:"locale,omitempty"`
+	Method       []string               `json:"method,omitempty"`
+	Issuer       string                 `json:"issuer,omitempty"` // iDEAL bank or other issuer, see ListMethods
+	Metadata     map[string]interface{} `json:"metadata,omitempty"`
+	SequenceType string                 `json:"sequenceType,omitempty"`
+	CustomerID   string                 `json:"customerId,omitempty"`
+	MandateID    string                 `json:"mandateId,omitempty"`
+}
+
+// MolliePayment struct
+type MolliePayment struct {
+	ID              string                 `json:"id"`
+	Mode            string                 `json:"mode"`
+	Status          string                 `json:"status"`
+	IsCancelable    bool                   `json:"isCancelable"`
+	Amount          *MollieAmount          `json:"amount"`
+	AmountRefunded  *MollieAmount          `json:"amountRefunded,omitempty"`
+	AmountRemaining *MollieAmount          `json:"amountRemaining,omitempty"`
+	Description     string                 `json:"description"`
+	Method          string                 `json:"method,omitempty"`
+	Metadata        map[string]interface{} `json:"metadata,omitempty"`
+	SequenceType    string                 `json:"sequenceType,omitempty"`
+	CustomerID      string                 `json:"customerId,omitempty"`
+	MandateID       string                 `json:"mandateId,omitempty"`
+	SubscriptionID  string                 `json:"subscriptionId,omitempty"`
+	RedirectURL     string                 `json:"redirectUrl,omitempty"`
+	WebhookURL      string                 `json:"webhookUrl,omitempty"`
+	CreatedAt       JSONTime               `json:"createdAt"`
+	PaidAt          *JSONTime              `json:"paidAt,omitempty"`
+	ExpiresAt       *JSONTime              `json:"expiresAt,omitempty"`
+	Links           MollieLinks            `json:"_links"`
+}
+
+// CheckoutURL returns the URL to redirect the customer to, empty once the payment is completed
+func (p *MolliePayment) CheckoutURL() string {
+	if p.Links.Checkout == nil {
+		return ""
+	}
+	return p.Links.Checkout.Href
+}
+
+// IsPaid reports whether the payment is paid
+func (p *MolliePayment) IsPaid() bool {
+	return p.Status == MolliePaymentStatusPaid
+}
+
+// MolliePaymentList struct
+type MolliePaymentList struct {
+	Count    int `json:"count"`
+	Embedded struct {
+		Payments []MolliePayment `json:"payments"`
+	} `json:"_embedded"`
+	Links MollieLinks `json:"_links"`
+}
+
+// MollieRefundRequest struct
+// Doc: https://docs.mollie.com/reference/v2/refunds-api/create-payment-refund
+type MollieRefundRequest struct {
+	Amount      MollieAmount           `json:"amount"`
+	Description string                 `json:"description,omitempty"`
+	Metadata    map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// MollieRefund struct
+type MollieRefund struct {
+	ID          string        `json:"id"`
+	Amount      *MollieAmount `json:"amount"`
+	Status      string        `json:"status"`
+	Description string        `json:"description,omitempty"`
+	PaymentID   string        `json:"paymentId"`
+	CreatedAt   JSONTime      `json:"createdAt"`
+}
+
+// MollieRefundList struct
+type MollieRefundList struct {
+	Count    int `json:"count"`
+	Embedded struct {
+		Refunds []MollieRefund `json:"refunds"`
+	} `json:"_embedded"`
+}
+
+// MollieIssuer is an issuer of a payment method, e.g. an iDEAL bank
+type MollieIssuer struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// MollieMethod struct
+type MollieMethod struct {
+	ID            string         `json:"id"`
+	Description   string         `json:"description"`
+	MinimumAmount *MollieAmount  `json:"minimumAmount,omitempty"`
+	MaximumAmount *MollieAmount  `json:"maximumAmount,omitempty"`
+	Issuers       []MollieIssuer `json:"issuers,omitempty"`
+}
+
+// MollieMethodList struct
+type MollieMethodList struct {
+	Count    int `json:"count"`
+	Embedded struct {
+		Methods []MollieMethod `json:"methods"`
+	} `json:"_embedded"`
+}
+
+// MollieCustomerRequest struct
+type MollieCustomerRequest struct {
+	Name     string                 `json:"name,omitempty"`
+	Email    string                 `json:"email,omitempty"`
+	Locale   string                 `json:"locale,omitempty"`
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// MollieCustomer struct
+type MollieCustomer struct {
+	ID        string                 `json:"id"`
+	Mode      string                 `json:"mode"`
+	Name      string                 `json:"name,omitempty"`
+	Email     string                 `json:"email,omitempty"`
+	Locale    string                 `json:"locale,omitempty"`
+	Metadata  map[string]interface{} `json:"metadata,omitempty"`
+	CreatedAt JSONTime               `json:"createdAt"`
+}
+
+// MollieSubscriptionRequest struct
+// Doc: https://docs.mollie.com/reference/v2/subscriptions-api/create-subscription
+type MollieSubscriptionRequest struct {
+	Amount      MollieAmount           `json:"amount"`
+	Interval    string                 `json:"interval"` // e.g. "1 month", "14 days"
+	Description string                 `json:"description"`
+	Times       int                    `json:"times,omitempty"`
+	StartDate   string                 `json:"startDate,omitempty"` // YYYY-MM-DD
+	Method      string                 `json:"method,omitempty"`
+	MandateID   string                 `json:"mandateId,omitempty"`
+	WebhookURL  string                 `json:"webhookUrl,omitempty"`
+	Metadata    map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// MollieSubscription struct
+type MollieSubscription struct {
+	ID              string                 `json:"id"`
+	Mode            string                 `json:"mode"`
+	Status          string                 `json:"status"`
+	Amount          *MollieAmount          `json:"amount"`
+	Times           int                    `json:"times,omitempty"`
+	TimesRemaining  int                    `json:"timesRemaining,omitempty"`
+	Interval        string                 `json:"interval"`
+	StartDate       string                 `json:"startDate"`
+	NextPaymentDate string                 `json:"nextPaymentDate,omitempty"`
+	Description     string                 `json:"description"`
+	Method          string                 `json:"method,omitempty"`
+	MandateID       string                 `json:"mandateId,omitempty"`
+	CustomerID      string                 `json:"customerId"`
+	WebhookURL      string                 `json:"webhookUrl,omitempty"`
+	Metadata        map[string]interface{} `json:"metadata,omitempty"`
+	CreatedAt       JSONTime               `json:"createdAt"`
+	CanceledAt      *JSONTime              `json:"canceledAt,omitempty"`
+}
+
+// MollieSubscriptionList struct
+type MollieSubscriptionList struct {
+	Count    int `json:"count"`
+	Embedded struct {
+		Subscriptions []MollieSubscription `json:"subscriptions"`
+	} `json:"_embedded"`
+}
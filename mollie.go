@@ -0,0 +1,222 @@
+package payment
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+)
+
+// MollieAPIBase is the base URL of the Mollie API, for both test and live API keys
+const MollieAPIBase = "https://api.mollie.com/v2"
+
+// ErrInvalidMollieConfig is returned when a Mollie client is created without APIKey
+var ErrInvalidMollieConfig = errors.New("mollie: APIKey is required to create a client")
+
+// IMollie is the Mollie client interface
+type IMollie interface {
+	CreatePayment(ctx context.Context, payment MolliePaymentRequest) (*MolliePayment, error)
+	GetPayment(ctx context.Context, paymentID string) (*MolliePayment, error)
+	CancelPayment(ctx context.Context, paymentID string) (*MolliePayment, error)
+	ListPayments(ctx context.Context, from string, limit int) (*MolliePaymentList, error)
+	CreateRefund(ctx context.Context, paymentID string, refund MollieRefundRequest) (*MollieRefund, error)
+	ListRefunds(ctx context.Context, paymentID string) (*MollieRefundList, error)
+	ListMethods(ctx context.Context, amount *MollieAmount, includeIssuers bool) (*MollieMethodList, error)
+	CreateCustomer(ctx context.Context, customer MollieCustomerRequest) (*MollieCustomer, error)
+	CreateSubscription(ctx context.Context, customerID string, subscription MollieSubscriptionRequest) (*MollieSubscription, error)
+	GetSubscription(ctx context.Context, customerID, subscriptionID string) (*MollieSubscription, error)
+	CancelSubscription(ctx context.Context, customerID, subscriptionID string) (*MollieSubscription, error)
+	ListSubscriptions(ctx context.Context, customerID string) (*MollieSubscriptionList, error)
+	HandleWebhook(req *http.Request) (*MolliePayment, error)
+}
+
+// MollieClient represents a Mollie REST API client
+type MollieClient struct {
+	Client  *http.Client
+	APIKey  string
+	APIBase string
+}
+
+// NewMollieClient returns a Mollie client for config, APIBase defaults to MollieAPIBase
+func NewMollieClient(config *Mollie) (IMollie, error) {
+	if config == nil || config.APIKey == "" {
+		return nil, ErrInvalidMollieConfig
+	}
+
+	apiBase := config.APIBase
+	if apiBase == "" {
+		apiBase = MollieAPIBase
+	}
+
+	return &MollieClient{Client: &http.Client{}, APIKey: config.APIKey, APIBase: apiBase}, nil
+}
+
+// newMollie returns a Mollie client, or nil when config is invalid
+func newMollie(config *Mollie) IMollie {
+	client, err := NewMollieClient(config)
+	if err != nil {
+		log.Println("Unable to init Mollie client: ", err)
+		return nil
+	}
+
+	return client
+}
+
+// send makes an authenticated request to the Mollie API
+func (c *MollieClient) send(ctx context.Context, method, path string, payload, v interface{}) error {
+	req, err := newJSONRequest(ctx, method, c.APIBase+path, payload)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.APIKey)
+
+	return sendJSON(c.Client, req, v, func(resp *http.Response, body []byte) error {
+		errResp := &MollieError{Response: resp, Status: resp.StatusCode}
+		json.Unmarshal(body, errResp)
+		return errResp
+	})
+}
+
+// CreatePayment creates a payment, redirect the customer to its CheckoutURL
+// Endpoint: POST /v2/payments
+func (c *MollieClient) CreatePayment(ctx context.Context, payment MolliePaymentRequest) (*MolliePayment, error) {
+	response := &MolliePayment{}
+	err := c.send(ctx, http.MethodPost, "/payments", payment, response)
+	return response, err
+}
+
+// GetPayment returns a payment by ID
+// Endpoint: GET /v2/payments/ID
+func (c *MollieClient) GetPayment(ctx context.Context, paymentID string) (*MolliePayment, error) {
+	response := &MolliePayment{}
+	err := c.send(ctx, http.MethodGet, "/payments/"+url.PathEscape(paymentID), nil, response)
+	return response, err
+}
+
+// CancelPayment cancels a payment, only possible while IsCancelable is true
+// Endpoint: DELETE /v2/payments/ID
+func (c *MollieClient) CancelPayment(ctx context.Context, paymentID string) (*MolliePayment, error) {
+	response := &MolliePayment{}
+	err := c.send(ctx, http.MethodDelete, "/payments/"+url.PathEscape(paymentID), nil, response)
+	return response, err
+}
+
+// ListPayments returns the payments, newest first, starting at the payment from when set
+// Endpoint: GET /v2/payments
+func (c *MollieClient) ListPayments(ctx context.Context, from string, limit int) (*MolliePaymentList, error) {
+	q := url.Values{}
+	if from != "" {
+		q.Set("from", from)
+	}
+	if limit > 0 {
+		q.Set("limit", fmt.Sprint(limit))
+	}
+
+	path := "/payments"
+	if len(q) > 0 {
+		path += "?" + q.Encode()
+	}
+
+	response := &MolliePaymentList{}
+	err := c.send(ctx, http.MethodGet, path, nil, response)
+	return response, err
+}
+
+// CreateRefund refunds a payment, fully when refund.Amount is the payment amount
+// Endpoint: POST /v2/payments/ID/refunds
+func (c *MollieClient) CreateRefund(ctx context.Context, paymentID string, refund MollieRefundRequest) (*MollieRefund, error) {
+	response := &MollieRefund{}
+	err := c.send(ctx, http.MethodPost, "/payments/"+url.PathEscape(paymentID)+"/refunds", refund, response)
+	return response, err
+}
+
+// ListRefunds returns the refunds of a payment
+// Endpoint: GET /v2/payments/ID/refunds
+func (c *MollieClient) ListRefunds(ctx context.Context, paymentID string) (*MollieRefundList, error) {
+	response := &MollieRefundList{}
+	err := c.send(ctx, http.MethodGet, "/payments/"+url.PathEscape(paymentID)+"/refunds", nil, response)
+	return response, err
+}
+
+// ListMethods returns the payment methods enabled for the account and available for amount, when set.
+// With includeIssuers, the iDEAL banks and the other issuers of the methods are returned too
+// Endpoint: GET /v2/methods
+func (c *MollieClient) ListMethods(ctx context.Context, amount *MollieAmount, includeIssuers bool) (*MollieMethodList, error) {
+	q := url.Values{}
+	if amount != nil {
+		q.Set("amount[value]", amount.Value)
+		q.Set("amount[currency]", amount.Currency)
+	}
+	if includeIssuers {
+		q.Set("include", "issuers")
+	}
+
+	path := "/methods"
+	if len(q) > 0 {
+		path += "?" + q.Encode()
+	}
+
+	response := &MollieMethodList{}
+	err := c.send(ctx, http.MethodGet, path, nil, response)
+	return response, err
+}
+
+// CreateCustomer creates a customer, required for subscriptions
+// Endpoint: POST /v2/customers
+func (c *MollieClient) CreateCustomer(ctx context.Context, customer MollieCustomerRequest) (*MollieCustomer, error) {
+	response := &MollieCustomer{}
+	err := c.send(ctx, http.MethodPost, "/customers", customer, response)
+	return response, err
+}
+
+// CreateSubscription creates a subscription for a customer having a valid mandate
+// Endpoint: POST /v2/customers/ID/subscriptions
+func (c *MollieClient) CreateSubscription(ctx context.Context, customerID string, subscription MollieSubscriptionRequest) (*MollieSubscription, error) {
+	response := &MollieSubscription{}
+	err := c.send(ctx, http.MethodPost, "/customers/"+url.PathEscape(customerID)+"/subscriptions", subscription, response)
+	return response, err
+}
+
+// GetSubscription returns a subscription of a customer
+// Endpoint: GET /v2/customers/ID/subscriptions/ID
+func (c *MollieClient) GetSubscription(ctx context.Context, customerID, subscriptionID string) (*MollieSubscription, error) {
+	response := &MollieSubscription{}
+	err := c.send(ctx, http.MethodGet, "/customers/"+url.PathEscape(customerID)+"/subscriptions/"+url.PathEscape(subscriptionID), nil, response)
+	return response, err
+}
+
+// CancelSubscription cancels a subscription of a customer
+// Endpoint: DELETE /v2/customers/ID/subscriptions/ID
+func (c *MollieClient) CancelSubscription(ctx context.Context, customerID, subscriptionID string) (*MollieSubscription, error) {
+	response := &MollieSubscription{}
+	err := c.send(ctx, http.MethodDelete, "/customers/"+url.PathEscape(customerID)+"/subscriptions/"+url.PathEscape(subscriptionID), nil, response)
+	return response, err
+}
+
+// ListSubscriptions returns the subscriptions of a customer
+// Endpoint: GET /v2/customers/ID/subscriptions
+func (c *MollieClient) ListSubscriptions(ctx context.Context, customerID string) (*MollieSubscriptionList, error) {
+	response := &MollieSubscriptionList{}
+	err := c.send(ctx, http.MethodGet, "/customers/"+url.PathEscape(customerID)+"/subscriptions", nil, response)
+	return response, err
+}
+
+// HandleWebhook returns the payment a webhook call is about.
+// Mollie webhooks only post the payment ID and are not signed, so the payment is fetched from the API,
+// which also proves the call is genuine
+// Doc: https://docs.mollie.com/overview/webhooks
+func (c *MollieClient) HandleWebhook(req *http.Request) (*MolliePayment, error) {
+	if err := req.ParseForm(); err != nil {
+		return nil, err
+	}
+
+	id := req.PostForm.Get("id")
+	if id == "" {
+		return nil, ErrMollieWebhookMissingID
+	}
+
+	return c.GetPayment(req.Context(), id)
+}
@@ -0,0 +1,161 @@
+package payment
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// MoMo request types
+const (
+	MoMoRequestTypeCaptureWallet = "captureWallet"
+	MoMoRequestTypePayWithATM    = "payWithATM"
+	MoMoRequestTypePayWithCC     = "payWithCC"
+)
+
+// MoMo result codes
+// Doc: https://developers.momo.vn/v3/docs/payment/api/result-handling/resultcode
+const (
+	MoMoResultSuccess             = 0
+	MoMoResultAccessDenied        = 11
+	MoMoResultAuthenticationError = 13
+	MoMoResultBadFormat           = 20
+	MoMoResultInvalidAmount       = 21
+	MoMoResultInvalidRequest      = 22
+	MoMoResultOrderNotFound       = 42
+	MoMoResultInitiated           = 1000
+	MoMoResultUserCanceled        = 1006
+	MoMoResultAuthorized          = 9000
+)
+
+// MoMoError is returned for a non 2xx response or a failed result code
+type MoMoError struct {
+	Response   *http.Response `json:"-"`
+	ResultCode int            `json:"resultCode"`
+	Message    string         `json:"message"`
+}
+
+func (e *MoMoError) Error() string {
+	if e.Response != nil {
+		return fmt.Sprintf("%v %v: %d %d %s", e.Response.Request.Method, e.Response.Request.URL, e.Response.StatusCode, e.ResultCode, e.Message)
+	}
+	return fmt.Sprintf("momo: %d %s", e.ResultCode, e.Message)
+}
+
+// Is reports whether the error belongs to the target error category
+func (e *MoMoError) Is(target error) bool {
+	switch target {
+	case ErrUnauthorized:
+		if e.ResultCode == MoMoResultAccessDenied || e.ResultCode == MoMoResultAuthenticationError {
+			return true
+		}
+	case ErrNotFound:
+		if e.ResultCode == MoMoResultOrderNotFound {
+			return true
+		}
+	case ErrValidation:
+		if e.ResultCode == MoMoResultBadFormat || e.ResultCode == MoMoResultInvalidAmount || e.ResultCode == MoMoResultInvalidRequest {
+			return true
+		}
+	}
+	if e.Response != nil {
+		return statusErrorIs(e.Response.StatusCode, target)
+	}
+	return false
+}
+
+// momoRequestFailed reports whether a result code is an error of the request rather than a state of the payment
+func momoRequestFailed(code int) bool {
+	switch code {
+	case MoMoResultAccessDenied, MoMoResultAuthenticationError, MoMoResultBadFormat,
+		MoMoResultInvalidAmount, MoMoResultInvalidRequest, MoMoResultOrderNotFound:
+		return true
+	}
+	return false
+}
+
+// momoResult is implemented by the responses carrying a result code
+type momoResult interface {
+	result() (int, string)
+}
+
+// MoMoResult are the fields common to the responses
+type MoMoResult struct {
+	PartnerCode  string `json:"partnerCode"`
+	OrderID      string `json:"orderId"`
+	RequestID    string `json:"requestId"`
+	Amount       int64  `json:"amount"`
+	ResponseTime int64  `json:"responseTime"`
+	Message      string `json:"message"`
+	ResultCode   int    `json:"resultCode"`
+}
+
+func (r *MoMoResult) result() (int, string) {
+	return r.ResultCode, r.Message
+}
+
+// MoMoPaymentRequest is a payment in VND. PartnerCode and Signature are set by the client,
+// RequestID, RequestType, RedirectURL, IPNURL and Lang when empty
+type MoMoPaymentRequest struct {
+	PartnerCode string `json:"partnerCode"`
+	RequestID   string `json:"requestId"`
+	Amount      int64  `json:"amount"`
+	OrderID     string `json:"orderId"`
+	OrderInfo   string `json:"orderInfo"`
+	RedirectURL string `json:"redirectUrl"`
+	IPNURL      string `json:"ipnUrl"`
+	RequestType string `json:"requestType"`
+	ExtraData   string `json:"extraData"` // Base64 encoded JSON, may be empty
+	Lang        string `json:"lang"`
+	Signature   string `json:"signature"`
+}
+
+// MoMoPaymentResponse struct
+type MoMoPaymentResponse struct {
+	MoMoResult
+	PayURL    string `json:"payUrl"`
+	Deeplink  string `json:"deeplink,omitempty"`
+	QRCodeURL string `json:"qrCodeUrl,omitempty"`
+}
+
+// MoMoIPN is an instant payment notification
+type MoMoIPN struct {
+	PartnerCode  string `json:"partnerCode"`
+	OrderID      string `json:"orderId"`
+	RequestID    string `json:"requestId"`
+	Amount       int64  `json:"amount"`
+	OrderInfo    string `json:"orderInfo"`
+	OrderType    string `json:"orderType"`
+	TransID      int64  `json:"transId"`
+	ResultCode   int    `json:"resultCode"`
+	Message      string `json:"message"`
+	PayType      string `json:"payType"`
+	ResponseTime int64  `json:"responseTime"`
+	ExtraData    string `json:"extraData"`
+	Signature    string `json:"signature"`
+}
+
+// MoMoQueryResponse struct
+type MoMoQueryResponse struct {
+	MoMoResult
+	ExtraData string `json:"extraData"`
+	TransID   int64  `json:"transId"`
+	PayType   string `json:"payType"`
+}
+
+// MoMoRefundRequest is a refund in VND. PartnerCode and Signature are set by the client, RequestID and Lang when empty
+type MoMoRefundRequest struct {
+	PartnerCode string `json:"partnerCode"`
+	OrderID     string `json:"orderId"` // A new order ID for the refund
+	RequestID   string `json:"requestId"`
+	Amount      int64  `json:"amount"`
+	TransID     int64  `json:"transId"` // MoMo transaction ID of the payment
+	Lang        string `json:"lang"`
+	Description string `json:"description"`
+	Signature   string `json:"signature"`
+}
+
+// MoMoRefundResponse struct
+type MoMoRefundResponse struct {
+	MoMoResult
+	TransID int64 `json:"transId"`
+}
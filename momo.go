@@ -0,0 +1,247 @@
+package payment
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// MoMoAPIBaseSandBox points to the test environment of the MoMo payment gateway
+	MoMoAPIBaseSandBox = "https://test-payment.momo.vn"
+
+	// MoMoAPIBaseLive points to the live MoMo payment gateway
+	MoMoAPIBaseLive = "https://payment.momo.vn"
+)
+
+var (
+	// ErrInvalidMoMoConfig is returned when a MoMo client is created without PartnerCode, AccessKey, SecretKey or APIBase
+	ErrInvalidMoMoConfig = errors.New("momo: PartnerCode, AccessKey, SecretKey and APIBase are required to create a client")
+
+	// ErrMoMoSignature is returned when the signature of an IPN is invalid
+	ErrMoMoSignature = errors.New("momo: invalid signature")
+)
+
+// IMoMo is the MoMo client interface
+type IMoMo interface {
+	CreatePayment(ctx context.Context, payment MoMoPaymentRequest) (*MoMoPaymentResponse, error)
+	VerifyIPN(req *http.Request) (*MoMoIPN, error)
+	QueryPayment(ctx context.Context, orderID string) (*MoMoQueryResponse, error)
+	Refund(ctx context.Context, refund MoMoRefundRequest) (*MoMoRefundResponse, error)
+}
+
+// MoMoClient represents a MoMo payment gateway client
+type MoMoClient struct {
+	Client      *http.Client
+	PartnerCode string
+	AccessKey   string
+	SecretKey   string
+	APIBase     string
+	RedirectURL string
+	IPNURL      string
+}
+
+// NewMoMoClient returns a MoMo client for config
+func NewMoMoClient(config *MoMo) (IMoMo, error) {
+	if config == nil || config.PartnerCode == "" || config.AccessKey == "" || config.SecretKey == "" || config.APIBase == "" {
+		return nil, ErrInvalidMoMoConfig
+	}
+
+	return &MoMoClient{
+		Client:      &http.Client{},
+		PartnerCode: config.PartnerCode,
+		AccessKey:   config.AccessKey,
+		SecretKey:   config.SecretKey,
+		APIBase:     config.APIBase,
+		RedirectURL: config.RedirectURL,
+		IPNURL:      config.IPNURL,
+	}, nil
+}
+
+// newMoMo returns a MoMo client, or nil when config is invalid
+func newMoMo(config *MoMo) IMoMo {
+	client, err := NewMoMoClient(config)
+	if err != nil {
+		log.Println("Unable to init MoMo client: ", err)
+		return nil
+	}
+
+	return client
+}
+
+// sign returns the hex HMAC-SHA256 of the "key=value" pairs of fields sorted by key and joined by "&".
+// The access key is always part of the signed fields
+func (c *MoMoClient) sign(fields map[string]string) string {
+	fields["accessKey"] = c.AccessKey
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, len(keys))
+	for i, key := range keys {
+		pairs[i] = key + "=" + fields[key]
+	}
+
+	mac := hmac.New(sha256.New, []byte(c.SecretKey))
+	mac.Write([]byte(strings.Join(pairs, "&")))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// send posts payload to path and decodes the response into v.
+// A result code for which failed reports true is returned as a MoMoError
+func (c *MoMoClient) send(ctx context.Context, path string, payload interface{}, v momoResult, failed func(code int) bool) error {
+	req, err := newJSONRequest(ctx, http.MethodPost, c.APIBase+path, payload)
+	if err != nil {
+		return err
+	}
+
+	err = sendJSON(c.Client, req, v, func(resp *http.Response, body []byte) error {
+		e := &MoMoError{Response: resp}
+		json.Unmarshal(body, e)
+		return e
+	})
+	if err != nil {
+		return err
+	}
+
+	if code, message := v.result(); failed(code) {
+		return &MoMoError{ResultCode: code, Message: message}
+	}
+	return nil
+}
+
+// newRequestID returns a request ID unique to the partner
+func (c *MoMoClient) newRequestID() string {
+	return c.PartnerCode + strconv.FormatInt(time.Now().UnixNano(), 10)
+}
+
+// CreatePayment creates a captureWallet payment, of which the buyer opens PayURL, Deeplink or QRCodeURL.
+// The result is posted to the IPN URL, see VerifyIPN
+// Endpoint: POST /v2/gateway/api/create
+// Doc: https://developers.momo.vn/v3/docs/payment/api/wallet/onetime
+func (c *MoMoClient) CreatePayment(ctx context.Context, payment MoMoPaymentRequest) (*MoMoPaymentResponse, error) {
+	payment.PartnerCode = c.PartnerCode
+	if payment.RequestID == "" {
+		payment.RequestID = c.newRequestID()
+	}
+	if payment.RequestType == "" {
+		payment.RequestType = MoMoRequestTypeCaptureWallet
+	}
+	if payment.RedirectURL == "" {
+		payment.RedirectURL = c.RedirectURL
+	}
+	if payment.IPNURL == "" {
+		payment.IPNURL = c.IPNURL
+	}
+	if payment.Lang == "" {
+		payment.Lang = "vi"
+	}
+	payment.Signature = c.sign(map[string]string{
+		"amount":      strconv.FormatInt(payment.Amount, 10),
+		"extraData":   payment.ExtraData,
+		"ipnUrl":      payment.IPNURL,
+		"orderId":     payment.OrderID,
+		"orderInfo":   payment.OrderInfo,
+		"partnerCode": payment.PartnerCode,
+		"redirectUrl": payment.RedirectURL,
+		"requestId":   payment.RequestID,
+		"requestType": payment.RequestType,
+	})
+
+	response := &MoMoPaymentResponse{}
+	err := c.send(ctx, "/v2/gateway/api/create", payment, response, func(code int) bool { return code != MoMoResultSuccess })
+	return response, err
+}
+
+// VerifyIPN checks the signature of an instant payment notification and decodes it.
+// Answer the notification with 204 No Content within 15 seconds
+// Doc: https://developers.momo.vn/v3/docs/payment/api/result-handling/notification
+func (c *MoMoClient) VerifyIPN(req *http.Request) (*MoMoIPN, error) {
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	ipn := &MoMoIPN{}
+	if err = json.Unmarshal(body, ipn); err != nil {
+		return nil, err
+	}
+
+	signature := c.sign(map[string]string{
+		"amount":       strconv.FormatInt(ipn.Amount, 10),
+		"extraData":    ipn.ExtraData,
+		"message":      ipn.Message,
+		"orderId":      ipn.OrderID,
+		"orderInfo":    ipn.OrderInfo,
+		"orderType":    ipn.OrderType,
+		"partnerCode":  ipn.PartnerCode,
+		"payType":      ipn.PayType,
+		"requestId":    ipn.RequestID,
+		"responseTime": strconv.FormatInt(ipn.ResponseTime, 10),
+		"resultCode":   strconv.Itoa(ipn.ResultCode),
+		"transId":      strconv.FormatInt(ipn.TransID, 10),
+	})
+	if !hmac.Equal([]byte(signature), []byte(ipn.Signature)) || ipn.PartnerCode != c.PartnerCode {
+		return nil, ErrMoMoSignature
+	}
+
+	return ipn, nil
+}
+
+// QueryPayment returns the status of a payment. A pending payment is not an error,
+// check ResultCode against MoMoResultSuccess and MoMoResultInitiated
+// Endpoint: POST /v2/gateway/api/query
+func (c *MoMoClient) QueryPayment(ctx context.Context, orderID string) (*MoMoQueryResponse, error) {
+	requestID := c.newRequestID()
+	payload := map[string]string{
+		"partnerCode": c.PartnerCode,
+		"requestId":   requestID,
+		"orderId":     orderID,
+		"lang":        "en",
+	}
+	payload["signature"] = c.sign(map[string]string{
+		"orderId":     orderID,
+		"partnerCode": c.PartnerCode,
+		"requestId":   requestID,
+	})
+
+	response := &MoMoQueryResponse{}
+	err := c.send(ctx, "/v2/gateway/api/query", payload, response, momoRequestFailed)
+	return response, err
+}
+
+// Refund refunds a payment by its MoMo transaction ID, partially when Amount is less than the amount of the payment
+// Endpoint: POST /v2/gateway/api/refund
+func (c *MoMoClient) Refund(ctx context.Context, refund MoMoRefundRequest) (*MoMoRefundResponse, error) {
+	refund.PartnerCode = c.PartnerCode
+	if refund.RequestID == "" {
+		refund.RequestID = c.newRequestID()
+	}
+	if refund.Lang == "" {
+		refund.Lang = "en"
+	}
+	refund.Signature = c.sign(map[string]string{
+		"amount":      strconv.FormatInt(refund.Amount, 10),
+		"description": refund.Description,
+		"orderId":     refund.OrderID,
+		"partnerCode": refund.PartnerCode,
+		"requestId":   refund.RequestID,
+		"transId":     strconv.FormatInt(refund.TransID, 10),
+	})
+
+	response := &MoMoRefundResponse{}
+	err := c.send(ctx, "/v2/gateway/api/refund", refund, response, func(code int) bool { return code != MoMoResultSuccess })
+	return response, err
+}
@@ -0,0 +1,226 @@
+package payment
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// Paddle transaction statuses
+const (
+	PaddleTransactionStatusDraft     = "draft"
+	PaddleTransactionStatusReady     = "ready"
+	PaddleTransactionStatusBilled    = "billed"
+	PaddleTransactionStatusPaid      = "paid"
+	PaddleTransactionStatusCompleted = "completed"
+	PaddleTransactionStatusCanceled  = "canceled"
+	PaddleTransactionStatusPastDue   = "past_due"
+)
+
+// Paddle subscription statuses
+const (
+	PaddleSubscriptionStatusActive   = "active"
+	PaddleSubscriptionStatusCanceled = "canceled"
+	PaddleSubscriptionStatusPastDue  = "past_due"
+	PaddleSubscriptionStatusPaused   = "paused"
+	PaddleSubscriptionStatusTrialing = "trialing"
+)
+
+// Values of effective_from of the pause, resume and cancel calls
+const (
+	PaddleEffectiveFromNextBillingPeriod = "next_billing_period"
+	PaddleEffectiveFromImmediately       = "immediately"
+)
+
+// Paddle proration billing modes
+const (
+	PaddleProrationProratedImmediately = "prorated_immediately"
+	PaddleProrationProratedNextBilling = "prorated_next_billing_period"
+	PaddleProrationFullImmediately     = "full_immediately"
+	PaddleProrationFullNextBilling     = "full_next_billing_period"
+	PaddleProrationDoNotBill           = "do_not_bill"
+)
+
+// Paddle webhook event types
+const (
+	PaddleEventTransactionCompleted     = "transaction.completed"
+	PaddleEventTransactionPaymentFailed = "transaction.payment_failed"
+	PaddleEventSubscriptionCreated      = "subscription.created"
+	PaddleEventSubscriptionUpdated      = "subscription.updated"
+	PaddleEventSubscriptionPaused       = "subscription.paused"
+	PaddleEventSubscriptionResumed      = "subscription.resumed"
+	PaddleEventSubscriptionCanceled     = "subscription.canceled"
+	PaddleEventSubscriptionPastDue      = "subscription.past_due"
+)
+
+// PaddleError is returned for a non 2xx response
+// Doc: https://developer.paddle.com/api-reference/about/errors
+type PaddleError struct {
+	Response *http.Response `json:"-"`
+	Type     string         `json:"type"`
+	Code     string         `json:"code"`
+	Detail   string         `json:"detail"`
+	Errors   []struct {
+		Field   string `json:"field"`
+		Message string `json:"message"`
+	} `json:"errors,omitempty"`
+}
+
+func (e *PaddleError) Error() string {
+	return fmt.Sprintf("%v %v: %d %s: %s", e.Response.Request.Method, e.Response.Request.URL, e.Response.StatusCode, e.Code, e.Detail)
+}
+
+// Is reports whether the error belongs to the target error category
+func (e *PaddleError) Is(target error) bool {
+	return statusErrorIs(e.Response.StatusCode, target)
+}
+
+// PaddlePagination is the pagination of a list
+type PaddlePagination struct {
+	PerPage        int    `json:"per_page"`
+	Next           string `json:"next"`
+	HasMore        bool   `json:"has_more"`
+	EstimatedTotal int    `json:"estimated_total"`
+}
+
+// PaddleItem is a price and its quantity
+type PaddleItem struct {
+	PriceID  string `json:"price_id"`
+	Quantity int    `json:"quantity"`
+}
+
+// PaddleCheckout struct
+type PaddleCheckout struct {
+	URL string `json:"url,omitempty"`
+}
+
+// PaddleMoney is an amount in the lowest denomination of CurrencyCode, e.g. "1000" for 10.00 USD
+type PaddleMoney struct {
+	Amount       string `json:"amount"`
+	CurrencyCode string `json:"currency_code"`
+}
+
+// PaddleTransactionRequest struct
+type PaddleTransactionRequest struct {
+	Items          []PaddleItem           `json:"items"`
+	CustomerID     string                 `json:"customer_id,omitempty"`
+	AddressID      string                 `json:"address_id,omitempty"`
+	BusinessID     string                 `json:"business_id,omitempty"`
+	CurrencyCode   string                 `json:"currency_code,omitempty"`
+	CollectionMode string                 `json:"collection_mode,omitempty"` // automatic or manual
+	DiscountID     string                 `json:"discount_id,omitempty"`
+	CustomData     map[string]interface{} `json:"custom_data,omitempty"`
+	Checkout       *PaddleCheckout        `json:"checkout,omitempty"`
+}
+
+// PaddleTransaction struct
+type PaddleTransaction struct {
+	ID             string                 `json:"id"`
+	Status         string                 `json:"status"`
+	CustomerID     string                 `json:"customer_id"`
+	AddressID      string                 `json:"address_id"`
+	SubscriptionID string                 `json:"subscription_id"`
+	InvoiceID      string                 `json:"invoice_id"`
+	InvoiceNumber  string                 `json:"invoice_number"`
+	Origin         string                 `json:"origin"`
+	CollectionMode string                 `json:"collection_mode"`
+	CurrencyCode   string                 `json:"currency_code"`
+	CustomData     map[string]interface{} `json:"custom_data"`
+	Checkout       *PaddleCheckout        `json:"checkout"`
+	Details        struct {
+		Totals struct {
+			Subtotal     string `json:"subtotal"`
+			Discount     string `json:"discount"`
+			Tax          string `json:"tax"`
+			Total        string `json:"total"`
+			GrandTotal   string `json:"grand_total"`
+			CurrencyCode string `json:"currency_code"`
+		} `json:"totals"`
+	} `json:"details"`
+	BilledAt  string `json:"billed_at"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+// PaddleSubscriptionItem struct
+type PaddleSubscriptionItem struct {
+	Status    string `json:"status"`
+	Quantity  int    `json:"quantity"`
+	Recurring bool   `json:"recurring"`
+	Price     struct {
+		ID          string      `json:"id"`
+		ProductID   string      `json:"product_id"`
+		Description string      `json:"description"`
+		UnitPrice   PaddleMoney `json:"unit_price"`
+	} `json:"price"`
+	NextBilledAt string `json:"next_billed_at"`
+}
+
+// PaddleSubscription struct
+type PaddleSubscription struct {
+	ID             string                   `json:"id"`
+	Status         string                   `json:"status"`
+	CustomerID     string                   `json:"customer_id"`
+	AddressID      string                   `json:"address_id"`
+	CurrencyCode   string                   `json:"currency_code"`
+	CollectionMode string                   `json:"collection_mode"`
+	Items          []PaddleSubscriptionItem `json:"items"`
+	CustomData     map[string]interface{}   `json:"custom_data"`
+	BillingCycle   struct {
+		Interval  string `json:"interval"`
+		Frequency int    `json:"frequency"`
+	} `json:"billing_cycle"`
+	CurrentBillingPeriod *struct {
+		StartsAt string `json:"starts_at"`
+		EndsAt   string `json:"ends_at"`
+	} `json:"current_billing_period"`
+	ScheduledChange *struct {
+		Action      string `json:"action"` // pause, resume or cancel
+		EffectiveAt string `json:"effective_at"`
+		ResumeAt    string `json:"resume_at"`
+	} `json:"scheduled_change"`
+	ManagementURLs struct {
+		UpdatePaymentMethod string `json:"update_payment_method"`
+		Cancel              string `json:"cancel"`
+	} `json:"management_urls"`
+	StartedAt     string `json:"started_at"`
+	FirstBilledAt string `json:"first_billed_at"`
+	NextBilledAt  string `json:"next_billed_at"`
+	PausedAt      string `json:"paused_at"`
+	CanceledAt    string `json:"canceled_at"`
+	CreatedAt     string `json:"created_at"`
+	UpdatedAt     string `json:"updated_at"`
+}
+
+// PaddleSubscriptionUpdate struct, Items replaces every item of the subscription
+type PaddleSubscriptionUpdate struct {
+	Items                []PaddleItem           `json:"items,omitempty"`
+	NextBilledAt         string                 `json:"next_billed_at,omitempty"`
+	ProrationBillingMode string                 `json:"proration_billing_mode,omitempty"`
+	CollectionMode       string                 `json:"collection_mode,omitempty"`
+	DiscountID           string                 `json:"discount_id,omitempty"`
+	CustomData           map[string]interface{} `json:"custom_data,omitempty"`
+}
+
+// PaddleWebhookEvent is a notification, of which Data is the entity of EventType
+type PaddleWebhookEvent struct {
+	EventID        string          `json:"event_id"`
+	EventType      string          `json:"event_type"`
+	OccurredAt     string          `json:"occurred_at"`
+	NotificationID string          `json:"notification_id"`
+	Data           json.RawMessage `json:"data"`
+}
+
+// Transaction decodes the data of a transaction.* event
+func (e *PaddleWebhookEvent) Transaction() (*PaddleTransaction, error) {
+	transaction := &PaddleTransaction{}
+	err := json.Unmarshal(e.Data, transaction)
+	return transaction, err
+}
+
+// Subscription decodes the data of a subscription.* event
+func (e *PaddleWebhookEvent) Subscription() (*PaddleSubscription, error) {
+	subscription := &PaddleSubscription{}
+	err := json.Unmarshal(e.Data, subscription)
+	return subscription, err
+}
@@ -0,0 +1,281 @@
+package payment
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// PaddleAPIBaseSandBox points to the Paddle Billing sandbox
+	PaddleAPIBaseSandBox = "https://sandbox-api.paddle.com"
+
+	// PaddleAPIBaseLive points to the live Paddle Billing API
+	PaddleAPIBaseLive = "https://api.paddle.com"
+
+	// PaddleWebhookTolerance is how old the timestamp of a webhook signature may be
+	PaddleWebhookTolerance = 5 * time.Minute
+)
+
+var (
+	// ErrInvalidPaddleConfig is returned when a Paddle client is created without APIKey
+	ErrInvalidPaddleConfig = errors.New("paddle: APIKey is required to create a client")
+
+	// ErrPaddleWebhookSignature is returned by VerifyPaddleWebhook when the signature does not match or is too old
+	ErrPaddleWebhookSignature = errors.New("paddle: invalid webhook signature")
+)
+
+// IPaddle is the Paddle client interface
+type IPaddle interface {
+	CreateCheckout(ctx context.Context, transaction PaddleTransactionRequest) (*PaddleTransaction, error)
+	GetTransaction(ctx context.Context, transactionID string) (*PaddleTransaction, error)
+	ListTransactions(ctx context.Context, query url.Values) ([]PaddleTransaction, *PaddlePagination, error)
+	GetInvoicePDF(ctx context.Context, transactionID string) (string, error)
+	GetSubscription(ctx context.Context, subscriptionID string) (*PaddleSubscription, error)
+	ListSubscriptions(ctx context.Context, query url.Values) ([]PaddleSubscription, *PaddlePagination, error)
+	UpdateSubscription(ctx context.Context, subscriptionID string, update PaddleSubscriptionUpdate) (*PaddleSubscription, error)
+	PauseSubscription(ctx context.Context, subscriptionID, effectiveFrom string) (*PaddleSubscription, error)
+	ResumeSubscription(ctx context.Context, subscriptionID, effectiveFrom string) (*PaddleSubscription, error)
+	CancelSubscription(ctx context.Context, subscriptionID, effectiveFrom string) (*PaddleSubscription, error)
+	GetUpdatePaymentMethodTransaction(ctx context.Context, subscriptionID string) (*PaddleTransaction, error)
+}
+
+// PaddleClient represents a Paddle Billing API client
+type PaddleClient struct {
+	Client  *http.Client
+	APIKey  string
+	APIBase string
+}
+
+// NewPaddleClient returns a Paddle client for config, APIBase defaults to PaddleAPIBaseLive
+func NewPaddleClient(config *Paddle) (IPaddle, error) {
+	if config == nil || config.APIKey == "" {
+		return nil, ErrInvalidPaddleConfig
+	}
+
+	client := &PaddleClient{
+		Client:  &http.Client{},
+		APIKey:  config.APIKey,
+		APIBase: config.APIBase,
+	}
+	if client.APIBase == "" {
+		client.APIBase = PaddleAPIBaseLive
+	}
+
+	return client, nil
+}
+
+// newPaddle returns a Paddle client, or nil when config is invalid
+func newPaddle(config *Paddle) IPaddle {
+	client, err := NewPaddleClient(config)
+	if err != nil {
+		log.Println("Unable to init Paddle client: ", err)
+		return nil
+	}
+
+	return client
+}
+
+// newPaddleError decodes the error returned by the Paddle API
+func newPaddleError(resp *http.Response, body []byte) error {
+	errResp := &PaddleError{Response: resp}
+	var aux struct {
+		Error *PaddleError `json:"error"`
+	}
+	aux.Error = errResp
+	json.Unmarshal(body, &aux)
+	return errResp
+}
+
+// send makes an authenticated request to the API and decodes the data of the response into v,
+// and its pagination into pagination when not nil
+func (c *PaddleClient) send(ctx context.Context, method, path string, payload, v interface{}, pagination *PaddlePagination) error {
+	req, err := newJSONRequest(ctx, method, c.APIBase+path, payload)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.APIKey)
+
+	response := &struct {
+		Data interface{} `json:"data"`
+		Meta struct {
+			Pagination *PaddlePagination `json:"pagination"`
+		} `json:"meta"`
+	}{Data: v}
+	response.Meta.Pagination = pagination
+	return sendJSON(c.Client, req, response, newPaddleError)
+}
+
+// withQuery appends the encoded query to path
+func withQuery(path string, query url.Values) string {
+	if len(query) == 0 {
+		return path
+	}
+	return path + "?" + query.Encode()
+}
+
+// CreateCheckout creates a transaction to be paid at Checkout.URL, the checkout link.
+// Set Checkout.URL to the page of the approved domain hosting Paddle.js, the default payment link is used otherwise
+// Endpoint: POST /transactions
+// Doc: https://developer.paddle.com/api-reference/transactions/create-transaction
+func (c *PaddleClient) CreateCheckout(ctx context.Context, transaction PaddleTransactionRequest) (*PaddleTransaction, error) {
+	response := &PaddleTransaction{}
+	err := c.send(ctx, http.MethodPost, "/transactions", transaction, response, nil)
+	return response, err
+}
+
+// GetTransaction returns a transaction
+// Endpoint: GET /transactions/{transaction_id}
+func (c *PaddleClient) GetTransaction(ctx context.Context, transactionID string) (*PaddleTransaction, error) {
+	response := &PaddleTransaction{}
+	err := c.send(ctx, http.MethodGet, "/transactions/"+url.PathEscape(transactionID), nil, response, nil)
+	return response, err
+}
+
+// ListTransactions returns a page of transactions, filtered by query,
+// e.g. subscription_id and status=billed,completed for the invoices of a subscription.
+// Set query "after" to the ID of the last transaction to get the next page
+// Endpoint: GET /transactions
+func (c *PaddleClient) ListTransactions(ctx context.Context, query url.Values) ([]PaddleTransaction, *PaddlePagination, error) {
+	var response []PaddleTransaction
+	pagination := &PaddlePagination{}
+	err := c.send(ctx, http.MethodGet, withQuery("/transactions", query), nil, &response, pagination)
+	return response, pagination, err
+}
+
+// GetInvoicePDF returns a temporary link to the PDF invoice of a billed or completed transaction
+// Endpoint: GET /transactions/{transaction_id}/invoice
+func (c *PaddleClient) GetInvoicePDF(ctx context.Context, transactionID string) (string, error) {
+	response := &struct {
+		URL string `json:"url"`
+	}{}
+	err := c.send(ctx, http.MethodGet, "/transactions/"+url.PathEscape(transactionID)+"/invoice", nil, response, nil)
+	return response.URL, err
+}
+
+// GetSubscription returns a subscription
+// Endpoint: GET /subscriptions/{subscription_id}
+func (c *PaddleClient) GetSubscription(ctx context.Context, subscriptionID string) (*PaddleSubscription, error) {
+	response := &PaddleSubscription{}
+	err := c.send(ctx, http.MethodGet, "/subscriptions/"+url.PathEscape(subscriptionID), nil, response, nil)
+	return response, err
+}
+
+// ListSubscriptions returns a page of subscriptions, filtered by query, e.g. customer_id or status
+// Endpoint: GET /subscriptions
+func (c *PaddleClient) ListSubscriptions(ctx context.Context, query url.Values) ([]PaddleSubscription, *PaddlePagination, error) {
+	var response []PaddleSubscription
+	pagination := &PaddlePagination{}
+	err := c.send(ctx, http.MethodGet, withQuery("/subscriptions", query), nil, &response, pagination)
+	return response, pagination, err
+}
+
+// UpdateSubscription changes the items or the billing date of a subscription, prorated according to ProrationBillingMode
+// Endpoint: PATCH /subscriptions/{subscription_id}
+func (c *PaddleClient) UpdateSubscription(ctx context.Context, subscriptionID string, update PaddleSubscriptionUpdate) (*PaddleSubscription, error) {
+	response := &PaddleSubscription{}
+	err := c.send(ctx, http.MethodPatch, "/subscriptions/"+url.PathEscape(subscriptionID), update, response, nil)
+	return response, err
+}
+
+// subscriptionAction posts an action with its effective_from to a subscription
+func (c *PaddleClient) subscriptionAction(ctx context.Context, subscriptionID, action, effectiveFrom string) (*PaddleSubscription, error) {
+	var payload interface{}
+	if effectiveFrom != "" {
+		payload = map[string]string{"effective_from": effectiveFrom}
+	}
+	response := &PaddleSubscription{}
+	err := c.send(ctx, http.MethodPost, "/subscriptions/"+url.PathEscape(subscriptionID)+"/"+action, payload, response, nil)
+	return response, err
+}
+
+// PauseSubscription pauses a subscription, effectiveFrom is PaddleEffectiveFromNextBillingPeriod (default) or PaddleEffectiveFromImmediately
+// Endpoint: POST /subscriptions/{subscription_id}/pause
+func (c *PaddleClient) PauseSubscription(ctx context.Context, subscriptionID, effectiveFrom string) (*PaddleSubscription, error) {
+	return c.subscriptionAction(ctx, subscriptionID, "pause", effectiveFrom)
+}
+
+// ResumeSubscription resumes a paused subscription, effectiveFrom is PaddleEffectiveFromImmediately (default) or an RFC 3339 date
+// Endpoint: POST /subscriptions/{subscription_id}/resume
+func (c *PaddleClient) ResumeSubscription(ctx context.Context, subscriptionID, effectiveFrom string) (*PaddleSubscription, error) {
+	return c.subscriptionAction(ctx, subscriptionID, "resume", effectiveFrom)
+}
+
+// CancelSubscription cancels a subscription, effectiveFrom is PaddleEffectiveFromNextBillingPeriod (default) or PaddleEffectiveFromImmediately
+// Endpoint: POST /subscriptions/{subscription_id}/cancel
+func (c *PaddleClient) CancelSubscription(ctx context.Context, subscriptionID, effectiveFrom string) (*PaddleSubscription, error) {
+	return c.subscriptionAction(ctx, subscriptionID, "cancel", effectiveFrom)
+}
+
+// GetUpdatePaymentMethodTransaction returns a transaction of which Checkout.URL lets the customer update their payment method
+// Endpoint: GET /subscriptions/{subscription_id}/update-payment-method-transaction
+func (c *PaddleClient) GetUpdatePaymentMethodTransaction(ctx context.Context, subscriptionID string) (*PaddleTransaction, error) {
+	response := &PaddleTransaction{}
+	err := c.send(ctx, http.MethodGet, "/subscriptions/"+url.PathEscape(subscriptionID)+"/update-payment-method-transaction", nil, response, nil)
+	return response, err
+}
+
+// VerifyPaddleWebhook checks the Paddle-Signature header of a webhook with the secret key of the notification destination
+// and decodes the event. Signatures older than PaddleWebhookTolerance are rejected
+// Doc: https://developer.paddle.com/webhooks/signature-verification
+func VerifyPaddleWebhook(req *http.Request, secretKey string) (*PaddleWebhookEvent, error) {
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var timestamp string
+	var signatures [][]byte
+	for _, part := range strings.Split(req.Header.Get("Paddle-Signature"), ";") {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		switch kv[0] {
+		case "ts":
+			timestamp = kv[1]
+		case "h1":
+			if signature, err := hex.DecodeString(kv[1]); err == nil {
+				signatures = append(signatures, signature)
+			}
+		}
+	}
+
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return nil, ErrPaddleWebhookSignature
+	}
+	if age := time.Since(time.Unix(ts, 0)); age > PaddleWebhookTolerance || age < -PaddleWebhookTolerance {
+		return nil, ErrPaddleWebhookSignature
+	}
+
+	mac := hmac.New(sha256.New, []byte(secretKey))
+	mac.Write([]byte(timestamp + ":"))
+	mac.Write(body)
+	expected := mac.Sum(nil)
+
+	valid := false
+	for _, signature := range signatures {
+		valid = valid || hmac.Equal(signature, expected)
+	}
+	if !valid {
+		return nil, ErrPaddleWebhookSignature
+	}
+
+	event := &PaddleWebhookEvent{}
+	if err = json.Unmarshal(body, event); err != nil {
+		return nil, fmt.Errorf("paddle: decode webhook: %w", err)
+	}
+	return event, nil
+}
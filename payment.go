@@ -5,6 +5,36 @@ import "context"
 const (
 	// Paypal services
 	PAYPAL = iota
+	// Mollie services
+	MOLLIE
+	// Payoneer services
+	PAYONEER
+	// Dwolla services
+	DWOLLA
+	// Coinbase Commerce services
+	COINBASE_COMMERCE
+	// BitPay services
+	BITPAY
+	// Alipay services
+	ALIPAY
+	// WeChat Pay services
+	WECHAT_PAY
+	// VNPay services
+	VNPAY
+	// MoMo services
+	MOMO
+	// ZaloPay services
+	ZALOPAY
+	// Amazon Pay services
+	AMAZON_PAY
+	// Apple Pay merchant validation and token decryption
+	APPLE_PAY
+	// Google Pay token decryption
+	GOOGLE_PAY
+	// Paddle services
+	PADDLE
+	// 2Checkout (Verifone) services
+	TWO_CHECKOUT
 )
 
 var (
@@ -13,12 +43,43 @@ var (
 )
 
 // New payment by abstract factory pattern
+// It returns nil if the config is invalid, use the New<Company>Client constructors to get the error
 func New(context context.Context, paymentCompany int, config *Config) interface{} {
 	SetContext(context)
 
 	switch paymentCompany {
 	case PAYPAL:
 		return newPayPal(&config.PayPal)
+	case MOLLIE:
+		return newMollie(&config.Mollie)
+	case PAYONEER:
+		return newPayoneer(&config.Payoneer)
+	case DWOLLA:
+		return newDwolla(&config.Dwolla)
+	case COINBASE_COMMERCE:
+		return newCoinbaseCommerce(&config.CoinbaseCommerce)
+	case BITPAY:
+		return newBitPay(&config.BitPay)
+	case ALIPAY:
+		return newAlipay(&config.Alipay)
+	case WECHAT_PAY:
+		return newWeChatPay(&config.WeChatPay)
+	case VNPAY:
+		return newVNPay(&config.VNPay)
+	case MOMO:
+		return newMoMo(&config.MoMo)
+	case ZALOPAY:
+		return newZaloPay(&config.ZaloPay)
+	case AMAZON_PAY:
+		return newAmazonPay(&config.AmazonPay)
+	case APPLE_PAY:
+		return newApplePay(&config.ApplePay)
+	case GOOGLE_PAY:
+		return newGooglePay(&config.GooglePay)
+	case PADDLE:
+		return newPaddle(&config.Paddle)
+	case TWO_CHECKOUT:
+		return newTwoCheckout(&config.TwoCheckout)
 	default:
 		return nil
 	}
@@ -0,0 +1,115 @@
+package payment
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+// Payoneer payout statuses
+const (
+	PayoneerPayoutStatusPending     = "Pending"
+	PayoneerPayoutStatusTransferred = "Transferred"
+	PayoneerPayoutStatusCancelled   = "Cancelled"
+	PayoneerPayoutStatusFailed      = "Failed"
+)
+
+// PayoneerError is the error returned by the Payoneer API
+type PayoneerError struct {
+	Response    *http.Response         `json:"-"`
+	Code        string                 `json:"error"`
+	Description string                 `json:"error_description"`
+	Details     map[string]interface{} `json:"error_details,omitempty"`
+}
+
+func (e *PayoneerError) Error() string {
+	status := 0
+	if e.Response != nil {
+		status = e.Response.StatusCode
+	}
+	return fmt.Sprintf("payoneer: %d %s: %s", status, e.Code, e.Description)
+}
+
+// Is reports whether the error belongs to the target error category
+func (e *PayoneerError) Is(target error) bool {
+	if e.Response == nil {
+		return false
+	}
+	return statusErrorIs(e.Response.StatusCode, target)
+}
+
+// PayoneerToken is the response of the token endpoint
+type PayoneerToken struct {
+	TokenType   string `json:"token_type"`
+	AccessToken string `json:"access_token"`
+	ExpiresIn   int64  `json:"expires_in"`
+	Scope       string `json:"scope"`
+}
+
+// PayoneerRegistrationRequest struct
+type PayoneerRegistrationRequest struct {
+	PayeeID              string `json:"payee_id"` // Your identifier of the payee
+	RedirectURL          string `json:"redirect_url,omitempty"`
+	RedirectTime         int    `json:"redirect_time,omitempty"`
+	LockType             string `json:"lock_type,omitempty"`
+	LanguageID           int    `json:"language_id,omitempty"`
+	AlreadyHaveAnAccount bool   `json:"already_have_an_account,omitempty"`
+}
+
+// PayoneerRegistrationLink struct
+type PayoneerRegistrationLink struct {
+	RegistrationLink string `json:"registration_link"`
+	Token            string `json:"token"`
+}
+
+// PayoneerPayeeStatus struct
+type PayoneerPayeeStatus struct {
+	Status struct {
+		Type        int    `json:"type"`
+		Description string `json:"description"`
+	} `json:"status"`
+}
+
+// IsActive reports whether the payee can receive payouts
+func (s *PayoneerPayeeStatus) IsActive() bool {
+	return s.Status.Description == "Active"
+}
+
+// PayoneerPayout is one payment of a mass payout
+type PayoneerPayout struct {
+	ClientReferenceID string  `json:"client_reference_id"` // Unique, used to track the payout
+	PayeeID           string  `json:"payee_id"`
+	Description       string  `json:"description"`
+	Currency          string  `json:"currency"`
+	Amount            float64 `json:"amount"`
+}
+
+// NewPayoneerPayout returns a payout of amount, converted from minor units to the decimal number the API expects
+func NewPayoneerPayout(clientReferenceID, payeeID, description string, amount MoneyAmount) PayoneerPayout {
+	value, _ := strconv.ParseFloat(amount.String(), 64)
+	return PayoneerPayout{
+		ClientReferenceID: clientReferenceID,
+		PayeeID:           payeeID,
+		Description:       description,
+		Currency:          amount.Currency,
+		Amount:            value,
+	}
+}
+
+// PayoneerPayoutSubmission is the response to a mass payout
+type PayoneerPayoutSubmission struct {
+	Result string `json:"result"`
+}
+
+// PayoneerPayoutStatus struct
+type PayoneerPayoutStatus struct {
+	PayoutID          string  `json:"payout_id,omitempty"`
+	ClientReferenceID string  `json:"client_reference_id,omitempty"`
+	PayeeID           string  `json:"payee_id,omitempty"`
+	Status            string  `json:"status"`
+	StatusDescription string  `json:"status_description,omitempty"`
+	Amount            float64 `json:"amount,omitempty"`
+	Currency          string  `json:"currency,omitempty"`
+	PayoutDate        string  `json:"payout_date,omitempty"`
+	LoadDate          string  `json:"load_date,omitempty"`
+}
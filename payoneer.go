@@ -0,0 +1,201 @@
+package payment
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// PayoneerAPIBaseSandBox points to the sandbox version of the Payoneer API
+	PayoneerAPIBaseSandBox = "https://api.sandbox.payoneer.com"
+
+	// PayoneerAPIBaseLive points to the live version of the Payoneer API
+	PayoneerAPIBaseLive = "https://api.payoneer.com"
+
+	// PayoneerAuthBaseSandBox is the sandbox authorization server
+	PayoneerAuthBaseSandBox = "https://login.sandbox.payoneer.com"
+
+	// PayoneerAuthBaseLive is the live authorization server
+	PayoneerAuthBaseLive = "https://login.payoneer.com"
+)
+
+// ErrInvalidPayoneerConfig is returned when a Payoneer client is created without ClientID, SecretID, ProgramID or APIBase
+var ErrInvalidPayoneerConfig = errors.New("payoneer: ClientID, SecretID, ProgramID and APIBase are required to create a client")
+
+// IPayoneer is the Payoneer mass payout client interface
+type IPayoneer interface {
+	GetAccessToken(ctx context.Context) (*PayoneerToken, error)
+	CreateRegistrationLink(ctx context.Context, registration PayoneerRegistrationRequest) (*PayoneerRegistrationLink, error)
+	GetPayeeStatus(ctx context.Context, payeeID string) (*PayoneerPayeeStatus, error)
+	SubmitPayouts(ctx context.Context, payments []PayoneerPayout) (*PayoneerPayoutSubmission, error)
+	GetPayoutStatus(ctx context.Context, clientReferenceID string) (*PayoneerPayoutStatus, error)
+	CancelPayout(ctx context.Context, clientReferenceID string) (*PayoneerPayoutStatus, error)
+}
+
+// PayoneerClient represents a Payoneer Mass Payout v4 API client
+type PayoneerClient struct {
+	sync.Mutex
+	Client         *http.Client
+	ClientID       string
+	Secret         string
+	ProgramID      string
+	APIBase        string
+	AuthBase       string
+	Token          *PayoneerToken
+	tokenExpiresAt time.Time
+}
+
+// NewPayoneerClient returns a Payoneer client for config.
+// AuthBase defaults to the authorization server matching APIBase
+func NewPayoneerClient(config *Payoneer) (IPayoneer, error) {
+	if config == nil || config.ClientID == "" || config.SecretID == "" || config.ProgramID == "" || config.APIBase == "" {
+		return nil, ErrInvalidPayoneerConfig
+	}
+
+	authBase := config.AuthBase
+	if authBase == "" {
+		switch config.APIBase {
+		case PayoneerAPIBaseLive:
+			authBase = PayoneerAuthBaseLive
+		case PayoneerAPIBaseSandBox:
+			authBase = PayoneerAuthBaseSandBox
+		default:
+			authBase = config.APIBase
+		}
+	}
+
+	return &PayoneerClient{
+		Client:    &http.Client{},
+		ClientID:  config.ClientID,
+		Secret:    config.SecretID,
+		ProgramID: config.ProgramID,
+		APIBase:   config.APIBase,
+		AuthBase:  authBase,
+	}, nil
+}
+
+// newPayoneer returns a Payoneer client, or nil when config is invalid
+func newPayoneer(config *Payoneer) IPayoneer {
+	client, err := NewPayoneerClient(config)
+	if err != nil {
+		log.Println("Unable to init Payoneer client: ", err)
+		return nil
+	}
+
+	return client
+}
+
+// newPayoneerError decodes the error returned by the Payoneer API
+func newPayoneerError(resp *http.Response, body []byte) error {
+	errResp := &PayoneerError{Response: resp}
+	json.Unmarshal(body, errResp)
+	return errResp
+}
+
+// GetAccessToken requests an application access token, used by every other call.
+// It is requested automatically when missing or about to expire
+// Endpoint: POST /api/v2/oauth2/token
+func (c *PayoneerClient) GetAccessToken(ctx context.Context) (*PayoneerToken, error) {
+	form := url.Values{"grant_type": {"client_credentials"}, "scope": {"read write"}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.AuthBase+"/api/v2/oauth2/token", strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.SetBasicAuth(c.ClientID, c.Secret)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	token := &PayoneerToken{}
+	if err = sendJSON(c.Client, req, token, newPayoneerError); err != nil {
+		return nil, err
+	}
+
+	c.Lock()
+	c.Token = token
+	c.tokenExpiresAt = time.Now().Add(time.Duration(token.ExpiresIn) * time.Second)
+	c.Unlock()
+
+	return token, nil
+}
+
+// send makes an authenticated request to the program API, refreshing the access token when needed
+func (c *PayoneerClient) send(ctx context.Context, method, path string, payload, v interface{}) error {
+	c.Lock()
+	token := c.Token
+	expired := token == nil || time.Until(c.tokenExpiresAt) < RequestNewTokenBeforeExpiresIn
+	c.Unlock()
+
+	if expired {
+		var err error
+		if token, err = c.GetAccessToken(ctx); err != nil {
+			return err
+		}
+	}
+
+	req, err := newJSONRequest(ctx, method, fmt.Sprintf("%s/v4/programs/%s%s", c.APIBase, url.PathEscape(c.ProgramID), path), payload)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token.AccessToken)
+
+	return sendJSON(c.Client, req, v, newPayoneerError)
+}
+
+// CreateRegistrationLink returns the link a payee signs up or signs in to Payoneer with, to receive payouts
+// Endpoint: POST /v4/programs/ID/payees/registration-link
+func (c *PayoneerClient) CreateRegistrationLink(ctx context.Context, registration PayoneerRegistrationRequest) (*PayoneerRegistrationLink, error) {
+	response := &struct {
+		Result PayoneerRegistrationLink `json:"result"`
+	}{}
+	err := c.send(ctx, http.MethodPost, "/payees/registration-link", registration, response)
+	return &response.Result, err
+}
+
+// GetPayeeStatus returns the status of a payee, payouts can only be sent to active payees
+// Endpoint: GET /v4/programs/ID/payees/ID/status
+func (c *PayoneerClient) GetPayeeStatus(ctx context.Context, payeeID string) (*PayoneerPayeeStatus, error) {
+	response := &struct {
+		Result PayoneerPayeeStatus `json:"result"`
+	}{}
+	err := c.send(ctx, http.MethodGet, "/payees/"+url.PathEscape(payeeID)+"/status", nil, response)
+	return &response.Result, err
+}
+
+// SubmitPayouts submits a mass payout. The payouts are processed asynchronously,
+// track each of them with GetPayoutStatus and its ClientReferenceID
+// Endpoint: POST /v4/programs/ID/masspayouts
+func (c *PayoneerClient) SubmitPayouts(ctx context.Context, payments []PayoneerPayout) (*PayoneerPayoutSubmission, error) {
+	payload := struct {
+		Payments []PayoneerPayout `json:"Payments"`
+	}{payments}
+	response := &PayoneerPayoutSubmission{}
+	err := c.send(ctx, http.MethodPost, "/masspayouts", payload, response)
+	return response, err
+}
+
+// GetPayoutStatus returns the status of a payout
+// Endpoint: GET /v4/programs/ID/payouts/ID/status
+func (c *PayoneerClient) GetPayoutStatus(ctx context.Context, clientReferenceID string) (*PayoneerPayoutStatus, error) {
+	response := &struct {
+		Result PayoneerPayoutStatus `json:"result"`
+	}{}
+	err := c.send(ctx, http.MethodGet, "/payouts/"+url.PathEscape(clientReferenceID)+"/status", nil, response)
+	return &response.Result, err
+}
+
+// CancelPayout cancels a payout not yet claimed by the payee
+// Endpoint: POST /v4/programs/ID/payouts/ID/cancel
+func (c *PayoneerClient) CancelPayout(ctx context.Context, clientReferenceID string) (*PayoneerPayoutStatus, error) {
+	response := &struct {
+		Result PayoneerPayoutStatus `json:"result"`
+	}{}
+	err := c.send(ctx, http.MethodPost, "/payouts/"+url.PathEscape(clientReferenceID)+"/cancel", nil, response)
+	return &response.Result, err
+}
@@ -3,6 +3,7 @@ package payment
 import (
 	"bytes"
 	"context"
+	"crypto/rand"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -13,8 +14,14 @@ import (
 )
 
 // NewRequest constructs a request
+// Validate payload when the client uses WithValidation
 // Convert payload to a JSON
-func (c *PayPalClient) NewRequest(ctx context.Context, method, url string, payload interface{}) (*http.Request, error) {
+// Apply request options to the built request
+func (c *PayPalClient) NewRequest(ctx context.Context, method, url string, payload interface{}, opts ...RequestOption) (*http.Request, error) {
+	if err := c.validate(payload); err != nil {
+		return nil, err
+	}
+
 	var buf io.Reader
 	if payload != nil {
 		b, err := json.Marshal(&payload)
@@ -23,34 +30,144 @@ func (c *PayPalClient) NewRequest(ctx context.Context, method, url string, paylo
 		}
 		buf = bytes.NewBuffer(b)
 	}
-	return http.NewRequestWithContext(ctx, method, url, buf)
+
+	req, err := http.NewRequestWithContext(ctx, method, url, buf)
+	if err != nil {
+		return nil, err
+	}
+
+	c.applyRequestOptions(req, opts)
+
+	return req, nil
 }
 
 // SendWithAuth makes a request to the API and apply OAuth2 header automatically.
 // If the access token soon to be expired or already expired, it will try to get a new one before
 // making the main request
+// If PayPal rejects the access token before its expiry, a new one is requested once and the request is replayed
 // client.Token will be updated when changed
 func (c *PayPalClient) SendWithAuth(req *http.Request, v interface{}) error {
 	c.Lock()
-	// Note: Here we do not want to `defer c.Unlock()` because we need `c.Send(...)`
-	// to happen outside of the locked section.
+	token, tokenExpiresAt, tokenStore := c.Token, c.tokenExpiresAt, c.tokenStore
+	c.Unlock()
 
-	if c.Token != nil {
-		if !c.tokenExpiresAt.IsZero() && c.tokenExpiresAt.Sub(time.Now()) < RequestNewTokenBeforeExpiresIn {
-			// c.Token will be updated in GetAccessToken call
-			if _, err := c.GetAccessToken(req.Context()); err != nil {
-				c.Unlock()
-				return err
-			}
+	// With a token store, a new client starts from the shared token instead of sending unauthenticated requests
+	if (token == nil && tokenStore != nil) ||
+		(token != nil && !tokenExpiresAt.IsZero() && tokenExpiresAt.Sub(time.Now()) < RequestNewTokenBeforeExpiresIn) {
+		// c.Token will be updated by the refresh
+		if err := c.refreshToken(req.Context(), token); err != nil {
+			return err
 		}
+	}
 
+	// Note: Here we do not want to `defer c.Unlock()` because we need `c.Send(...)`
+	// to happen outside of the locked section.
+	c.Lock()
+	if c.Token != nil {
 		req.Header.Set("Authorization", "Bearer "+c.Token.Token)
 	}
 
+	if c.authAssertion != "" && req.Header.Get("PayPal-Auth-Assertion") == "" {
+		req.Header.Set("PayPal-Auth-Assertion", c.authAssertion)
+	}
+
+	token = c.Token
+
 	// Unlock the client mutex before sending the request, this allows multiple requests
 	// to be in progress at the same time.
 	c.Unlock()
-	return c.Send(req, v)
+
+	err := c.Send(req, v)
+	if token == nil || !isUnauthorized(err) {
+		return err
+	}
+
+	retry, rewindErr := rewindRequest(req)
+	if rewindErr != nil {
+		return err
+	}
+
+	if err = c.refreshToken(req.Context(), token); err != nil {
+		return err
+	}
+
+	c.Lock()
+	retry.Header.Set("Authorization", "Bearer "+c.Token.Token)
+	c.Unlock()
+
+	return c.Send(retry, v)
+}
+
+// tokenRefresh is an access token request shared by every caller refreshing the same token
+type tokenRefresh struct {
+	done chan struct{}
+	err  error
+}
+
+// refreshToken replaces the stale access token with a new one, taken from the token store when it holds a fresh one.
+// Concurrent callers share a single token request, and nothing is requested when the stale token was already replaced.
+func (c *PayPalClient) refreshToken(ctx context.Context, stale *TokenResponse) error {
+	c.Lock()
+	if c.Token != stale && c.Token != nil {
+		c.Unlock()
+		return nil
+	}
+	tokenStore := c.tokenStore
+
+	if refresh := c.tokenRefresh; refresh != nil {
+		c.Unlock()
+
+		select {
+		case <-refresh.done:
+			return refresh.err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	refresh := &tokenRefresh{done: make(chan struct{})}
+	c.tokenRefresh = refresh
+	c.Unlock()
+
+	if tokenStore == nil || !c.loadStoredToken(ctx, tokenStore, stale) {
+		_, refresh.err = c.GetAccessToken(ctx)
+		if refresh.err == nil && tokenStore != nil {
+			c.saveToken(ctx, tokenStore)
+		}
+	}
+
+	c.Lock()
+	c.tokenRefresh = nil
+	c.Unlock()
+	close(refresh.done)
+
+	return refresh.err
+}
+
+// rewindRequest returns a copy of the sent request ready to be sent again, with a fresh body
+func rewindRequest(req *http.Request) (*http.Request, error) {
+	retry := req.Clone(req.Context())
+	if req.Body == nil || req.Body == http.NoBody {
+		return retry, nil
+	}
+
+	if req.GetBody == nil {
+		return nil, fmt.Errorf("paypal: unable to rewind the body of %s %s", req.Method, req.URL)
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	retry.Body = body
+
+	return retry, nil
+}
+
+// isUnauthorized reports whether err is a 401 response from the API
+func isUnauthorized(err error) bool {
+	errResp, ok := err.(*ErrorResponse)
+	return ok && errResp.Response != nil && errResp.Response.StatusCode == http.StatusUnauthorized
 }
 
 // SendWithBasicAuth makes a request to the API using clientID:secret basic auth
@@ -60,12 +177,28 @@ func (c *PayPalClient) SendWithBasicAuth(req *http.Request, v interface{}) error
 	return c.Send(req, v)
 }
 
-// SetReturnRepresentation enables verbose response
+// SetReturnRepresentation enables verbose response for every request made by the client
 // Verbose response: https://developer.paypal.com/docs/api/orders/v2/#orders-authorize-header-parameters
+// Deprecated: use the WithReturnRepresentation request option or the WithDefaultReturnRepresentation client option instead
 func (c *PayPalClient) SetReturnRepresentation() {
+	c.Lock()
+	defer c.Unlock()
+
 	c.returnRepresentation = true
 }
 
+// NewIdempotencyKey returns a random UUID (version 4) suitable for the PayPal-Request-Id header
+func NewIdempotencyKey() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
 // Send makes a request to the API, the response body will be
 // unmarshalled into v, or if v is an io.Writer, the response will
 // be written to it without decoding
@@ -77,18 +210,31 @@ func (c *PayPalClient) Send(req *http.Request, v interface{}) error {
 	)
 
 	// Set default headers
-	req.Header.Set("Accept", "application/json")
+	if req.Header.Get("Accept") == "" {
+		req.Header.Set("Accept", "application/json")
+	}
 	req.Header.Set("Accept-Language", "en_US")
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+	if c.mockResponse != "" && c.APIBase != APIBaseLive && req.Header.Get("PayPal-Mock-Response") == "" {
+		req.Header.Set("PayPal-Mock-Response", mockResponseHeader(c.mockResponse))
+	}
 
 	// Default values for headers
 	if req.Header.Get("Content-type") == "" {
 		req.Header.Set("Content-type", "application/json")
 	}
-	if c.returnRepresentation {
+	c.Lock()
+	returnRepresentation := c.returnRepresentation
+	c.Unlock()
+	if returnRepresentation && req.Header.Get("Prefer") == "" {
 		req.Header.Set("Prefer", "return=representation")
 	}
 
-	resp, err = c.Client.Do(req)
+	start := time.Now()
+	resp, err = c.roundTrip(req)
+	recordResponseMetadata(req, resp, start)
 	c.log(req, resp)
 
 	if err != nil {
@@ -111,11 +257,16 @@ func (c *PayPalClient) Send(req *http.Request, v interface{}) error {
 	}
 
 	if w, ok := v.(io.Writer); ok {
-		io.Copy(w, resp.Body)
-		return nil
+		_, err = io.Copy(w, resp.Body)
+		return err
+	}
+
+	// Some calls answer 204 No Content unless the full representation is asked for
+	if err = json.NewDecoder(resp.Body).Decode(v); err != nil && err != io.EOF {
+		return err
 	}
 
-	return json.NewDecoder(resp.Body).Decode(v)
+	return nil
 }
 
 // log will dump request and response to the log file
@@ -142,27 +293,44 @@ func (r *ErrorResponse) Error() string {
 	return fmt.Sprintf("%v %v: %d %s, %+v", r.Response.Request.Method, r.Response.Request.URL, r.Response.StatusCode, r.Message, r.Details)
 }
 
+// PurchaseUnitPatchPath returns the order patch path of a purchase unit, or of one of its fields when field is set.
+// An empty referenceID addresses the purchase unit PayPal names "default".
+//
+//	PurchaseUnitPatchPath("", "amount") // /purchase_units/@reference_id=='default'/amount
+func PurchaseUnitPatchPath(referenceID, field string) string {
+	if referenceID == "" {
+		referenceID = "default"
+	}
+
+	path := fmt.Sprintf("/purchase_units/@reference_id=='%s'", referenceID)
+	if field != "" {
+		path += "/" + field
+	}
+
+	return path
+}
+
 // GetUpdatePatch for catalog (product)
 func (product *Product) GetUpdatePatch() []Patch {
 	return []Patch{
 		{
-			Operation: "replace",
-			Path:      "/description",
+			Operation: PatchOperationReplace,
+			Path:      ProductPatchPathDescription,
 			Value:     product.Description,
 		},
 		{
-			Operation: "replace",
-			Path:      "/category",
+			Operation: PatchOperationReplace,
+			Path:      ProductPatchPathCategory,
 			Value:     product.Category,
 		},
 		{
-			Operation: "replace",
-			Path:      "/image_url",
+			Operation: PatchOperationReplace,
+			Path:      ProductPatchPathImageURL,
 			Value:     product.ImageUrl,
 		},
 		{
-			Operation: "replace",
-			Path:      "/home_url",
+			Operation: PatchOperationReplace,
+			Path:      ProductPatchPathHomeURL,
 			Value:     product.HomeUrl,
 		},
 	}
@@ -172,16 +340,16 @@ func (product *Product) GetUpdatePatch() []Patch {
 func (subPlan *SubscriptionPlan) GetUpdatePatch() []Patch {
 	result := []Patch{
 		{
-			Operation: "replace",
-			Path:      "/description",
+			Operation: PatchOperationReplace,
+			Path:      PlanPatchPathDescription,
 			Value:     subPlan.Description,
 		},
 	}
 
 	if subPlan.Taxes != nil {
 		result = append(result, Patch{
-			Operation: "replace",
-			Path:      "/taxes/percentage",
+			Operation: PatchOperationReplace,
+			Path:      PlanPatchPathTaxesPercentage,
 			Value:     subPlan.Taxes.Percentage,
 		})
 	}
@@ -189,26 +357,26 @@ func (subPlan *SubscriptionPlan) GetUpdatePatch() []Patch {
 	if subPlan.PaymentPreferences != nil {
 		if subPlan.PaymentPreferences.SetupFee != nil {
 			result = append(result, Patch{
-				Operation: "replace",
-				Path:      "/payment_preferences/setup_fee",
+				Operation: PatchOperationReplace,
+				Path:      PlanPatchPathSetupFee,
 				Value:     subPlan.PaymentPreferences.SetupFee,
 			},
 			)
 		}
 
 		result = append(result, []Patch{{
-			Operation: "replace",
-			Path:      "/payment_preferences/auto_bill_outstanding",
+			Operation: PatchOperationReplace,
+			Path:      PlanPatchPathAutoBillOutstanding,
 			Value:     subPlan.PaymentPreferences.AutoBillOutstanding,
 		},
 			{
-				Operation: "replace",
-				Path:      "/payment_preferences/payment_failure_threshold",
+				Operation: PatchOperationReplace,
+				Path:      PlanPatchPathPaymentFailureThreshold,
 				Value:     subPlan.PaymentPreferences.PaymentFailureThreshold,
 			},
 			{
-				Operation: "replace",
-				Path:      "/payment_preferences/setup_fee_failure_action",
+				Operation: PatchOperationReplace,
+				Path:      PlanPatchPathSetupFeeFailureAction,
 				Value:     subPlan.PaymentPreferences.SetupFeeFailureAction,
 			}}...)
 	}
@@ -220,10 +388,42 @@ func (subPlan *SubscriptionPlan) GetUpdatePatch() []Patch {
 func (sub *Subscription) GetUpdatePatch() []Patch {
 	result := []Patch{
 		{
-			Operation: "replace",
-			Path:      "/billing_info/outstanding_balance",
+			Operation: PatchOperationReplace,
+			Path:      SubscriptionPatchPathOutstandingBalance,
 			Value:     sub.BillingInfo.OutstandingBalance,
 		},
 	}
 	return result
 }
+
+// SubscriptionOutstandingBalancePatch sets the outstanding balance of a subscription,
+// e.g. to waive part of the missed payments before charging the rest with CaptureSubscription
+func SubscriptionOutstandingBalancePatch(balance Money) Patch {
+	return Patch{Operation: PatchOperationReplace, Path: SubscriptionPatchPathOutstandingBalance, Value: balance}
+}
+
+// SubscriptionAutoBillOutstandingPatch sets whether the outstanding balance is charged automatically in the next billing cycle
+func SubscriptionAutoBillOutstandingPatch(enabled bool) Patch {
+	return Patch{Operation: PatchOperationReplace, Path: SubscriptionPatchPathAutoBillOutstanding, Value: enabled}
+}
+
+// SubscriptionPaymentFailureThresholdPatch sets the number of failed payments after which the subscription is suspended
+func SubscriptionPaymentFailureThresholdPatch(threshold int) Patch {
+	return Patch{Operation: PatchOperationReplace, Path: SubscriptionPatchPathPaymentFailureThreshold, Value: threshold}
+}
+
+// SubscriptionShippingAmountPatch sets the shipping amount charged with every billing cycle
+func SubscriptionShippingAmountPatch(amount Money) Patch {
+	return Patch{Operation: PatchOperationReplace, Path: SubscriptionPatchPathShippingAmount, Value: amount}
+}
+
+// findLink returns the first link with the given relation, nil when there is none
+func findLink(links []Link, rel string) *Link {
+	for i := range links {
+		if links[i].Rel == rel {
+			return &links[i]
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,149 @@
+package payment
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Error categories matched by errors.Is against the errors returned by the client
+var (
+	ErrUnauthorized       = errors.New("paypal: unauthorized")
+	ErrNotFound           = errors.New("paypal: resource not found")
+	ErrValidation         = errors.New("paypal: validation failed")
+	ErrRateLimited        = errors.New("paypal: rate limit reached")
+	ErrInstrumentDeclined = errors.New("paypal: instrument declined")
+)
+
+// CaptureStatusDeclined is the status of a capture refused by the processor
+const CaptureStatusDeclined = "DECLINED"
+
+// Is reports whether the error belongs to the target error category, e.g.
+//
+//	if errors.Is(err, payment.ErrInstrumentDeclined) {
+//		// ask the payer for another funding source
+//	}
+func (r *ErrorResponse) Is(target error) bool {
+	status := 0
+	if r.Response != nil {
+		status = r.Response.StatusCode
+	}
+
+	switch target {
+	case ErrUnauthorized:
+		return status == http.StatusUnauthorized || r.Name == "AUTHENTICATION_FAILURE"
+	case ErrNotFound:
+		return status == http.StatusNotFound || r.Name == "RESOURCE_NOT_FOUND"
+	case ErrValidation:
+		return status == http.StatusBadRequest || status == http.StatusUnprocessableEntity || r.Name == "INVALID_REQUEST" || r.Name == "UNPROCESSABLE_ENTITY"
+	case ErrRateLimited:
+		return status == http.StatusTooManyRequests || r.Name == "RATE_LIMIT_REACHED"
+	case ErrInstrumentDeclined:
+		for _, detail := range r.Details {
+			if detail.Issue == "INSTRUMENT_DECLINED" {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// UnmarshalJSON decodes the links of an error detail from "link", used by Payouts v1, or from "links"
+func (d *ErrorResponseDetail) UnmarshalJSON(data []byte) error {
+	type detail ErrorResponseDetail
+	var aux struct {
+		detail
+		Links []Link `json:"links"`
+	}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	*d = ErrorResponseDetail(aux.detail)
+	if len(d.Links) == 0 {
+		d.Links = aux.Links
+	}
+
+	return nil
+}
+
+// CaptureDeclinedError describes a capture refused by the processor although the capture call succeeded.
+// It matches ErrInstrumentDeclined with errors.Is.
+type CaptureDeclinedError struct {
+	ReferenceID       string
+	CaptureID         string
+	StatusDetails     *CaptureStatusDetails
+	ProcessorResponse *ProcessorResponse
+}
+
+// Error method implementation for CaptureDeclinedError struct
+func (e *CaptureDeclinedError) Error() string {
+	msg := fmt.Sprintf("paypal: capture %s of purchase unit %q declined", e.CaptureID, e.ReferenceID)
+	if e.ProcessorResponse != nil {
+		msg += fmt.Sprintf(", processor response %+v", *e.ProcessorResponse)
+	}
+	return msg
+}
+
+// Is reports whether target is ErrInstrumentDeclined
+func (e *CaptureDeclinedError) Is(target error) bool {
+	return target == ErrInstrumentDeclined
+}
+
+// DeclinedError returns a *CaptureDeclinedError for the first declined capture of the order, or nil
+func (r *CaptureOrderResponse) DeclinedError() error {
+	for _, unit := range r.PurchaseUnits {
+		if unit.Payments == nil {
+			continue
+		}
+		for _, capture := range unit.Payments.Captures {
+			if capture.Status == CaptureStatusDeclined {
+				return &CaptureDeclinedError{
+					ReferenceID:       unit.ReferenceID,
+					CaptureID:         capture.ID,
+					StatusDetails:     capture.StatusDetails,
+					ProcessorResponse: capture.ProcessorResponse,
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// InstrumentDeclinedError is returned by CaptureOrder when the funding source of the payer was declined.
+// PayPal recommends redirecting the payer to ApproveURL to choose another funding source, then capturing
+// the same order again. ApproveURL is empty when PayPal sent no redirect link, use RetryOrderApproval then.
+// It matches ErrInstrumentDeclined with errors.Is and unwraps to the *ErrorResponse.
+type InstrumentDeclinedError struct {
+	OrderID    string
+	ApproveURL string
+	Response   *ErrorResponse
+}
+
+// Error method implementation for InstrumentDeclinedError struct
+func (e *InstrumentDeclinedError) Error() string {
+	return fmt.Sprintf("paypal: instrument declined for order %s: %v", e.OrderID, e.Response)
+}
+
+// Unwrap returns the PayPal error response
+func (e *InstrumentDeclinedError) Unwrap() error {
+	return e.Response
+}
+
+// newInstrumentDeclinedError wraps err into an *InstrumentDeclinedError when it is an INSTRUMENT_DECLINED response
+func newInstrumentDeclinedError(orderID string, err error) error {
+	errResp, ok := err.(*ErrorResponse)
+	if !ok || !errResp.Is(ErrInstrumentDeclined) {
+		return err
+	}
+
+	declined := &InstrumentDeclinedError{OrderID: orderID, Response: errResp}
+	if link := findLink(errResp.Links, "redirect"); link != nil {
+		declined.ApproveURL = link.Href
+	}
+
+	return declined
+}
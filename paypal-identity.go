@@ -0,0 +1,81 @@
+package payment
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+const (
+	// ConnectURLSandBox is the consent page of Log in with PayPal for the sandbox
+	ConnectURLSandBox = "https://www.sandbox.paypal.com/connect"
+
+	// ConnectURLLive is the consent page of Log in with PayPal for the live environment
+	ConnectURLLive = "https://www.paypal.com/connect"
+)
+
+// ErrConsentStateMismatch is returned when the state of a consent callback is not the one sent by ConsentURL
+var ErrConsentStateMismatch = errors.New("paypal: consent state mismatch")
+
+// ConsentURL returns the Log in with PayPal page asking the user to grant scopes to the application.
+// PayPal redirects to redirectURI with the authorization code to pass to GrantNewAccessTokenFromAuthCode,
+// and with state unchanged, see NewConsentState and ParseConsentCallback.
+// Doc: https://developer.paypal.com/docs/log-in-with-paypal/integrate/
+func (c *PayPalClient) ConsentURL(redirectURI string, scopes []string, state string) string {
+	connectURL := ConnectURLSandBox
+	if c.APIBase == APIBaseLive {
+		connectURL = ConnectURLLive
+	}
+
+	q := url.Values{}
+	q.Set("flowEntry", "static")
+	q.Set("client_id", c.ClientID)
+	q.Set("response_type", "code")
+	q.Set("scope", strings.Join(scopes, " "))
+	q.Set("redirect_uri", redirectURI)
+	q.Set("state", state)
+
+	return connectURL + "?" + q.Encode()
+}
+
+// NewConsentState returns a random state to pass to ConsentURL and keep in the user session until the callback
+func NewConsentState() string {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// ValidateConsentState checks in constant time that the state of a consent callback is the expected one
+func ValidateConsentState(expected, received string) error {
+	if expected == "" || subtle.ConstantTimeCompare([]byte(expected), []byte(received)) != 1 {
+		return ErrConsentStateMismatch
+	}
+
+	return nil
+}
+
+// ParseConsentCallback validates the query of the redirect URI request and returns the authorization code.
+// It returns an error when the user denied the consent or when the state does not match.
+func ParseConsentCallback(query url.Values, expectedState string) (string, error) {
+	if err := ValidateConsentState(expectedState, query.Get("state")); err != nil {
+		return "", err
+	}
+
+	if consentErr := query.Get("error"); consentErr != "" {
+		return "", fmt.Errorf("paypal: consent failed: %s %s", consentErr, query.Get("error_description"))
+	}
+
+	code := query.Get("code")
+	if code == "" {
+		return "", errors.New("paypal: consent callback without authorization code")
+	}
+
+	return code, nil
+}
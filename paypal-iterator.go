@@ -0,0 +1,347 @@
+package payment
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// TransactionSearchMaxRange is the widest date range PayPal accepts in a single transaction search
+const TransactionSearchMaxRange = 31 * 24 * time.Hour
+
+// TransactionIterator walks the transaction search results of an arbitrary date range.
+// The range is split into windows PayPal accepts and every page of every window is fetched lazily.
+//
+//	it := client.NewTransactionIterator(ctx, TransactionSearchRequest{StartDate: from, EndDate: to})
+//	for it.Next() {
+//		detail := it.Current()
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type TransactionIterator struct {
+	client      *PayPalClient
+	ctx         context.Context
+	request     TransactionSearchRequest
+	opts        []RequestOption
+	windowStart time.Time
+	windowEnd   time.Time
+	page        int
+	items       []SearchTransactionDetails
+	current     SearchTransactionDetails
+	done        bool
+	err         error
+}
+
+// NewTransactionIterator returns an iterator over all transactions matching req between req.StartDate and req.EndDate.
+// req.Page is ignored, req.PageSize and opts are kept for every request.
+func (c *PayPalClient) NewTransactionIterator(ctx context.Context, req TransactionSearchRequest, opts ...RequestOption) *TransactionIterator {
+	it := &TransactionIterator{
+		client:      c,
+		ctx:         ctx,
+		request:     req,
+		opts:        opts,
+		windowStart: req.StartDate,
+		page:        1,
+	}
+	it.windowEnd = it.nextWindowEnd()
+	it.done = !req.StartDate.Before(req.EndDate)
+
+	return it
+}
+
+// Next advances the iterator to the next transaction.
+// It returns false when all transactions are consumed, the context is done or a request failed.
+func (it *TransactionIterator) Next() bool {
+	for len(it.items) == 0 {
+		if it.done || it.err != nil {
+			return false
+		}
+		if err := it.ctx.Err(); err != nil {
+			it.err = err
+			return false
+		}
+		it.fetch()
+	}
+
+	it.current = it.items[0]
+	it.items = it.items[1:]
+
+	return true
+}
+
+// Current returns the transaction the iterator is positioned on
+func (it *TransactionIterator) Current() SearchTransactionDetails {
+	return it.current
+}
+
+// Err returns the first error met by the iterator, if any
+func (it *TransactionIterator) Err() error {
+	return it.err
+}
+
+// fetch loads the current page of the current window and moves the cursor forward
+func (it *TransactionIterator) fetch() {
+	req := it.request
+	page := it.page
+	req.StartDate = it.windowStart
+	req.EndDate = it.windowEnd
+	req.Page = &page
+
+	response, err := it.client.ListTransactions(it.ctx, &req, it.opts...)
+	if err != nil {
+		it.err = err
+		return
+	}
+
+	it.items = response.TransactionDetails
+
+	if findLink(response.Links, "next") != nil || response.Page < response.TotalPages {
+		it.page++
+		return
+	}
+
+	// PayPal dates have a precision of one second, start the next window right after the current one
+	it.windowStart = it.windowEnd.Add(time.Second)
+	it.windowEnd = it.nextWindowEnd()
+	it.page = 1
+	it.done = it.windowStart.After(it.request.EndDate)
+}
+
+// nextWindowEnd returns the end of the window starting at windowStart
+func (it *TransactionIterator) nextWindowEnd() time.Time {
+	end := it.windowStart.Add(TransactionSearchMaxRange)
+	if end.After(it.request.EndDate) {
+		return it.request.EndDate
+	}
+
+	return end
+}
+
+// ProductIterator walks every catalog product, fetching pages lazily.
+//
+//	it := client.NewProductIterator(ctx, ProductListParameters{ListParams: ListParams{PageSize: "20"}})
+//	for it.Next() {
+//		product := it.Current()
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type ProductIterator struct {
+	client  *PayPalClient
+	ctx     context.Context
+	params  ProductListParameters
+	opts    []RequestOption
+	page    int
+	items   []Product
+	current Product
+	done    bool
+	err     error
+}
+
+// NewProductIterator returns an iterator over the catalog products.
+// params.Page is ignored, params.PageSize and opts are kept for every request.
+func (c *PayPalClient) NewProductIterator(ctx context.Context, params ProductListParameters, opts ...RequestOption) *ProductIterator {
+	return &ProductIterator{client: c, ctx: ctx, params: params, opts: opts, page: 1}
+}
+
+// Next advances the iterator to the next product.
+// It returns false when all products are consumed, the context is done or a request failed.
+func (it *ProductIterator) Next() bool {
+	for len(it.items) == 0 {
+		if it.done || it.err != nil {
+			return false
+		}
+		if err := it.ctx.Err(); err != nil {
+			it.err = err
+			return false
+		}
+
+		params := it.params
+		params.Page = strconv.Itoa(it.page)
+
+		response, err := it.client.ListProducts(it.ctx, &params, it.opts...)
+		if err != nil {
+			it.err = err
+			return false
+		}
+
+		it.items = response.Products
+		it.page, it.done = nextPage(response.Links, it.page, response.TotalPages, len(response.Products))
+	}
+
+	it.current = it.items[0]
+	it.items = it.items[1:]
+
+	return true
+}
+
+// Current returns the product the iterator is positioned on
+func (it *ProductIterator) Current() Product {
+	return it.current
+}
+
+// Err returns the first error met by the iterator, if any
+func (it *ProductIterator) Err() error {
+	return it.err
+}
+
+// PlanIterator walks every subscription plan matching the list parameters, fetching pages lazily.
+//
+//	it := client.NewPlanIterator(ctx, SubscriptionPlanListParameters{ProductId: productID})
+//	for it.Next() {
+//		plan := it.Current()
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type PlanIterator struct {
+	client  *PayPalClient
+	ctx     context.Context
+	params  SubscriptionPlanListParameters
+	opts    []RequestOption
+	page    int
+	items   []SubscriptionPlan
+	current SubscriptionPlan
+	done    bool
+	err     error
+}
+
+// NewPlanIterator returns an iterator over the subscription plans matching params.
+// params.Page is ignored, the other parameters and opts are kept for every request.
+func (c *PayPalClient) NewPlanIterator(ctx context.Context, params SubscriptionPlanListParameters, opts ...RequestOption) *PlanIterator {
+	return &PlanIterator{client: c, ctx: ctx, params: params, opts: opts, page: 1}
+}
+
+// Next advances the iterator to the next plan.
+// It returns false when all plans are consumed, the context is done or a request failed.
+func (it *PlanIterator) Next() bool {
+	for len(it.items) == 0 {
+		if it.done || it.err != nil {
+			return false
+		}
+		if err := it.ctx.Err(); err != nil {
+			it.err = err
+			return false
+		}
+
+		params := it.params
+		params.Page = strconv.Itoa(it.page)
+
+		response, err := it.client.ListSubscriptionPlans(it.ctx, &params, it.opts...)
+		if err != nil {
+			it.err = err
+			return false
+		}
+
+		it.items = response.Plans
+		it.page, it.done = nextPage(response.Links, it.page, response.TotalPages, len(response.Plans))
+	}
+
+	it.current = it.items[0]
+	it.items = it.items[1:]
+
+	return true
+}
+
+// Current returns the plan the iterator is positioned on
+func (it *PlanIterator) Current() SubscriptionPlan {
+	return it.current
+}
+
+// Err returns the first error met by the iterator, if any
+func (it *PlanIterator) Err() error {
+	return it.err
+}
+
+// CreditCardIterator walks every vaulted credit card matching a filter, fetching pages lazily.
+//
+//	it := client.NewCreditCardIterator(ctx, CreditCardsFilter{ExternalCustomerID: customerID})
+//	for it.Next() {
+//		card := it.Current()
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type CreditCardIterator struct {
+	client  *PayPalClient
+	ctx     context.Context
+	filter  CreditCardsFilter
+	opts    []RequestOption
+	page    int
+	items   []CreditCard
+	current CreditCard
+	done    bool
+	err     error
+}
+
+// NewCreditCardIterator returns an iterator over the vaulted credit cards matching ccf.
+// ccf.Page is ignored, the other filters and opts are kept for every request.
+func (c *PayPalClient) NewCreditCardIterator(ctx context.Context, ccf CreditCardsFilter, opts ...RequestOption) *CreditCardIterator {
+	return &CreditCardIterator{client: c, ctx: ctx, filter: ccf, opts: opts, page: 1}
+}
+
+// Next advances the iterator to the next credit card.
+// It returns false when all credit cards are consumed, the context is done or a request failed.
+func (it *CreditCardIterator) Next() bool {
+	for len(it.items) == 0 {
+		if it.done || it.err != nil {
+			return false
+		}
+		if err := it.ctx.Err(); err != nil {
+			it.err = err
+			return false
+		}
+
+		filter := it.filter
+		filter.Page = it.page
+
+		response, err := it.client.GetCreditCards(it.ctx, &filter, it.opts...)
+		if err != nil {
+			it.err = err
+			return false
+		}
+
+		it.items = response.Items
+		it.page, it.done = nextPage(response.Links, it.page, response.TotalPages, len(response.Items))
+	}
+
+	it.current = it.items[0]
+	it.items = it.items[1:]
+
+	return true
+}
+
+// Current returns the credit card the iterator is positioned on
+func (it *CreditCardIterator) Current() CreditCard {
+	return it.current
+}
+
+// Err returns the first error met by the iterator, if any
+func (it *CreditCardIterator) Err() error {
+	return it.err
+}
+
+// nextPage returns the page following page and whether the list is exhausted.
+// The page of the "next" link is preferred, total pages are used when PayPal sent no link.
+func nextPage(links []Link, page, totalPages, itemCount int) (int, bool) {
+	if itemCount == 0 {
+		return page, true
+	}
+
+	if link := findLink(links, "next"); link != nil {
+		if u, err := url.Parse(link.Href); err == nil {
+			if next, err := strconv.Atoi(u.Query().Get("page")); err == nil && next > page {
+				return next, false
+			}
+		}
+		return page + 1, false
+	}
+
+	if page < totalPages {
+		return page + 1, false
+	}
+
+	return page, true
+}
@@ -0,0 +1,134 @@
+package payment
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// Disbursement modes of a purchase unit or a capture
+// Doc: https://developer.paypal.com/docs/multiparty/checkout/delayed-disbursement/
+const (
+	DisbursementModeInstant = "INSTANT"
+	DisbursementModeDelayed = "DELAYED"
+)
+
+// ReferenceTypeTransactionID is the reference type of a referenced payout of a capture
+const ReferenceTypeTransactionID = "TRANSACTION_ID"
+
+// WithPartnerAttributionID sets the PayPal-Partner-Attribution-Id header (BN code)
+// identifying the platform, required by the Commerce Platform
+func WithPartnerAttributionID(bnCode string) RequestOption {
+	return WithHeader("PayPal-Partner-Attribution-Id", bnCode)
+}
+
+// NewPlatformFee returns a platform fee of amount, paid to payee, or to the API caller when payee is nil
+func NewPlatformFee(amount MoneyAmount, payee *PayeeForOrders) PlatformFee {
+	return PlatformFee{Amount: amount.Money(), Payee: payee}
+}
+
+// Validate checks the disbursement mode and the amount of every platform fee
+func (p PaymentInstruction) Validate() error {
+	v := &validator{}
+	switch p.DisbursementMode {
+	case "", DisbursementModeInstant, DisbursementModeDelayed:
+	default:
+		v.add("disbursement_mode", "must be %s or %s", DisbursementModeInstant, DisbursementModeDelayed)
+	}
+	for i, fee := range p.PlatformFees {
+		field := fmt.Sprintf("platform_fees[%d].amount", i)
+		if fee.Amount == nil {
+			v.add(field, "is required")
+			continue
+		}
+		v.currency(field+".currency_code", fee.Amount.Currency)
+		v.amount(field+".value", fee.Amount.Currency, fee.Amount.Value)
+	}
+	return v.err()
+}
+
+// PlatformFee adds a platform fee to the current purchase unit, see NewPlatformFee.
+// Build checks that the fees do not exceed the purchase unit total
+func (b *OrderBuilder) PlatformFee(amount MoneyAmount, payee *PayeeForOrders) *OrderBuilder {
+	unit := &b.current().unit
+	if unit.PaymentInstruction == nil {
+		unit.PaymentInstruction = &PaymentInstruction{}
+	}
+	unit.PaymentInstruction.PlatformFees = append(unit.PaymentInstruction.PlatformFees, NewPlatformFee(amount, payee))
+	return b
+}
+
+// DisbursementMode sets the disbursement mode of the current purchase unit.
+// With DisbursementModeDelayed, the funds are held until DisburseCapture is called
+func (b *OrderBuilder) DisbursementMode(mode string) *OrderBuilder {
+	unit := &b.current().unit
+	if unit.PaymentInstruction == nil {
+		unit.PaymentInstruction = &PaymentInstruction{}
+	}
+	unit.PaymentInstruction.DisbursementMode = mode
+	return b
+}
+
+// validatePaymentInstruction reports the errors of the payment instruction of a purchase unit to v
+func validatePaymentInstruction(v *validator, field string, instruction *PaymentInstruction, total MoneyAmount) {
+	if instruction == nil {
+		return
+	}
+	if errs, ok := instruction.Validate().(ValidationErrors); ok {
+		for _, err := range errs {
+			v.add(field+".payment_instruction."+err.Field, err.Message)
+		}
+		return
+	}
+
+	fees := NewMoneyAmount(total.Currency, 0)
+	for i, fee := range instruction.PlatformFees {
+		amount, err := fee.Amount.MoneyAmount()
+		if err == nil {
+			amount, err = fees.Add(amount)
+		}
+		if err != nil {
+			v.add(fmt.Sprintf("%s.payment_instruction.platform_fees[%d]", field, i), err.Error())
+			continue
+		}
+		fees = amount
+	}
+	if fees.Minor > total.Minor {
+		v.add(field+".payment_instruction.platform_fees", "total %s exceeds the purchase unit amount %s", fees, total)
+	}
+}
+
+// CreateReferencedPayoutItem disburses the funds of a capture made with DisbursementModeDelayed
+// Doc: https://developer.paypal.com/docs/api/referenced-payouts/v1/
+// Endpoint: POST /v1/payments/referenced-payouts-items
+func (c *PayPalClient) CreateReferencedPayoutItem(ctx context.Context, item ReferencedPayoutItemRequest, opts ...RequestOption) (*ReferencedPayoutItem, error) {
+	req, err := c.NewRequest(ctx, http.MethodPost, fmt.Sprintf("%s%s", c.APIBase, "/v1/payments/referenced-payouts-items"), item, opts...)
+	response := &ReferencedPayoutItem{}
+	if err != nil {
+		return response, err
+	}
+
+	err = c.SendWithAuth(req, response)
+	return response, err
+}
+
+// GetReferencedPayoutItem shows the status of a referenced payout item
+// Endpoint: GET /v1/payments/referenced-payouts-items/ID
+func (c *PayPalClient) GetReferencedPayoutItem(ctx context.Context, itemID string, opts ...RequestOption) (*ReferencedPayoutItem, error) {
+	req, err := c.NewRequest(ctx, http.MethodGet, fmt.Sprintf("%s%s%s", c.APIBase, "/v1/payments/referenced-payouts-items/", itemID), nil, opts...)
+	response := &ReferencedPayoutItem{}
+	if err != nil {
+		return response, err
+	}
+
+	err = c.SendWithAuth(req, response)
+	return response, err
+}
+
+// DisburseCapture releases the funds of a capture made with DisbursementModeDelayed to the payee.
+// It is the second half of a delayed disbursement: create the order with
+// DisbursementMode(DisbursementModeDelayed), capture it, then call DisburseCapture with the capture ID
+// once the goods are delivered
+func (c *PayPalClient) DisburseCapture(ctx context.Context, captureID string, opts ...RequestOption) (*ReferencedPayoutItem, error) {
+	return c.CreateReferencedPayoutItem(ctx, ReferencedPayoutItemRequest{ReferenceID: captureID, ReferenceType: ReferenceTypeTransactionID}, opts...)
+}
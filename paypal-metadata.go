@@ -0,0 +1,54 @@
+package payment
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+// ResponseMetadata holds the HTTP details of a PayPal response.
+// PayPal support asks for the DebugID when investigating a call.
+type ResponseMetadata struct {
+	StatusCode int
+	Header     http.Header
+	DebugID    string        // PayPal-Debug-Id header
+	Duration   time.Duration // Time spent sending the request, retries included
+	Body       []byte        // Raw response body
+}
+
+type responseMetadataKey struct{}
+
+// WithResponseMetadata fills metadata with the details of the response once the call returns,
+// whether it succeeded or not. When a rejected token is refreshed, the replayed response is kept.
+func WithResponseMetadata(metadata *ResponseMetadata) RequestOption {
+	return func(c *PayPalClient, req *http.Request) {
+		*req = *req.WithContext(context.WithValue(req.Context(), responseMetadataKey{}, metadata))
+	}
+}
+
+// recordResponseMetadata fills the ResponseMetadata attached to req, if any.
+// The response body is buffered so it can still be decoded afterwards.
+func recordResponseMetadata(req *http.Request, resp *http.Response, start time.Time) {
+	metadata, _ := req.Context().Value(responseMetadataKey{}).(*ResponseMetadata)
+	if metadata == nil {
+		return
+	}
+
+	*metadata = ResponseMetadata{Duration: time.Since(start)}
+	if resp == nil {
+		return
+	}
+
+	metadata.StatusCode = resp.StatusCode
+	metadata.Header = resp.Header
+	metadata.DebugID = resp.Header.Get("Paypal-Debug-Id")
+
+	data, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err == nil {
+		metadata.Body = data
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(data))
+}
@@ -0,0 +1,40 @@
+package payment
+
+import "net/http"
+
+// RoundTripFunc sends a request to PayPal and returns its response
+type RoundTripFunc func(req *http.Request) (*http.Response, error)
+
+// Middleware wraps the sending of every request made by the client, token requests included.
+// It can inspect or change the request before calling next, and the response after it.
+//
+//	tracing := func(next RoundTripFunc) RoundTripFunc {
+//		return func(req *http.Request) (*http.Response, error) {
+//			start := time.Now()
+//			resp, err := next(req)
+//			log.Printf("%s %s took %v", req.Method, req.URL.Path, time.Since(start))
+//			return resp, err
+//		}
+//	}
+type Middleware func(next RoundTripFunc) RoundTripFunc
+
+// WithMiddleware adds middlewares to the client. The first middleware is the outermost one.
+func WithMiddleware(middlewares ...Middleware) ClientOption {
+	return func(c *PayPalClient) {
+		c.middlewares = append(c.middlewares, middlewares...)
+	}
+}
+
+// roundTrip sends req through the middleware chain of the client.
+// Retries happen inside the chain, so middlewares see a single call per request.
+func (c *PayPalClient) roundTrip(req *http.Request) (*http.Response, error) {
+	next := c.rateLimit(c.Client.Do)
+	if c.retryPolicy != nil {
+		next = c.retryPolicy.retry(next)
+	}
+	for i := len(c.middlewares) - 1; i >= 0; i-- {
+		next = c.middlewares[i](next)
+	}
+
+	return next(req)
+}
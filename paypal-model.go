@@ -14,7 +14,8 @@ type ShippingPreference string
 // UserAction has type is string. This type may change in the future
 type UserAction string
 
-// JSONTime overrides MarshalJson method to format in ISO8601
+// JSONTime is a time.Time that marshals to and from the ISO8601 (RFC3339)
+// formats used by PayPal, see paypal-time.go
 type JSONTime time.Time
 
 //Doc: https://developer.paypal.com/docs/api/catalog-products/v1/#definition-product_category
@@ -36,14 +37,128 @@ type SubscriptionTransactionStatus string
 
 type SubscriptionStatus string
 
+// Billing plan statuses
+const (
+	BillingPlanStatusCreated  BillingPlanStatus = "CREATED"
+	BillingPlanStatusActive   BillingPlanStatus = "ACTIVE" // BillingPlanStatusActive is used by BillingPlan and a few others
+	BillingPlanStatusInactive BillingPlanStatus = "INACTIVE"
+	BillingPlanStatusDeleted  BillingPlanStatus = "DELETED"
+)
+
+// Shipping preferences
+// Doc: https://developer.paypal.com/docs/api/orders/v2/#definition-order_application_context
+const (
+	ShippingPreferenceGetFromFile        ShippingPreference = "GET_FROM_FILE"
+	ShippingPreferenceNoShipping         ShippingPreference = "NO_SHIPPING"
+	ShippingPreferenceSetProvidedAddress ShippingPreference = "SET_PROVIDED_ADDRESS"
+)
+
+// User actions
+const (
+	UserActionContinue     UserAction = "CONTINUE"
+	UserActionPayNow       UserAction = "PAY_NOW"
+	UserActionSubscribeNow UserAction = "SUBSCRIBE_NOW"
+)
+
+// Product types
+// Doc: https://developer.paypal.com/docs/api/catalog-products/v1/#products_create
+const (
+	ProductTypePhysical ProductType = "PHYSICAL"
+	ProductTypeDigital  ProductType = "DIGITAL"
+	ProductTypeService  ProductType = "SERVICE"
+)
+
+// Common product categories, any value of the PayPal list may be used
+const (
+	ProductCategorySoftware                        ProductCategory = "SOFTWARE"
+	ProductCategoryDigitalGames                    ProductCategory = "DIGITAL_GAMES"
+	ProductCategoryDigitalMediaBooksMoviesMusic    ProductCategory = "DIGITAL_MEDIA_BOOKS_MOVIES_MUSIC"
+	ProductCategoryOnlineServices                  ProductCategory = "ONLINE_SERVICES"
+	ProductCategoryOnlineGaming                    ProductCategory = "ONLINE_GAMING"
+	ProductCategoryEducationalAndTextbooks         ProductCategory = "EDUCATIONAL_AND_TEXTBOOKS"
+	ProductCategoryMembershipClubsAndOrganizations ProductCategory = "MEMBERSHIP_CLUBS_AND_ORGANIZATIONS"
+	ProductCategoryOther                           ProductCategory = "OTHER"
+)
+
+// Subscription plan statuses
+// Doc: https://developer.paypal.com/docs/api/subscriptions/v1/#plans_get
+const (
+	SubscriptionPlanStatusCreated  SubscriptionPlanStatus = "CREATED"
+	SubscriptionPlanStatusActive   SubscriptionPlanStatus = "ACTIVE"
+	SubscriptionPlanStatusInactive SubscriptionPlanStatus = "INACTIVE"
+)
+
+// Billing cycle interval units
+const (
+	IntervalUnitDay   IntervalUnit = "DAY"
+	IntervalUnitWeek  IntervalUnit = "WEEK"
+	IntervalUnitMonth IntervalUnit = "MONTH"
+	IntervalUnitYear  IntervalUnit = "YEAR"
+)
+
+// Billing cycle tenure types
+const (
+	TenureTypeRegular TenureType = "REGULAR"
+	TenureTypeTrial   TenureType = "TRIAL"
+)
+
+// Actions taken when the initial setup fee payment fails
+const (
+	SetupFeeFailureActionContinue SetupFeeFailureAction = "CONTINUE"
+	SetupFeeFailureActionCancel   SetupFeeFailureAction = "CANCEL"
+)
+
+// Subscription capture types
+const (
+	CaptureTypeOutstandingBalance CaptureType = "OUTSTANDING_BALANCE"
+)
+
+// Subscription transaction statuses
+// Doc: https://developer.paypal.com/docs/api/subscriptions/v1/#subscriptions_transactions
+const (
+	SubscriptionTransactionStatusCompleted         SubscriptionTransactionStatus = "COMPLETED"
+	SubscriptionTransactionStatusDeclined          SubscriptionTransactionStatus = "DECLINED"
+	SubscriptionTransactionStatusPartiallyRefunded SubscriptionTransactionStatus = "PARTIALLY_REFUNDED"
+	SubscriptionTransactionStatusPending           SubscriptionTransactionStatus = "PENDING"
+	SubscriptionTransactionStatusRefunded          SubscriptionTransactionStatus = "REFUNDED"
+)
+
+// Subscription statuses
+// Doc: https://developer.paypal.com/docs/api/subscriptions/v1/#subscriptions_get
+const (
+	SubscriptionStatusApprovalPending SubscriptionStatus = "APPROVAL_PENDING"
+	SubscriptionStatusApproved        SubscriptionStatus = "APPROVED"
+	SubscriptionStatusActive          SubscriptionStatus = "ACTIVE"
+	SubscriptionStatusSuspended       SubscriptionStatus = "SUSPENDED"
+	SubscriptionStatusCancelled       SubscriptionStatus = "CANCELLED"
+	SubscriptionStatusExpired         SubscriptionStatus = "EXPIRED"
+)
+
 // TokenResponse is for API response for the /oauth2/token endpoint
 type TokenResponse struct {
 	RefreshToken string `json:"refresh_token"`
 	Token        string `json:"access_token"`
 	Type         string `json:"token_type"`
 	ExpiresIn    int64  `json:"expires_in"`
+	Scope        string `json:"scope,omitempty"`
+	IDToken      string `json:"id_token,omitempty"`
+	AppID        string `json:"app_id,omitempty"`
+	Nonce        string `json:"nonce,omitempty"`
+}
+
+// TokenRequest holds the optional parameters of a client credentials token request
+// https://developer.paypal.com/api/rest/authentication/
+type TokenRequest struct {
+	Scopes           []string // Limits the token to these scopes
+	TargetClientID   string   // Client the token is issued for, in first-party flows
+	TargetSubject    string   // Payer ID of the merchant the token acts for
+	TargetCustomerID string   // Vault customer the id_token is issued for
+	ResponseType     string   // TokenResponseTypeIDToken to get an id_token along with the access token
 }
 
+// TokenResponseTypeIDToken asks for an id_token along with the access token
+const TokenResponseTypeIDToken = "id_token"
+
 // ErrorResponse struct
 // https://developer.paypal.com/docs/api/errors/
 type ErrorResponse struct {
@@ -53,13 +168,18 @@ type ErrorResponse struct {
 	Message         string                `json:"message"`
 	InformationLink string                `json:"information_link"`
 	Details         []ErrorResponseDetail `json:"details"`
+	Links           []Link                `json:"links,omitempty"`
 }
 
 // ErrorResponseDetail struct
+// https://developer.paypal.com/docs/api/errors/
 type ErrorResponseDetail struct {
-	Field string `json:"field"`
-	Issue string `json:"issue"`
-	Links []Link `json:"link"`
+	Field       string `json:"field"`
+	Value       string `json:"value,omitempty"`
+	Location    string `json:"location,omitempty"`
+	Issue       string `json:"issue"`
+	Description string `json:"description,omitempty"`
+	Links       []Link `json:"link"`
 }
 
 // Payout struct
@@ -109,6 +229,33 @@ type BatchHeader struct {
 	SenderBatchHeader *SenderBatchHeader `json:"sender_batch_header,omitempty"`
 }
 
+// ReferencedPayoutItemRequest struct
+// https://developer.paypal.com/docs/api/referenced-payouts/v1/
+type ReferencedPayoutItemRequest struct {
+	ReferenceID   string `json:"reference_id"`
+	ReferenceType string `json:"reference_type"`
+}
+
+// ReferencedPayoutItemProcessingState struct
+type ReferencedPayoutItemProcessingState struct {
+	Status string `json:"status,omitempty"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// ReferencedPayoutItem struct
+type ReferencedPayoutItem struct {
+	ItemID                    string                               `json:"item_id,omitempty"`
+	ProcessingState           *ReferencedPayoutItemProcessingState `json:"processing_state,omitempty"`
+	ReferenceID               string                               `json:"reference_id,omitempty"`
+	ReferenceType             string                               `json:"reference_type,omitempty"`
+	PayoutTransactionID       string                               `json:"payout_transaction_id,omitempty"`
+	DisbursementTransactionID string                               `json:"disbursement_transaction_id,omitempty"`
+	PayeeEmail                string                               `json:"payee_email,omitempty"`
+	PayoutAmount              *Money                               `json:"payout_amount,omitempty"`
+	PayoutDestination         string                               `json:"payout_destination,omitempty"`
+	Links                     []Link                               `json:"links,omitempty"`
+}
+
 // PayoutItemResponse struct
 type PayoutItemResponse struct {
 	PayoutItemID      string        `json:"payout_item_id"`
@@ -185,19 +332,42 @@ type Refund struct {
 	UpdateTime    *time.Time `json:"update_time,omitempty"`
 }
 
+// RefundCaptureRequest struct
+// https://developer.paypal.com/docs/api/payments/v2/#captures_refund
+type RefundCaptureRequest struct {
+	Amount      *Money `json:"amount,omitempty"`
+	InvoiceID   string `json:"invoice_id,omitempty"`
+	NoteToPayer string `json:"note_to_payer,omitempty"`
+}
+
+// RefundResponse struct
+// https://developer.paypal.com/docs/api/payments/v2/#definition-refund
+type RefundResponse struct {
+	ID          string     `json:"id,omitempty"`
+	Status      string     `json:"status,omitempty"`
+	Amount      *Money     `json:"amount,omitempty"`
+	InvoiceID   string     `json:"invoice_id,omitempty"`
+	NoteToPayer string     `json:"note_to_payer,omitempty"`
+	CreateTime  *time.Time `json:"create_time,omitempty"`
+	UpdateTime  *time.Time `json:"update_time,omitempty"`
+	Links       []Link     `json:"links,omitempty"`
+}
+
 // Authorization struct
 type Authorization struct {
-	ID               string                `json:"id,omitempty"`
-	CustomID         string                `json:"custom_id,omitempty"`
-	InvoiceID        string                `json:"invoice_id,omitempty"`
-	Status           string                `json:"status,omitempty"`
-	StatusDetails    *CaptureStatusDetails `json:"status_details,omitempty"`
-	Amount           *PurchaseUnitAmount   `json:"amount,omitempty"`
-	SellerProtection *SellerProtection     `json:"seller_protection,omitempty"`
-	CreateTime       *time.Time            `json:"create_time,omitempty"`
-	UpdateTime       *time.Time            `json:"update_time,omitempty"`
-	ExpirationTime   *time.Time            `json:"expiration_time,omitempty"`
-	Links            []Link                `json:"links,omitempty"`
+	ID                          string                       `json:"id,omitempty"`
+	CustomID                    string                       `json:"custom_id,omitempty"`
+	InvoiceID                   string                       `json:"invoice_id,omitempty"`
+	Status                      string                       `json:"status,omitempty"`
+	StatusDetails               *CaptureStatusDetails        `json:"status_details,omitempty"`
+	Amount                      *PurchaseUnitAmount          `json:"amount,omitempty"`
+	SellerProtection            *SellerProtection            `json:"seller_protection,omitempty"`
+	CreateTime                  *time.Time                   `json:"create_time,omitempty"`
+	UpdateTime                  *time.Time                   `json:"update_time,omitempty"`
+	ExpirationTime              *time.Time                   `json:"expiration_time,omitempty"`
+	NetworkTransactionReference *NetworkTransactionReference `json:"network_transaction_reference,omitempty"`
+	ProcessorResponse           *ProcessorResponse           `json:"processor_response,omitempty"`
+	Links                       []Link                       `json:"links,omitempty"`
 }
 
 // CaptureStatusDetails struct
@@ -206,6 +376,15 @@ type CaptureStatusDetails struct {
 	Reason string `json:"reason,omitempty"`
 }
 
+// ProcessorResponse struct holds the result returned by the card processor
+// https://developer.paypal.com/docs/api/payments/v2/#definition-processor_response
+type ProcessorResponse struct {
+	AVSCode           string `json:"avs_code,omitempty"`
+	CVVCode           string `json:"cvv_code,omitempty"`
+	ResponseCode      string `json:"response_code,omitempty"`
+	PaymentAdviceCode string `json:"payment_advice_code,omitempty"`
+}
+
 // PurchaseUnitAmount struct
 type PurchaseUnitAmount struct {
 	Currency  string                       `json:"currency_code"`
@@ -240,23 +419,26 @@ type SellerProtection struct {
 // PaymentCaptureRequest struct
 // https://developer.paypal.com/docs/api/payments/v2/#authorizations_capture
 type PaymentCaptureRequest struct {
-	InvoiceID      string `json:"invoice_id,omitempty"`
-	NoteToPayer    string `json:"note_to_payer,omitempty"`
-	SoftDescriptor string `json:"soft_descriptor,omitempty"`
-	Amount         *Money `json:"amount,omitempty"`
-	FinalCapture   bool   `json:"final_capture,omitempty"`
+	InvoiceID          string              `json:"invoice_id,omitempty"`
+	NoteToPayer        string              `json:"note_to_payer,omitempty"`
+	SoftDescriptor     string              `json:"soft_descriptor,omitempty"`
+	Amount             *Money              `json:"amount,omitempty"`
+	FinalCapture       bool                `json:"final_capture,omitempty"`
+	PaymentInstruction *PaymentInstruction `json:"payment_instruction,omitempty"`
 }
 
 // PaymentCaptureResponse struct
 type PaymentCaptureResponse struct {
-	Status           string                `json:"status,omitempty"`
-	StatusDetails    *CaptureStatusDetails `json:"status_details,omitempty"`
-	ID               string                `json:"id,omitempty"`
-	Amount           *Money                `json:"amount,omitempty"`
-	InvoiceID        string                `json:"invoice_id,omitempty"`
-	FinalCapture     bool                  `json:"final_capture,omitempty"`
-	DisbursementMode string                `json:"disbursement_mode,omitempty"`
-	Links            []Link                `json:"links,omitempty"`
+	Status                      string                       `json:"status,omitempty"`
+	StatusDetails               *CaptureStatusDetails        `json:"status_details,omitempty"`
+	ID                          string                       `json:"id,omitempty"`
+	Amount                      *Money                       `json:"amount,omitempty"`
+	InvoiceID                   string                       `json:"invoice_id,omitempty"`
+	FinalCapture                bool                         `json:"final_capture,omitempty"`
+	DisbursementMode            string                       `json:"disbursement_mode,omitempty"`
+	NetworkTransactionReference *NetworkTransactionReference `json:"network_transaction_reference,omitempty"`
+	ProcessorResponse           *ProcessorResponse           `json:"processor_response,omitempty"`
+	Links                       []Link                       `json:"links,omitempty"`
 }
 
 // Capture struct
@@ -351,7 +533,7 @@ type CreateBillingResponse struct {
 type Patch struct {
 	Operation string      `json:"op"`
 	Path      string      `json:"path"`
-	Value     interface{} `json:"value"`
+	Value     interface{} `json:"value,omitempty"`
 }
 
 // BillingAgreement struct
@@ -673,11 +855,79 @@ type SearchCheckoutOption struct {
 	CheckoutOptionValue string `json:"checkout_option_value"`
 }
 
+// BalancesResponse struct
+// https://developer.paypal.com/docs/api/transaction-search/v1/#balances_get
+type BalancesResponse struct {
+	Balances        []BalanceDetail `json:"balances"`
+	AccountID       string          `json:"account_id,omitempty"`
+	AsOfTime        *time.Time      `json:"as_of_time,omitempty"`
+	LastRefreshTime *time.Time      `json:"last_refresh_time,omitempty"`
+}
+
+// BalanceDetail struct
+type BalanceDetail struct {
+	Currency         string `json:"currency"`
+	Primary          bool   `json:"primary,omitempty"`
+	TotalBalance     Money  `json:"total_balance"`
+	AvailableBalance *Money `json:"available_balance,omitempty"`
+	WithheldBalance  *Money `json:"withheld_balance,omitempty"`
+}
+
+// TransactionContextKey is the key of a Set Transaction Context additional data entry
+type TransactionContextKey string
+
+const (
+	// Common Set Transaction Context keys
+	TransactionContextSenderAccountID          TransactionContextKey = "sender_account_id"
+	TransactionContextSenderFirstName          TransactionContextKey = "sender_first_name"
+	TransactionContextSenderLastName           TransactionContextKey = "sender_last_name"
+	TransactionContextSenderEmail              TransactionContextKey = "sender_email"
+	TransactionContextSenderPhone              TransactionContextKey = "sender_phone"
+	TransactionContextSenderCountryCode        TransactionContextKey = "sender_country_code"
+	TransactionContextSenderCreateDate         TransactionContextKey = "sender_create_date"
+	TransactionContextSenderAddressZip         TransactionContextKey = "sender_address_zip"
+	TransactionContextSenderAddressState       TransactionContextKey = "sender_address_state"
+	TransactionContextSenderAddressCity        TransactionContextKey = "sender_address_city"
+	TransactionContextSenderAddressCountryCode TransactionContextKey = "sender_address_country_code"
+	TransactionContextReceiverAccountID        TransactionContextKey = "receiver_account_id"
+	TransactionContextReceiverCreateDate       TransactionContextKey = "receiver_create_date"
+	TransactionContextReceiverEmail            TransactionContextKey = "receiver_email"
+	TransactionContextReceiverAddressCountry   TransactionContextKey = "receiver_address_country_code"
+	TransactionContextBusinessName             TransactionContextKey = "business_name"
+	TransactionContextRecipientPopularityScore TransactionContextKey = "recipient_popularity_score"
+	TransactionContextFirstInteractionDate     TransactionContextKey = "first_interaction_date"
+	TransactionContextTxnCountTotal            TransactionContextKey = "txn_count_total"
+	TransactionContextVertical                 TransactionContextKey = "vertical"
+	TransactionContextHighRiskTxnFlag          TransactionContextKey = "highrisk_txn_flag"
+	TransactionContextCDString                 TransactionContextKey = "cd_string"
+)
+
+// TransactionContextData is a Set Transaction Context additional data entry
+type TransactionContextData struct {
+	Key   TransactionContextKey `json:"key"`
+	Value string                `json:"value"`
+}
+
 // CreditCardsFilter struct
 type CreditCardsFilter struct {
-	PageSize int
-	Page     int
-}
+	PageSize           int
+	Page               int
+	ExternalCustomerID string
+	ExternalCardID     string
+	MerchantID         string
+	StartTime          time.Time // Cards created at or after, ignored when zero
+	EndTime            time.Time // Cards created before, ignored when zero
+	SortBy             string    // CreditCardsSortByCreateTime or CreditCardsSortByUpdateTime
+	SortOrder          string    // SortOrderAsc or SortOrderDesc
+}
+
+// Sort parameters of CreditCardsFilter
+const (
+	CreditCardsSortByCreateTime = "create_time"
+	CreditCardsSortByUpdateTime = "update_time"
+	SortOrderAsc                = "asc"
+	SortOrderDesc               = "desc"
+)
 
 // CreditCards struct
 type CreditCards struct {
@@ -699,6 +949,7 @@ type Order struct {
 	Intent        string                 `json:"intent,omitempty"`
 	Payer         *PayerWithNameAndPhone `json:"payer,omitempty"`
 	PurchaseUnits []PurchaseUnit         `json:"purchase_units,omitempty"`
+	PaymentSource *PaymentSource         `json:"payment_source,omitempty"`
 	Links         []Link                 `json:"links,omitempty"`
 	CreateTime    *time.Time             `json:"create_time,omitempty"`
 	UpdateTime    *time.Time             `json:"update_time,omitempty"`
@@ -767,7 +1018,8 @@ type PayeeForOrders struct {
 
 // CapturedPayments has the amounts for a captured order
 type CapturedPayments struct {
-	Captures []CaptureAmount `json:"captures,omitempty"`
+	Authorizations []Authorization `json:"authorizations,omitempty"`
+	Captures       []CaptureAmount `json:"captures,omitempty"`
 }
 
 // https://developer.paypal.com/docs/api/payments/v2/#definition-payment_instruction
@@ -795,11 +1047,15 @@ type Name struct {
 
 // CaptureAmount struct
 type CaptureAmount struct {
-	ID                        string                     `json:"id,omitempty"`
-	CustomID                  string                     `json:"custom_id,omitempty"`
-	Amount                    *PurchaseUnitAmount        `json:"amount,omitempty"`
-	SellerProtection          *SellerProtection          `json:"seller_protection,omitempty"`
-	SellerReceivableBreakdown *SellerReceivableBreakdown `json:"seller_receivable_breakdown,omitempty"`
+	ID                          string                       `json:"id,omitempty"`
+	Status                      string                       `json:"status,omitempty"`
+	StatusDetails               *CaptureStatusDetails        `json:"status_details,omitempty"`
+	CustomID                    string                       `json:"custom_id,omitempty"`
+	Amount                      *PurchaseUnitAmount          `json:"amount,omitempty"`
+	SellerProtection            *SellerProtection            `json:"seller_protection,omitempty"`
+	SellerReceivableBreakdown   *SellerReceivableBreakdown   `json:"seller_receivable_breakdown,omitempty"`
+	NetworkTransactionReference *NetworkTransactionReference `json:"network_transaction_reference,omitempty"`
+	ProcessorResponse           *ProcessorResponse           `json:"processor_response,omitempty"`
 }
 
 // SellerReceivableBreakdown has the detailed breakdown of the capture activity.
@@ -864,6 +1120,17 @@ type CreateOrderPayer struct {
 	Address      *ShippingDetailAddressPortable `json:"address,omitempty"`
 }
 
+// CreateOrderRequest struct.
+// Set PaymentSource with an ExperienceContext instead of the legacy Payer and ApplicationContext for new integrations.
+// https://developer.paypal.com/docs/api/orders/v2/#orders_create
+type CreateOrderRequest struct {
+	Intent             string                `json:"intent"`
+	Payer              *CreateOrderPayer     `json:"payer,omitempty"`
+	PurchaseUnits      []PurchaseUnitRequest `json:"purchase_units"`
+	PaymentSource      *PaymentSource        `json:"payment_source,omitempty"`
+	ApplicationContext *ApplicationContext   `json:"application_context,omitempty"`
+}
+
 // ApplicationContext struct
 type ApplicationContext struct {
 	BrandName          string             `json:"brand_name,omitempty"`
@@ -882,21 +1149,201 @@ type AuthorizeOrderRequest struct {
 }
 
 // PaymentSource structure
+// https://developer.paypal.com/docs/api/orders/v2/#definition-payment_source
 type PaymentSource struct {
-	Card  *PaymentSourceCard  `json:"card,omitempty"`
-	Token *PaymentSourceToken `json:"token,omitempty"`
+	Card      *PaymentSourceCard      `json:"card,omitempty"`
+	Token     *PaymentSourceToken     `json:"token,omitempty"`
+	PayPal    *PaymentSourcePayPal    `json:"paypal,omitempty"`
+	Venmo     *PaymentSourceVenmo     `json:"venmo,omitempty"`
+	ApplePay  *PaymentSourceApplePay  `json:"apple_pay,omitempty"`
+	GooglePay *PaymentSourceGooglePay `json:"google_pay,omitempty"`
+}
+
+// ExperienceContext customizes the payer experience of a payment source.
+// Wallets use every field, cards and Apple/Google Pay only use the return and cancel URLs.
+// https://developer.paypal.com/docs/api/orders/v2/#definition-paypal_wallet_experience_context
+type ExperienceContext struct {
+	BrandName               string             `json:"brand_name,omitempty"`
+	Locale                  string             `json:"locale,omitempty"`
+	ShippingPreference      ShippingPreference `json:"shipping_preference,omitempty"`
+	UserAction              UserAction         `json:"user_action,omitempty"`
+	LandingPage             string             `json:"landing_page,omitempty"`
+	PaymentMethodPreference string             `json:"payment_method_preference,omitempty"`
+	ReturnURL               string             `json:"return_url,omitempty"`
+	CancelURL               string             `json:"cancel_url,omitempty"`
 }
 
 // PaymentSourceCard struct
 type PaymentSourceCard struct {
-	ID             string              `json:"id"`
-	Name           string              `json:"name"`
-	Number         string              `json:"number"`
-	Expiry         string              `json:"expiry"`
-	SecurityCode   string              `json:"security_code"`
-	LastDigits     string              `json:"last_digits"`
-	CardType       string              `json:"card_type"`
-	BillingAddress *CardBillingAddress `json:"billing_address"`
+	ID                   string                `json:"id,omitempty"`
+	Name                 string                `json:"name,omitempty"`
+	Number               string                `json:"number,omitempty"`
+	Expiry               string                `json:"expiry,omitempty"`
+	SecurityCode         string                `json:"security_code,omitempty"`
+	LastDigits           string                `json:"last_digits,omitempty"`
+	CardType             string                `json:"card_type,omitempty"`
+	Brand                string                `json:"brand,omitempty"`
+	BillingAddress       *CardBillingAddress   `json:"billing_address,omitempty"`
+	ExperienceContext    *ExperienceContext    `json:"experience_context,omitempty"`
+	Attributes           *CardAttributes       `json:"attributes,omitempty"`
+	StoredCredential     *CardStoredCredential `json:"stored_credential,omitempty"`
+	AuthenticationResult *AuthenticationResult `json:"authentication_result,omitempty"`
+}
+
+// CardAttributes struct
+// https://developer.paypal.com/docs/api/orders/v2/#definition-card_attributes
+type CardAttributes struct {
+	Verification *CardVerification `json:"verification,omitempty"`
+}
+
+// CardVerification selects how the card is verified, e.g. SCA_WHEN_REQUIRED to satisfy PSD2 strong customer authentication
+// https://developer.paypal.com/docs/api/orders/v2/#definition-card_verification
+type CardVerification struct {
+	Method string `json:"method,omitempty"`
+}
+
+// CardStoredCredential describes a payment with stored card credentials, e.g. merchant initiated recurring payments
+// https://developer.paypal.com/docs/api/orders/v2/#definition-card_stored_credential
+type CardStoredCredential struct {
+	PaymentInitiator                    string                       `json:"payment_initiator"`
+	PaymentType                         string                       `json:"payment_type"`
+	Usage                               string                       `json:"usage,omitempty"`
+	PreviousNetworkTransactionReference *NetworkTransactionReference `json:"previous_network_transaction_reference,omitempty"`
+}
+
+// NetworkTransactionReference is the card network reference of a transaction
+// https://developer.paypal.com/docs/api/orders/v2/#definition-network_transaction_reference
+type NetworkTransactionReference struct {
+	ID                      string `json:"id"`
+	Date                    string `json:"date,omitempty"`
+	Network                 string `json:"network,omitempty"`
+	AcquirerReferenceNumber string `json:"acquirer_reference_number,omitempty"`
+}
+
+// AuthenticationResult is the 3-D Secure outcome of a card payment source
+// https://developer.paypal.com/docs/api/orders/v2/#definition-authentication_response
+type AuthenticationResult struct {
+	LiabilityShift string                              `json:"liability_shift,omitempty"`
+	ThreeDSecure   *ThreeDSecureAuthenticationResponse `json:"three_d_secure,omitempty"`
+}
+
+// ThreeDSecureAuthenticationResponse struct
+// https://developer.paypal.com/docs/api/orders/v2/#definition-three_d_secure_authentication_response
+type ThreeDSecureAuthenticationResponse struct {
+	AuthenticationStatus string `json:"authentication_status,omitempty"`
+	EnrollmentStatus     string `json:"enrollment_status,omitempty"`
+}
+
+const (
+	// Card verification methods, used by CardVerification
+	CardVerificationSCAAlways       string = "SCA_ALWAYS"
+	CardVerificationSCAWhenRequired string = "SCA_WHEN_REQUIRED"
+	CardVerification3DSecure        string = "3D_SECURE"
+	CardVerificationAVSCVV          string = "AVS_CVV"
+
+	// Liability shift values of AuthenticationResult
+	LiabilityShiftPossible string = "POSSIBLE"
+	LiabilityShiftNo       string = "NO"
+	LiabilityShiftUnknown  string = "UNKNOWN"
+
+	// Values of CardStoredCredential
+	PaymentInitiatorCustomer   string = "CUSTOMER"
+	PaymentInitiatorMerchant   string = "MERCHANT"
+	StoredPaymentOneTime       string = "ONE_TIME"
+	StoredPaymentRecurring     string = "RECURRING"
+	StoredPaymentUnscheduled   string = "UNSCHEDULED"
+	StoredCredentialFirst      string = "FIRST"
+	StoredCredentialSubsequent string = "SUBSEQUENT"
+	StoredCredentialDerived    string = "DERIVED"
+)
+
+// PaymentSourcePayPal struct
+// https://developer.paypal.com/docs/api/orders/v2/#definition-paypal_wallet
+type PaymentSourcePayPal struct {
+	ExperienceContext *ExperienceContext             `json:"experience_context,omitempty"`
+	VaultID           string                         `json:"vault_id,omitempty"`
+	EmailAddress      string                         `json:"email_address,omitempty"`
+	AccountID         string                         `json:"account_id,omitempty"`
+	Name              *CreateOrderPayerName          `json:"name,omitempty"`
+	Phone             *PhoneWithType                 `json:"phone,omitempty"`
+	BirthDate         string                         `json:"birth_date,omitempty"`
+	TaxInfo           *TaxInfo                       `json:"tax_info,omitempty"`
+	Address           *ShippingDetailAddressPortable `json:"address,omitempty"`
+}
+
+// PaymentSourceVenmo struct
+// https://developer.paypal.com/docs/api/orders/v2/#definition-venmo_wallet_request
+type PaymentSourceVenmo struct {
+	ExperienceContext *ExperienceContext    `json:"experience_context,omitempty"`
+	VaultID           string                `json:"vault_id,omitempty"`
+	EmailAddress      string                `json:"email_address,omitempty"`
+	AccountID         string                `json:"account_id,omitempty"`
+	UserName          string                `json:"user_name,omitempty"`
+	Name              *CreateOrderPayerName `json:"name,omitempty"`
+}
+
+// PaymentSourceApplePay struct
+// https://developer.paypal.com/docs/api/orders/v2/#definition-apple_pay_request
+type PaymentSourceApplePay struct {
+	ID                string                  `json:"id,omitempty"`
+	Name              string                  `json:"name,omitempty"`
+	EmailAddress      string                  `json:"email_address,omitempty"`
+	PhoneNumber       *PhoneWithTypeNumber    `json:"phone_number,omitempty"`
+	DecryptedToken    *ApplePayDecryptedToken `json:"decrypted_token,omitempty"`
+	VaultID           string                  `json:"vault_id,omitempty"`
+	ExperienceContext *ExperienceContext      `json:"experience_context,omitempty"`
+}
+
+// ApplePayDecryptedToken struct
+// https://developer.paypal.com/docs/api/orders/v2/#definition-apple_pay_decrypted_token_data
+type ApplePayDecryptedToken struct {
+	TransactionAmount    *Money               `json:"transaction_amount,omitempty"`
+	TokenizedCard        *TokenizedCard       `json:"tokenized_card"`
+	DeviceManufacturerID string               `json:"device_manufacturer_id,omitempty"`
+	PaymentDataType      string               `json:"payment_data_type,omitempty"`
+	PaymentData          *ApplePayPaymentData `json:"payment_data,omitempty"`
+}
+
+// ApplePayPaymentData struct
+type ApplePayPaymentData struct {
+	Cryptogram   string `json:"cryptogram,omitempty"`
+	ECIIndicator string `json:"eci_indicator,omitempty"`
+	EMVData      string `json:"emv_data,omitempty"`
+	Pin          string `json:"pin,omitempty"`
+}
+
+// PaymentSourceGooglePay struct
+// https://developer.paypal.com/docs/api/orders/v2/#definition-google_pay_request
+type PaymentSourceGooglePay struct {
+	Name              string                   `json:"name,omitempty"`
+	EmailAddress      string                   `json:"email_address,omitempty"`
+	PhoneNumber       *PhoneWithTypeNumber     `json:"phone_number,omitempty"`
+	Card              *TokenizedCard           `json:"card,omitempty"`
+	DecryptedToken    *GooglePayDecryptedToken `json:"decrypted_token,omitempty"`
+	ExperienceContext *ExperienceContext       `json:"experience_context,omitempty"`
+}
+
+// GooglePayDecryptedToken struct
+// https://developer.paypal.com/docs/api/orders/v2/#definition-google_pay_decrypted_token
+type GooglePayDecryptedToken struct {
+	MessageID            string         `json:"message_id,omitempty"`
+	MessageExpiration    string         `json:"message_expiration,omitempty"`
+	PaymentMethod        string         `json:"payment_method"`
+	Card                 *TokenizedCard `json:"card"`
+	AuthenticationMethod string         `json:"authentication_method"`
+	Cryptogram           string         `json:"cryptogram,omitempty"`
+	ECIIndicator         string         `json:"eci_indicator,omitempty"`
+}
+
+// TokenizedCard is the card data of a wallet token
+type TokenizedCard struct {
+	Name           string              `json:"name,omitempty"`
+	Number         string              `json:"number,omitempty"`
+	Expiry         string              `json:"expiry,omitempty"`
+	LastDigits     string              `json:"last_digits,omitempty"`
+	Type           string              `json:"type,omitempty"`
+	Brand          string              `json:"brand,omitempty"`
+	BillingAddress *CardBillingAddress `json:"billing_address,omitempty"`
 }
 
 // CardBillingAddress struct
@@ -909,6 +1356,14 @@ type CardBillingAddress struct {
 	CountryCode  string `json:"country_code"`
 }
 
+// ConfirmPaymentSourceRequest struct
+// https://developer.paypal.com/docs/api/orders/v2/#orders_confirm
+type ConfirmPaymentSourceRequest struct {
+	PaymentSource         *PaymentSource      `json:"payment_source"`
+	ProcessingInstruction string              `json:"processing_instruction,omitempty"`
+	ApplicationContext    *ApplicationContext `json:"application_context,omitempty"`
+}
+
 // PaymentSourceToken struct
 type PaymentSourceToken struct {
 	ID   string `json:"id"`
@@ -928,6 +1383,7 @@ type CaptureOrderResponse struct {
 	Payer         *PayerWithNameAndPhone `json:"payer,omitempty"`
 	Address       *Address               `json:"address,omitempty"`
 	PurchaseUnits []CapturedPurchaseUnit `json:"purchase_units,omitempty"`
+	PaymentSource *PaymentSource         `json:"payment_source,omitempty"`
 }
 
 // CapturedPurchaseUnit are purchase units for a captured order
@@ -0,0 +1,174 @@
+package payment
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrCurrencyMismatch is returned when adding or subtracting amounts of different currencies
+var ErrCurrencyMismatch = errors.New("paypal: currency mismatch")
+
+// currencyExponents lists the currencies that do not use 2 decimals at PayPal
+// Doc: https://developer.paypal.com/reference/currency-codes/
+var currencyExponents = map[string]int{
+	"HUF": 0,
+	"JPY": 0,
+	"TWD": 0,
+	"BIF": 0,
+	"CLP": 0,
+	"DJF": 0,
+	"GNF": 0,
+	"ISK": 0,
+	"KMF": 0,
+	"KRW": 0,
+	"PYG": 0,
+	"RWF": 0,
+	"UGX": 0,
+	"VND": 0,
+	"VUV": 0,
+	"XAF": 0,
+	"XOF": 0,
+	"XPF": 0,
+	"BHD": 3,
+	"JOD": 3,
+	"KWD": 3,
+	"OMR": 3,
+	"TND": 3,
+}
+
+// CurrencyExponent returns the number of decimals PayPal accepts for currency
+func CurrencyExponent(currency string) int {
+	if exp, ok := currencyExponents[strings.ToUpper(currency)]; ok {
+		return exp
+	}
+	return 2
+}
+
+// MoneyAmount is an amount in the minor unit of its currency, e.g. cents for USD and yen for JPY.
+// Use it to compute amounts and convert the result to the string based models
+//
+//	total, _ := payment.ParseMoneyAmount("USD", "19.99")
+//	unit.Amount = total.Mul(3).PurchaseUnitAmount()
+type MoneyAmount struct {
+	Currency string
+	Minor    int64
+}
+
+// NewMoneyAmount returns an amount of minor units of currency
+func NewMoneyAmount(currency string, minor int64) MoneyAmount {
+	return MoneyAmount{Currency: strings.ToUpper(currency), Minor: minor}
+}
+
+// ParseMoneyAmount parses a decimal string such as "10.5" or "1000",
+// rejecting more decimals than the currency allows
+func ParseMoneyAmount(currency, value string) (MoneyAmount, error) {
+	exp := CurrencyExponent(currency)
+
+	s := value
+	negative := strings.HasPrefix(s, "-")
+	if negative {
+		s = s[1:]
+	}
+
+	whole, frac := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		whole, frac = s[:i], s[i+1:]
+		if frac == "" {
+			return MoneyAmount{}, fmt.Errorf("paypal: invalid amount %q", value)
+		}
+	}
+	if whole == "" || len(frac) > exp || strings.ContainsAny(whole+frac, "+-") {
+		return MoneyAmount{}, fmt.Errorf("paypal: invalid %s amount %q", currency, value)
+	}
+
+	digits := whole + frac + strings.Repeat("0", exp-len(frac))
+	minor, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil {
+		return MoneyAmount{}, fmt.Errorf("paypal: invalid amount %q: %w", value, err)
+	}
+	if negative {
+		minor = -minor
+	}
+
+	return NewMoneyAmount(currency, minor), nil
+}
+
+// Add returns m + o, both amounts must be of the same currency
+func (m MoneyAmount) Add(o MoneyAmount) (MoneyAmount, error) {
+	if !strings.EqualFold(m.Currency, o.Currency) {
+		return MoneyAmount{}, fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Currency, o.Currency)
+	}
+	return MoneyAmount{Currency: m.Currency, Minor: m.Minor + o.Minor}, nil
+}
+
+// Sub returns m - o, both amounts must be of the same currency
+func (m MoneyAmount) Sub(o MoneyAmount) (MoneyAmount, error) {
+	return m.Add(MoneyAmount{Currency: o.Currency, Minor: -o.Minor})
+}
+
+// Mul returns m multiplied by n, e.g. a unit price by a quantity
+func (m MoneyAmount) Mul(n int64) MoneyAmount {
+	return MoneyAmount{Currency: m.Currency, Minor: m.Minor * n}
+}
+
+// IsZero reports whether the amount is zero
+func (m MoneyAmount) IsZero() bool {
+	return m.Minor == 0
+}
+
+// String formats the amount with the number of decimals of its currency, e.g. "10.50" or "1000"
+func (m MoneyAmount) String() string {
+	minor := m.Minor
+	sign := ""
+	if minor < 0 {
+		sign, minor = "-", -minor
+	}
+
+	exp := CurrencyExponent(m.Currency)
+	s := strconv.FormatInt(minor, 10)
+	if exp == 0 {
+		return sign + s
+	}
+	if len(s) <= exp {
+		s = strings.Repeat("0", exp-len(s)+1) + s
+	}
+
+	return sign + s[:len(s)-exp] + "." + s[len(s)-exp:]
+}
+
+// Money converts the amount to a Money
+func (m MoneyAmount) Money() *Money {
+	return &Money{Currency: m.Currency, Value: m.String()}
+}
+
+// PurchaseUnitAmount converts the amount to a PurchaseUnitAmount, without breakdown
+func (m MoneyAmount) PurchaseUnitAmount() *PurchaseUnitAmount {
+	return &PurchaseUnitAmount{Currency: m.Currency, Value: m.String()}
+}
+
+// AmountPayout converts the amount to an AmountPayout
+func (m MoneyAmount) AmountPayout() *AmountPayout {
+	return &AmountPayout{Currency: m.Currency, Value: m.String()}
+}
+
+// Amount converts the amount to a Payment v1 Amount
+func (m MoneyAmount) Amount() *Amount {
+	return &Amount{Currency: m.Currency, Total: m.String()}
+}
+
+// MoneyAmount parses the value of m
+func (m Money) MoneyAmount() (MoneyAmount, error) {
+	return ParseMoneyAmount(m.Currency, m.Value)
+}
+
+// MoneyAmount parses the value of a
+func (a PurchaseUnitAmount) MoneyAmount() (MoneyAmount, error) {
+	return ParseMoneyAmount(a.Currency, a.Value)
+}
+
+// MoneyAmount parses the value of a
+func (a AmountPayout) MoneyAmount() (MoneyAmount, error) {
+	return ParseMoneyAmount(a.Currency, a.Value)
+}
@@ -0,0 +1,234 @@
+package payment
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// RequestOption customizes a single PayPal API request
+type RequestOption func(c *PayPalClient, req *http.Request)
+
+// ClientOption customizes a PayPal client when it is created
+type ClientOption func(c *PayPalClient)
+
+// WithDefaultReturnRepresentation asks for the verbose response on every request made by the client.
+// A Prefer header set on a single request takes precedence.
+func WithDefaultReturnRepresentation() ClientOption {
+	return func(c *PayPalClient) {
+		c.returnRepresentation = true
+	}
+}
+
+// WithHeader sets an extra header on the request, replacing any value already set
+func WithHeader(key, value string) RequestOption {
+	return func(c *PayPalClient, req *http.Request) {
+		req.Header.Set(key, value)
+	}
+}
+
+// WithQuery adds a query parameter to the request URL
+func WithQuery(key, value string) RequestOption {
+	return func(c *PayPalClient, req *http.Request) {
+		q := req.URL.Query()
+		q.Add(key, value)
+		req.URL.RawQuery = q.Encode()
+	}
+}
+
+// WithHTTPClient makes the client send its requests with httpClient
+func WithHTTPClient(httpClient *http.Client) ClientOption {
+	return func(c *PayPalClient) {
+		c.Client = httpClient
+	}
+}
+
+// WithTimeout sets the time limit of every request made by the client, token requests included
+func WithTimeout(timeout time.Duration) ClientOption {
+	return func(c *PayPalClient) {
+		httpClient := copyHTTPClient(c.Client)
+		httpClient.Timeout = timeout
+		c.Client = httpClient
+	}
+}
+
+// WithLogger writes every request and response made by the client to w
+func WithLogger(w io.Writer) ClientOption {
+	return func(c *PayPalClient) {
+		c.Log = w
+	}
+}
+
+// WithAPIBase overrides the API base URL of the config, e.g. APIBaseLive or APIBaseSandBox
+func WithAPIBase(apiBase string) ClientOption {
+	return func(c *PayPalClient) {
+		c.APIBase = apiBase
+	}
+}
+
+// WithUserAgent sets the User-Agent header of every request made by the client
+func WithUserAgent(userAgent string) ClientOption {
+	return func(c *PayPalClient) {
+		c.userAgent = userAgent
+	}
+}
+
+// WithProxy routes every request made by the client through proxyURL
+func WithProxy(proxyURL *url.URL) ClientOption {
+	return func(c *PayPalClient) {
+		var transport *http.Transport
+		if c.Client != nil {
+			transport, _ = c.Client.Transport.(*http.Transport)
+		}
+		if transport == nil {
+			transport = http.DefaultTransport.(*http.Transport)
+		}
+		transport = transport.Clone()
+		transport.Proxy = http.ProxyURL(proxyURL)
+
+		httpClient := copyHTTPClient(c.Client)
+		httpClient.Transport = transport
+		c.Client = httpClient
+	}
+}
+
+// copyHTTPClient returns a copy of httpClient so options never modify a client owned by the caller
+func copyHTTPClient(httpClient *http.Client) *http.Client {
+	if httpClient == nil {
+		return &http.Client{}
+	}
+	copied := *httpClient
+	return &copied
+}
+
+// withoutHeaders removes headers set by the options applied before it
+func withoutHeaders(keys ...string) RequestOption {
+	return func(c *PayPalClient, req *http.Request) {
+		for _, key := range keys {
+			req.Header.Del(key)
+		}
+	}
+}
+
+// WithReturnRepresentation asks PayPal to return the complete resource in the response
+// Doc: https://developer.paypal.com/docs/api/reference/api-requests/#prefer
+func WithReturnRepresentation() RequestOption {
+	return WithHeader("Prefer", "return=representation")
+}
+
+// WithIdempotencyKey sets the PayPal-Request-Id header so that retried POST calls are processed only once.
+// Doc: https://developer.paypal.com/docs/api/reference/api-requests/#paypal-request-id
+func WithIdempotencyKey(key string) RequestOption {
+	return WithHeader("PayPal-Request-Id", key)
+}
+
+// WithClientMetadataID sets the PayPal-Client-Metadata-Id header used by PayPal risk checks
+func WithClientMetadataID(id string) RequestOption {
+	return WithHeader("PayPal-Client-Metadata-Id", id)
+}
+
+// Mock application codes accepted by the PayPal-Mock-Response header for sandbox negative testing
+// Doc: https://developer.paypal.com/tools/sandbox/negative-testing/request-headers/
+const (
+	MockInstrumentDeclined             = "INSTRUMENT_DECLINED"
+	MockTransactionRefused             = "TRANSACTION_REFUSED"
+	MockDuplicateInvoiceID             = "DUPLICATE_INVOICE_ID"
+	MockInternalServerError            = "INTERNAL_SERVER_ERROR"
+	MockPermissionDenied               = "PERMISSION_DENIED"
+	MockOrderNotApproved               = "ORDER_NOT_APPROVED"
+	MockOrderAlreadyCaptured           = "ORDER_ALREADY_CAPTURED"
+	MockPayeeAccountRestricted         = "PAYEE_ACCOUNT_RESTRICTED"
+	MockPayerAccountRestricted         = "PAYER_ACCOUNT_RESTRICTED"
+	MockMaxPaymentAttemptsExceeded     = "MAX_NUMBER_OF_PAYMENT_ATTEMPTS_EXCEEDED"
+	MockAuthorizationExpired           = "AUTHORIZATION_EXPIRED"
+	MockCannotBeZeroOrNegative         = "CANNOT_BE_ZERO_OR_NEGATIVE"
+	MockRefundTimeLimitExceeded        = "REFUND_TIME_LIMIT_EXCEEDED"
+	MockCaptureFullyRefunded           = "CAPTURE_FULLY_REFUNDED"
+	MockTransactionRefusedByPayPalRisk = "TRANSACTION_REFUSED_BY_PAYPAL_RISK"
+)
+
+// WithMockResponse makes the sandbox answer the request with the error of the mock application code.
+// The header is never sent to APIBaseLive.
+func WithMockResponse(code string) RequestOption {
+	return func(c *PayPalClient, req *http.Request) {
+		if c.APIBase != APIBaseLive {
+			req.Header.Set("PayPal-Mock-Response", mockResponseHeader(code))
+		}
+	}
+}
+
+// WithDefaultMockResponse makes the sandbox answer every request made by the client with the error of the
+// mock application code, unless the request sets its own. The header is never sent to APIBaseLive.
+func WithDefaultMockResponse(code string) ClientOption {
+	return func(c *PayPalClient) {
+		c.mockResponse = code
+	}
+}
+
+// mockResponseHeader returns the PayPal-Mock-Response header value for code
+func mockResponseHeader(code string) string {
+	value, _ := json.Marshal(map[string]string{"mock_application_codes": code})
+	return string(value)
+}
+
+// WithAuthAssertion makes the request on behalf of the merchant identified by payerID or email.
+// Required for platforms acting for their connected merchants.
+// Doc: https://developer.paypal.com/docs/api/reference/api-requests/#paypal-auth-assertion
+func WithAuthAssertion(payerID, email string) RequestOption {
+	return func(c *PayPalClient, req *http.Request) {
+		req.Header.Set("PayPal-Auth-Assertion", buildAuthAssertion(c.ClientID, payerID, email))
+	}
+}
+
+// WithDefaultAuthAssertion makes every authenticated request of the client on behalf of the merchant identified by payerID or email.
+// A per-request WithAuthAssertion option takes precedence. Being a client option, it never applies to a client shared between callers.
+func WithDefaultAuthAssertion(payerID, email string) ClientOption {
+	return func(c *PayPalClient) {
+		c.authAssertion = buildAuthAssertion(c.ClientID, payerID, email)
+	}
+}
+
+// WithAutoIdempotencyKey makes every POST request of the client carry a generated PayPal-Request-Id header
+// unless one is already set, so replayed requests are processed only once by PayPal. Token requests never carry one.
+func WithAutoIdempotencyKey() ClientOption {
+	return func(c *PayPalClient) {
+		c.autoIdempotencyKey = true
+	}
+}
+
+// applyRequestOptions applies opts to req in order, then adds a generated idempotency key
+// to POST requests without one, token requests excepted, when WithAutoIdempotencyKey is set
+func (c *PayPalClient) applyRequestOptions(req *http.Request, opts []RequestOption) {
+	for _, opt := range opts {
+		opt(c, req)
+	}
+
+	if c.autoIdempotencyKey && req.Method == http.MethodPost && !isTokenRequest(req) && req.Header.Get("PayPal-Request-Id") == "" {
+		req.Header.Set("PayPal-Request-Id", NewIdempotencyKey())
+	}
+}
+
+// isTokenRequest reports whether req asks for an access token
+func isTokenRequest(req *http.Request) bool {
+	return strings.HasSuffix(req.URL.Path, "/v1/oauth2/token") || strings.HasSuffix(req.URL.Path, "/v1/identity/openidconnect/tokenservice")
+}
+
+// buildAuthAssertion builds the unsigned JWT expected by the PayPal-Auth-Assertion header.
+// payerID takes precedence over email when both are set.
+func buildAuthAssertion(clientID, payerID, email string) string {
+	claims := map[string]string{"iss": clientID}
+	if payerID != "" {
+		claims["payer_id"] = payerID
+	} else {
+		claims["email"] = email
+	}
+
+	header, _ := json.Marshal(map[string]string{"alg": "none"})
+	payload, _ := json.Marshal(claims)
+
+	return base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload) + "."
+}
@@ -0,0 +1,260 @@
+package payment
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// OrderBuilder assembles a CreateOrderRequest and computes the amount breakdown of
+// every purchase unit, so that the totals always add up
+//
+//	req, err := payment.NewOrderBuilder(payment.OrderIntentCapture, "USD").
+//		AddItem(payment.Item{Name: "T-shirt", Quantity: "2", UnitAmount: &payment.Money{Currency: "USD", Value: "15.00"}}).
+//		Shipping(payment.NewMoneyAmount("USD", 500)).
+//		Build()
+//	order, err := client.CreateOrderFromRequest(ctx, req)
+type OrderBuilder struct {
+	intent        string
+	currency      string
+	payer         *CreateOrderPayer
+	appContext    *ApplicationContext
+	paymentSource *PaymentSource
+	units         []*purchaseUnitDraft
+}
+
+// purchaseUnitDraft is a purchase unit of which the amounts are computed at Build
+type purchaseUnitDraft struct {
+	unit             PurchaseUnitRequest
+	shipping         MoneyAmount
+	handling         MoneyAmount
+	insurance        MoneyAmount
+	shippingDiscount MoneyAmount
+	discount         MoneyAmount
+}
+
+// NewOrderBuilder returns a builder for an order in currency, with one purchase unit.
+// Set its reference ID with ReferenceID before adding more purchase units
+func NewOrderBuilder(intent, currency string) *OrderBuilder {
+	b := &OrderBuilder{intent: intent, currency: currency}
+	return b.NewPurchaseUnit("")
+}
+
+// NewPurchaseUnit starts a new purchase unit, the following calls apply to it.
+// referenceID is required by PayPal on every purchase unit when an order has several of them
+func (b *OrderBuilder) NewPurchaseUnit(referenceID string) *OrderBuilder {
+	zero := NewMoneyAmount(b.currency, 0)
+	b.units = append(b.units, &purchaseUnitDraft{
+		unit:             PurchaseUnitRequest{ReferenceID: referenceID},
+		shipping:         zero,
+		handling:         zero,
+		insurance:        zero,
+		shippingDiscount: zero,
+		discount:         zero,
+	})
+	return b
+}
+
+func (b *OrderBuilder) current() *purchaseUnitDraft {
+	return b.units[len(b.units)-1]
+}
+
+// Payer sets the payer of the order
+func (b *OrderBuilder) Payer(payer *CreateOrderPayer) *OrderBuilder {
+	b.payer = payer
+	return b
+}
+
+// ApplicationContext sets the application context of the order
+func (b *OrderBuilder) ApplicationContext(appContext *ApplicationContext) *OrderBuilder {
+	b.appContext = appContext
+	return b
+}
+
+// PaymentSource sets the payment source of the order
+func (b *OrderBuilder) PaymentSource(paymentSource *PaymentSource) *OrderBuilder {
+	b.paymentSource = paymentSource
+	return b
+}
+
+// ReferenceID sets the reference ID of the current purchase unit
+func (b *OrderBuilder) ReferenceID(referenceID string) *OrderBuilder {
+	b.current().unit.ReferenceID = referenceID
+	return b
+}
+
+// Description sets the description of the current purchase unit
+func (b *OrderBuilder) Description(description string) *OrderBuilder {
+	b.current().unit.Description = description
+	return b
+}
+
+// CustomID sets the custom ID of the current purchase unit
+func (b *OrderBuilder) CustomID(customID string) *OrderBuilder {
+	b.current().unit.CustomID = customID
+	return b
+}
+
+// InvoiceID sets the invoice ID of the current purchase unit
+func (b *OrderBuilder) InvoiceID(invoiceID string) *OrderBuilder {
+	b.current().unit.InvoiceID = invoiceID
+	return b
+}
+
+// Payee sets the payee of the current purchase unit
+func (b *OrderBuilder) Payee(payee *PayeeForOrders) *OrderBuilder {
+	b.current().unit.Payee = payee
+	return b
+}
+
+// ShipTo sets the shipping detail of the current purchase unit
+func (b *OrderBuilder) ShipTo(shipping *ShippingDetail) *OrderBuilder {
+	b.current().unit.Shipping = shipping
+	return b
+}
+
+// AddItem adds an item to the current purchase unit. UnitAmount and Tax are per unit,
+// item_total and tax_total are computed from them and Quantity
+func (b *OrderBuilder) AddItem(item Item) *OrderBuilder {
+	b.current().unit.Items = append(b.current().unit.Items, item)
+	return b
+}
+
+// Shipping sets the shipping fee of the current purchase unit
+func (b *OrderBuilder) Shipping(amount MoneyAmount) *OrderBuilder {
+	b.current().shipping = amount
+	return b
+}
+
+// Handling sets the handling fee of the current purchase unit
+func (b *OrderBuilder) Handling(amount MoneyAmount) *OrderBuilder {
+	b.current().handling = amount
+	return b
+}
+
+// Insurance sets the insurance fee of the current purchase unit
+func (b *OrderBuilder) Insurance(amount MoneyAmount) *OrderBuilder {
+	b.current().insurance = amount
+	return b
+}
+
+// ShippingDiscount sets the shipping discount of the current purchase unit
+func (b *OrderBuilder) ShippingDiscount(amount MoneyAmount) *OrderBuilder {
+	b.current().shippingDiscount = amount
+	return b
+}
+
+// Discount sets the discount of the current purchase unit
+func (b *OrderBuilder) Discount(amount MoneyAmount) *OrderBuilder {
+	b.current().discount = amount
+	return b
+}
+
+// Build computes the amounts of every purchase unit and returns the request, or ValidationErrors
+// when an amount is invalid, the total is negative or a unit of a multi-unit order has no reference ID
+func (b *OrderBuilder) Build() (CreateOrderRequest, error) {
+	v := &validator{}
+	v.currency("currency_code", b.currency)
+
+	units := make([]PurchaseUnitRequest, len(b.units))
+	for i, draft := range b.units {
+		field := fmt.Sprintf("purchase_units[%d]", i)
+		if len(b.units) > 1 && draft.unit.ReferenceID == "" {
+			v.add(field+".reference_id", "is required when the order has several purchase units")
+		}
+		units[i] = draft.build(v, field, b.currency)
+	}
+
+	req := CreateOrderRequest{
+		Intent:             b.intent,
+		Payer:              b.payer,
+		PurchaseUnits:      units,
+		PaymentSource:      b.paymentSource,
+		ApplicationContext: b.appContext,
+	}
+	if err := v.err(); err != nil {
+		return req, err
+	}
+
+	return req, req.Validate()
+}
+
+// build computes the amount of the purchase unit, reporting the errors to v
+func (d *purchaseUnitDraft) build(v *validator, field, currency string) PurchaseUnitRequest {
+	unit := d.unit
+	itemTotal := NewMoneyAmount(currency, 0)
+	taxTotal := NewMoneyAmount(currency, 0)
+
+	add := func(total *MoneyAmount, name string, amount MoneyAmount) {
+		sum, err := total.Add(amount)
+		if err != nil {
+			v.add(name, err.Error())
+			return
+		}
+		*total = sum
+	}
+	parse := func(name string, money *Money) (MoneyAmount, bool) {
+		amount, err := money.MoneyAmount()
+		if err != nil {
+			v.add(name, err.Error())
+			return MoneyAmount{}, false
+		}
+		return amount, true
+	}
+
+	for i, item := range unit.Items {
+		itemField := fmt.Sprintf("%s.items[%d]", field, i)
+		quantity, err := strconv.ParseInt(item.Quantity, 10, 64)
+		if err != nil || quantity <= 0 {
+			v.add(itemField+".quantity", "%q is not a positive integer", item.Quantity)
+			continue
+		}
+		if item.UnitAmount == nil {
+			v.add(itemField+".unit_amount", "is required")
+			continue
+		}
+		if amount, ok := parse(itemField+".unit_amount", item.UnitAmount); ok {
+			add(&itemTotal, itemField+".unit_amount", amount.Mul(quantity))
+		}
+		if item.Tax != nil {
+			if tax, ok := parse(itemField+".tax", item.Tax); ok {
+				add(&taxTotal, itemField+".tax", tax.Mul(quantity))
+			}
+		}
+	}
+
+	total := itemTotal
+	add(&total, field+".tax_total", taxTotal)
+	add(&total, field+".shipping", d.shipping)
+	add(&total, field+".handling", d.handling)
+	add(&total, field+".insurance", d.insurance)
+	add(&total, field+".shipping_discount", d.shippingDiscount.Mul(-1))
+	add(&total, field+".discount", d.discount.Mul(-1))
+	if total.Minor < 0 {
+		v.add(field+".amount", "total %s is negative", total)
+	}
+	validatePaymentInstruction(v, field, unit.PaymentInstruction, total)
+
+	breakdown := &PurchaseUnitAmountBreakdown{}
+	if len(unit.Items) > 0 {
+		breakdown.ItemTotal = itemTotal.Money()
+	}
+	optional := func(amount MoneyAmount) *Money {
+		if amount.IsZero() {
+			return nil
+		}
+		return amount.Money()
+	}
+	breakdown.TaxTotal = optional(taxTotal)
+	breakdown.Shipping = optional(d.shipping)
+	breakdown.Handling = optional(d.handling)
+	breakdown.Insurance = optional(d.insurance)
+	breakdown.ShippingDiscount = optional(d.shippingDiscount)
+	breakdown.Discount = optional(d.discount)
+
+	unit.Amount = total.PurchaseUnitAmount()
+	if *breakdown != (PurchaseUnitAmountBreakdown{}) {
+		unit.Amount.Breakdown = breakdown
+	}
+
+	return unit
+}
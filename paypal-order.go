@@ -0,0 +1,134 @@
+package payment
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Order statuses
+// Doc: https://developer.paypal.com/docs/api/orders/v2/#orders_get
+const (
+	OrderStatusCreated             = "CREATED"
+	OrderStatusSaved               = "SAVED"
+	OrderStatusApproved            = "APPROVED"
+	OrderStatusVoided              = "VOIDED"
+	OrderStatusCompleted           = "COMPLETED"
+	OrderStatusPayerActionRequired = "PAYER_ACTION_REQUIRED"
+)
+
+// Order intents
+// Doc: https://developer.paypal.com/docs/api/orders/v2/#orders_create
+const (
+	OrderIntentCapture   = "CAPTURE"
+	OrderIntentAuthorize = "AUTHORIZE"
+)
+
+// Capture statuses, see CaptureStatusDeclined for refused captures
+// Doc: https://developer.paypal.com/docs/api/payments/v2/#captures_get
+const (
+	CaptureStatusCompleted         = "COMPLETED"
+	CaptureStatusPending           = "PENDING"
+	CaptureStatusPartiallyRefunded = "PARTIALLY_REFUNDED"
+	CaptureStatusRefunded          = "REFUNDED"
+	CaptureStatusFailed            = "FAILED"
+)
+
+// Authorization statuses
+// Doc: https://developer.paypal.com/docs/api/payments/v2/#authorizations_get
+const (
+	AuthorizationStatusCreated           = "CREATED"
+	AuthorizationStatusCaptured          = "CAPTURED"
+	AuthorizationStatusDenied            = "DENIED"
+	AuthorizationStatusPartiallyCaptured = "PARTIALLY_CAPTURED"
+	AuthorizationStatusVoided            = "VOIDED"
+	AuthorizationStatusPending           = "PENDING"
+)
+
+// maxOrderPollInterval is the default MaxInterval of WaitForApproval, a payer approves an order within minutes
+const maxOrderPollInterval = 30 * time.Second
+
+// ApproveURL returns the URL the payer approves the order at, empty when the order has none
+func (o *Order) ApproveURL() string {
+	for _, rel := range []string{"approve", "payer-action"} {
+		if link := findLink(o.Links, rel); link != nil {
+			return link.Href
+		}
+	}
+	return ""
+}
+
+// CaptureURL returns the URL capturing the order, empty when the order has none
+func (o *Order) CaptureURL() string {
+	if link := findLink(o.Links, "capture"); link != nil {
+		return link.Href
+	}
+	return ""
+}
+
+// SelfURL returns the URL of the order, empty when the order has none
+func (o *Order) SelfURL() string {
+	if link := findLink(o.Links, "self"); link != nil {
+		return link.Href
+	}
+	return ""
+}
+
+// WaitForApproval polls the order until the payer approved it. The first poll is immediate, then the wait
+// between polls starts at pollOptions.Interval, 5 seconds when 0, and doubles up to pollOptions.MaxInterval.
+// Unlike the 1 minute default of PollOptions, MaxInterval is 30 seconds when 0.
+// It stops when ctx is done, or with an error when the order is voided or completed without approval being observed.
+// Endpoint: GET /v2/checkout/orders/ID
+func (c *PayPalClient) WaitForApproval(ctx context.Context, orderID string, pollOptions PollOptions, opts ...RequestOption) (*Order, error) {
+	if pollOptions.MaxInterval <= 0 {
+		pollOptions.MaxInterval = maxOrderPollInterval
+	}
+
+	for {
+		order, err := c.GetOrder(ctx, orderID, opts...)
+		if err != nil {
+			return order, err
+		}
+
+		switch order.Status {
+		case OrderStatusApproved:
+			return order, nil
+		case OrderStatusVoided, OrderStatusCompleted:
+			return order, fmt.Errorf("paypal: order %s is %s", orderID, order.Status)
+		}
+
+		if err = pollOptions.wait(ctx); err != nil {
+			return order, err
+		}
+	}
+}
+
+// VoidAuthorizedOrder voids every authorization of the order that can still be captured
+// and returns the voided authorizations. opts are applied to every request, so they must not hold an idempotency key.
+// Endpoint: POST /v2/payments/authorizations/ID/void
+func (c *PayPalClient) VoidAuthorizedOrder(ctx context.Context, orderID string, opts ...RequestOption) ([]Authorization, error) {
+	order, err := c.GetOrder(ctx, orderID, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	var voided []Authorization
+	for _, unit := range order.PurchaseUnits {
+		if unit.Payments == nil {
+			continue
+		}
+		for _, authorization := range unit.Payments.Authorizations {
+			if authorization.Status != AuthorizationStatusCreated && authorization.Status != AuthorizationStatusPending {
+				continue
+			}
+
+			result, err := c.VoidAuthorization(ctx, authorization.ID, opts...)
+			if err != nil {
+				return voided, err
+			}
+			voided = append(voided, *result)
+		}
+	}
+
+	return voided, nil
+}
@@ -0,0 +1,114 @@
+package payment
+
+// Patch operations
+const (
+	PatchOperationAdd     = "add"
+	PatchOperationReplace = "replace"
+	PatchOperationRemove  = "remove"
+)
+
+// Order patch paths, see PurchaseUnitPatchPath for the paths of a purchase unit
+// Doc: https://developer.paypal.com/docs/api/orders/v2/#orders_patch
+const (
+	OrderPatchPathIntent = "/intent"
+)
+
+// Purchase unit fields to pass to PurchaseUnitPatchPath
+const (
+	PurchaseUnitFieldAmount             = "amount"
+	PurchaseUnitFieldCustomID           = "custom_id"
+	PurchaseUnitFieldDescription        = "description"
+	PurchaseUnitFieldInvoiceID          = "invoice_id"
+	PurchaseUnitFieldPayee              = "payee/email"
+	PurchaseUnitFieldPaymentInstruction = "payment_instruction"
+	PurchaseUnitFieldShippingName       = "shipping/name"
+	PurchaseUnitFieldShippingAddress    = "shipping/address"
+	PurchaseUnitFieldSoftDescriptor     = "soft_descriptor"
+)
+
+// Catalog product patch paths
+// Doc: https://developer.paypal.com/docs/api/catalog-products/v1/#products_patch
+const (
+	ProductPatchPathDescription = "/description"
+	ProductPatchPathCategory    = "/category"
+	ProductPatchPathImageURL    = "/image_url"
+	ProductPatchPathHomeURL     = "/home_url"
+)
+
+// Subscription plan patch paths
+// Doc: https://developer.paypal.com/docs/api/subscriptions/v1/#plans_patch
+const (
+	PlanPatchPathDescription             = "/description"
+	PlanPatchPathTaxesPercentage         = "/taxes/percentage"
+	PlanPatchPathSetupFee                = "/payment_preferences/setup_fee"
+	PlanPatchPathAutoBillOutstanding     = "/payment_preferences/auto_bill_outstanding"
+	PlanPatchPathPaymentFailureThreshold = "/payment_preferences/payment_failure_threshold"
+	PlanPatchPathSetupFeeFailureAction   = "/payment_preferences/setup_fee_failure_action"
+)
+
+// Subscription patch paths
+// Doc: https://developer.paypal.com/docs/api/subscriptions/v1/#subscriptions_patch
+const (
+	SubscriptionPatchPathOutstandingBalance      = "/billing_info/outstanding_balance"
+	SubscriptionPatchPathCustomID                = "/custom_id"
+	SubscriptionPatchPathAutoBillOutstanding     = "/plan/payment_preferences/auto_bill_outstanding"
+	SubscriptionPatchPathPaymentFailureThreshold = "/plan/payment_preferences/payment_failure_threshold"
+	SubscriptionPatchPathTaxesPercentage         = "/plan/taxes/percentage"
+	SubscriptionPatchPathShippingAmount          = "/shipping_amount"
+	SubscriptionPatchPathShippingAddress         = "/subscriber/shipping_address"
+	SubscriptionPatchPathStartTime               = "/start_time"
+)
+
+// Webhook patch paths
+// Doc: https://developer.paypal.com/docs/api/webhooks/v1/#webhooks_update
+const (
+	WebhookPatchPathURL        = "/url"
+	WebhookPatchPathEventTypes = "/event_types"
+)
+
+// PatchBuilder builds a JSON Patch document
+//
+//	patches := NewPatchBuilder().
+//		Replace(PurchaseUnitPatchPath("", PurchaseUnitFieldAmount), amount).
+//		Add(PurchaseUnitPatchPath("", PurchaseUnitFieldInvoiceID), "INV-001").
+//		Build()
+type PatchBuilder struct {
+	patches []Patch
+}
+
+// NewPatchBuilder returns an empty PatchBuilder
+func NewPatchBuilder() *PatchBuilder {
+	return &PatchBuilder{}
+}
+
+// Add appends an add operation
+func (b *PatchBuilder) Add(path string, value interface{}) *PatchBuilder {
+	b.patches = append(b.patches, Patch{Operation: PatchOperationAdd, Path: path, Value: value})
+	return b
+}
+
+// Replace appends a replace operation
+func (b *PatchBuilder) Replace(path string, value interface{}) *PatchBuilder {
+	b.patches = append(b.patches, Patch{Operation: PatchOperationReplace, Path: path, Value: value})
+	return b
+}
+
+// Remove appends a remove operation
+func (b *PatchBuilder) Remove(path string) *PatchBuilder {
+	b.patches = append(b.patches, Patch{Operation: PatchOperationRemove, Path: path})
+	return b
+}
+
+// Build returns the patches in the order they were added
+func (b *PatchBuilder) Build() []Patch {
+	return append([]Patch(nil), b.patches...)
+}
+
+// BuildWebhookFields returns the patches as expected by UpdateWebhook
+func (b *PatchBuilder) BuildWebhookFields() []WebhookField {
+	fields := make([]WebhookField, len(b.patches))
+	for i, patch := range b.patches {
+		fields[i] = WebhookField{Operation: patch.Operation, Path: patch.Path, Value: patch.Value}
+	}
+	return fields
+}
@@ -0,0 +1,171 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// PayoutMaxItems is the largest number of items PayPal accepts in a single payout batch
+const PayoutMaxItems = 15000
+
+// Payout batch statuses
+// Doc: https://developer.paypal.com/docs/api/payments.payouts-batch/v1/#definition-batch_enum
+const (
+	PayoutBatchStatusPending    = "PENDING"
+	PayoutBatchStatusProcessing = "PROCESSING"
+	PayoutBatchStatusSuccess    = "SUCCESS"
+	PayoutBatchStatusDenied     = "DENIED"
+	PayoutBatchStatusCanceled   = "CANCELED"
+)
+
+// PollOptions configures how a status is polled
+type PollOptions struct {
+	Interval    time.Duration // Wait after the first poll, 5 seconds when 0
+	MaxInterval time.Duration // The wait doubles after every poll up to MaxInterval, when 0 1 minute, or 30 seconds for WaitForApproval
+}
+
+// wait blocks for the current interval, then doubles it. It returns early with the error of ctx when it is done.
+func (p *PollOptions) wait(ctx context.Context) error {
+	if p.Interval <= 0 {
+		p.Interval = 5 * time.Second
+	}
+	if p.MaxInterval <= 0 {
+		p.MaxInterval = time.Minute
+	}
+
+	timer := time.NewTimer(p.Interval)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+	}
+
+	if p.Interval *= 2; p.Interval > p.MaxInterval {
+		p.Interval = p.MaxInterval
+	}
+	return nil
+}
+
+// PayoutBatchOptions configures CreatePayoutsBatched
+type PayoutBatchOptions struct {
+	BatchSize   int // Items per batch, PayoutMaxItems when 0 or larger
+	Concurrency int // Batches submitted at the same time, 1 when 0 or less
+}
+
+// PayoutBatchResult is the outcome of one batch submitted by CreatePayoutsBatched
+type PayoutBatchResult struct {
+	SenderBatchID string
+	Items         []PayoutItem
+	Response      *PayoutResponse
+	Err           error
+}
+
+// CreatePayoutsBatched splits items into batches PayPal accepts and submits them with bounded parallelism.
+// Batch n gets the sender_batch_id "<header.SenderBatchID>-<n>", so calling it again with the same header and
+// items never pays twice: PayPal rejects sender batch ids used in the last 30 days.
+// Results are returned in batch order. The error reports how many batches failed, see each result for details.
+// opts are applied to every batch, so they must not hold an idempotency key.
+// Endpoint: POST /v1/payments/payouts
+func (c *PayPalClient) CreatePayoutsBatched(ctx context.Context, header SenderBatchHeader, items []PayoutItem, options PayoutBatchOptions, opts ...RequestOption) ([]PayoutBatchResult, error) {
+	if header.SenderBatchID == "" {
+		return nil, errors.New("paypal: a sender batch id is required to submit payouts in batches")
+	}
+
+	batchSize := options.BatchSize
+	if batchSize <= 0 || batchSize > PayoutMaxItems {
+		batchSize = PayoutMaxItems
+	}
+	concurrency := options.Concurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	var results []PayoutBatchResult
+	for start := 0; start < len(items); start += batchSize {
+		end := start + batchSize
+		if end > len(items) {
+			end = len(items)
+		}
+		results = append(results, PayoutBatchResult{
+			SenderBatchID: fmt.Sprintf("%s-%d", header.SenderBatchID, len(results)+1),
+			Items:         items[start:end],
+		})
+	}
+
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, concurrency)
+	for i := range results {
+		select {
+		case <-ctx.Done():
+			results[i].Err = ctx.Err()
+			continue
+		case semaphore <- struct{}{}:
+		}
+
+		wg.Add(1)
+		go func(result *PayoutBatchResult) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			batchHeader := header
+			batchHeader.SenderBatchID = result.SenderBatchID
+			result.Response, result.Err = c.CreatePayout(ctx, Payout{SenderBatchHeader: &batchHeader, Items: result.Items}, opts...)
+		}(&results[i])
+	}
+	wg.Wait()
+
+	var failed int
+	var firstErr error
+	for _, result := range results {
+		if result.Err != nil {
+			if firstErr == nil {
+				firstErr = result.Err
+			}
+			failed++
+		}
+	}
+	if failed > 0 {
+		return results, fmt.Errorf("paypal: %d of %d payout batches failed: %w", failed, len(results), firstErr)
+	}
+
+	return results, nil
+}
+
+// WaitForPayoutCompletion polls a payout batch until it is SUCCESS, DENIED or CANCELED,
+// then returns the batch with every item and its outcome. It stops when ctx is done.
+// Endpoint: GET /v1/payments/payouts/ID
+func (c *PayPalClient) WaitForPayoutCompletion(ctx context.Context, payoutBatchID string, pollOptions PollOptions, opts ...RequestOption) (*PayoutResponse, error) {
+	for {
+		payout, err := c.GetPayout(ctx, payoutBatchID, opts...)
+		if err != nil {
+			return payout, err
+		}
+
+		if payout.BatchHeader != nil {
+			switch payout.BatchHeader.BatchStatus {
+			case PayoutBatchStatusSuccess, PayoutBatchStatusDenied, PayoutBatchStatusCanceled:
+				return c.getPayoutWithAllItems(ctx, payoutBatchID, opts)
+			}
+		}
+
+		if err = pollOptions.wait(ctx); err != nil {
+			return payout, err
+		}
+	}
+}
+
+// getPayoutWithAllItems returns the payout batch with the items of every page
+func (c *PayPalClient) getPayoutWithAllItems(ctx context.Context, payoutBatchID string, opts []RequestOption) (*PayoutResponse, error) {
+	payout := &PayoutResponse{}
+	err := c.forEachPayoutPage(ctx, payoutBatchID, payoutReportPageSize, func(response *PayoutResponse) error {
+		payout.BatchHeader = response.BatchHeader
+		payout.Items = append(payout.Items, response.Items...)
+		return nil
+	}, opts)
+
+	return payout, err
+}
@@ -0,0 +1,131 @@
+package payment
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RateLimit holds the rate limit state reported by PayPal in a response
+type RateLimit struct {
+	Limit      int           // X-RateLimit-Limit, -1 when not sent
+	Remaining  int           // X-RateLimit-Remaining, -1 when not sent
+	Reset      time.Time     // X-RateLimit-Reset, zero when not sent
+	RetryAfter time.Duration // Retry-After, sent with 429 Too Many Requests
+	Limited    bool          // The request was rejected with 429 Too Many Requests
+}
+
+// ParseRateLimit reads the rate limit headers of resp.
+// It returns false when resp carries no rate limit information.
+func ParseRateLimit(resp *http.Response) (RateLimit, bool) {
+	rateLimit := RateLimit{
+		Limit:     headerInt(resp.Header, "X-RateLimit-Limit"),
+		Remaining: headerInt(resp.Header, "X-RateLimit-Remaining"),
+		Limited:   resp.StatusCode == http.StatusTooManyRequests,
+	}
+
+	if reset := headerInt(resp.Header, "X-RateLimit-Reset"); reset >= 0 {
+		rateLimit.Reset = time.Unix(int64(reset), 0)
+	}
+	rateLimit.RetryAfter, _ = parseRetryAfter(resp.Header.Get("Retry-After"))
+
+	ok := rateLimit.Limited || rateLimit.Limit >= 0 || rateLimit.Remaining >= 0 || !rateLimit.Reset.IsZero()
+	return rateLimit, ok
+}
+
+func headerInt(h http.Header, key string) int {
+	v, err := strconv.Atoi(h.Get(key))
+	if err != nil {
+		return -1
+	}
+	return v
+}
+
+// WithRateLimitCallback calls fn with the rate limit state of every response carrying one,
+// retried attempts included, so applications can export the remaining quota as a metric
+func WithRateLimitCallback(fn func(req *http.Request, rateLimit RateLimit)) ClientOption {
+	return func(c *PayPalClient) {
+		c.rateLimitCallback = fn
+	}
+}
+
+// WithRateLimiter throttles the client to requestsPerSecond with bursts of up to burst requests.
+// Every attempt counts, retries and token requests included. Waiting stops when the request context is done.
+// A requestsPerSecond of 0 or less disables throttling.
+func WithRateLimiter(requestsPerSecond float64, burst int) ClientOption {
+	return func(c *PayPalClient) {
+		if requestsPerSecond <= 0 {
+			c.rateLimiter = nil
+			return
+		}
+		if burst < 1 {
+			burst = 1
+		}
+		c.rateLimiter = &tokenBucket{
+			rate:   requestsPerSecond,
+			burst:  float64(burst),
+			tokens: float64(burst),
+			last:   time.Now(),
+		}
+	}
+}
+
+// rateLimit wraps next with the rate limiter and the rate limit callback of the client
+func (c *PayPalClient) rateLimit(next RoundTripFunc) RoundTripFunc {
+	return func(req *http.Request) (*http.Response, error) {
+		if c.rateLimiter != nil {
+			if err := c.rateLimiter.wait(req.Context()); err != nil {
+				return nil, err
+			}
+		}
+
+		resp, err := next(req)
+		if err == nil && c.rateLimitCallback != nil {
+			if rateLimit, ok := ParseRateLimit(resp); ok {
+				c.rateLimitCallback(req, rateLimit)
+			}
+		}
+
+		return resp, err
+	}
+}
+
+// tokenBucket is a token bucket limiter refilled at rate tokens per second
+type tokenBucket struct {
+	sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// wait blocks until a token is available or ctx is done
+func (b *tokenBucket) wait(ctx context.Context) error {
+	for {
+		b.Lock()
+		now := time.Now()
+		b.tokens += now.Sub(b.last).Seconds() * b.rate
+		if b.tokens > b.burst {
+			b.tokens = b.burst
+		}
+		b.last = now
+
+		if b.tokens >= 1 {
+			b.tokens--
+			b.Unlock()
+			return nil
+		}
+		wait := time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+		b.Unlock()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
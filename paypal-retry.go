@@ -0,0 +1,146 @@
+package payment
+
+import (
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy retries requests failing with a network error, 429 Too Many Requests or a 5xx status.
+// GET, HEAD, PUT and DELETE requests are always retried. POST and PATCH requests are retried only
+// when they carry a PayPal-Request-Id header, see WithIdempotencyKey and WithAutoIdempotencyKey.
+type RetryPolicy struct {
+	MaxAttempts int           // Attempts including the first one, 1 or less disables retries
+	MinBackoff  time.Duration // Wait before the first retry, doubled after each attempt
+	MaxBackoff  time.Duration // Upper bound of the wait, Retry-After included, 30 seconds when 0
+	Jitter      float64       // Fraction of the wait picked at random, between 0 and 1
+}
+
+// defaultMaxBackoff bounds the wait of a RetryPolicy without MaxBackoff
+const defaultMaxBackoff = 30 * time.Second
+
+// DefaultRetryPolicy is a sensible policy for most applications
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	MinBackoff:  500 * time.Millisecond,
+	MaxBackoff:  10 * time.Second,
+	Jitter:      0.2,
+}
+
+// WithRetryPolicy retries failed requests made by the client according to policy.
+// A Retry-After header sent by PayPal takes precedence over the computed backoff, up to MaxBackoff.
+func WithRetryPolicy(policy RetryPolicy) ClientOption {
+	return func(c *PayPalClient) {
+		c.retryPolicy = &policy
+	}
+}
+
+// retry wraps next so failed requests are sent again according to the policy
+func (p *RetryPolicy) retry(next RoundTripFunc) RoundTripFunc {
+	return func(req *http.Request) (*http.Response, error) {
+		resp, err := next(req)
+		for attempt := 1; attempt < p.MaxAttempts && p.shouldRetry(req, resp, err); attempt++ {
+			wait := p.backoff(attempt, resp)
+			if resp != nil {
+				io.Copy(ioutil.Discard, resp.Body)
+				resp.Body.Close()
+			}
+
+			timer := time.NewTimer(wait)
+			select {
+			case <-req.Context().Done():
+				timer.Stop()
+				return nil, req.Context().Err()
+			case <-timer.C:
+			}
+
+			if req.GetBody != nil {
+				body, bodyErr := req.GetBody()
+				if bodyErr != nil {
+					return nil, bodyErr
+				}
+				req.Body = body
+			}
+
+			resp, err = next(req)
+		}
+
+		return resp, err
+	}
+}
+
+// shouldRetry tells whether the outcome of req is worth another attempt
+func (p *RetryPolicy) shouldRetry(req *http.Request, resp *http.Response, err error) bool {
+	if req.Context().Err() != nil {
+		return false
+	}
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete:
+	default:
+		if req.Header.Get("PayPal-Request-Id") == "" {
+			return false
+		}
+	}
+
+	if err != nil {
+		return true
+	}
+
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+}
+
+// backoff returns the wait before the given retry attempt, bounded by MaxBackoff
+func (p *RetryPolicy) backoff(attempt int, resp *http.Response) time.Duration {
+	maxBackoff := p.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = defaultMaxBackoff
+	}
+
+	if resp != nil {
+		if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+			if retryAfter > maxBackoff {
+				return maxBackoff
+			}
+			return retryAfter
+		}
+	}
+
+	// Double the wait step by step, so it never overflows
+	wait := p.MinBackoff
+	for i := 1; i < attempt && wait < maxBackoff; i++ {
+		wait *= 2
+	}
+	if wait > maxBackoff || wait < 0 {
+		wait = maxBackoff
+	}
+	if p.Jitter > 0 {
+		wait -= time.Duration(rand.Float64() * p.Jitter * float64(wait))
+	}
+
+	return wait
+}
+
+// parseRetryAfter reads a Retry-After header given in seconds or as an HTTP date
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		if wait := time.Until(date); wait > 0 {
+			return wait, true
+		}
+		return 0, true
+	}
+
+	return 0, false
+}
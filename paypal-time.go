@@ -0,0 +1,74 @@
+package payment
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// jsonTimeLayouts are the timestamp formats PayPal returns, tried in order
+var jsonTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05Z0700",
+	"2006-01-02T15:04:05.999999999Z0700",
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+}
+
+// NewJSONTime converts a time.Time to a JSONTime
+func NewJSONTime(t time.Time) JSONTime {
+	return JSONTime(t)
+}
+
+// Time returns the JSONTime as a time.Time
+func (t JSONTime) Time() time.Time {
+	return time.Time(t)
+}
+
+// IsZero reports whether t is the zero time
+func (t JSONTime) IsZero() bool {
+	return time.Time(t).IsZero()
+}
+
+// String returns t in RFC3339 format, UTC
+func (t JSONTime) String() string {
+	return time.Time(t).UTC().Format(time.RFC3339)
+}
+
+// MarshalJSON formats t as RFC3339 in UTC without fractional seconds, which
+// every PayPal endpoint accepts. The zero time is encoded as null
+func (t JSONTime) MarshalJSON() ([]byte, error) {
+	if t.IsZero() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(t.String())
+}
+
+// UnmarshalJSON parses RFC3339 timestamps with or without fractional
+// seconds, as well as the "+0000" offset form used by the reporting APIs.
+// null and "" decode to the zero time
+func (t *JSONTime) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		*t = JSONTime{}
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	if s == "" {
+		*t = JSONTime{}
+		return nil
+	}
+
+	for _, layout := range jsonTimeLayouts {
+		if parsed, err := time.Parse(layout, s); err == nil {
+			*t = JSONTime(parsed)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("payment: cannot parse %q as time", s)
+}
@@ -0,0 +1,261 @@
+package payment
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/golang-common-packages/hash"
+)
+
+// TokenStore shares PayPal access tokens between clients, e.g. between the replicas of a service.
+// The client consults the store before requesting a new access token and saves every token it requests.
+type TokenStore interface {
+	// Get returns the token stored under key and its remaining time to live, a nil token when there is none
+	Get(ctx context.Context, key string) (*TokenResponse, time.Duration, error)
+	// Set stores the token under key for ttl
+	Set(ctx context.Context, key string, token *TokenResponse, ttl time.Duration) error
+}
+
+// storedToken is the serialized form of a token in the file and Redis stores
+type storedToken struct {
+	Token     *TokenResponse `json:"token"`
+	ExpiresAt time.Time      `json:"expires_at"`
+}
+
+// ttl returns the remaining time to live of the stored token, 0 when it expired
+func (s *storedToken) ttl() time.Duration {
+	if s.Token == nil {
+		return 0
+	}
+
+	if ttl := time.Until(s.ExpiresAt); ttl > 0 {
+		return ttl
+	}
+
+	return 0
+}
+
+// WithTokenStore makes the client share its access tokens through store.
+// Being a client option, it never applies to a client shared between callers.
+func WithTokenStore(store TokenStore) ClientOption {
+	return func(c *PayPalClient) {
+		c.tokenStore = store
+	}
+}
+
+// tokenStoreKey returns the key of the client tokens in the token store, secrets are kept out of it
+func (c *PayPalClient) tokenStoreKey() string {
+	hasher := &hash.Client{}
+	return "paypal:token:" + hasher.SHA1(c.APIBase+"|"+c.ClientID)
+}
+
+// loadStoredToken sets the token found in the store as the client token, when it differs from stale and is not about to expire.
+// It reports whether a token was loaded, store errors are treated as a miss.
+func (c *PayPalClient) loadStoredToken(ctx context.Context, store TokenStore, stale *TokenResponse) bool {
+	token, ttl, err := store.Get(ctx, c.tokenStoreKey())
+	if err != nil || token == nil || ttl < RequestNewTokenBeforeExpiresIn {
+		return false
+	}
+	if stale != nil && token.Token == stale.Token {
+		return false
+	}
+
+	c.Lock()
+	c.Token = token
+	c.tokenExpiresAt = time.Now().Add(ttl)
+	c.Unlock()
+
+	return true
+}
+
+// saveToken stores the current client token. A failure is not fatal, the token is still used by this client.
+func (c *PayPalClient) saveToken(ctx context.Context, store TokenStore) {
+	c.Lock()
+	token, tokenExpiresAt := c.Token, c.tokenExpiresAt
+	c.Unlock()
+
+	if token != nil {
+		store.Set(ctx, c.tokenStoreKey(), token, time.Until(tokenExpiresAt))
+	}
+}
+
+// MemoryTokenStore keeps tokens in memory, it shares tokens between clients of the same process
+type MemoryTokenStore struct {
+	sync.Mutex
+	tokens map[string]storedToken
+}
+
+// NewMemoryTokenStore returns an empty in-memory token store
+func NewMemoryTokenStore() *MemoryTokenStore {
+	return &MemoryTokenStore{tokens: make(map[string]storedToken)}
+}
+
+// Get implements TokenStore
+func (s *MemoryTokenStore) Get(ctx context.Context, key string) (*TokenResponse, time.Duration, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	stored := s.tokens[key]
+	ttl := stored.ttl()
+	if ttl == 0 {
+		delete(s.tokens, key)
+		return nil, 0, nil
+	}
+
+	return stored.Token, ttl, nil
+}
+
+// Set implements TokenStore
+func (s *MemoryTokenStore) Set(ctx context.Context, key string, token *TokenResponse, ttl time.Duration) error {
+	s.Lock()
+	defer s.Unlock()
+
+	s.tokens[key] = storedToken{Token: token, ExpiresAt: time.Now().Add(ttl)}
+	return nil
+}
+
+// FileTokenStore keeps tokens in a JSON file readable only by its owner, it shares tokens between processes of the same host
+type FileTokenStore struct {
+	sync.Mutex
+	Path string
+}
+
+// NewFileTokenStore returns a token store saving tokens into the file at path
+func NewFileTokenStore(path string) *FileTokenStore {
+	return &FileTokenStore{Path: path}
+}
+
+// Get implements TokenStore
+func (s *FileTokenStore) Get(ctx context.Context, key string) (*TokenResponse, time.Duration, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	tokens, err := s.read()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	stored := tokens[key]
+	ttl := stored.ttl()
+	if ttl == 0 {
+		return nil, 0, nil
+	}
+
+	return stored.Token, ttl, nil
+}
+
+// Set implements TokenStore
+func (s *FileTokenStore) Set(ctx context.Context, key string, token *TokenResponse, ttl time.Duration) error {
+	s.Lock()
+	defer s.Unlock()
+
+	tokens, err := s.read()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for k, stored := range tokens {
+		if !stored.ExpiresAt.After(now) {
+			delete(tokens, k)
+		}
+	}
+	tokens[key] = storedToken{Token: token, ExpiresAt: now.Add(ttl)}
+
+	data, err := json.Marshal(tokens)
+	if err != nil {
+		return err
+	}
+
+	// Write a temporary file then rename it, so other processes never read a partial file
+	tmp, err := ioutil.TempFile(filepath.Dir(s.Path), filepath.Base(s.Path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), s.Path)
+}
+
+// read loads every token of the file, a missing file holds no token
+func (s *FileTokenStore) read() (map[string]storedToken, error) {
+	tokens := make(map[string]storedToken)
+
+	data, err := ioutil.ReadFile(s.Path)
+	if os.IsNotExist(err) {
+		return tokens, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) > 0 {
+		if err = json.Unmarshal(data, &tokens); err != nil {
+			return nil, err
+		}
+	}
+
+	return tokens, nil
+}
+
+// RedisClient is the subset of a Redis client used by RedisTokenStore.
+// Adapt the Redis client of your choice to it.
+type RedisClient interface {
+	// Get returns the value of key, an empty string when the key does not exist
+	Get(ctx context.Context, key string) (string, error)
+	// Set sets the value of key, expiring after ttl
+	Set(ctx context.Context, key, value string, ttl time.Duration) error
+}
+
+// RedisTokenStore keeps tokens in Redis, it shares tokens between every replica of a service
+type RedisTokenStore struct {
+	Client RedisClient
+}
+
+// NewRedisTokenStore returns a token store saving tokens with client
+func NewRedisTokenStore(client RedisClient) *RedisTokenStore {
+	return &RedisTokenStore{Client: client}
+}
+
+// Get implements TokenStore
+func (s *RedisTokenStore) Get(ctx context.Context, key string) (*TokenResponse, time.Duration, error) {
+	value, err := s.Client.Get(ctx, key)
+	if err != nil || value == "" {
+		return nil, 0, err
+	}
+
+	stored := storedToken{}
+	if err = json.Unmarshal([]byte(value), &stored); err != nil {
+		return nil, 0, err
+	}
+
+	ttl := stored.ttl()
+	if ttl == 0 {
+		return nil, 0, nil
+	}
+
+	return stored.Token, ttl, nil
+}
+
+// Set implements TokenStore
+func (s *RedisTokenStore) Set(ctx context.Context, key string, token *TokenResponse, ttl time.Duration) error {
+	value, err := json.Marshal(storedToken{Token: token, ExpiresAt: time.Now().Add(ttl)})
+	if err != nil {
+		return err
+	}
+
+	return s.Client.Set(ctx, key, string(value), ttl)
+}
@@ -0,0 +1,192 @@
+package payment
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Payout recipient types
+// Doc: https://developer.paypal.com/docs/api/payments.payouts-batch/v1/#payouts_post
+const (
+	RecipientTypeEmail    = "EMAIL"
+	RecipientTypePhone    = "PHONE"
+	RecipientTypePayPalID = "PAYPAL_ID"
+)
+
+var (
+	currencyCodePattern = regexp.MustCompile(`^[A-Z]{3}$`)
+	amountPattern       = regexp.MustCompile(`^\d+(?:\.(\d+))?$`)
+)
+
+// Validator is implemented by the request payloads that can be checked locally
+type Validator interface {
+	Validate() error
+}
+
+// ValidationError describes one invalid field of a request payload
+type ValidationError struct {
+	Field   string
+	Message string
+}
+
+func (e ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
+
+// ValidationErrors is returned by Validate with every invalid field of the payload.
+// errors.Is(err, ErrValidation) reports true, as for a 400/422 returned by PayPal
+type ValidationErrors []ValidationError
+
+func (e ValidationErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return "paypal: invalid request: " + strings.Join(msgs, "; ")
+}
+
+// Is reports whether target is ErrValidation
+func (e ValidationErrors) Is(target error) bool {
+	return target == ErrValidation
+}
+
+// WithValidation validates the payloads implementing Validator before sending them,
+// so that obvious mistakes fail locally instead of costing an API round trip
+func WithValidation() ClientOption {
+	return func(c *PayPalClient) {
+		c.validateRequests = true
+	}
+}
+
+// validate runs payload.Validate when validation is enabled on the client
+func (c *PayPalClient) validate(payload interface{}) error {
+	if !c.validateRequests {
+		return nil
+	}
+	if v, ok := payload.(Validator); ok {
+		return v.Validate()
+	}
+	return nil
+}
+
+// validator collects the errors of a payload
+type validator struct {
+	errs ValidationErrors
+}
+
+func (v *validator) add(field, format string, args ...interface{}) {
+	v.errs = append(v.errs, ValidationError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) required(field, value string) {
+	if value == "" {
+		v.add(field, "is required")
+	}
+}
+
+func (v *validator) currency(field, value string) {
+	if !currencyCodePattern.MatchString(value) {
+		v.add(field, "%q is not an ISO 4217 currency code", value)
+	}
+}
+
+func (v *validator) amount(field, currency, value string) {
+	match := amountPattern.FindStringSubmatch(value)
+	if match == nil || len(match[1]) > CurrencyExponent(currency) {
+		v.add(field, "%q is not a valid %s amount", value, strings.ToUpper(currency))
+	}
+}
+
+func (v *validator) err() error {
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return v.errs
+}
+
+// Validate checks the currency code and the amount format
+func (m Money) Validate() error {
+	v := &validator{}
+	v.currency("currency_code", m.Currency)
+	v.amount("value", m.Currency, m.Value)
+	return v.err()
+}
+
+// Validate checks the intent and the amount of every purchase unit
+func (r CreateOrderRequest) Validate() error {
+	v := &validator{}
+	if r.Intent != OrderIntentCapture && r.Intent != OrderIntentAuthorize {
+		v.add("intent", "must be %s or %s", OrderIntentCapture, OrderIntentAuthorize)
+	}
+	if len(r.PurchaseUnits) == 0 {
+		v.add("purchase_units", "at least one purchase unit is required")
+	}
+	for i, unit := range r.PurchaseUnits {
+		field := fmt.Sprintf("purchase_units[%d].amount", i)
+		if unit.Amount == nil {
+			v.add(field, "is required")
+			continue
+		}
+		v.currency(field+".currency_code", unit.Amount.Currency)
+		v.amount(field+".value", unit.Amount.Currency, unit.Amount.Value)
+	}
+	return v.err()
+}
+
+// Validate checks the refunded amount, when set
+func (r RefundCaptureRequest) Validate() error {
+	v := &validator{}
+	if r.Amount != nil {
+		v.currency("amount.currency_code", r.Amount.Currency)
+		v.amount("amount.value", r.Amount.Currency, r.Amount.Value)
+	}
+	return v.err()
+}
+
+// Validate checks the number of items, their recipient and their amount
+func (p Payout) Validate() error {
+	v := &validator{}
+	if len(p.Items) == 0 {
+		v.add("items", "at least one item is required")
+	}
+	if len(p.Items) > PayoutMaxItems {
+		v.add("items", "at most %d items are allowed, use CreatePayoutsBatched", PayoutMaxItems)
+	}
+	for i, item := range p.Items {
+		field := fmt.Sprintf("items[%d]", i)
+		switch item.RecipientType {
+		case "", RecipientTypeEmail, RecipientTypePhone, RecipientTypePayPalID:
+		default:
+			v.add(field+".recipient_type", "must be %s, %s or %s", RecipientTypeEmail, RecipientTypePhone, RecipientTypePayPalID)
+		}
+		v.required(field+".receiver", item.Receiver)
+		if item.Amount == nil {
+			v.add(field+".amount", "is required")
+			continue
+		}
+		v.currency(field+".amount.currency", item.Amount.Currency)
+		v.amount(field+".amount.value", item.Amount.Currency, item.Amount.Value)
+	}
+	return v.err()
+}
+
+// Validate checks that the webhook URL uses HTTPS and that event types are set
+func (r *CreateWebhookRequest) Validate() error {
+	v := &validator{}
+	if r == nil {
+		v.add("webhook", "is required")
+		return v.err()
+	}
+	if u, err := url.Parse(r.URL); err != nil || u.Scheme != "https" || u.Host == "" {
+		v.add("url", "%q must be an absolute HTTPS URL", r.URL)
+	}
+	if len(r.EventTypes) == 0 {
+		v.add("event_types", "at least one event type is required")
+	}
+	for i, eventType := range r.EventTypes {
+		v.required(fmt.Sprintf("event_types[%d].name", i), eventType.Name)
+	}
+	return v.err()
+}
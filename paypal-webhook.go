@@ -0,0 +1,71 @@
+package payment
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// EnsureWebhook makes the application have exactly one webhook listening on url for eventTypes.
+// The webhook is created when missing, its event types are replaced when they differ from
+// eventTypes, and the other webhooks registered on the same url are deleted.
+// Webhooks listening on other URLs are left untouched.
+// opts are applied to every request made, so they must not include an idempotency key
+func (c *PayPalClient) EnsureWebhook(ctx context.Context, url string, eventTypes []string, opts ...RequestOption) (*Webhook, error) {
+	list, err := c.ListWebhooks(ctx, AncorTypeApplication, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	var webhook *Webhook
+	for i := range list.Webhooks {
+		if list.Webhooks[i].URL != url {
+			continue
+		}
+		if webhook == nil {
+			webhook = &list.Webhooks[i]
+			continue
+		}
+		if err := c.DeleteWebhook(ctx, list.Webhooks[i].ID, opts...); err != nil {
+			return nil, fmt.Errorf("paypal: delete duplicate webhook %s: %w", list.Webhooks[i].ID, err)
+		}
+	}
+
+	desired := make([]WebhookEventType, len(eventTypes))
+	for i, name := range eventTypes {
+		desired[i] = WebhookEventType{Name: name}
+	}
+
+	if webhook == nil {
+		return c.CreateWebhook(ctx, &CreateWebhookRequest{URL: url, EventTypes: desired}, opts...)
+	}
+
+	if sameEventTypes(webhook.EventTypes, eventTypes) {
+		return webhook, nil
+	}
+
+	fields := NewPatchBuilder().Replace(WebhookPatchPathEventTypes, desired).BuildWebhookFields()
+	return c.UpdateWebhook(ctx, webhook.ID, fields, opts...)
+}
+
+// sameEventTypes reports whether the event types subscribed match names, in any order
+func sameEventTypes(eventTypes []WebhookEventType, names []string) bool {
+	if len(eventTypes) != len(names) {
+		return false
+	}
+
+	current := make([]string, len(eventTypes))
+	for i, eventType := range eventTypes {
+		current[i] = eventType.Name
+	}
+	desired := append([]string(nil), names...)
+	sort.Strings(current)
+	sort.Strings(desired)
+
+	for i := range current {
+		if current[i] != desired[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -21,78 +21,102 @@ import (
 
 // IPayPal interface for PayPal services
 type IPayPal interface {
-	GetAccessToken(ctx context.Context) (*TokenResponse, error)
-	CreatePayout(ctx context.Context, p Payout) (*PayoutResponse, error)
-	GetPayout(ctx context.Context, payoutBatchID string) (*PayoutResponse, error)
-	GetPayoutItem(ctx context.Context, payoutItemID string) (*PayoutItemResponse, error)
-	CancelPayoutItem(ctx context.Context, payoutItemID string) (*PayoutItemResponse, error)
-	GetSale(ctx context.Context, saleID string) (*Sale, error)
-	RefundSale(ctx context.Context, saleID string, a *Amount) (*Refund, error)
-	ListBillingPlans(ctx context.Context, bplp BillingPlanListParams) (*BillingPlanListResponse, error)
-	CreateBillingPlan(ctx context.Context, plan BillingPlan) (*CreateBillingResponse, error)
-	UpdateBillingPlan(ctx context.Context, planId string, pathValues map[string]map[string]interface{}) error
-	ActivatePlan(ctx context.Context, planID string) error
-	CreateBillingAgreement(ctx context.Context, a BillingAgreement) (*CreateAgreementResponse, error)
-	ExecuteApprovedAgreement(ctx context.Context, token string) (*ExecuteAgreementResponse, error)
-	GetAuthorization(ctx context.Context, authID string) (*Authorization, error)
-	CaptureAuthorization(ctx context.Context, authID string, paymentCaptureRequest *PaymentCaptureRequest) (*PaymentCaptureResponse, error)
-	CaptureAuthorizationWithPaypalRequestId(ctx context.Context, authID string, paymentCaptureRequest *PaymentCaptureRequest, requestID string) (*PaymentCaptureResponse, error)
-	VoidAuthorization(ctx context.Context, authID string) (*Authorization, error)
-	ReauthorizeAuthorization(ctx context.Context, authID string, a *Amount) (*Authorization, error)
-	GetCapturedPaymentDetails(ctx context.Context, id string) (*Capture, error)
-	GetRefund(ctx context.Context, refundID string) (*Refund, error)
-	GetUserInfo(ctx context.Context, schema string) (*UserInfo, error)
-	GrantNewAccessTokenFromAuthCode(ctx context.Context, code, redirectURI string) (*TokenResponse, error)
-	GrantNewAccessTokenFromRefreshToken(ctx context.Context, refreshToken string) (*TokenResponse, error)
-	CreateWebProfile(ctx context.Context, wp WebProfile) (*WebProfile, error)
-	GetWebProfile(ctx context.Context, profileID string) (*WebProfile, error)
-	GetWebProfiles(ctx context.Context) ([]WebProfile, error)
-	SetWebProfile(ctx context.Context, wp WebProfile) error
-	DeleteWebProfile(ctx context.Context, profileID string) error
-	ListTransactions(ctx context.Context, req *TransactionSearchRequest) (*TransactionSearchResponse, error)
-	StoreCreditCard(ctx context.Context, cc CreditCard) (*CreditCard, error)
-	DeleteCreditCard(ctx context.Context, id string) error
-	GetCreditCard(ctx context.Context, id string) (*CreditCard, error)
-	GetCreditCards(ctx context.Context, ccf *CreditCardsFilter) (*CreditCards, error)
-	PatchCreditCard(ctx context.Context, id string, ccf []CreditCardField) (*CreditCard, error)
-	GetOrder(ctx context.Context, orderID string) (*Order, error)
-	CreateOrder(ctx context.Context, intent string, purchaseUnits []PurchaseUnitRequest, payer *CreateOrderPayer, appContext *ApplicationContext) (*Order, error)
-	UpdateOrder(ctx context.Context, orderID string, purchaseUnits []PurchaseUnitRequest) (*Order, error)
-	AuthorizeOrder(ctx context.Context, orderID string, authorizeOrderRequest AuthorizeOrderRequest) (*Authorization, error)
-	CaptureOrder(ctx context.Context, orderID string, captureOrderRequest CaptureOrderRequest) (*CaptureOrderResponse, error)
-	CaptureOrderWithPaypalRequestId(ctx context.Context, orderID string, captureOrderRequest CaptureOrderRequest, requestID string) (*CaptureOrderResponse, error)
-	CreateWebhook(ctx context.Context, createWebhookRequest *CreateWebhookRequest) (*Webhook, error)
-	GetWebhook(ctx context.Context, webhookID string) (*Webhook, error)
-	UpdateWebhook(ctx context.Context, webhookID string, fields []WebhookField) (*Webhook, error)
-	ListWebhooks(ctx context.Context, anchorType string) (*ListWebhookResponse, error)
-	DeleteWebhook(ctx context.Context, webhookID string) error
-	VerifyWebhookSignature(ctx context.Context, httpReq *http.Request, webhookID string) (*VerifyWebhookResponse, error)
-	GetWebhookEventTypes(ctx context.Context) (*WebhookEventTypesResponse, error)
-	CreateProduct(ctx context.Context, product Product) (*CreateProductResponse, error)
-	UpdateProduct(ctx context.Context, product Product) error
-	GetProduct(ctx context.Context, productId string) (*Product, error)
-	ListProducts(ctx context.Context, params *ProductListParameters) (*ListProductsResponse, error)
-	CreateSubscriptionPlan(ctx context.Context, newPlan SubscriptionPlan) (*CreateSubscriptionPlanResponse, error)
-	UpdateSubscriptionPlan(ctx context.Context, updatedPlan SubscriptionPlan) error
-	GetSubscriptionPlan(ctx context.Context, planId string) (*SubscriptionPlan, error)
-	ListSubscriptionPlans(ctx context.Context, params *SubscriptionPlanListParameters) (*ListSubscriptionPlansResponse, error)
-	ActivateSubscriptionPlan(ctx context.Context, planId string) error
-	DeactivateSubscriptionPlans(ctx context.Context, planId string) error
-	UpdateSubscriptionPlanPricing(ctx context.Context, planId string, pricingSchemes []PricingSchemeUpdate) error
-	CreateSubscription(ctx context.Context, newSubscription SubscriptionBase) (*SubscriptionDetailResp, error)
-	UpdateSubscription(ctx context.Context, updatedSubscription Subscription) error
-	GetSubscriptionDetails(ctx context.Context, subscriptionID string) (*SubscriptionDetailResp, error)
-	ActivateSubscription(ctx context.Context, subscriptionId, activateReason string) error
-	CancelSubscription(ctx context.Context, subscriptionId, cancelReason string) error
-	CaptureSubscription(ctx context.Context, subscriptionId string, request CaptureReqeust) (*SubscriptionCaptureResponse, error)
-	SuspendSubscription(ctx context.Context, subscriptionId, reason string) error
-	GetSubscriptionTransactions(ctx context.Context, requestParams SubscriptionTransactionsParams) (*SubscriptionTransactionsResponse, error)
-	ReviseSubscription(ctx context.Context, subscriptionId string, reviseSubscription SubscriptionBase) (*SubscriptionDetailResp, error)
-	CreatePaypalBillingAgreementToken(ctx context.Context, description *string, shippingAddress *ShippingAddress, payer *Payer, plan *BillingPlan) (*BillingAgreementToken, error)
-	CreateBillingAgreementToken(ctx context.Context, description *string, shippingAddress *ShippingAddress, payer *Payer, plan *BillingPlan) (*BillingAgreementToken, error)
-	CreatePaypalBillingAgreementFromToken(ctx context.Context, tokenID string) (*BillingAgreementFromToken, error)
-	CreateBillingAgreementFromToken(ctx context.Context, tokenID string) (*BillingAgreementFromToken, error)
-	CancelBillingAgreement(ctx context.Context, billingAgreementID string) error
+	GetAccessToken(ctx context.Context, opts ...RequestOption) (*TokenResponse, error)
+	GetScopedAccessToken(ctx context.Context, tokenRequest TokenRequest, opts ...RequestOption) (*TokenResponse, error)
+	CreatePayout(ctx context.Context, p Payout, opts ...RequestOption) (*PayoutResponse, error)
+	CreatePayoutsBatched(ctx context.Context, header SenderBatchHeader, items []PayoutItem, options PayoutBatchOptions, opts ...RequestOption) ([]PayoutBatchResult, error)
+	GetPayout(ctx context.Context, payoutBatchID string, opts ...RequestOption) (*PayoutResponse, error)
+	WaitForPayoutCompletion(ctx context.Context, payoutBatchID string, pollOptions PollOptions, opts ...RequestOption) (*PayoutResponse, error)
+	GetPayoutItem(ctx context.Context, payoutItemID string, opts ...RequestOption) (*PayoutItemResponse, error)
+	CancelPayoutItem(ctx context.Context, payoutItemID string, opts ...RequestOption) (*PayoutItemResponse, error)
+	ExportPayoutReport(ctx context.Context, payoutBatchID string, w io.Writer, opts ...RequestOption) error
+	CreateReferencedPayoutItem(ctx context.Context, item ReferencedPayoutItemRequest, opts ...RequestOption) (*ReferencedPayoutItem, error)
+	GetReferencedPayoutItem(ctx context.Context, itemID string, opts ...RequestOption) (*ReferencedPayoutItem, error)
+	DisburseCapture(ctx context.Context, captureID string, opts ...RequestOption) (*ReferencedPayoutItem, error)
+	DownloadReport(ctx context.Context, reportURL string, w io.Writer, opts ...RequestOption) error
+	GetSale(ctx context.Context, saleID string, opts ...RequestOption) (*Sale, error)
+	RefundSale(ctx context.Context, saleID string, a *Amount, opts ...RequestOption) (*Refund, error)
+	ListBillingPlans(ctx context.Context, bplp BillingPlanListParams, opts ...RequestOption) (*BillingPlanListResponse, error)
+	CreateBillingPlan(ctx context.Context, plan BillingPlan, opts ...RequestOption) (*CreateBillingResponse, error)
+	UpdateBillingPlan(ctx context.Context, planId string, pathValues map[string]map[string]interface{}, opts ...RequestOption) error
+	ActivatePlan(ctx context.Context, planID string, opts ...RequestOption) error
+	CreateBillingAgreement(ctx context.Context, a BillingAgreement, opts ...RequestOption) (*CreateAgreementResponse, error)
+	ExecuteApprovedAgreement(ctx context.Context, token string, opts ...RequestOption) (*ExecuteAgreementResponse, error)
+	GetAuthorization(ctx context.Context, authID string, opts ...RequestOption) (*Authorization, error)
+	CaptureAuthorization(ctx context.Context, authID string, paymentCaptureRequest *PaymentCaptureRequest, opts ...RequestOption) (*PaymentCaptureResponse, error)
+	CaptureAuthorizationWithPaypalRequestId(ctx context.Context, authID string, paymentCaptureRequest *PaymentCaptureRequest, requestID string, opts ...RequestOption) (*PaymentCaptureResponse, error)
+	VoidAuthorization(ctx context.Context, authID string, opts ...RequestOption) (*Authorization, error)
+	ReauthorizeAuthorization(ctx context.Context, authID string, a *Amount, opts ...RequestOption) (*Authorization, error)
+	GetCapturedPaymentDetails(ctx context.Context, id string, opts ...RequestOption) (*Capture, error)
+	GetRefund(ctx context.Context, refundID string, opts ...RequestOption) (*Refund, error)
+	RefundCapture(ctx context.Context, captureID string, refundCaptureRequest RefundCaptureRequest, opts ...RequestOption) (*RefundResponse, error)
+	GetUserInfo(ctx context.Context, schema string, opts ...RequestOption) (*UserInfo, error)
+	ConsentURL(redirectURI string, scopes []string, state string) string
+	GrantNewAccessTokenFromAuthCode(ctx context.Context, code, redirectURI string, opts ...RequestOption) (*TokenResponse, error)
+	GrantNewAccessTokenFromRefreshToken(ctx context.Context, refreshToken string, opts ...RequestOption) (*TokenResponse, error)
+	CreateWebProfile(ctx context.Context, wp WebProfile, opts ...RequestOption) (*WebProfile, error)
+	GetWebProfile(ctx context.Context, profileID string, opts ...RequestOption) (*WebProfile, error)
+	GetWebProfiles(ctx context.Context, opts ...RequestOption) ([]WebProfile, error)
+	SetWebProfile(ctx context.Context, wp WebProfile, opts ...RequestOption) error
+	DeleteWebProfile(ctx context.Context, profileID string, opts ...RequestOption) error
+	ListTransactions(ctx context.Context, req *TransactionSearchRequest, opts ...RequestOption) (*TransactionSearchResponse, error)
+	NewTransactionIterator(ctx context.Context, req TransactionSearchRequest, opts ...RequestOption) *TransactionIterator
+	ListBalances(ctx context.Context, asOfTime time.Time, currency string, opts ...RequestOption) (*BalancesResponse, error)
+	SetTransactionContext(ctx context.Context, merchantID, trackingID string, additionalData []TransactionContextData, opts ...RequestOption) error
+	StoreCreditCard(ctx context.Context, cc CreditCard, opts ...RequestOption) (*CreditCard, error)
+	DeleteCreditCard(ctx context.Context, id string, opts ...RequestOption) error
+	GetCreditCard(ctx context.Context, id string, opts ...RequestOption) (*CreditCard, error)
+	GetCreditCards(ctx context.Context, ccf *CreditCardsFilter, opts ...RequestOption) (*CreditCards, error)
+	NewCreditCardIterator(ctx context.Context, ccf CreditCardsFilter, opts ...RequestOption) *CreditCardIterator
+	PatchCreditCard(ctx context.Context, id string, ccf []CreditCardField, opts ...RequestOption) (*CreditCard, error)
+	GetOrder(ctx context.Context, orderID string, opts ...RequestOption) (*Order, error)
+	CreateOrder(ctx context.Context, intent string, purchaseUnits []PurchaseUnitRequest, payer *CreateOrderPayer, appContext *ApplicationContext, opts ...RequestOption) (*Order, error)
+	CreateOrderFromRequest(ctx context.Context, createOrderRequest CreateOrderRequest, opts ...RequestOption) (*Order, error)
+	UpdateOrder(ctx context.Context, orderID string, purchaseUnits []PurchaseUnitRequest, opts ...RequestOption) (*Order, error)
+	UpdateOrderWithPatches(ctx context.Context, orderID string, patches []Patch, opts ...RequestOption) error
+	ConfirmPaymentSource(ctx context.Context, orderID string, confirmPaymentSourceRequest ConfirmPaymentSourceRequest, opts ...RequestOption) (*Order, error)
+	AuthorizeOrder(ctx context.Context, orderID string, authorizeOrderRequest AuthorizeOrderRequest, opts ...RequestOption) (*Authorization, error)
+	CaptureOrder(ctx context.Context, orderID string, captureOrderRequest CaptureOrderRequest, opts ...RequestOption) (*CaptureOrderResponse, error)
+	CaptureOrderWithPaypalRequestId(ctx context.Context, orderID string, captureOrderRequest CaptureOrderRequest, requestID string, opts ...RequestOption) (*CaptureOrderResponse, error)
+	RetryOrderApproval(ctx context.Context, orderID string, opts ...RequestOption) (string, error)
+	WaitForApproval(ctx context.Context, orderID string, pollOptions PollOptions, opts ...RequestOption) (*Order, error)
+	VoidAuthorizedOrder(ctx context.Context, orderID string, opts ...RequestOption) ([]Authorization, error)
+	CreateWebhook(ctx context.Context, createWebhookRequest *CreateWebhookRequest, opts ...RequestOption) (*Webhook, error)
+	GetWebhook(ctx context.Context, webhookID string, opts ...RequestOption) (*Webhook, error)
+	UpdateWebhook(ctx context.Context, webhookID string, fields []WebhookField, opts ...RequestOption) (*Webhook, error)
+	ListWebhooks(ctx context.Context, anchorType string, opts ...RequestOption) (*ListWebhookResponse, error)
+	DeleteWebhook(ctx context.Context, webhookID string, opts ...RequestOption) error
+	VerifyWebhookSignature(ctx context.Context, httpReq *http.Request, webhookID string, opts ...RequestOption) (*VerifyWebhookResponse, error)
+	GetWebhookEventTypes(ctx context.Context, opts ...RequestOption) (*WebhookEventTypesResponse, error)
+	EnsureWebhook(ctx context.Context, url string, eventTypes []string, opts ...RequestOption) (*Webhook, error)
+	CreateProduct(ctx context.Context, product Product, opts ...RequestOption) (*CreateProductResponse, error)
+	UpdateProduct(ctx context.Context, product Product, opts ...RequestOption) error
+	GetProduct(ctx context.Context, productId string, opts ...RequestOption) (*Product, error)
+	ListProducts(ctx context.Context, params *ProductListParameters, opts ...RequestOption) (*ListProductsResponse, error)
+	NewProductIterator(ctx context.Context, params ProductListParameters, opts ...RequestOption) *ProductIterator
+	CreateSubscriptionPlan(ctx context.Context, newPlan SubscriptionPlan, opts ...RequestOption) (*CreateSubscriptionPlanResponse, error)
+	UpdateSubscriptionPlan(ctx context.Context, updatedPlan SubscriptionPlan, opts ...RequestOption) error
+	GetSubscriptionPlan(ctx context.Context, planId string, opts ...RequestOption) (*SubscriptionPlan, error)
+	ListSubscriptionPlans(ctx context.Context, params *SubscriptionPlanListParameters, opts ...RequestOption) (*ListSubscriptionPlansResponse, error)
+	NewPlanIterator(ctx context.Context, params SubscriptionPlanListParameters, opts ...RequestOption) *PlanIterator
+	ActivateSubscriptionPlan(ctx context.Context, planId string, opts ...RequestOption) error
+	DeactivateSubscriptionPlans(ctx context.Context, planId string, opts ...RequestOption) error
+	UpdateSubscriptionPlanPricing(ctx context.Context, planId string, pricingSchemes []PricingSchemeUpdate, opts ...RequestOption) error
+	CreateSubscription(ctx context.Context, newSubscription SubscriptionBase, opts ...RequestOption) (*SubscriptionDetailResp, error)
+	UpdateSubscription(ctx context.Context, updatedSubscription Subscription, opts ...RequestOption) error
+	PatchSubscription(ctx context.Context, subscriptionID string, patches []Patch, opts ...RequestOption) error
+	GetSubscriptionDetails(ctx context.Context, subscriptionID string, opts ...RequestOption) (*SubscriptionDetailResp, error)
+	ActivateSubscription(ctx context.Context, subscriptionId, activateReason string, opts ...RequestOption) error
+	CancelSubscription(ctx context.Context, subscriptionId, cancelReason string, opts ...RequestOption) error
+	CaptureSubscription(ctx context.Context, subscriptionId string, request CaptureReqeust, opts ...RequestOption) (*SubscriptionCaptureResponse, error)
+	SuspendSubscription(ctx context.Context, subscriptionId, reason string, opts ...RequestOption) error
+	GetSubscriptionTransactions(ctx context.Context, requestParams SubscriptionTransactionsParams, opts ...RequestOption) (*SubscriptionTransactionsResponse, error)
+	ReviseSubscription(ctx context.Context, subscriptionId string, reviseSubscription SubscriptionBase, opts ...RequestOption) (*SubscriptionDetailResp, error)
+	CreatePaypalBillingAgreementToken(ctx context.Context, description *string, shippingAddress *ShippingAddress, payer *Payer, plan *BillingPlan, opts ...RequestOption) (*BillingAgreementToken, error)
+	CreateBillingAgreementToken(ctx context.Context, description *string, shippingAddress *ShippingAddress, payer *Payer, plan *BillingPlan, opts ...RequestOption) (*BillingAgreementToken, error)
+	CreatePaypalBillingAgreementFromToken(ctx context.Context, tokenID string, opts ...RequestOption) (*BillingAgreementFromToken, error)
+	CreateBillingAgreementFromToken(ctx context.Context, tokenID string, opts ...RequestOption) (*BillingAgreementFromToken, error)
+	CancelBillingAgreement(ctx context.Context, billingAgreementID string, opts ...RequestOption) error
 }
 
 // PayPalClient represents a Paypal REST API Client
@@ -106,6 +130,17 @@ type PayPalClient struct {
 	Token                *TokenResponse
 	tokenExpiresAt       time.Time
 	returnRepresentation bool
+	authAssertion        string
+	tokenRefresh         *tokenRefresh
+	tokenStore           TokenStore
+	autoIdempotencyKey   bool
+	userAgent            string
+	middlewares          []Middleware
+	retryPolicy          *RetryPolicy
+	rateLimiter          *tokenBucket
+	rateLimitCallback    func(req *http.Request, rateLimit RateLimit)
+	mockResponse         string
+	validateRequests     bool
 }
 
 const (
@@ -118,77 +153,141 @@ const (
 	// RequestNewTokenBeforeExpiresIn is used by SendWithAuth and try to get new Token when it's about to expire
 	RequestNewTokenBeforeExpiresIn = time.Duration(60) * time.Second
 
-	// BillingPlanStatusActive is used by BillingPlan and a few others
-	BillingPlanStatusActive BillingPlanStatus = "ACTIVE"
-
 	AncorTypeApplication string = "APPLICATION"
 	AncorTypeAccount     string = "ACCOUNT"
 )
 
-// payPalClientSessionMapping singleton pattern
-var payPalClientSessionMapping = make(map[string]*PayPalClient)
+// ErrInvalidPayPalConfig is returned when a PayPal client is created without ClientID, SecretID or APIBase
+var ErrInvalidPayPalConfig = errors.New("paypal: ClientID, SecretID and APIBase are required to create a client")
+
+// payPalClientRegistry holds the clients shared between callers using the same credentials
+var payPalClientRegistry = struct {
+	sync.Mutex
+	clients map[string]*PayPalClient
+}{clients: make(map[string]*PayPalClient)}
+
+// NewPayPalClient returns a PayPal client for config.
+// APIBase is a base API URL, for testing you can use APIBaseSandBox.
+// Without opts, callers using the same credentials share one client and its access token.
+// A client customized by opts is never shared, and config.APIBase may then be left empty in favor of WithAPIBase.
+func NewPayPalClient(config *PayPal, opts ...ClientOption) (IPayPal, error) {
+	if config == nil {
+		return nil, ErrInvalidPayPalConfig
+	}
+
+	if len(opts) > 0 {
+		client := newPayPalClient(config)
+		for _, opt := range opts {
+			opt(client)
+		}
+		if client.ClientID == "" || client.Secret == "" || client.APIBase == "" || client.Client == nil {
+			return nil, ErrInvalidPayPalConfig
+		}
+		return client, nil
+	}
 
-// newPayPal init new instance.
-// APIBase is a base API URL, for testing you can use paypal.APIBaseSandBox
-func newPayPal(config *PayPal) IPayPal {
-	// Validate config file
 	if config.ClientID == "" || config.SecretID == "" || config.APIBase == "" {
-		log.Fatalln("ClientID, Secret and APIBase are required to create a Client")
+		return nil, ErrInvalidPayPalConfig
 	}
 
-	// Init PayPal client with singleton pattern
 	hasher := &hash.Client{}
-	configAsJSON, err := json.Marshal(config)
-	if err != nil {
-		log.Fatalln("Unable to marshal PayPal configuration: ", err)
-	}
-	configAsString := hasher.SHA1(string(configAsJSON))
+	key := hasher.SHA1(config.APIBase + "|" + config.ClientID + "|" + config.SecretID)
 
-	currentPayPalSession := payPalClientSessionMapping[configAsString]
-	if currentPayPalSession == nil {
-		currentPayPalSession = &PayPalClient{}
+	payPalClientRegistry.Lock()
+	defer payPalClientRegistry.Unlock()
+
+	client := payPalClientRegistry.clients[key]
+	if client == nil {
+		client = newPayPalClient(config)
+		payPalClientRegistry.clients[key] = client
+	}
 
-		currentPayPalSession.Client = &http.Client{}
-		currentPayPalSession.ClientID = config.ClientID
-		currentPayPalSession.Secret = config.SecretID
-		currentPayPalSession.APIBase = config.APIBase
-		payPalClientSessionMapping[configAsString] = currentPayPalSession
+	return client, nil
+}
 
-		log.Println("Init PayPal client successfully")
+// newPayPal returns the shared client for config, or nil if config is invalid
+func newPayPal(config *PayPal) IPayPal {
+	client, err := NewPayPalClient(config)
+	if err != nil {
+		log.Println("Unable to init PayPal client: ", err)
+		return nil
 	}
 
-	return currentPayPalSession
+	return client
+}
+
+func newPayPalClient(config *PayPal) *PayPalClient {
+	return &PayPalClient{
+		Client:   &http.Client{},
+		ClientID: config.ClientID,
+		Secret:   config.SecretID,
+		APIBase:  config.APIBase,
+	}
 }
 
 // GetAccessToken returns struct of TokenResponse.
 // No need to call SetAccessToken to apply new access token for current Client.
 // Endpoint: POST /v1/oauth2/token
-func (c *PayPalClient) GetAccessToken(ctx context.Context) (*TokenResponse, error) {
-	buf := bytes.NewBuffer([]byte("grant_type=client_credentials"))
-	req, err := http.NewRequestWithContext(ctx, "POST", fmt.Sprintf("%s%s", c.APIBase, "/v1/oauth2/token"), buf)
+func (c *PayPalClient) GetAccessToken(ctx context.Context, opts ...RequestOption) (*TokenResponse, error) {
+	response, err := c.requestToken(ctx, url.Values{"grant_type": {"client_credentials"}}, opts)
+
+	// Set Token for current Client
+	if response.Token != "" {
+		c.Lock()
+		c.Token = response
+		c.tokenExpiresAt = time.Now().Add(time.Duration(response.ExpiresIn) * time.Second)
+		c.Unlock()
+	}
+
+	return response, err
+}
+
+// GetScopedAccessToken returns a token limited to scopes, issued for another client or subject, or carrying an id_token.
+// Unlike GetAccessToken, the token is not used by the client for its own requests.
+// Endpoint: POST /v1/oauth2/token
+func (c *PayPalClient) GetScopedAccessToken(ctx context.Context, tokenRequest TokenRequest, opts ...RequestOption) (*TokenResponse, error) {
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(tokenRequest.Scopes) > 0 {
+		form.Set("scope", strings.Join(tokenRequest.Scopes, " "))
+	}
+	if tokenRequest.TargetClientID != "" {
+		form.Set("target_client_id", tokenRequest.TargetClientID)
+	}
+	if tokenRequest.TargetSubject != "" {
+		form.Set("target_subject", tokenRequest.TargetSubject)
+	}
+	if tokenRequest.TargetCustomerID != "" {
+		form.Set("target_customer_id", tokenRequest.TargetCustomerID)
+	}
+	if tokenRequest.ResponseType != "" {
+		form.Set("response_type", tokenRequest.ResponseType)
+	}
+
+	return c.requestToken(ctx, form, opts)
+}
+
+// requestToken posts form to the token endpoint using basic auth
+func (c *PayPalClient) requestToken(ctx context.Context, form url.Values, opts []RequestOption) (*TokenResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", fmt.Sprintf("%s%s", c.APIBase, "/v1/oauth2/token"), strings.NewReader(form.Encode()))
 	if err != nil {
 		return &TokenResponse{}, err
 	}
 
+	c.applyRequestOptions(req, opts)
+
 	req.Header.Set("Content-type", "application/x-www-form-urlencoded")
 
 	response := &TokenResponse{}
 	err = c.SendWithBasicAuth(req, response)
 
-	// Set Token for current Client
-	if response.Token != "" {
-		c.Token = response
-		c.tokenExpiresAt = time.Now().Add(time.Duration(response.ExpiresIn) * time.Second)
-	}
-
 	return response, err
 }
 
 // CreatePayout submits a payout with an asynchronous API call, which immediately returns the results of a PayPal payment.
 // For email payout set RecipientType: "EMAIL" and receiver email into Receiver
 // Endpoint: POST /v1/payments/payouts
-func (c *PayPalClient) CreatePayout(ctx context.Context, p Payout) (*PayoutResponse, error) {
-	req, err := c.NewRequest(ctx, "POST", fmt.Sprintf("%s%s", c.APIBase, "/v1/payments/payouts"), p)
+func (c *PayPalClient) CreatePayout(ctx context.Context, p Payout, opts ...RequestOption) (*PayoutResponse, error) {
+	req, err := c.NewRequest(ctx, "POST", fmt.Sprintf("%s%s", c.APIBase, "/v1/payments/payouts"), p, opts...)
 	response := &PayoutResponse{}
 
 	if err != nil {
@@ -205,8 +304,8 @@ func (c *PayPalClient) CreatePayout(ctx context.Context, p Payout) (*PayoutRespo
 // GetPayout shows the latest status of a batch payout along with the transaction status and other data for individual items.
 // Also, returns IDs for the individual payout items. You can use these item IDs in other calls.
 // Endpoint: GET /v1/payments/payouts/ID
-func (c *PayPalClient) GetPayout(ctx context.Context, payoutBatchID string) (*PayoutResponse, error) {
-	req, err := c.NewRequest(ctx, "GET", fmt.Sprintf("%s%s", c.APIBase, "/v1/payments/payouts/"+payoutBatchID), nil)
+func (c *PayPalClient) GetPayout(ctx context.Context, payoutBatchID string, opts ...RequestOption) (*PayoutResponse, error) {
+	req, err := c.NewRequest(ctx, "GET", fmt.Sprintf("%s%s", c.APIBase, "/v1/payments/payouts/"+payoutBatchID), nil, opts...)
 	response := &PayoutResponse{}
 
 	if err != nil {
@@ -223,8 +322,8 @@ func (c *PayPalClient) GetPayout(ctx context.Context, payoutBatchID string) (*Pa
 // GetPayoutItem shows the details for a payout item.
 // Use this call to review the current status of a previously unclaimed, or pending, payout item.
 // Endpoint: GET /v1/payments/payouts-item/ID
-func (c *PayPalClient) GetPayoutItem(ctx context.Context, payoutItemID string) (*PayoutItemResponse, error) {
-	req, err := c.NewRequest(ctx, "GET", fmt.Sprintf("%s%s", c.APIBase, "/v1/payments/payouts-item/"+payoutItemID), nil)
+func (c *PayPalClient) GetPayoutItem(ctx context.Context, payoutItemID string, opts ...RequestOption) (*PayoutItemResponse, error) {
+	req, err := c.NewRequest(ctx, "GET", fmt.Sprintf("%s%s", c.APIBase, "/v1/payments/payouts-item/"+payoutItemID), nil, opts...)
 	response := &PayoutItemResponse{}
 
 	if err != nil {
@@ -241,8 +340,8 @@ func (c *PayPalClient) GetPayoutItem(ctx context.Context, payoutItemID string) (
 // CancelPayoutItem cancels an unclaimed Payout Item. If no one claims the unclaimed item within 30 days,
 // the funds are automatically returned to the sender. Use this call to cancel the unclaimed item before the automatic 30-day refund.
 // Endpoint: POST /v1/payments/payouts-item/ID/cancel
-func (c *PayPalClient) CancelPayoutItem(ctx context.Context, payoutItemID string) (*PayoutItemResponse, error) {
-	req, err := c.NewRequest(ctx, "POST", fmt.Sprintf("%s%s", c.APIBase, "/v1/payments/payouts-item/"+payoutItemID+"/cancel"), nil)
+func (c *PayPalClient) CancelPayoutItem(ctx context.Context, payoutItemID string, opts ...RequestOption) (*PayoutItemResponse, error) {
+	req, err := c.NewRequest(ctx, "POST", fmt.Sprintf("%s%s", c.APIBase, "/v1/payments/payouts-item/"+payoutItemID+"/cancel"), nil, opts...)
 	response := &PayoutItemResponse{}
 
 	if err != nil {
@@ -260,10 +359,10 @@ func (c *PayPalClient) CancelPayoutItem(ctx context.Context, payoutItemID string
 // Use this call to get details about a sale transaction.
 // Note: This call returns only the sales that were created via the REST API.
 // Endpoint: GET /v1/payments/sale/ID
-func (c *PayPalClient) GetSale(ctx context.Context, saleID string) (*Sale, error) {
+func (c *PayPalClient) GetSale(ctx context.Context, saleID string, opts ...RequestOption) (*Sale, error) {
 	sale := &Sale{}
 
-	req, err := c.NewRequest(ctx, "GET", fmt.Sprintf("%s%s", c.APIBase, "/v1/payments/sale/"+saleID), nil)
+	req, err := c.NewRequest(ctx, "GET", fmt.Sprintf("%s%s", c.APIBase, "/v1/payments/sale/"+saleID), nil, opts...)
 	if err != nil {
 		return sale, err
 	}
@@ -278,14 +377,14 @@ func (c *PayPalClient) GetSale(ctx context.Context, saleID string) (*Sale, error
 // RefundSale refunds a completed payment.
 // Use this call to refund a completed payment. Provide the sale_id in the URI and an empty JSON payload for a full refund. For partial refunds, you can include an amount.
 // Endpoint: POST /v1/payments/sale/ID/refund
-func (c *PayPalClient) RefundSale(ctx context.Context, saleID string, a *Amount) (*Refund, error) {
+func (c *PayPalClient) RefundSale(ctx context.Context, saleID string, a *Amount, opts ...RequestOption) (*Refund, error) {
 	type refundRequest struct {
 		Amount *Amount `json:"amount"`
 	}
 
 	refund := &Refund{}
 
-	req, err := c.NewRequest(ctx, "POST", fmt.Sprintf("%s%s", c.APIBase, "/v1/payments/sale/"+saleID+"/refund"), &refundRequest{Amount: a})
+	req, err := c.NewRequest(ctx, "POST", fmt.Sprintf("%s%s", c.APIBase, "/v1/payments/sale/"+saleID+"/refund"), &refundRequest{Amount: a}, opts...)
 	if err != nil {
 		return refund, err
 	}
@@ -299,8 +398,8 @@ func (c *PayPalClient) RefundSale(ctx context.Context, saleID string, a *Amount)
 
 // ListBillingPlans lists billing-plans
 // Endpoint: GET /v1/payments/billing-plans
-func (c *PayPalClient) ListBillingPlans(ctx context.Context, bplp BillingPlanListParams) (*BillingPlanListResponse, error) {
-	req, err := c.NewRequest(ctx, "GET", fmt.Sprintf("%s%s", c.APIBase, "/v1/payments/billing-plans"), nil)
+func (c *PayPalClient) ListBillingPlans(ctx context.Context, bplp BillingPlanListParams, opts ...RequestOption) (*BillingPlanListResponse, error) {
+	req, err := c.NewRequest(ctx, "GET", fmt.Sprintf("%s%s", c.APIBase, "/v1/payments/billing-plans"), nil, opts...)
 	response := &BillingPlanListResponse{}
 	if err != nil {
 		return response, err
@@ -319,8 +418,8 @@ func (c *PayPalClient) ListBillingPlans(ctx context.Context, bplp BillingPlanLis
 
 // CreateBillingPlan creates a billing plan in Paypal
 // Endpoint: POST /v1/payments/billing-plans
-func (c *PayPalClient) CreateBillingPlan(ctx context.Context, plan BillingPlan) (*CreateBillingResponse, error) {
-	req, err := c.NewRequest(ctx, http.MethodPost, fmt.Sprintf("%s%s", c.APIBase, "/v1/payments/billing-plans"), plan)
+func (c *PayPalClient) CreateBillingPlan(ctx context.Context, plan BillingPlan, opts ...RequestOption) (*CreateBillingResponse, error) {
+	req, err := c.NewRequest(ctx, http.MethodPost, fmt.Sprintf("%s%s", c.APIBase, "/v1/payments/billing-plans"), plan, opts...)
 	response := &CreateBillingResponse{}
 	if err != nil {
 		return response, err
@@ -331,7 +430,7 @@ func (c *PayPalClient) CreateBillingPlan(ctx context.Context, plan BillingPlan)
 
 // UpdateBillingPlan updates values inside a billing plan
 // Endpoint: PATCH /v1/payments/billing-plans
-func (c *PayPalClient) UpdateBillingPlan(ctx context.Context, planId string, pathValues map[string]map[string]interface{}) error {
+func (c *PayPalClient) UpdateBillingPlan(ctx context.Context, planId string, pathValues map[string]map[string]interface{}, opts ...RequestOption) error {
 	patchData := []Patch{}
 	for path, data := range pathValues {
 		patchData = append(patchData, Patch{
@@ -341,7 +440,7 @@ func (c *PayPalClient) UpdateBillingPlan(ctx context.Context, planId string, pat
 		})
 	}
 
-	req, err := c.NewRequest(ctx, http.MethodPatch, fmt.Sprintf("%s%s%s", c.APIBase, "/v1/payments/billing-plans/", planId), patchData)
+	req, err := c.NewRequest(ctx, http.MethodPatch, fmt.Sprintf("%s%s%s", c.APIBase, "/v1/payments/billing-plans/", planId), patchData, opts...)
 	if err != nil {
 		return err
 	}
@@ -352,22 +451,22 @@ func (c *PayPalClient) UpdateBillingPlan(ctx context.Context, planId string, pat
 // ActivatePlan activates a billing plan
 // By default, a new plan is not activated
 // Endpoint: PATCH /v1/payments/billing-plans/
-func (c *PayPalClient) ActivatePlan(ctx context.Context, planID string) error {
+func (c *PayPalClient) ActivatePlan(ctx context.Context, planID string, opts ...RequestOption) error {
 	return c.UpdateBillingPlan(ctx, planID, map[string]map[string]interface{}{
 		"/": {"state": BillingPlanStatusActive},
-	})
+	}, opts...)
 }
 
 // CreateBillingAgreement creates an agreement for specified plan
 // Endpoint: POST /v1/payments/billing-agreements
 // Deprecated: Use POST /v1/billing-agreements/agreements
-func (c *PayPalClient) CreateBillingAgreement(ctx context.Context, a BillingAgreement) (*CreateAgreementResponse, error) {
+func (c *PayPalClient) CreateBillingAgreement(ctx context.Context, a BillingAgreement, opts ...RequestOption) (*CreateAgreementResponse, error) {
 	// PayPal needs only ID, so we will remove all fields except Plan ID
 	a.Plan = BillingPlan{
 		ID: a.Plan.ID,
 	}
 
-	req, err := c.NewRequest(ctx, http.MethodPost, fmt.Sprintf("%s%s", c.APIBase, "/v1/payments/billing-agreements"), a)
+	req, err := c.NewRequest(ctx, http.MethodPost, fmt.Sprintf("%s%s", c.APIBase, "/v1/payments/billing-agreements"), a, opts...)
 	response := &CreateAgreementResponse{}
 	if err != nil {
 		return response, err
@@ -378,7 +477,7 @@ func (c *PayPalClient) CreateBillingAgreement(ctx context.Context, a BillingAgre
 
 // ExecuteApprovedAgreement - Use this call to execute (complete) a PayPal agreement that has been approved by the payer.
 // Endpoint: POST /v1/payments/billing-agreements/token/agreement-execute
-func (c *PayPalClient) ExecuteApprovedAgreement(ctx context.Context, token string) (*ExecuteAgreementResponse, error) {
+func (c *PayPalClient) ExecuteApprovedAgreement(ctx context.Context, token string, opts ...RequestOption) (*ExecuteAgreementResponse, error) {
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("%s/v1/payments/billing-agreements/%s/agreement-execute", c.APIBase, token), nil)
 	response := &ExecuteAgreementResponse{}
 
@@ -386,6 +485,8 @@ func (c *PayPalClient) ExecuteApprovedAgreement(ctx context.Context, token strin
 		return response, err
 	}
 
+	c.applyRequestOptions(req, opts)
+
 	req.SetBasicAuth(c.ClientID, c.Secret)
 	req.Header.Set("Authorization", "Bearer "+c.Token.Token)
 
@@ -402,7 +503,7 @@ func (c *PayPalClient) ExecuteApprovedAgreement(ctx context.Context, token strin
 
 // GetAuthorization returns an authorization by ID
 // Endpoint: GET /v2/payments/authorizations/ID
-func (c *PayPalClient) GetAuthorization(ctx context.Context, authID string) (*Authorization, error) {
+func (c *PayPalClient) GetAuthorization(ctx context.Context, authID string, opts ...RequestOption) (*Authorization, error) {
 	buf := bytes.NewBuffer([]byte(""))
 	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s%s%s", c.APIBase, "/v2/payments/authorizations/", authID), buf)
 	auth := &Authorization{}
@@ -411,6 +512,8 @@ func (c *PayPalClient) GetAuthorization(ctx context.Context, authID string) (*Au
 		return auth, err
 	}
 
+	c.applyRequestOptions(req, opts)
+
 	err = c.SendWithAuth(req, auth)
 	return auth, err
 }
@@ -418,15 +521,15 @@ func (c *PayPalClient) GetAuthorization(ctx context.Context, authID string) (*Au
 // CaptureAuthorization captures and process an existing authorization.
 // To use this method, the original payment must have Intent set to "authorize"
 // Endpoint: POST /v2/payments/authorizations/ID/capture
-func (c *PayPalClient) CaptureAuthorization(ctx context.Context, authID string, paymentCaptureRequest *PaymentCaptureRequest) (*PaymentCaptureResponse, error) {
-	return c.CaptureAuthorizationWithPaypalRequestId(ctx, authID, paymentCaptureRequest, "")
+func (c *PayPalClient) CaptureAuthorization(ctx context.Context, authID string, paymentCaptureRequest *PaymentCaptureRequest, opts ...RequestOption) (*PaymentCaptureResponse, error) {
+	return c.CaptureAuthorizationWithPaypalRequestId(ctx, authID, paymentCaptureRequest, "", opts...)
 }
 
 // CaptureAuthorization captures and process an existing authorization with idempotency.
 // To use this method, the original payment must have Intent set to "authorize"
 // Endpoint: POST /v2/payments/authorizations/ID/capture
-func (c *PayPalClient) CaptureAuthorizationWithPaypalRequestId(ctx context.Context, authID string, paymentCaptureRequest *PaymentCaptureRequest, requestID string) (*PaymentCaptureResponse, error) {
-	req, err := c.NewRequest(ctx, "POST", fmt.Sprintf("%s%s", c.APIBase, "/v2/payments/authorizations/"+authID+"/capture"), paymentCaptureRequest)
+func (c *PayPalClient) CaptureAuthorizationWithPaypalRequestId(ctx context.Context, authID string, paymentCaptureRequest *PaymentCaptureRequest, requestID string, opts ...RequestOption) (*PaymentCaptureResponse, error) {
+	req, err := c.NewRequest(ctx, "POST", fmt.Sprintf("%s%s", c.APIBase, "/v2/payments/authorizations/"+authID+"/capture"), paymentCaptureRequest, opts...)
 	paymentCaptureResponse := &PaymentCaptureResponse{}
 
 	if err != nil {
@@ -443,7 +546,7 @@ func (c *PayPalClient) CaptureAuthorizationWithPaypalRequestId(ctx context.Conte
 
 // VoidAuthorization voids a previously authorized payment
 // Endpoint: POST /v2/payments/authorizations/ID/void
-func (c *PayPalClient) VoidAuthorization(ctx context.Context, authID string) (*Authorization, error) {
+func (c *PayPalClient) VoidAuthorization(ctx context.Context, authID string, opts ...RequestOption) (*Authorization, error) {
 	buf := bytes.NewBuffer([]byte(""))
 	req, err := http.NewRequestWithContext(ctx, "POST", fmt.Sprintf("%s%s", c.APIBase, "/v2/payments/authorizations/"+authID+"/void"), buf)
 	auth := &Authorization{}
@@ -452,6 +555,8 @@ func (c *PayPalClient) VoidAuthorization(ctx context.Context, authID string) (*A
 		return auth, err
 	}
 
+	c.applyRequestOptions(req, opts)
+
 	err = c.SendWithAuth(req, auth)
 	return auth, err
 }
@@ -459,7 +564,7 @@ func (c *PayPalClient) VoidAuthorization(ctx context.Context, authID string) (*A
 // ReauthorizeAuthorization reauthorize a Paypal account payment.
 // PayPal recommends reauthorizing payment after ~3 days
 // Endpoint: POST /v2/payments/authorizations/ID/reauthorize
-func (c *PayPalClient) ReauthorizeAuthorization(ctx context.Context, authID string, a *Amount) (*Authorization, error) {
+func (c *PayPalClient) ReauthorizeAuthorization(ctx context.Context, authID string, a *Amount, opts ...RequestOption) (*Authorization, error) {
 	buf := bytes.NewBuffer([]byte(`{"amount":{"currency_code":"` + a.Currency + `","value":"` + a.Total + `"}}`))
 	req, err := http.NewRequestWithContext(ctx, "POST", fmt.Sprintf("%s%s", c.APIBase, "/v2/payments/authorizations/"+authID+"/reauthorize"), buf)
 	auth := &Authorization{}
@@ -468,16 +573,18 @@ func (c *PayPalClient) ReauthorizeAuthorization(ctx context.Context, authID stri
 		return auth, err
 	}
 
+	c.applyRequestOptions(req, opts)
+
 	err = c.SendWithAuth(req, auth)
 	return auth, err
 }
 
 // GetCapturedPaymentDetails.
 // Endpoint: GET /v1/payments/capture/:id
-func (c *PayPalClient) GetCapturedPaymentDetails(ctx context.Context, id string) (*Capture, error) {
+func (c *PayPalClient) GetCapturedPaymentDetails(ctx context.Context, id string, opts ...RequestOption) (*Capture, error) {
 	res := &Capture{}
 
-	req, err := c.NewRequest(ctx, "GET", fmt.Sprintf("%s%s%s", c.APIBase, "/v1/payments/capture/", id), nil)
+	req, err := c.NewRequest(ctx, "GET", fmt.Sprintf("%s%s%s", c.APIBase, "/v1/payments/capture/", id), nil, opts...)
 	if err != nil {
 		return res, err
 	}
@@ -492,10 +599,28 @@ func (c *PayPalClient) GetCapturedPaymentDetails(ctx context.Context, id string)
 // GetRefund by ID
 // Use it to look up details of a specific refund on direct and captured payments.
 // Endpoint: GET /v2/payments/refund/ID
-func (c *PayPalClient) GetRefund(ctx context.Context, refundID string) (*Refund, error) {
+func (c *PayPalClient) GetRefund(ctx context.Context, refundID string, opts ...RequestOption) (*Refund, error) {
 	refund := &Refund{}
 
-	req, err := c.NewRequest(ctx, "GET", fmt.Sprintf("%s%s", c.APIBase, "/v2/payments/refund/"+refundID), nil)
+	req, err := c.NewRequest(ctx, "GET", fmt.Sprintf("%s%s", c.APIBase, "/v2/payments/refund/"+refundID), nil, opts...)
+	if err != nil {
+		return refund, err
+	}
+
+	if err = c.SendWithAuth(req, refund); err != nil {
+		return refund, err
+	}
+
+	return refund, nil
+}
+
+// RefundCapture refunds a captured payment, by ID. Leave the amount empty for a full refund.
+// Doc: https://developer.paypal.com/docs/api/payments/v2/#captures_refund
+// Endpoint: POST /v2/payments/captures/ID/refund
+func (c *PayPalClient) RefundCapture(ctx context.Context, captureID string, refundCaptureRequest RefundCaptureRequest, opts ...RequestOption) (*RefundResponse, error) {
+	refund := &RefundResponse{}
+
+	req, err := c.NewRequest(ctx, "POST", fmt.Sprintf("%s%s", c.APIBase, "/v2/payments/captures/"+captureID+"/refund"), refundCaptureRequest, opts...)
 	if err != nil {
 		return refund, err
 	}
@@ -510,7 +635,7 @@ func (c *PayPalClient) GetRefund(ctx context.Context, refundID string) (*Refund,
 // GetUserInfo for retrieve user profile attributes.
 // Pass the schema that is used to return as per openidconnect protocol. The only supported schema value is openid.
 // Endpoint: GET /v1/identity/openidconnect/userinfo/?schema=<Schema>
-func (c *PayPalClient) GetUserInfo(ctx context.Context, schema string) (*UserInfo, error) {
+func (c *PayPalClient) GetUserInfo(ctx context.Context, schema string, opts ...RequestOption) (*UserInfo, error) {
 	u := &UserInfo{}
 
 	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s%s%s", c.APIBase, "/v1/identity/openidconnect/userinfo/?schema=", schema), nil)
@@ -518,6 +643,8 @@ func (c *PayPalClient) GetUserInfo(ctx context.Context, schema string) (*UserInf
 		return u, err
 	}
 
+	c.applyRequestOptions(req, opts)
+
 	if err = c.SendWithAuth(req, u); err != nil {
 		return u, err
 	}
@@ -527,7 +654,7 @@ func (c *PayPalClient) GetUserInfo(ctx context.Context, schema string) (*UserInf
 
 // GrantNewAccessTokenFromAuthCode - Use this call to grant a new access token, using the previously obtained authorization code.
 // Endpoint: POST /v1/identity/openidconnect/tokenservice
-func (c *PayPalClient) GrantNewAccessTokenFromAuthCode(ctx context.Context, code, redirectURI string) (*TokenResponse, error) {
+func (c *PayPalClient) GrantNewAccessTokenFromAuthCode(ctx context.Context, code, redirectURI string, opts ...RequestOption) (*TokenResponse, error) {
 	token := &TokenResponse{}
 
 	q := url.Values{}
@@ -540,6 +667,8 @@ func (c *PayPalClient) GrantNewAccessTokenFromAuthCode(ctx context.Context, code
 		return token, err
 	}
 
+	c.applyRequestOptions(req, opts)
+
 	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
 
 	if err = c.SendWithBasicAuth(req, token); err != nil {
@@ -551,7 +680,7 @@ func (c *PayPalClient) GrantNewAccessTokenFromAuthCode(ctx context.Context, code
 
 // GrantNewAccessTokenFromRefreshToken - Use this call to grant a new access token, using a refresh token.
 // Endpoint: POST /v1/identity/openidconnect/tokenservice
-func (c *PayPalClient) GrantNewAccessTokenFromRefreshToken(ctx context.Context, refreshToken string) (*TokenResponse, error) {
+func (c *PayPalClient) GrantNewAccessTokenFromRefreshToken(ctx context.Context, refreshToken string, opts ...RequestOption) (*TokenResponse, error) {
 	type request struct {
 		GrantType    string `json:"grant_type"`
 		RefreshToken string `json:"refresh_token"`
@@ -559,7 +688,7 @@ func (c *PayPalClient) GrantNewAccessTokenFromRefreshToken(ctx context.Context,
 
 	token := &TokenResponse{}
 
-	req, err := c.NewRequest(ctx, "POST", fmt.Sprintf("%s%s", c.APIBase, "/v1/identity/openidconnect/tokenservice"), request{GrantType: "refresh_token", RefreshToken: refreshToken})
+	req, err := c.NewRequest(ctx, "POST", fmt.Sprintf("%s%s", c.APIBase, "/v1/identity/openidconnect/tokenservice"), request{GrantType: "refresh_token", RefreshToken: refreshToken}, opts...)
 	if err != nil {
 		return token, err
 	}
@@ -574,9 +703,9 @@ func (c *PayPalClient) GrantNewAccessTokenFromRefreshToken(ctx context.Context,
 // CreateWebProfile creates a new web experience profile in Paypal.
 // Allows for the customisation of the payment experience.
 // Endpoint: POST /v1/payment-experience/web-profiles
-func (c *PayPalClient) CreateWebProfile(ctx context.Context, wp WebProfile) (*WebProfile, error) {
+func (c *PayPalClient) CreateWebProfile(ctx context.Context, wp WebProfile, opts ...RequestOption) (*WebProfile, error) {
 	url := fmt.Sprintf("%s%s", c.APIBase, "/v1/payment-experience/web-profiles")
-	req, err := c.NewRequest(ctx, "POST", url, wp)
+	req, err := c.NewRequest(ctx, "POST", url, wp, opts...)
 	response := &WebProfile{}
 
 	if err != nil {
@@ -592,7 +721,7 @@ func (c *PayPalClient) CreateWebProfile(ctx context.Context, wp WebProfile) (*We
 
 // GetWebProfile gets an exists payment experience from Paypal.
 // Endpoint: GET /v1/payment-experience/web-profiles/<profile-id>
-func (c *PayPalClient) GetWebProfile(ctx context.Context, profileID string) (*WebProfile, error) {
+func (c *PayPalClient) GetWebProfile(ctx context.Context, profileID string, opts ...RequestOption) (*WebProfile, error) {
 	var wp WebProfile
 
 	url := fmt.Sprintf("%s%s%s", c.APIBase, "/v1/payment-experience/web-profiles/", profileID)
@@ -602,6 +731,8 @@ func (c *PayPalClient) GetWebProfile(ctx context.Context, profileID string) (*We
 		return &wp, err
 	}
 
+	c.applyRequestOptions(req, opts)
+
 	if err = c.SendWithAuth(req, &wp); err != nil {
 		return &wp, err
 	}
@@ -615,7 +746,7 @@ func (c *PayPalClient) GetWebProfile(ctx context.Context, profileID string) (*We
 
 // GetWebProfiles retrieves web experience profiles from Paypal.
 // Endpoint: GET /v1/payment-experience/web-profiles
-func (c *PayPalClient) GetWebProfiles(ctx context.Context) ([]WebProfile, error) {
+func (c *PayPalClient) GetWebProfiles(ctx context.Context, opts ...RequestOption) ([]WebProfile, error) {
 	var wps []WebProfile
 
 	url := fmt.Sprintf("%s%s", c.APIBase, "/v1/payment-experience/web-profiles")
@@ -625,6 +756,8 @@ func (c *PayPalClient) GetWebProfiles(ctx context.Context) ([]WebProfile, error)
 		return wps, err
 	}
 
+	c.applyRequestOptions(req, opts)
+
 	if err = c.SendWithAuth(req, &wps); err != nil {
 		return wps, err
 	}
@@ -634,7 +767,7 @@ func (c *PayPalClient) GetWebProfiles(ctx context.Context) ([]WebProfile, error)
 
 // SetWebProfile sets a web experience profile in Paypal with given id.
 // Endpoint: PUT /v1/payment-experience/web-profiles
-func (c *PayPalClient) SetWebProfile(ctx context.Context, wp WebProfile) error {
+func (c *PayPalClient) SetWebProfile(ctx context.Context, wp WebProfile, opts ...RequestOption) error {
 
 	if wp.ID == "" {
 		return fmt.Errorf("paypal: no ID specified for WebProfile")
@@ -642,7 +775,7 @@ func (c *PayPalClient) SetWebProfile(ctx context.Context, wp WebProfile) error {
 
 	url := fmt.Sprintf("%s%s%s", c.APIBase, "/v1/payment-experience/web-profiles/", wp.ID)
 
-	req, err := c.NewRequest(ctx, "PUT", url, wp)
+	req, err := c.NewRequest(ctx, "PUT", url, wp, opts...)
 
 	if err != nil {
 		return err
@@ -657,11 +790,11 @@ func (c *PayPalClient) SetWebProfile(ctx context.Context, wp WebProfile) error {
 
 // DeleteWebProfile deletes a web experience profile from Paypal with given id.
 // Endpoint: DELETE /v1/payment-experience/web-profiles
-func (c *PayPalClient) DeleteWebProfile(ctx context.Context, profileID string) error {
+func (c *PayPalClient) DeleteWebProfile(ctx context.Context, profileID string, opts ...RequestOption) error {
 
 	url := fmt.Sprintf("%s%s%s", c.APIBase, "/v1/payment-experience/web-profiles/", profileID)
 
-	req, err := c.NewRequest(ctx, "DELETE", url, nil)
+	req, err := c.NewRequest(ctx, "DELETE", url, nil, opts...)
 
 	if err != nil {
 		return err
@@ -676,10 +809,10 @@ func (c *PayPalClient) DeleteWebProfile(ctx context.Context, profileID string) e
 
 // ListTransactions for search transactions from the last 31 days.
 // Endpoint: GET /v1/reporting/transactions
-func (c *PayPalClient) ListTransactions(ctx context.Context, req *TransactionSearchRequest) (*TransactionSearchResponse, error) {
+func (c *PayPalClient) ListTransactions(ctx context.Context, req *TransactionSearchRequest, opts ...RequestOption) (*TransactionSearchResponse, error) {
 	response := &TransactionSearchResponse{}
 
-	r, err := c.NewRequest(ctx, "GET", fmt.Sprintf("%s%s", c.APIBase, "/v1/reporting/transactions"), nil)
+	r, err := c.NewRequest(ctx, "GET", fmt.Sprintf("%s%s", c.APIBase, "/v1/reporting/transactions"), nil, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -735,10 +868,53 @@ func (c *PayPalClient) ListTransactions(ctx context.Context, req *TransactionSea
 	return response, nil
 }
 
+// ListBalances lists the available and withheld balances of the account, per currency.
+// A zero asOfTime returns the latest balances, an empty currency returns all currencies.
+// Endpoint: GET /v1/reporting/balances
+func (c *PayPalClient) ListBalances(ctx context.Context, asOfTime time.Time, currency string, opts ...RequestOption) (*BalancesResponse, error) {
+	response := &BalancesResponse{}
+
+	req, err := c.NewRequest(ctx, "GET", fmt.Sprintf("%s%s", c.APIBase, "/v1/reporting/balances"), nil, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	q := req.URL.Query()
+	if !asOfTime.IsZero() {
+		q.Add("as_of_time", asOfTime.Format(time.RFC3339))
+	}
+	if currency != "" {
+		q.Add("currency_code", currency)
+	}
+	req.URL.RawQuery = q.Encode()
+
+	if err = c.SendWithAuth(req, response); err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// SetTransactionContext shares risk data of a transaction with PayPal before it is processed, e.g. before a payout.
+// merchantID is the payer ID of the merchant, trackingID is the unique ID later sent as the PayPal-Client-Metadata-Id header.
+// Endpoint: PUT /v1/risk/transaction-contexts/MERCHANT_ID/TRACKING_ID
+func (c *PayPalClient) SetTransactionContext(ctx context.Context, merchantID, trackingID string, additionalData []TransactionContextData, opts ...RequestOption) error {
+	type transactionContextRequest struct {
+		AdditionalData []TransactionContextData `json:"additional_data"`
+	}
+
+	req, err := c.NewRequest(ctx, "PUT", fmt.Sprintf("%s/v1/risk/transaction-contexts/%s/%s", c.APIBase, merchantID, trackingID), transactionContextRequest{AdditionalData: additionalData}, opts...)
+	if err != nil {
+		return err
+	}
+
+	return c.SendWithAuth(req, nil)
+}
+
 // StoreCreditCard function.
 // Endpoint: POST /v1/vault/credit-cards
-func (c *PayPalClient) StoreCreditCard(ctx context.Context, cc CreditCard) (*CreditCard, error) {
-	req, err := c.NewRequest(ctx, "POST", fmt.Sprintf("%s%s", c.APIBase, "/v1/vault/credit-cards"), cc)
+func (c *PayPalClient) StoreCreditCard(ctx context.Context, cc CreditCard, opts ...RequestOption) (*CreditCard, error) {
+	req, err := c.NewRequest(ctx, "POST", fmt.Sprintf("%s%s", c.APIBase, "/v1/vault/credit-cards"), cc, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -754,8 +930,8 @@ func (c *PayPalClient) StoreCreditCard(ctx context.Context, cc CreditCard) (*Cre
 
 // DeleteCreditCard function.
 // Endpoint: DELETE /v1/vault/credit-cards/credit_card_id
-func (c *PayPalClient) DeleteCreditCard(ctx context.Context, id string) error {
-	req, err := c.NewRequest(ctx, "DELETE", fmt.Sprintf("%s/v1/vault/credit-cards/%s", c.APIBase, id), nil)
+func (c *PayPalClient) DeleteCreditCard(ctx context.Context, id string, opts ...RequestOption) error {
+	req, err := c.NewRequest(ctx, "DELETE", fmt.Sprintf("%s/v1/vault/credit-cards/%s", c.APIBase, id), nil, opts...)
 	if err != nil {
 		return err
 	}
@@ -769,8 +945,8 @@ func (c *PayPalClient) DeleteCreditCard(ctx context.Context, id string) error {
 
 // GetCreditCard function.
 // Endpoint: GET /v1/vault/credit-cards/credit_card_id
-func (c *PayPalClient) GetCreditCard(ctx context.Context, id string) (*CreditCard, error) {
-	req, err := c.NewRequest(ctx, "GET", fmt.Sprintf("%s/v1/vault/credit-cards/%s", c.APIBase, id), nil)
+func (c *PayPalClient) GetCreditCard(ctx context.Context, id string, opts ...RequestOption) (*CreditCard, error) {
+	req, err := c.NewRequest(ctx, "GET", fmt.Sprintf("%s/v1/vault/credit-cards/%s", c.APIBase, id), nil, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -786,7 +962,7 @@ func (c *PayPalClient) GetCreditCard(ctx context.Context, id string) (*CreditCar
 
 // GetCreditCards function.
 // Endpoint: GET /v1/vault/credit-cards
-func (c *PayPalClient) GetCreditCards(ctx context.Context, ccf *CreditCardsFilter) (*CreditCards, error) {
+func (c *PayPalClient) GetCreditCards(ctx context.Context, ccf *CreditCardsFilter, opts ...RequestOption) (*CreditCards, error) {
 	page := 1
 	if ccf != nil && ccf.Page > 0 {
 		page = ccf.Page
@@ -796,11 +972,33 @@ func (c *PayPalClient) GetCreditCards(ctx context.Context, ccf *CreditCardsFilte
 		pageSize = ccf.PageSize
 	}
 
-	req, err := c.NewRequest(ctx, "GET", fmt.Sprintf("%s/v1/vault/credit-cards?page=%d&page_size=%d", c.APIBase, page, pageSize), nil)
+	req, err := c.NewRequest(ctx, "GET", fmt.Sprintf("%s/v1/vault/credit-cards?page=%d&page_size=%d", c.APIBase, page, pageSize), nil, opts...)
 	if err != nil {
 		return nil, err
 	}
 
+	if ccf != nil {
+		q := req.URL.Query()
+		for key, value := range map[string]string{
+			"external_customer_id": ccf.ExternalCustomerID,
+			"external_card_id":     ccf.ExternalCardID,
+			"merchant_id":          ccf.MerchantID,
+			"sort_by":              ccf.SortBy,
+			"sort_order":           ccf.SortOrder,
+		} {
+			if value != "" {
+				q.Set(key, value)
+			}
+		}
+		if !ccf.StartTime.IsZero() {
+			q.Set("start_time", ccf.StartTime.UTC().Format(time.RFC3339))
+		}
+		if !ccf.EndTime.IsZero() {
+			q.Set("end_time", ccf.EndTime.UTC().Format(time.RFC3339))
+		}
+		req.URL.RawQuery = q.Encode()
+	}
+
 	response := &CreditCards{}
 
 	if err = c.SendWithAuth(req, response); err != nil {
@@ -812,8 +1010,8 @@ func (c *PayPalClient) GetCreditCards(ctx context.Context, ccf *CreditCardsFilte
 
 // PatchCreditCard function.
 // Endpoint: PATCH /v1/vault/credit-cards/credit_card_id
-func (c *PayPalClient) PatchCreditCard(ctx context.Context, id string, ccf []CreditCardField) (*CreditCard, error) {
-	req, err := c.NewRequest(ctx, "PATCH", fmt.Sprintf("%s/v1/vault/credit-cards/%s", c.APIBase, id), ccf)
+func (c *PayPalClient) PatchCreditCard(ctx context.Context, id string, ccf []CreditCardField, opts ...RequestOption) (*CreditCard, error) {
+	req, err := c.NewRequest(ctx, "PATCH", fmt.Sprintf("%s/v1/vault/credit-cards/%s", c.APIBase, id), ccf, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -829,10 +1027,10 @@ func (c *PayPalClient) PatchCreditCard(ctx context.Context, id string, ccf []Cre
 
 // GetOrder retrieves order by ID
 // Endpoint: GET /v2/checkout/orders/ID
-func (c *PayPalClient) GetOrder(ctx context.Context, orderID string) (*Order, error) {
+func (c *PayPalClient) GetOrder(ctx context.Context, orderID string, opts ...RequestOption) (*Order, error) {
 	order := &Order{}
 
-	req, err := c.NewRequest(ctx, "GET", fmt.Sprintf("%s%s%s", c.APIBase, "/v2/checkout/orders/", orderID), nil)
+	req, err := c.NewRequest(ctx, "GET", fmt.Sprintf("%s%s%s", c.APIBase, "/v2/checkout/orders/", orderID), nil, opts...)
 	if err != nil {
 		return order, err
 	}
@@ -846,23 +1044,28 @@ func (c *PayPalClient) GetOrder(ctx context.Context, orderID string) (*Order, er
 
 // CreateOrder - Use this call to create an order
 // Endpoint: POST /v2/checkout/orders
-func (c *PayPalClient) CreateOrder(ctx context.Context, intent string, purchaseUnits []PurchaseUnitRequest, payer *CreateOrderPayer, appContext *ApplicationContext) (*Order, error) {
-	return c.CreateOrderWithPaypalRequestID(ctx, intent, purchaseUnits, payer, appContext, "")
+func (c *PayPalClient) CreateOrder(ctx context.Context, intent string, purchaseUnits []PurchaseUnitRequest, payer *CreateOrderPayer, appContext *ApplicationContext, opts ...RequestOption) (*Order, error) {
+	return c.CreateOrderWithPaypalRequestID(ctx, intent, purchaseUnits, payer, appContext, "", opts...)
 }
 
 // CreateOrderWithPaypalRequestID - Use this call to create an order with idempotency
 // Endpoint: POST /v2/checkout/orders
-func (c *PayPalClient) CreateOrderWithPaypalRequestID(ctx context.Context, intent string, purchaseUnits []PurchaseUnitRequest, payer *CreateOrderPayer, appContext *ApplicationContext, requestID string) (*Order, error) {
-	type createOrderRequest struct {
-		Intent             string                `json:"intent"`
-		Payer              *CreateOrderPayer     `json:"payer,omitempty"`
-		PurchaseUnits      []PurchaseUnitRequest `json:"purchase_units"`
-		ApplicationContext *ApplicationContext   `json:"application_context,omitempty"`
-	}
+func (c *PayPalClient) CreateOrderWithPaypalRequestID(ctx context.Context, intent string, purchaseUnits []PurchaseUnitRequest, payer *CreateOrderPayer, appContext *ApplicationContext, requestID string, opts ...RequestOption) (*Order, error) {
+	return c.createOrder(ctx, CreateOrderRequest{Intent: intent, PurchaseUnits: purchaseUnits, Payer: payer, ApplicationContext: appContext}, requestID, opts...)
+}
 
+// CreateOrderFromRequest - Use this call to create an order with a payment source (paypal, venmo, card, apple_pay, google_pay)
+// Doc: https://developer.paypal.com/docs/api/orders/v2/#orders_create
+// Endpoint: POST /v2/checkout/orders
+func (c *PayPalClient) CreateOrderFromRequest(ctx context.Context, createOrderRequest CreateOrderRequest, opts ...RequestOption) (*Order, error) {
+	return c.createOrder(ctx, createOrderRequest, "", opts...)
+}
+
+// createOrder sends the create order request, with idempotency when requestID is set
+func (c *PayPalClient) createOrder(ctx context.Context, createOrderRequest CreateOrderRequest, requestID string, opts ...RequestOption) (*Order, error) {
 	order := &Order{}
 
-	req, err := c.NewRequest(ctx, "POST", fmt.Sprintf("%s%s", c.APIBase, "/v2/checkout/orders"), createOrderRequest{Intent: intent, PurchaseUnits: purchaseUnits, Payer: payer, ApplicationContext: appContext})
+	req, err := c.NewRequest(ctx, "POST", fmt.Sprintf("%s%s", c.APIBase, "/v2/checkout/orders"), createOrderRequest, opts...)
 	if err != nil {
 		return order, err
 	}
@@ -878,12 +1081,49 @@ func (c *PayPalClient) CreateOrderWithPaypalRequestID(ctx context.Context, inten
 	return order, nil
 }
 
-// UpdateOrder updates the order by ID
+// UpdateOrder replaces the given purchase units of the order, matched by reference ID, and returns the updated order.
+// opts apply to the PATCH and to the read of the updated order, e.g. WithAuthAssertion or WithHeader,
+// except the idempotency key and the mock response, which only apply to the PATCH.
+// Endpoint: PATCH /v2/checkout/orders/ID
+func (c *PayPalClient) UpdateOrder(ctx context.Context, orderID string, purchaseUnits []PurchaseUnitRequest, opts ...RequestOption) (*Order, error) {
+	patches := make([]Patch, 0, len(purchaseUnits))
+	for _, purchaseUnit := range purchaseUnits {
+		patches = append(patches, Patch{
+			Operation: PatchOperationReplace,
+			Path:      PurchaseUnitPatchPath(purchaseUnit.ReferenceID, ""),
+			Value:     purchaseUnit,
+		})
+	}
+
+	if err := c.UpdateOrderWithPatches(ctx, orderID, patches, opts...); err != nil {
+		return &Order{}, err
+	}
+
+	readOpts := append(opts[:len(opts):len(opts)], withoutHeaders("PayPal-Request-Id", "PayPal-Mock-Response"))
+	return c.GetOrder(ctx, orderID, readOpts...)
+}
+
+// UpdateOrderWithPatches applies JSON Patch operations (add, replace, remove) to the order, e.g. on its intent,
+// or the amount, shipping or invoice_id of a purchase unit. Use PurchaseUnitPatchPath to build purchase unit paths.
+// Doc: https://developer.paypal.com/docs/api/orders/v2/#orders_patch
 // Endpoint: PATCH /v2/checkout/orders/ID
-func (c *PayPalClient) UpdateOrder(ctx context.Context, orderID string, purchaseUnits []PurchaseUnitRequest) (*Order, error) {
+func (c *PayPalClient) UpdateOrderWithPatches(ctx context.Context, orderID string, patches []Patch, opts ...RequestOption) error {
+	req, err := c.NewRequest(ctx, "PATCH", fmt.Sprintf("%s%s%s", c.APIBase, "/v2/checkout/orders/", orderID), patches, opts...)
+	if err != nil {
+		return err
+	}
+
+	return c.SendWithAuth(req, nil)
+}
+
+// ConfirmPaymentSource confirms the payer's payment source, e.g. to continue a buyer-present card flow after 3-D Secure.
+// The card payment source of the returned order carries the 3-D Secure authentication result.
+// Doc: https://developer.paypal.com/docs/api/orders/v2/#orders_confirm
+// Endpoint: POST /v2/checkout/orders/ID/confirm-payment-source
+func (c *PayPalClient) ConfirmPaymentSource(ctx context.Context, orderID string, confirmPaymentSourceRequest ConfirmPaymentSourceRequest, opts ...RequestOption) (*Order, error) {
 	order := &Order{}
 
-	req, err := c.NewRequest(ctx, "PATCH", fmt.Sprintf("%s%s%s", c.APIBase, "/v2/checkout/orders/", orderID), purchaseUnits)
+	req, err := c.NewRequest(ctx, "POST", fmt.Sprintf("%s%s", c.APIBase, "/v2/checkout/orders/"+orderID+"/confirm-payment-source"), confirmPaymentSourceRequest, opts...)
 	if err != nil {
 		return order, err
 	}
@@ -897,10 +1137,10 @@ func (c *PayPalClient) UpdateOrder(ctx context.Context, orderID string, purchase
 
 // AuthorizeOrder - https://developer.paypal.com/docs/api/orders/v2/#orders_authorize
 // Endpoint: POST /v2/checkout/orders/ID/authorize
-func (c *PayPalClient) AuthorizeOrder(ctx context.Context, orderID string, authorizeOrderRequest AuthorizeOrderRequest) (*Authorization, error) {
+func (c *PayPalClient) AuthorizeOrder(ctx context.Context, orderID string, authorizeOrderRequest AuthorizeOrderRequest, opts ...RequestOption) (*Authorization, error) {
 	auth := &Authorization{}
 
-	req, err := c.NewRequest(ctx, "POST", fmt.Sprintf("%s%s", c.APIBase, "/v2/checkout/orders/"+orderID+"/authorize"), authorizeOrderRequest)
+	req, err := c.NewRequest(ctx, "POST", fmt.Sprintf("%s%s", c.APIBase, "/v2/checkout/orders/"+orderID+"/authorize"), authorizeOrderRequest, opts...)
 	if err != nil {
 		return auth, err
 	}
@@ -914,18 +1154,18 @@ func (c *PayPalClient) AuthorizeOrder(ctx context.Context, orderID string, autho
 
 // CaptureOrder - https://developer.paypal.com/docs/api/orders/v2/#orders_capture
 // Endpoint: POST /v2/checkout/orders/ID/capture
-func (c *PayPalClient) CaptureOrder(ctx context.Context, orderID string, captureOrderRequest CaptureOrderRequest) (*CaptureOrderResponse, error) {
-	return c.CaptureOrderWithPaypalRequestId(ctx, orderID, captureOrderRequest, "")
+func (c *PayPalClient) CaptureOrder(ctx context.Context, orderID string, captureOrderRequest CaptureOrderRequest, opts ...RequestOption) (*CaptureOrderResponse, error) {
+	return c.CaptureOrderWithPaypalRequestId(ctx, orderID, captureOrderRequest, "", opts...)
 }
 
 // CaptureOrder with idempotency - https://developer.paypal.com/docs/api/orders/v2/#orders_capture
 // Endpoint: POST /v2/checkout/orders/ID/capture
 // https://developer.paypal.com/docs/api/reference/api-requests/#http-request-headers
-func (c *PayPalClient) CaptureOrderWithPaypalRequestId(ctx context.Context, orderID string, captureOrderRequest CaptureOrderRequest, requestID string) (*CaptureOrderResponse, error) {
+func (c *PayPalClient) CaptureOrderWithPaypalRequestId(ctx context.Context, orderID string, captureOrderRequest CaptureOrderRequest, requestID string, opts ...RequestOption) (*CaptureOrderResponse, error) {
 	capture := &CaptureOrderResponse{}
 
-	c.SetReturnRepresentation()
-	req, err := c.NewRequest(ctx, "POST", fmt.Sprintf("%s%s", c.APIBase, "/v2/checkout/orders/"+orderID+"/capture"), captureOrderRequest)
+	opts = append([]RequestOption{WithReturnRepresentation()}, opts...)
+	req, err := c.NewRequest(ctx, "POST", fmt.Sprintf("%s%s", c.APIBase, "/v2/checkout/orders/"+orderID+"/capture"), captureOrderRequest, opts...)
 	if err != nil {
 		return capture, err
 	}
@@ -935,16 +1175,33 @@ func (c *PayPalClient) CaptureOrderWithPaypalRequestId(ctx context.Context, orde
 	}
 
 	if err = c.SendWithAuth(req, capture); err != nil {
-		return capture, err
+		return capture, newInstrumentDeclinedError(orderID, err)
 	}
 
 	return capture, nil
 }
 
+// RetryOrderApproval returns the URL to redirect the payer to after a capture failed with INSTRUMENT_DECLINED,
+// so they can choose another funding source before the same order is captured again.
+// Doc: https://developer.paypal.com/docs/checkout/standard/customize/handle-funding-failures/
+// Endpoint: GET /v2/checkout/orders/ID
+func (c *PayPalClient) RetryOrderApproval(ctx context.Context, orderID string, opts ...RequestOption) (string, error) {
+	order, err := c.GetOrder(ctx, orderID, opts...)
+	if err != nil {
+		return "", err
+	}
+
+	if approveURL := order.ApproveURL(); approveURL != "" {
+		return approveURL, nil
+	}
+
+	return "", fmt.Errorf("paypal: order %s has no approval link", orderID)
+}
+
 // CreateWebhook - Subscribes your webhook listener to events.
 // Endpoint: POST /v1/notifications/webhooks
-func (c *PayPalClient) CreateWebhook(ctx context.Context, createWebhookRequest *CreateWebhookRequest) (*Webhook, error) {
-	req, err := c.NewRequest(ctx, http.MethodPost, fmt.Sprintf("%s%s", c.APIBase, "/v1/notifications/webhooks"), createWebhookRequest)
+func (c *PayPalClient) CreateWebhook(ctx context.Context, createWebhookRequest *CreateWebhookRequest, opts ...RequestOption) (*Webhook, error) {
+	req, err := c.NewRequest(ctx, http.MethodPost, fmt.Sprintf("%s%s", c.APIBase, "/v1/notifications/webhooks"), createWebhookRequest, opts...)
 	webhook := &Webhook{}
 	if err != nil {
 		return webhook, err
@@ -956,8 +1213,8 @@ func (c *PayPalClient) CreateWebhook(ctx context.Context, createWebhookRequest *
 
 // GetWebhook - Shows details for a webhook, by ID.
 // Endpoint: GET /v1/notifications/webhooks/ID
-func (c *PayPalClient) GetWebhook(ctx context.Context, webhookID string) (*Webhook, error) {
-	req, err := c.NewRequest(ctx, http.MethodGet, fmt.Sprintf("%s%s%s", c.APIBase, "/v1/notifications/webhooks/", webhookID), nil)
+func (c *PayPalClient) GetWebhook(ctx context.Context, webhookID string, opts ...RequestOption) (*Webhook, error) {
+	req, err := c.NewRequest(ctx, http.MethodGet, fmt.Sprintf("%s%s%s", c.APIBase, "/v1/notifications/webhooks/", webhookID), nil, opts...)
 	webhook := &Webhook{}
 	if err != nil {
 		return webhook, err
@@ -969,8 +1226,8 @@ func (c *PayPalClient) GetWebhook(ctx context.Context, webhookID string) (*Webho
 
 // UpdateWebhook - Updates a webhook to replace webhook fields with new values.
 // Endpoint: PATCH /v1/notifications/webhooks/ID
-func (c *PayPalClient) UpdateWebhook(ctx context.Context, webhookID string, fields []WebhookField) (*Webhook, error) {
-	req, err := c.NewRequest(ctx, http.MethodPatch, fmt.Sprintf("%s/v1/notifications/webhooks/%s", c.APIBase, webhookID), fields)
+func (c *PayPalClient) UpdateWebhook(ctx context.Context, webhookID string, fields []WebhookField, opts ...RequestOption) (*Webhook, error) {
+	req, err := c.NewRequest(ctx, http.MethodPatch, fmt.Sprintf("%s/v1/notifications/webhooks/%s", c.APIBase, webhookID), fields, opts...)
 	webhook := &Webhook{}
 	if err != nil {
 		return webhook, err
@@ -982,11 +1239,11 @@ func (c *PayPalClient) UpdateWebhook(ctx context.Context, webhookID string, fiel
 
 // ListWebhooks - Lists webhooks for an app.
 // Endpoint: GET /v1/notifications/webhooks
-func (c *PayPalClient) ListWebhooks(ctx context.Context, anchorType string) (*ListWebhookResponse, error) {
+func (c *PayPalClient) ListWebhooks(ctx context.Context, anchorType string, opts ...RequestOption) (*ListWebhookResponse, error) {
 	if len(anchorType) == 0 {
 		anchorType = AncorTypeApplication
 	}
-	req, err := c.NewRequest(ctx, http.MethodGet, fmt.Sprintf("%s%s", c.APIBase, "/v1/notifications/webhooks"), nil)
+	req, err := c.NewRequest(ctx, http.MethodGet, fmt.Sprintf("%s%s", c.APIBase, "/v1/notifications/webhooks"), nil, opts...)
 	q := req.URL.Query()
 	q.Add("anchor_type", anchorType)
 	req.URL.RawQuery = q.Encode()
@@ -1001,8 +1258,8 @@ func (c *PayPalClient) ListWebhooks(ctx context.Context, anchorType string) (*Li
 
 // DeleteWebhook - Deletes a webhook, by ID.
 // Endpoint: DELETE /v1/notifications/webhooks/ID
-func (c *PayPalClient) DeleteWebhook(ctx context.Context, webhookID string) error {
-	req, err := c.NewRequest(ctx, http.MethodDelete, fmt.Sprintf("%s/v1/notifications/webhooks/%s", c.APIBase, webhookID), nil)
+func (c *PayPalClient) DeleteWebhook(ctx context.Context, webhookID string, opts ...RequestOption) error {
+	req, err := c.NewRequest(ctx, http.MethodDelete, fmt.Sprintf("%s/v1/notifications/webhooks/%s", c.APIBase, webhookID), nil, opts...)
 	if err != nil {
 		return err
 	}
@@ -1013,7 +1270,7 @@ func (c *PayPalClient) DeleteWebhook(ctx context.Context, webhookID string) erro
 
 // VerifyWebhookSignature - Use this to verify the signature of a webhook recieved from paypal.
 // Endpoint: POST /v1/notifications/verify-webhook-signature
-func (c *PayPalClient) VerifyWebhookSignature(ctx context.Context, httpReq *http.Request, webhookID string) (*VerifyWebhookResponse, error) {
+func (c *PayPalClient) VerifyWebhookSignature(ctx context.Context, httpReq *http.Request, webhookID string, opts ...RequestOption) (*VerifyWebhookResponse, error) {
 	type verifyWebhookSignatureRequest struct {
 		AuthAlgo         string          `json:"auth_algo,omitempty"`
 		CertURL          string          `json:"cert_url,omitempty"`
@@ -1044,7 +1301,7 @@ func (c *PayPalClient) VerifyWebhookSignature(ctx context.Context, httpReq *http
 
 	response := &VerifyWebhookResponse{}
 
-	req, err := c.NewRequest(ctx, "POST", fmt.Sprintf("%s%s", c.APIBase, "/v1/notifications/verify-webhook-signature"), verifyRequest)
+	req, err := c.NewRequest(ctx, "POST", fmt.Sprintf("%s%s", c.APIBase, "/v1/notifications/verify-webhook-signature"), verifyRequest, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -1058,8 +1315,8 @@ func (c *PayPalClient) VerifyWebhookSignature(ctx context.Context, httpReq *http
 
 // GetWebhookEventTypes - Lists all webhook event types.
 // Endpoint: GET /v1/notifications/webhooks-event-types
-func (c *PayPalClient) GetWebhookEventTypes(ctx context.Context) (*WebhookEventTypesResponse, error) {
-	req, err := c.NewRequest(ctx, http.MethodGet, fmt.Sprintf("%s%s", c.APIBase, "/v1/notifications/webhooks-event-types"), nil)
+func (c *PayPalClient) GetWebhookEventTypes(ctx context.Context, opts ...RequestOption) (*WebhookEventTypesResponse, error) {
+	req, err := c.NewRequest(ctx, http.MethodGet, fmt.Sprintf("%s%s", c.APIBase, "/v1/notifications/webhooks-event-types"), nil, opts...)
 	q := req.URL.Query()
 
 	req.URL.RawQuery = q.Encode()
@@ -1075,8 +1332,8 @@ func (c *PayPalClient) GetWebhookEventTypes(ctx context.Context) (*WebhookEventT
 // CreateProduct creates a product
 // Doc: https://developer.paypal.com/docs/api/catalog-products/v1/#products_create
 // Endpoint: POST /v1/catalogs/products
-func (c *PayPalClient) CreateProduct(ctx context.Context, product Product) (*CreateProductResponse, error) {
-	req, err := c.NewRequest(ctx, http.MethodPost, fmt.Sprintf("%s%s", c.APIBase, "/v1/catalogs/products"), product)
+func (c *PayPalClient) CreateProduct(ctx context.Context, product Product, opts ...RequestOption) (*CreateProductResponse, error) {
+	req, err := c.NewRequest(ctx, http.MethodPost, fmt.Sprintf("%s%s", c.APIBase, "/v1/catalogs/products"), product, opts...)
 	response := &CreateProductResponse{}
 	if err != nil {
 		return response, err
@@ -1088,8 +1345,8 @@ func (c *PayPalClient) CreateProduct(ctx context.Context, product Product) (*Cre
 // UpdateProduct. updates a product information
 // Doc: https://developer.paypal.com/docs/api/catalog-products/v1/#products_patch
 // Endpoint: PATCH /v1/catalogs/products/:product_id
-func (c *PayPalClient) UpdateProduct(ctx context.Context, product Product) error {
-	req, err := c.NewRequest(ctx, http.MethodPatch, fmt.Sprintf("%s%s%s", c.APIBase, "/v1/catalogs/products/", product.ID), product.GetUpdatePatch())
+func (c *PayPalClient) UpdateProduct(ctx context.Context, product Product, opts ...RequestOption) error {
+	req, err := c.NewRequest(ctx, http.MethodPatch, fmt.Sprintf("%s%s%s", c.APIBase, "/v1/catalogs/products/", product.ID), product.GetUpdatePatch(), opts...)
 	if err != nil {
 		return err
 	}
@@ -1100,8 +1357,8 @@ func (c *PayPalClient) UpdateProduct(ctx context.Context, product Product) error
 // Get product details
 // Doc: https://developer.paypal.com/docs/api/catalog-products/v1/#products_get
 // Endpoint: GET /v1/catalogs/products/:product_id
-func (c *PayPalClient) GetProduct(ctx context.Context, productId string) (*Product, error) {
-	req, err := c.NewRequest(ctx, http.MethodGet, fmt.Sprintf("%s%s%s", c.APIBase, "/v1/catalogs/products/", productId), nil)
+func (c *PayPalClient) GetProduct(ctx context.Context, productId string, opts ...RequestOption) (*Product, error) {
+	req, err := c.NewRequest(ctx, http.MethodGet, fmt.Sprintf("%s%s%s", c.APIBase, "/v1/catalogs/products/", productId), nil, opts...)
 	response := &Product{}
 	if err != nil {
 		return response, err
@@ -1113,8 +1370,8 @@ func (c *PayPalClient) GetProduct(ctx context.Context, productId string) (*Produ
 // List all products
 // Doc: https://developer.paypal.com/docs/api/catalog-products/v1/#products_list
 // Endpoint: GET /v1/catalogs/products
-func (c *PayPalClient) ListProducts(ctx context.Context, params *ProductListParameters) (*ListProductsResponse, error) {
-	req, err := c.NewRequest(ctx, http.MethodGet, fmt.Sprintf("%s%s", c.APIBase, "/v1/catalogs/products"), nil)
+func (c *PayPalClient) ListProducts(ctx context.Context, params *ProductListParameters, opts ...RequestOption) (*ListProductsResponse, error) {
+	req, err := c.NewRequest(ctx, http.MethodGet, fmt.Sprintf("%s%s", c.APIBase, "/v1/catalogs/products"), nil, opts...)
 	response := &ListProductsResponse{}
 	if err != nil {
 		return response, err
@@ -1135,8 +1392,8 @@ func (c *PayPalClient) ListProducts(ctx context.Context, params *ProductListPara
 // CreateSubscriptionPlan creates a subscriptionPlan
 // Doc: https://developer.paypal.com/docs/api/subscriptions/v1/#plans_create
 // Endpoint: POST /v1/billing/plans
-func (c *PayPalClient) CreateSubscriptionPlan(ctx context.Context, newPlan SubscriptionPlan) (*CreateSubscriptionPlanResponse, error) {
-	req, err := c.NewRequest(ctx, http.MethodPost, fmt.Sprintf("%s%s", c.APIBase, "/v1/billing/plans"), newPlan)
+func (c *PayPalClient) CreateSubscriptionPlan(ctx context.Context, newPlan SubscriptionPlan, opts ...RequestOption) (*CreateSubscriptionPlanResponse, error) {
+	req, err := c.NewRequest(ctx, http.MethodPost, fmt.Sprintf("%s%s", c.APIBase, "/v1/billing/plans"), newPlan, opts...)
 	response := &CreateSubscriptionPlanResponse{}
 	if err != nil {
 		return response, err
@@ -1148,8 +1405,8 @@ func (c *PayPalClient) CreateSubscriptionPlan(ctx context.Context, newPlan Subsc
 // UpdateSubscriptionPlan. updates a plan
 // Doc: https://developer.paypal.com/docs/api/subscriptions/v1/#plans_patch
 // Endpoint: PATCH /v1/billing/plans/:plan_id
-func (c *PayPalClient) UpdateSubscriptionPlan(ctx context.Context, updatedPlan SubscriptionPlan) error {
-	req, err := c.NewRequest(ctx, http.MethodPatch, fmt.Sprintf("%s%s%s", c.APIBase, "/v1/billing/plans/", updatedPlan.ID), updatedPlan.GetUpdatePatch())
+func (c *PayPalClient) UpdateSubscriptionPlan(ctx context.Context, updatedPlan SubscriptionPlan, opts ...RequestOption) error {
+	req, err := c.NewRequest(ctx, http.MethodPatch, fmt.Sprintf("%s%s%s", c.APIBase, "/v1/billing/plans/", updatedPlan.ID), updatedPlan.GetUpdatePatch(), opts...)
 	if err != nil {
 		return err
 	}
@@ -1160,8 +1417,8 @@ func (c *PayPalClient) UpdateSubscriptionPlan(ctx context.Context, updatedPlan S
 // UpdateSubscriptionPlan. updates a plan
 // Doc: https://developer.paypal.com/docs/api/subscriptions/v1/#plans_get
 // Endpoint: GET /v1/billing/plans/:plan_id
-func (c *PayPalClient) GetSubscriptionPlan(ctx context.Context, planId string) (*SubscriptionPlan, error) {
-	req, err := c.NewRequest(ctx, http.MethodGet, fmt.Sprintf("%s%s%s", c.APIBase, "/v1/billing/plans/", planId), nil)
+func (c *PayPalClient) GetSubscriptionPlan(ctx context.Context, planId string, opts ...RequestOption) (*SubscriptionPlan, error) {
+	req, err := c.NewRequest(ctx, http.MethodGet, fmt.Sprintf("%s%s%s", c.APIBase, "/v1/billing/plans/", planId), nil, opts...)
 	response := &SubscriptionPlan{}
 	if err != nil {
 		return response, err
@@ -1173,8 +1430,8 @@ func (c *PayPalClient) GetSubscriptionPlan(ctx context.Context, planId string) (
 // List all plans
 // Doc: https://developer.paypal.com/docs/api/subscriptions/v1/#plans_list
 // Endpoint: GET /v1/billing/plans
-func (c *PayPalClient) ListSubscriptionPlans(ctx context.Context, params *SubscriptionPlanListParameters) (*ListSubscriptionPlansResponse, error) {
-	req, err := c.NewRequest(ctx, http.MethodGet, fmt.Sprintf("%s%s", c.APIBase, "/v1/billing/plans"), nil)
+func (c *PayPalClient) ListSubscriptionPlans(ctx context.Context, params *SubscriptionPlanListParameters, opts ...RequestOption) (*ListSubscriptionPlansResponse, error) {
+	req, err := c.NewRequest(ctx, http.MethodGet, fmt.Sprintf("%s%s", c.APIBase, "/v1/billing/plans"), nil, opts...)
 	response := &ListSubscriptionPlansResponse{}
 	if err != nil {
 		return response, err
@@ -1197,8 +1454,8 @@ func (c *PayPalClient) ListSubscriptionPlans(ctx context.Context, params *Subscr
 // Activates a plan
 // Doc: https://developer.paypal.com/docs/api/subscriptions/v1/#plans_activate
 // Endpoint: POST /v1/billing/plans/{id}/activate
-func (c *PayPalClient) ActivateSubscriptionPlan(ctx context.Context, planId string) error {
-	req, err := c.NewRequest(ctx, http.MethodPost, fmt.Sprintf("%s/v1/billing/plans/%s/activate", c.APIBase, planId), nil)
+func (c *PayPalClient) ActivateSubscriptionPlan(ctx context.Context, planId string, opts ...RequestOption) error {
+	req, err := c.NewRequest(ctx, http.MethodPost, fmt.Sprintf("%s/v1/billing/plans/%s/activate", c.APIBase, planId), nil, opts...)
 	if err != nil {
 		return err
 	}
@@ -1210,8 +1467,8 @@ func (c *PayPalClient) ActivateSubscriptionPlan(ctx context.Context, planId stri
 // Deactivates a plan
 // Doc: https://developer.paypal.com/docs/api/subscriptions/v1/#plans_deactivate
 // Endpoint: POST /v1/billing/plans/{id}/deactivate
-func (c *PayPalClient) DeactivateSubscriptionPlans(ctx context.Context, planId string) error {
-	req, err := c.NewRequest(ctx, http.MethodPost, fmt.Sprintf("%s/v1/billing/plans/%s/deactivate", c.APIBase, planId), nil)
+func (c *PayPalClient) DeactivateSubscriptionPlans(ctx context.Context, planId string, opts ...RequestOption) error {
+	req, err := c.NewRequest(ctx, http.MethodPost, fmt.Sprintf("%s/v1/billing/plans/%s/deactivate", c.APIBase, planId), nil, opts...)
 	if err != nil {
 		return err
 	}
@@ -1223,10 +1480,10 @@ func (c *PayPalClient) DeactivateSubscriptionPlans(ctx context.Context, planId s
 // Updates pricing for a plan. For example, you can update a regular billing cycle from $5 per month to $7 per month.
 // Doc: https://developer.paypal.com/docs/api/subscriptions/v1/#plans_update-pricing-schemes
 // Endpoint: POST /v1/billing/plans/{id}/update-pricing-schemes
-func (c *PayPalClient) UpdateSubscriptionPlanPricing(ctx context.Context, planId string, pricingSchemes []PricingSchemeUpdate) error {
+func (c *PayPalClient) UpdateSubscriptionPlanPricing(ctx context.Context, planId string, pricingSchemes []PricingSchemeUpdate, opts ...RequestOption) error {
 	req, err := c.NewRequest(ctx, http.MethodPost, fmt.Sprintf("%s/v1/billing/plans/%s/update-pricing-schemes", c.APIBase, planId), PricingSchemeUpdateRequest{
 		Schemes: pricingSchemes,
-	})
+	}, opts...)
 	if err != nil {
 		return err
 	}
@@ -1238,8 +1495,8 @@ func (c *PayPalClient) UpdateSubscriptionPlanPricing(ctx context.Context, planId
 // CreateSubscriptionPlan creates a subscriptionPlan
 // Doc: https://developer.paypal.com/docs/api/subscriptions/v1/#subscriptions_create
 // Endpoint: POST /v1/billing/subscriptions
-func (c *PayPalClient) CreateSubscription(ctx context.Context, newSubscription SubscriptionBase) (*SubscriptionDetailResp, error) {
-	req, err := c.NewRequest(ctx, http.MethodPost, fmt.Sprintf("%s%s", c.APIBase, "/v1/billing/subscriptions"), newSubscription)
+func (c *PayPalClient) CreateSubscription(ctx context.Context, newSubscription SubscriptionBase, opts ...RequestOption) (*SubscriptionDetailResp, error) {
+	req, err := c.NewRequest(ctx, http.MethodPost, fmt.Sprintf("%s%s", c.APIBase, "/v1/billing/subscriptions"), newSubscription, opts...)
 	req.Header.Add("Prefer", "return=representation")
 	response := &SubscriptionDetailResp{}
 	if err != nil {
@@ -1252,8 +1509,15 @@ func (c *PayPalClient) CreateSubscription(ctx context.Context, newSubscription S
 // UpdateSubscriptionPlan. updates a plan
 // Doc: https://developer.paypal.com/docs/api/subscriptions/v1/#subscriptions_patch
 // Endpoint: PATCH /v1/billing/subscriptions/:subscription_id
-func (c *PayPalClient) UpdateSubscription(ctx context.Context, updatedSubscription Subscription) error {
-	req, err := c.NewRequest(ctx, http.MethodPatch, fmt.Sprintf("%s%s%s", c.APIBase, "/v1/billing/subscriptions/", updatedSubscription.ID), updatedSubscription.GetUpdatePatch())
+func (c *PayPalClient) UpdateSubscription(ctx context.Context, updatedSubscription Subscription, opts ...RequestOption) error {
+	return c.PatchSubscription(ctx, updatedSubscription.ID, updatedSubscription.GetUpdatePatch(), opts...)
+}
+
+// PatchSubscription applies patches to a subscription, see the Subscription*Patch helpers.
+// Doc: https://developer.paypal.com/docs/api/subscriptions/v1/#subscriptions_patch
+// Endpoint: PATCH /v1/billing/subscriptions/:subscription_id
+func (c *PayPalClient) PatchSubscription(ctx context.Context, subscriptionID string, patches []Patch, opts ...RequestOption) error {
+	req, err := c.NewRequest(ctx, http.MethodPatch, fmt.Sprintf("%s%s%s", c.APIBase, "/v1/billing/subscriptions/", subscriptionID), patches, opts...)
 	if err != nil {
 		return err
 	}
@@ -1263,12 +1527,14 @@ func (c *PayPalClient) UpdateSubscription(ctx context.Context, updatedSubscripti
 
 // GetSubscriptionDetails shows details for a subscription, by ID.
 // Endpoint: GET /v1/billing/subscriptions/
-func (c *PayPalClient) GetSubscriptionDetails(ctx context.Context, subscriptionID string) (*SubscriptionDetailResp, error) {
+func (c *PayPalClient) GetSubscriptionDetails(ctx context.Context, subscriptionID string, opts ...RequestOption) (*SubscriptionDetailResp, error) {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/v1/billing/subscriptions/%s", c.APIBase, subscriptionID), nil)
 	response := &SubscriptionDetailResp{}
 	if err != nil {
 		return response, err
 	}
+
+	c.applyRequestOptions(req, opts)
 	err = c.SendWithAuth(req, response)
 	return response, err
 }
@@ -1276,8 +1542,8 @@ func (c *PayPalClient) GetSubscriptionDetails(ctx context.Context, subscriptionI
 // Activates the subscription.
 // Doc: https://developer.paypal.com/docs/api/subscriptions/v1/#subscriptions_activate
 // Endpoint: POST /v1/billing/subscriptions/{id}/activate
-func (c *PayPalClient) ActivateSubscription(ctx context.Context, subscriptionId, activateReason string) error {
-	req, err := c.NewRequest(ctx, http.MethodPost, fmt.Sprintf("%s/v1/billing/subscriptions/%s/activate", c.APIBase, subscriptionId), map[string]string{"reason": activateReason})
+func (c *PayPalClient) ActivateSubscription(ctx context.Context, subscriptionId, activateReason string, opts ...RequestOption) error {
+	req, err := c.NewRequest(ctx, http.MethodPost, fmt.Sprintf("%s/v1/billing/subscriptions/%s/activate", c.APIBase, subscriptionId), map[string]string{"reason": activateReason}, opts...)
 	if err != nil {
 		return err
 	}
@@ -1288,8 +1554,8 @@ func (c *PayPalClient) ActivateSubscription(ctx context.Context, subscriptionId,
 // Cancels the subscription.
 // Doc: https://developer.paypal.com/docs/api/subscriptions/v1/#subscriptions_cancel
 // Endpoint: POST /v1/billing/subscriptions/{id}/cancel
-func (c *PayPalClient) CancelSubscription(ctx context.Context, subscriptionId, cancelReason string) error {
-	req, err := c.NewRequest(ctx, http.MethodPost, fmt.Sprintf("%s/v1/billing/subscriptions/%s/cancel", c.APIBase, subscriptionId), map[string]string{"reason": cancelReason})
+func (c *PayPalClient) CancelSubscription(ctx context.Context, subscriptionId, cancelReason string, opts ...RequestOption) error {
+	req, err := c.NewRequest(ctx, http.MethodPost, fmt.Sprintf("%s/v1/billing/subscriptions/%s/cancel", c.APIBase, subscriptionId), map[string]string{"reason": cancelReason}, opts...)
 	if err != nil {
 		return err
 	}
@@ -1300,8 +1566,8 @@ func (c *PayPalClient) CancelSubscription(ctx context.Context, subscriptionId, c
 // Captures an authorized payment from the subscriber on the subscription.
 // Doc: https://developer.paypal.com/docs/api/subscriptions/v1/#subscriptions_capture
 // Endpoint: POST /v1/billing/subscriptions/{id}/capture
-func (c *PayPalClient) CaptureSubscription(ctx context.Context, subscriptionId string, request CaptureReqeust) (*SubscriptionCaptureResponse, error) {
-	req, err := c.NewRequest(ctx, http.MethodPost, fmt.Sprintf("%s/v1/billing/subscriptions/%s/capture", c.APIBase, subscriptionId), request)
+func (c *PayPalClient) CaptureSubscription(ctx context.Context, subscriptionId string, request CaptureReqeust, opts ...RequestOption) (*SubscriptionCaptureResponse, error) {
+	req, err := c.NewRequest(ctx, http.MethodPost, fmt.Sprintf("%s/v1/billing/subscriptions/%s/capture", c.APIBase, subscriptionId), request, opts...)
 	response := &SubscriptionCaptureResponse{}
 	if err != nil {
 		return response, err
@@ -1313,8 +1579,8 @@ func (c *PayPalClient) CaptureSubscription(ctx context.Context, subscriptionId s
 // Suspends the subscription.
 // Doc: https://developer.paypal.com/docs/api/subscriptions/v1/#subscriptions_suspend
 // Endpoint: POST /v1/billing/subscriptions/{id}/suspend
-func (c *PayPalClient) SuspendSubscription(ctx context.Context, subscriptionId, reason string) error {
-	req, err := c.NewRequest(ctx, http.MethodPost, fmt.Sprintf("%s/v1/billing/subscriptions/%s/suspend", c.APIBase, subscriptionId), map[string]string{"reason": reason})
+func (c *PayPalClient) SuspendSubscription(ctx context.Context, subscriptionId, reason string, opts ...RequestOption) error {
+	req, err := c.NewRequest(ctx, http.MethodPost, fmt.Sprintf("%s/v1/billing/subscriptions/%s/suspend", c.APIBase, subscriptionId), map[string]string{"reason": reason}, opts...)
 	if err != nil {
 		return err
 	}
@@ -1325,7 +1591,7 @@ func (c *PayPalClient) SuspendSubscription(ctx context.Context, subscriptionId,
 // Lists transactions for a subscription.
 // Doc: https://developer.paypal.com/docs/api/subscriptions/v1/#subscriptions_transactions
 // Endpoint: GET /v1/billing/subscriptions/{id}/transactions
-func (c *PayPalClient) GetSubscriptionTransactions(ctx context.Context, requestParams SubscriptionTransactionsParams) (*SubscriptionTransactionsResponse, error) {
+func (c *PayPalClient) GetSubscriptionTransactions(ctx context.Context, requestParams SubscriptionTransactionsParams, opts ...RequestOption) (*SubscriptionTransactionsResponse, error) {
 	startTime := requestParams.StartTime.Format("2006-01-02T15:04:05Z")
 	endTime := requestParams.EndTime.Format("2006-01-02T15:04:05Z")
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/v1/billing/subscriptions/%s/transactions?start_time=%s&end_time=%s", c.APIBase, requestParams.SubscriptionId, startTime, endTime), nil)
@@ -1334,6 +1600,8 @@ func (c *PayPalClient) GetSubscriptionTransactions(ctx context.Context, requestP
 		return response, err
 	}
 
+	c.applyRequestOptions(req, opts)
+
 	err = c.SendWithAuth(req, response)
 	return response, err
 }
@@ -1341,8 +1609,8 @@ func (c *PayPalClient) GetSubscriptionTransactions(ctx context.Context, requestP
 // Revise plan or quantity of subscription
 // Doc: https://developer.paypal.com/docs/api/subscriptions/v1/#subscriptions_revise
 // Endpoint: POST /v1/billing/subscriptions/{id}/revise
-func (c *PayPalClient) ReviseSubscription(ctx context.Context, subscriptionId string, reviseSubscription SubscriptionBase) (*SubscriptionDetailResp, error) {
-	req, err := c.NewRequest(ctx, http.MethodPost, fmt.Sprintf("%s/v1/billing/subscriptions/%s/revise", c.APIBase, subscriptionId), reviseSubscription)
+func (c *PayPalClient) ReviseSubscription(ctx context.Context, subscriptionId string, reviseSubscription SubscriptionBase, opts ...RequestOption) (*SubscriptionDetailResp, error) {
+	req, err := c.NewRequest(ctx, http.MethodPost, fmt.Sprintf("%s/v1/billing/subscriptions/%s/revise", c.APIBase, subscriptionId), reviseSubscription, opts...)
 	response := &SubscriptionDetailResp{}
 	if err != nil {
 		return response, err
@@ -1357,13 +1625,13 @@ func (c *PayPalClient) ReviseSubscription(ctx context.Context, subscriptionId st
 // CreatePaypalBillingAgreementToken - Use this call to create a billing agreement token
 // Endpoint: POST /v1/billing-agreements/agreement-tokens
 // Deprecated: use CreateBillingAgreementToken instead
-func (c *PayPalClient) CreatePaypalBillingAgreementToken(ctx context.Context, description *string, shippingAddress *ShippingAddress, payer *Payer, plan *BillingPlan) (*BillingAgreementToken, error) {
-	return c.CreateBillingAgreementToken(ctx, description, shippingAddress, payer, plan)
+func (c *PayPalClient) CreatePaypalBillingAgreementToken(ctx context.Context, description *string, shippingAddress *ShippingAddress, payer *Payer, plan *BillingPlan, opts ...RequestOption) (*BillingAgreementToken, error) {
+	return c.CreateBillingAgreementToken(ctx, description, shippingAddress, payer, plan, opts...)
 }
 
 // CreateBillingAgreementToken - Use this call to create a billing agreement token
 // Endpoint: POST /v1/billing-agreements/agreement-tokens
-func (c *PayPalClient) CreateBillingAgreementToken(ctx context.Context, description *string, shippingAddress *ShippingAddress, payer *Payer, plan *BillingPlan) (*BillingAgreementToken, error) {
+func (c *PayPalClient) CreateBillingAgreementToken(ctx context.Context, description *string, shippingAddress *ShippingAddress, payer *Payer, plan *BillingPlan, opts ...RequestOption) (*BillingAgreementToken, error) {
 	type createBARequest struct {
 		Description     *string          `json:"description,omitempty"`
 		ShippingAddress *ShippingAddress `json:"shipping_address,omitempty"`
@@ -1377,7 +1645,8 @@ func (c *PayPalClient) CreateBillingAgreementToken(ctx context.Context, descript
 		ctx,
 		"POST",
 		fmt.Sprintf("%s%s", c.APIBase, "/v1/billing-agreements/agreement-tokens"),
-		createBARequest{Description: description, ShippingAddress: shippingAddress, Payer: payer, Plan: plan})
+		createBARequest{Description: description, ShippingAddress: shippingAddress, Payer: payer, Plan: plan},
+		opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -1392,13 +1661,13 @@ func (c *PayPalClient) CreateBillingAgreementToken(ctx context.Context, descript
 // CreatePaypalBillingAgreementFromToken - Use this call to create a billing agreement
 // Endpoint: POST /v1/billing-agreements/agreements
 // Deprecated: use CreateBillingAgreementFromToken instead
-func (c *PayPalClient) CreatePaypalBillingAgreementFromToken(ctx context.Context, tokenID string) (*BillingAgreementFromToken, error) {
-	return c.CreateBillingAgreementFromToken(ctx, tokenID)
+func (c *PayPalClient) CreatePaypalBillingAgreementFromToken(ctx context.Context, tokenID string, opts ...RequestOption) (*BillingAgreementFromToken, error) {
+	return c.CreateBillingAgreementFromToken(ctx, tokenID, opts...)
 }
 
 // CreateBillingAgreementFromToken - Use this call to create a billing agreement
 // Endpoint: POST /v1/billing-agreements/agreements
-func (c *PayPalClient) CreateBillingAgreementFromToken(ctx context.Context, tokenID string) (*BillingAgreementFromToken, error) {
+func (c *PayPalClient) CreateBillingAgreementFromToken(ctx context.Context, tokenID string, opts ...RequestOption) (*BillingAgreementFromToken, error) {
 	type createBARequest struct {
 		TokenID string `json:"token_id"`
 	}
@@ -1409,7 +1678,8 @@ func (c *PayPalClient) CreateBillingAgreementFromToken(ctx context.Context, toke
 		ctx,
 		"POST",
 		fmt.Sprintf("%s%s", c.APIBase, "/v1/billing-agreements/agreements"),
-		createBARequest{TokenID: tokenID})
+		createBARequest{TokenID: tokenID},
+		opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -1423,14 +1693,15 @@ func (c *PayPalClient) CreateBillingAgreementFromToken(ctx context.Context, toke
 
 // CancelBillingAgreement - Use this call to cancel a billing agreement
 // Endpoint: POST /v1/billing-agreements/agreements/{agreement_id}/cancel
-func (c *PayPalClient) CancelBillingAgreement(ctx context.Context, billingAgreementID string) error {
+func (c *PayPalClient) CancelBillingAgreement(ctx context.Context, billingAgreementID string, opts ...RequestOption) error {
 	type cancelBARequest struct{}
 
 	req, err := c.NewRequest(
 		ctx,
 		"POST",
 		fmt.Sprintf("%s%s%s%s", c.APIBase, "/v1/billing-agreements/agreements/", billingAgreementID, "/cancel"),
-		cancelBARequest{})
+		cancelBARequest{},
+		opts...)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,193 @@
+package payment
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// 2Checkout payment types
+const (
+	TwoCheckoutPaymentTypeToken = "EES_TOKEN_PAYMENT"
+	TwoCheckoutPaymentTypeTest  = "TEST"
+)
+
+// 2Checkout order statuses
+const (
+	TwoCheckoutOrderStatusPending         = "PENDING"
+	TwoCheckoutOrderStatusAuthReceived    = "AUTHRECEIVED"
+	TwoCheckoutOrderStatusComplete        = "COMPLETE"
+	TwoCheckoutOrderStatusCanceled        = "CANCELED"
+	TwoCheckoutOrderStatusRefund          = "REFUND"
+	TwoCheckoutOrderStatusReversed        = "REVERSED"
+	TwoCheckoutOrderStatusPurchasePending = "PURCHASE_PENDING"
+)
+
+// 2Checkout INS message types
+const (
+	TwoCheckoutINSOrderCreated         = "ORDER_CREATED"
+	TwoCheckoutINSFraudStatusChanged   = "FRAUD_STATUS_CHANGED"
+	TwoCheckoutINSInvoiceStatusChanged = "INVOICE_STATUS_CHANGED"
+	TwoCheckoutINSRefundIssued         = "REFUND_ISSUED"
+	TwoCheckoutINSRecurringInstallment = "RECURRING_INSTALLMENT_SUCCESS"
+	TwoCheckoutINSRecurringFailed      = "RECURRING_INSTALLMENT_FAILED"
+	TwoCheckoutINSRecurringStopped     = "RECURRING_STOPPED"
+	TwoCheckoutINSRecurringComplete    = "RECURRING_COMPLETE"
+	TwoCheckoutINSRecurringRestarted   = "RECURRING_RESTARTED"
+)
+
+// TwoCheckoutError is returned for a non 2xx response
+type TwoCheckoutError struct {
+	Response  *http.Response `json:"-"`
+	ErrorCode string         `json:"error_code"`
+	Message   string         `json:"message"`
+}
+
+func (e *TwoCheckoutError) Error() string {
+	return fmt.Sprintf("%v %v: %d %s: %s", e.Response.Request.Method, e.Response.Request.URL, e.Response.StatusCode, e.ErrorCode, e.Message)
+}
+
+// Is reports whether the error belongs to the target error category
+func (e *TwoCheckoutError) Is(target error) bool {
+	return statusErrorIs(e.Response.StatusCode, target)
+}
+
+// TwoCheckoutPrice is the price of a dynamic product
+type TwoCheckoutPrice struct {
+	Amount float64 `json:"Amount"`
+	Type   string  `json:"Type,omitempty"` // CUSTOM
+}
+
+// TwoCheckoutRecurringOptions is the billing cycle of a dynamic product
+type TwoCheckoutRecurringOptions struct {
+	CycleLength    int     `json:"CycleLength"`
+	CycleUnit      string  `json:"CycleUnit"` // DAY, WEEK, MONTH or YEAR
+	CycleAmount    float64 `json:"CycleAmount"`
+	ContractLength int     `json:"ContractLength"`
+	ContractUnit   string  `json:"ContractUnit"`
+}
+
+// TwoCheckoutItem is a catalog product, set by Code, or a dynamic product when IsDynamic is set
+type TwoCheckoutItem struct {
+	Code             string                       `json:"Code,omitempty"`
+	Quantity         int                          `json:"Quantity"`
+	IsDynamic        bool                         `json:"IsDynamic,omitempty"`
+	Name             string                       `json:"Name,omitempty"`
+	Tangible         bool                         `json:"Tangible,omitempty"`
+	Price            *TwoCheckoutPrice            `json:"Price,omitempty"`
+	RecurringOptions *TwoCheckoutRecurringOptions `json:"RecurringOptions,omitempty"`
+}
+
+// TwoCheckoutBillingDetails struct
+type TwoCheckoutBillingDetails struct {
+	FirstName   string `json:"FirstName"`
+	LastName    string `json:"LastName"`
+	Email       string `json:"Email"`
+	Address1    string `json:"Address1,omitempty"`
+	City        string `json:"City,omitempty"`
+	State       string `json:"State,omitempty"`
+	Zip         string `json:"Zip,omitempty"`
+	CountryCode string `json:"CountryCode"`
+}
+
+// TwoCheckoutPaymentMethod struct
+type TwoCheckoutPaymentMethod struct {
+	EesToken           string `json:"EesToken,omitempty"`
+	RecurringEnabled   bool   `json:"RecurringEnabled"`
+	Vendor3DSReturnURL string `json:"Vendor3DSReturnURL,omitempty"`
+	Vendor3DSCancelURL string `json:"Vendor3DSCancelURL,omitempty"`
+	Authorize3DS       *struct {
+		Href   string            `json:"Href"`
+		Method string            `json:"Method"`
+		Params map[string]string `json:"Params"`
+	} `json:"Authorize3DS,omitempty"`
+}
+
+// TwoCheckoutPaymentDetails struct
+type TwoCheckoutPaymentDetails struct {
+	Type          string                   `json:"Type"`
+	Currency      string                   `json:"Currency"`
+	CustomerIP    string                   `json:"CustomerIP,omitempty"`
+	PaymentMethod TwoCheckoutPaymentMethod `json:"PaymentMethod"`
+}
+
+// TwoCheckoutOrderRequest struct
+type TwoCheckoutOrderRequest struct {
+	Currency          string                    `json:"Currency"`
+	Country           string                    `json:"Country"`
+	Language          string                    `json:"Language,omitempty"`
+	CustomerIP        string                    `json:"CustomerIP,omitempty"`
+	ExternalReference string                    `json:"ExternalReference,omitempty"`
+	Source            string                    `json:"Source,omitempty"`
+	Items             []TwoCheckoutItem         `json:"Items"`
+	BillingDetails    TwoCheckoutBillingDetails `json:"BillingDetails"`
+	PaymentDetails    TwoCheckoutPaymentDetails `json:"PaymentDetails"`
+}
+
+// TwoCheckoutOrder struct
+type TwoCheckoutOrder struct {
+	RefNo             string  `json:"RefNo"`
+	ExternalReference string  `json:"ExternalReference"`
+	Status            string  `json:"Status"`
+	ApproveStatus     string  `json:"ApproveStatus"`
+	Currency          string  `json:"Currency"`
+	NetPrice          float64 `json:"NetPrice"`
+	GrossPrice        float64 `json:"GrossPrice"`
+	OrderDate         string  `json:"OrderDate"`
+	FinishDate        string  `json:"FinishDate"`
+	Items             []struct {
+		Code           string `json:"Code"`
+		Quantity       int    `json:"Quantity"`
+		ProductDetails struct {
+			Name          string `json:"Name"`
+			Subscriptions []struct {
+				SubscriptionReference string `json:"SubscriptionReference"`
+				SubscriptionStartDate string `json:"SubscriptionStartDate"`
+				ExpirationDate        string `json:"ExpirationDate"`
+			} `json:"Subscriptions"`
+		} `json:"ProductDetails"`
+		Price struct {
+			GrossPrice float64 `json:"GrossPrice"`
+		} `json:"Price"`
+	} `json:"Items"`
+	PaymentDetails TwoCheckoutPaymentDetails `json:"PaymentDetails"`
+}
+
+// TwoCheckoutRefundRequest struct, Amount is the total of the order for a total refund
+type TwoCheckoutRefundRequest struct {
+	Amount  float64 `json:"amount"`
+	Comment string  `json:"comment,omitempty"`
+	Reason  string  `json:"reason,omitempty"`
+}
+
+// TwoCheckoutSubscription struct
+type TwoCheckoutSubscription struct {
+	SubscriptionReference     string  `json:"SubscriptionReference"`
+	Status                    string  `json:"Status"` // ACTIVE, PASTDUE, EXPIRED or DISABLED
+	SubscriptionEnabled       bool    `json:"SubscriptionEnabled"`
+	RecurringEnabled          bool    `json:"RecurringEnabled"`
+	ExternalCustomerReference string  `json:"ExternalCustomerReference"`
+	StartDate                 string  `json:"StartDate"`
+	ExpirationDate            string  `json:"ExpirationDate"`
+	NextRenewalPrice          float64 `json:"NextRenewalPrice"`
+	NextRenewalPriceCurrency  string  `json:"NextRenewalPriceCurrency"`
+	Product                   struct {
+		ProductCode     string `json:"ProductCode"`
+		ProductName     string `json:"ProductName"`
+		ProductQuantity int    `json:"ProductQuantity"`
+	} `json:"Product"`
+	EndUser struct {
+		FirstName string `json:"FirstName"`
+		LastName  string `json:"LastName"`
+		Email     string `json:"Email"`
+	} `json:"EndUser"`
+}
+
+// TwoCheckoutINS is an Instant Notification Service message, Values holds every posted parameter
+type TwoCheckoutINS struct {
+	MessageType string
+	SaleID      string
+	InvoiceID   string
+	VendorID    string
+	Values      url.Values
+}
@@ -0,0 +1,193 @@
+package payment
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// TwoCheckoutAPIBase points to the 2Checkout (Verifone) REST API 6.0, test orders are placed with TwoCheckoutPaymentTypeTest
+	TwoCheckoutAPIBase = "https://api.2checkout.com/rest/6.0"
+)
+
+var (
+	// ErrInvalidTwoCheckoutConfig is returned when a 2Checkout client is created without MerchantCode or SecretKey
+	ErrInvalidTwoCheckoutConfig = errors.New("2checkout: MerchantCode and SecretKey are required to create a client")
+
+	// ErrTwoCheckoutINSSignature is returned by VerifyTwoCheckoutINS when md5_hash does not match
+	ErrTwoCheckoutINSSignature = errors.New("2checkout: invalid INS signature")
+)
+
+// ITwoCheckout is the 2Checkout client interface
+type ITwoCheckout interface {
+	CreateSale(ctx context.Context, order TwoCheckoutOrderRequest) (*TwoCheckoutOrder, error)
+	GetSale(ctx context.Context, refNo string) (*TwoCheckoutOrder, error)
+	RefundSale(ctx context.Context, refNo string, refund TwoCheckoutRefundRequest) error
+	GetSubscription(ctx context.Context, subscriptionReference string) (*TwoCheckoutSubscription, error)
+	EnableSubscription(ctx context.Context, subscriptionReference string) error
+	DisableSubscription(ctx context.Context, subscriptionReference string) error
+	EnableRecurring(ctx context.Context, subscriptionReference string) error
+	DisableRecurring(ctx context.Context, subscriptionReference string) error
+}
+
+// TwoCheckoutClient represents a 2Checkout REST API client
+type TwoCheckoutClient struct {
+	Client       *http.Client
+	MerchantCode string
+	SecretKey    string
+	APIBase      string
+}
+
+// NewTwoCheckoutClient returns a 2Checkout client for config, APIBase defaults to TwoCheckoutAPIBase
+func NewTwoCheckoutClient(config *TwoCheckout) (ITwoCheckout, error) {
+	if config == nil || config.MerchantCode == "" || config.SecretKey == "" {
+		return nil, ErrInvalidTwoCheckoutConfig
+	}
+
+	client := &TwoCheckoutClient{
+		Client:       &http.Client{},
+		MerchantCode: config.MerchantCode,
+		SecretKey:    config.SecretKey,
+		APIBase:      config.APIBase,
+	}
+	if client.APIBase == "" {
+		client.APIBase = TwoCheckoutAPIBase
+	}
+
+	return client, nil
+}
+
+// newTwoCheckout returns a 2Checkout client, or nil when config is invalid
+func newTwoCheckout(config *TwoCheckout) ITwoCheckout {
+	client, err := NewTwoCheckoutClient(config)
+	if err != nil {
+		log.Println("Unable to init 2Checkout client: ", err)
+		return nil
+	}
+
+	return client
+}
+
+// newTwoCheckoutError decodes the error returned by the 2Checkout API
+func newTwoCheckoutError(resp *http.Response, body []byte) error {
+	errResp := &TwoCheckoutError{Response: resp}
+	json.Unmarshal(body, errResp)
+	return errResp
+}
+
+// authentication returns the X-Avangate-Authentication header value at date,
+// the HMAC-SHA256 of the length prefixed merchant code and date
+// Doc: https://verifone.cloud/docs/2checkout/API-Integration/REST_6.0_Reference/Authentication
+func (c *TwoCheckoutClient) authentication(date time.Time) string {
+	requestDate := date.UTC().Format("2006-01-02 15:04:05")
+	mac := hmac.New(sha256.New, []byte(c.SecretKey))
+	mac.Write([]byte(strconv.Itoa(len(c.MerchantCode)) + c.MerchantCode + strconv.Itoa(len(requestDate)) + requestDate))
+	return `code="` + c.MerchantCode + `" date="` + requestDate + `" hash="` + hex.EncodeToString(mac.Sum(nil)) + `" algo="sha256"`
+}
+
+// send makes an authenticated request to the API and decodes the response into v
+func (c *TwoCheckoutClient) send(ctx context.Context, method, path string, payload, v interface{}) error {
+	req, err := newJSONRequest(ctx, method, c.APIBase+path, payload)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Avangate-Authentication", c.authentication(time.Now()))
+
+	return sendJSON(c.Client, req, v, newTwoCheckoutError)
+}
+
+// CreateSale places an order, paid with a 2Pay.js token (TwoCheckoutPaymentTypeToken).
+// Check PaymentDetails.PaymentMethod.Authorize3DS and redirect the buyer when 3D Secure is required
+// Endpoint: POST /orders/
+// Doc: https://verifone.cloud/docs/2checkout/API-Integration/REST_6.0_Reference/Orders
+func (c *TwoCheckoutClient) CreateSale(ctx context.Context, order TwoCheckoutOrderRequest) (*TwoCheckoutOrder, error) {
+	response := &TwoCheckoutOrder{}
+	err := c.send(ctx, http.MethodPost, "/orders/", order, response)
+	return response, err
+}
+
+// GetSale returns an order by its 2Checkout reference
+// Endpoint: GET /orders/{refNo}/
+func (c *TwoCheckoutClient) GetSale(ctx context.Context, refNo string) (*TwoCheckoutOrder, error) {
+	response := &TwoCheckoutOrder{}
+	err := c.send(ctx, http.MethodGet, "/orders/"+url.PathEscape(refNo)+"/", nil, response)
+	return response, err
+}
+
+// RefundSale issues a total or partial refund of a completed order
+// Endpoint: POST /orders/{refNo}/refund/
+func (c *TwoCheckoutClient) RefundSale(ctx context.Context, refNo string, refund TwoCheckoutRefundRequest) error {
+	return c.send(ctx, http.MethodPost, "/orders/"+url.PathEscape(refNo)+"/refund/", refund, nil)
+}
+
+// GetSubscription returns a subscription
+// Endpoint: GET /subscriptions/{subscriptionReference}/
+// Doc: https://verifone.cloud/docs/2checkout/API-Integration/REST_6.0_Reference/Subscriptions
+func (c *TwoCheckoutClient) GetSubscription(ctx context.Context, subscriptionReference string) (*TwoCheckoutSubscription, error) {
+	response := &TwoCheckoutSubscription{}
+	err := c.send(ctx, http.MethodGet, "/subscriptions/"+url.PathEscape(subscriptionReference)+"/", nil, response)
+	return response, err
+}
+
+// EnableSubscription re-enables a disabled subscription
+// Endpoint: POST /subscriptions/{subscriptionReference}/
+func (c *TwoCheckoutClient) EnableSubscription(ctx context.Context, subscriptionReference string) error {
+	return c.send(ctx, http.MethodPost, "/subscriptions/"+url.PathEscape(subscriptionReference)+"/", nil, nil)
+}
+
+// DisableSubscription cancels a subscription immediately
+// Endpoint: DELETE /subscriptions/{subscriptionReference}/
+func (c *TwoCheckoutClient) DisableSubscription(ctx context.Context, subscriptionReference string) error {
+	return c.send(ctx, http.MethodDelete, "/subscriptions/"+url.PathEscape(subscriptionReference)+"/", nil, nil)
+}
+
+// EnableRecurring turns on the automatic renewal of a subscription
+// Endpoint: POST /subscriptions/{subscriptionReference}/renewal/
+func (c *TwoCheckoutClient) EnableRecurring(ctx context.Context, subscriptionReference string) error {
+	return c.send(ctx, http.MethodPost, "/subscriptions/"+url.PathEscape(subscriptionReference)+"/renewal/", nil, nil)
+}
+
+// DisableRecurring turns off the automatic renewal of a subscription, which then expires at ExpirationDate
+// Endpoint: DELETE /subscriptions/{subscriptionReference}/renewal/
+func (c *TwoCheckoutClient) DisableRecurring(ctx context.Context, subscriptionReference string) error {
+	return c.send(ctx, http.MethodDelete, "/subscriptions/"+url.PathEscape(subscriptionReference)+"/renewal/", nil, nil)
+}
+
+// VerifyTwoCheckoutINS checks the md5_hash of an Instant Notification Service message,
+// the upper case MD5 of sale_id, vendor_id, invoice_id and the secret word of the account
+// Doc: https://verifone.cloud/docs/2checkout/Documentation/07Commerce/2Checkout-Legacy-Integration/INS
+func VerifyTwoCheckoutINS(req *http.Request, sellerID, secretWord string) (*TwoCheckoutINS, error) {
+	if err := req.ParseForm(); err != nil {
+		return nil, err
+	}
+
+	ins := &TwoCheckoutINS{
+		MessageType: req.PostForm.Get("message_type"),
+		SaleID:      req.PostForm.Get("sale_id"),
+		InvoiceID:   req.PostForm.Get("invoice_id"),
+		VendorID:    req.PostForm.Get("vendor_id"),
+		Values:      req.PostForm,
+	}
+	if ins.VendorID != sellerID {
+		return nil, ErrTwoCheckoutINSSignature
+	}
+
+	hash := md5.Sum([]byte(ins.SaleID + ins.VendorID + ins.InvoiceID + secretWord))
+	expected := strings.ToUpper(hex.EncodeToString(hash[:]))
+	if !hmac.Equal([]byte(strings.ToUpper(req.PostForm.Get("md5_hash"))), []byte(expected)) {
+		return nil, ErrTwoCheckoutINSSignature
+	}
+
+	return ins, nil
+}
@@ -1,13 +1,42 @@
 package payment
 
 import (
+	"bytes"
 	"context"
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
+	"encoding/pem"
+	"errors"
 	"fmt"
 	"io/ioutil"
+	"math/big"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 var testBillingAgreementID = "BillingAgreementID"
@@ -36,7 +65,7 @@ type webprofileTestServer struct {
 
 func TestNewClient(t *testing.T) {
 	c := New(ctx, PAYPAL, &Config{
-		PayPal{
+		PayPal: PayPal{
 			ClientID: "1",
 			SecretID: "2",
 			APIBase:  "3",
@@ -722,7 +751,7 @@ func TestCreateWebProfile_valid(t *testing.T) {
 	defer ts.Close()
 
 	c := New(ctx, PAYPAL, &Config{
-		PayPal{
+		PayPal: PayPal{
 			ClientID: "foo",
 			SecretID: "bar",
 			APIBase:  ts.URL,
@@ -765,7 +794,7 @@ func TestCreateWebProfile_invalid(t *testing.T) {
 	defer ts.Close()
 
 	c := New(ctx, PAYPAL, &Config{
-		PayPal{
+		PayPal: PayPal{
 			ClientID: "foo",
 			SecretID: "bar",
 			APIBase:  ts.URL,
@@ -786,7 +815,7 @@ func TestGetWebProfile_valid(t *testing.T) {
 	defer ts.Close()
 
 	c := New(ctx, PAYPAL, &Config{
-		PayPal{
+		PayPal: PayPal{
 			ClientID: "foo",
 			SecretID: "bar",
 			APIBase:  ts.URL,
@@ -813,7 +842,7 @@ func TestGetWebProfile_invalid(t *testing.T) {
 	defer ts.Close()
 
 	c := New(ctx, PAYPAL, &Config{
-		PayPal{
+		PayPal: PayPal{
 			ClientID: "foo",
 			SecretID: "bar",
 			APIBase:  ts.URL,
@@ -832,7 +861,7 @@ func TestGetWebProfiles(t *testing.T) {
 	defer ts.Close()
 
 	c := New(ctx, PAYPAL, &Config{
-		PayPal{
+		PayPal: PayPal{
 			ClientID: "foo",
 			SecretID: "bar",
 			APIBase:  ts.URL,
@@ -855,7 +884,7 @@ func TestSetWebProfile_valid(t *testing.T) {
 	defer ts.Close()
 
 	c := New(ctx, PAYPAL, &Config{
-		PayPal{
+		PayPal: PayPal{
 			ClientID: "foo",
 			SecretID: "bar",
 			APIBase:  ts.URL,
@@ -880,7 +909,7 @@ func TestSetWebProfile_invalid(t *testing.T) {
 	defer ts.Close()
 
 	c := New(ctx, PAYPAL, &Config{
-		PayPal{
+		PayPal: PayPal{
 			ClientID: "foo",
 			SecretID: "bar",
 			APIBase:  ts.URL,
@@ -911,7 +940,7 @@ func TestDeleteWebProfile_valid(t *testing.T) {
 	defer ts.Close()
 
 	c := New(ctx, PAYPAL, &Config{
-		PayPal{
+		PayPal: PayPal{
 			ClientID: "foo",
 			SecretID: "bar",
 			APIBase:  ts.URL,
@@ -936,7 +965,7 @@ func TestDeleteWebProfile_invalid(t *testing.T) {
 	defer ts.Close()
 
 	c := New(ctx, PAYPAL, &Config{
-		PayPal{
+		PayPal: PayPal{
 			ClientID: "foo",
 			SecretID: "bar",
 			APIBase:  ts.URL,
@@ -957,7 +986,7 @@ func TestCreateBillingAgreementToken(t *testing.T) {
 	defer ts.Close()
 
 	c := New(ctx, PAYPAL, &Config{
-		PayPal{
+		PayPal: PayPal{
 			ClientID: "foo",
 			SecretID: "bar",
 			APIBase:  ts.URL,
@@ -984,7 +1013,7 @@ func TestCreateBillingAgreementFromToken(t *testing.T) {
 	defer ts.Close()
 
 	c := New(ctx, PAYPAL, &Config{
-		PayPal{
+		PayPal: PayPal{
 			ClientID: "foo",
 			SecretID: "bar",
 			APIBase:  ts.URL,
@@ -1004,7 +1033,7 @@ func TestCancelBillingAgreement(t *testing.T) {
 	defer ts.Close()
 
 	c := New(ctx, PAYPAL, &Config{
-		PayPal{
+		PayPal: PayPal{
 			ClientID: "foo",
 			SecretID: "bar",
 			APIBase:  ts.URL,
@@ -1017,3 +1046,3595 @@ func TestCancelBillingAgreement(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestListBalances(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/reporting/balances" || r.URL.Query().Get("currency_code") != "USD" {
+			t.Errorf("unexpected request %s", r.URL.String())
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{
+			"balances": [{
+				"currency": "USD",
+				"primary": true,
+				"total_balance": {"currency_code": "USD", "value": "900000.00"},
+				"available_balance": {"currency_code": "USD", "value": "890000.00"},
+				"withheld_balance": {"currency_code": "USD", "value": "10000.00"}
+			}],
+			"account_id": "YJ7CHC9Y4YYNE",
+			"as_of_time": "2016-10-15T06:59:59Z",
+			"last_refresh_time": "2016-10-15T06:59:59Z"
+		}`))
+	}))
+	defer ts.Close()
+
+	c := New(ctx, PAYPAL, &Config{
+		PayPal: PayPal{
+			ClientID: "foo",
+			SecretID: "bar",
+			APIBase:  ts.URL,
+		},
+	}).(IPayPal)
+
+	res, err := c.ListBalances(context.Background(), time.Time{}, "USD")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(res.Balances) != 1 ||
+		res.Balances[0].AvailableBalance.Value != "890000.00" ||
+		res.Balances[0].WithheldBalance.Value != "10000.00" ||
+		res.AccountID != "YJ7CHC9Y4YYNE" {
+		t.Errorf("BalancesResponse decoded result is incorrect, Given: %+v", res)
+	}
+}
+
+func TestTransactionIterator(t *testing.T) {
+	var windows []string
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		start, _ := time.Parse(time.RFC3339, q.Get("start_date"))
+		end, _ := time.Parse(time.RFC3339, q.Get("end_date"))
+		if end.Sub(start) > TransactionSearchMaxRange {
+			t.Errorf("window %s - %s exceeds the maximum range", start, end)
+		}
+		windows = append(windows, q.Get("start_date")+"/"+q.Get("page"))
+
+		w.Header().Set("Content-Type", "application/json")
+		if len(windows) == 1 {
+			w.Write([]byte(`{"transaction_details":[{"transaction_info":{"transaction_id":"1"}},{"transaction_info":{"transaction_id":"2"}}],"page":1,"total_pages":2}`))
+			return
+		}
+		if len(windows) == 2 {
+			w.Write([]byte(`{"transaction_details":[{"transaction_info":{"transaction_id":"3"}}],"page":2,"total_pages":2}`))
+			return
+		}
+		w.Write([]byte(`{"transaction_details":[{"transaction_info":{"transaction_id":"4"}}],"page":1,"total_pages":1}`))
+	}))
+	defer ts.Close()
+
+	c := New(ctx, PAYPAL, &Config{
+		PayPal: PayPal{
+			ClientID: "foo",
+			SecretID: "bar",
+			APIBase:  ts.URL,
+		},
+	}).(IPayPal)
+
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	it := c.NewTransactionIterator(context.Background(), TransactionSearchRequest{
+		StartDate: start,
+		EndDate:   start.Add(40 * 24 * time.Hour),
+	})
+
+	var ids []string
+	for it.Next() {
+		ids = append(ids, it.Current().TransactionInfo.TransactionID)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	if fmt.Sprint(ids) != "[1 2 3 4]" || len(windows) != 3 {
+		t.Errorf("unexpected iteration, ids: %v, requests: %v", ids, windows)
+	}
+}
+
+func TestTransactionIteratorContextCanceled(t *testing.T) {
+	c := New(ctx, PAYPAL, &Config{
+		PayPal: PayPal{
+			ClientID: "foo",
+			SecretID: "bar",
+			APIBase:  "http://127.0.0.1:0",
+		},
+	}).(IPayPal)
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	it := c.NewTransactionIterator(canceled, TransactionSearchRequest{StartDate: start, EndDate: start.Add(time.Hour)})
+	if it.Next() || it.Err() != context.Canceled {
+		t.Errorf("expecting context.Canceled, got %v", it.Err())
+	}
+}
+
+func TestAuthAssertion(t *testing.T) {
+	var assertions []string
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assertions = append(assertions, r.Header.Get("PayPal-Auth-Assertion"))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"1JU08902781691411","status":"COMPLETED"}`))
+	}))
+	defer ts.Close()
+
+	c := New(ctx, PAYPAL, &Config{
+		PayPal: PayPal{
+			ClientID: "platform",
+			SecretID: "bar",
+			APIBase:  ts.URL,
+		},
+	}).(*PayPalClient)
+
+	if _, err := c.RefundCapture(context.Background(), "2GG279541U471931P", RefundCaptureRequest{}, WithAuthAssertion("MERCHANT", "")); err != nil {
+		t.Fatal(err)
+	}
+
+	merchant, err := NewPayPalClient(&PayPal{ClientID: "platform", SecretID: "bar", APIBase: ts.URL}, WithDefaultAuthAssertion("", "merchant@example.com"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if merchant == IPayPal(c) {
+		t.Fatal("a client with a default auth assertion must not be shared")
+	}
+	if _, err = merchant.GetOrder(context.Background(), "5O190127TN364715T"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = merchant.GetOrder(context.Background(), "5O190127TN364715T", WithAuthAssertion("MERCHANT", "")); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = c.GetOrder(context.Background(), "5O190127TN364715T"); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"eyJhbGciOiJub25lIn0.eyJpc3MiOiJwbGF0Zm9ybSIsInBheWVyX2lkIjoiTUVSQ0hBTlQifQ.",
+		"eyJhbGciOiJub25lIn0.eyJlbWFpbCI6Im1lcmNoYW50QGV4YW1wbGUuY29tIiwiaXNzIjoicGxhdGZvcm0ifQ.",
+		"eyJhbGciOiJub25lIn0.eyJpc3MiOiJwbGF0Zm9ybSIsInBheWVyX2lkIjoiTUVSQ0hBTlQifQ.",
+		"",
+	}
+	if fmt.Sprint(assertions) != fmt.Sprint(expected) {
+		t.Errorf("unexpected PayPal-Auth-Assertion headers,\n Given:    %v\n Expected: %v", assertions, expected)
+	}
+}
+
+func TestCreateOrderFromRequest(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		expected := `{"intent":"CAPTURE","purchase_units":[{"amount":{"currency_code":"USD","value":"100.00"}}],"payment_source":{"paypal":{"experience_context":{"brand_name":"EXAMPLE INC","shipping_preference":"NO_SHIPPING","user_action":"PAY_NOW","return_url":"https://example.com/returnUrl","cancel_url":"https://example.com/cancelUrl"}}}}`
+		if string(body) != expected {
+			t.Errorf("unexpected create order body,\n Given:    %s\n Expected: %s", body, expected)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"5O190127TN364715T","status":"PAYER_ACTION_REQUIRED","payment_source":{"paypal":{}},"links":[{"href":"https://www.paypal.com/checkoutnow?token=5O190127TN364715T","rel":"payer-action","method":"GET"}]}`))
+	}))
+	defer ts.Close()
+
+	c := New(ctx, PAYPAL, &Config{
+		PayPal: PayPal{
+			ClientID: "foo",
+			SecretID: "bar",
+			APIBase:  ts.URL,
+		},
+	}).(IPayPal)
+
+	order, err := c.CreateOrderFromRequest(context.Background(), CreateOrderRequest{
+		Intent:        "CAPTURE",
+		PurchaseUnits: []PurchaseUnitRequest{{Amount: &PurchaseUnitAmount{Currency: "USD", Value: "100.00"}}},
+		PaymentSource: &PaymentSource{
+			PayPal: &PaymentSourcePayPal{
+				ExperienceContext: &ExperienceContext{
+					BrandName:          "EXAMPLE INC",
+					ShippingPreference: "NO_SHIPPING",
+					UserAction:         "PAY_NOW",
+					ReturnURL:          "https://example.com/returnUrl",
+					CancelURL:          "https://example.com/cancelUrl",
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if order.ID != "5O190127TN364715T" || order.PaymentSource == nil || order.PaymentSource.PayPal == nil {
+		t.Errorf("Order decoded result is incorrect, Given: %+v", order)
+	}
+}
+
+func TestConfirmPaymentSource(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/checkout/orders/5O190127TN364715T/confirm-payment-source" {
+			t.Errorf("unexpected request %s", r.URL.Path)
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		expected := `{"payment_source":{"card":{"name":"John Doe","number":"4111111111111111","expiry":"2030-12","security_code":"123","experience_context":{"return_url":"https://example.com/returnUrl","cancel_url":"https://example.com/cancelUrl"}}}}`
+		if string(body) != expected {
+			t.Errorf("unexpected confirm payment source body,\n Given:    %s\n Expected: %s", body, expected)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{
+			"id": "5O190127TN364715T",
+			"status": "APPROVED",
+			"payment_source": {
+				"card": {
+					"last_digits": "1111",
+					"brand": "VISA",
+					"authentication_result": {
+						"liability_shift": "POSSIBLE",
+						"three_d_secure": {"authentication_status": "Y", "enrollment_status": "Y"}
+					}
+				}
+			}
+		}`))
+	}))
+	defer ts.Close()
+
+	c := New(ctx, PAYPAL, &Config{
+		PayPal: PayPal{
+			ClientID: "foo",
+			SecretID: "bar",
+			APIBase:  ts.URL,
+		},
+	}).(IPayPal)
+
+	order, err := c.ConfirmPaymentSource(context.Background(), "5O190127TN364715T", ConfirmPaymentSourceRequest{
+		PaymentSource: &PaymentSource{
+			Card: &PaymentSourceCard{
+				Name:         "John Doe",
+				Number:       "4111111111111111",
+				Expiry:       "2030-12",
+				SecurityCode: "123",
+				ExperienceContext: &ExperienceContext{
+					ReturnURL: "https://example.com/returnUrl",
+					CancelURL: "https://example.com/cancelUrl",
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	card := order.PaymentSource.Card
+	if card.LastDigits != "1111" ||
+		card.AuthenticationResult.LiabilityShift != "POSSIBLE" ||
+		card.AuthenticationResult.ThreeDSecure.AuthenticationStatus != "Y" ||
+		card.AuthenticationResult.ThreeDSecure.EnrollmentStatus != "Y" {
+		t.Errorf("Order decoded result is incorrect, Given: %+v", card)
+	}
+}
+
+func TestUpdateOrderWithPatches(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			if r.Header.Get("PayPal-Request-Id") != "" || r.Header.Get("PayPal-Mock-Response") != "" {
+				t.Errorf("the idempotency key and the mock response of the patch must not apply to the read, got %v", r.Header)
+			}
+			if strings.Contains(r.URL.RawQuery, "wrapper") && r.Header.Get("PayPal-Auth-Assertion") == "" {
+				t.Error("the auth assertion of the patch must apply to the read")
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"id":"5O190127TN364715T","status":"CREATED"}`))
+			return
+		}
+
+		body, _ := ioutil.ReadAll(r.Body)
+		expected := map[string]string{
+			"intent":  `[{"op":"replace","path":"/intent","value":"AUTHORIZE"},{"op":"remove","path":"/purchase_units/@reference_id=='default'/invoice_id"}]`,
+			"wrapper": `[{"op":"replace","path":"/purchase_units/@reference_id=='PUHF'","value":{"reference_id":"PUHF","amount":{"currency_code":"USD","value":"10.00"}}}]`,
+		}[r.URL.Query().Get("case")]
+		if r.Method != http.MethodPatch || string(body) != expected {
+			t.Errorf("unexpected patch body,\n Given:    %s\n Expected: %s", body, expected)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	c := New(ctx, PAYPAL, &Config{
+		PayPal: PayPal{
+			ClientID: "foo",
+			SecretID: "bar",
+			APIBase:  ts.URL,
+		},
+	}).(IPayPal)
+
+	err := c.UpdateOrderWithPatches(context.Background(), "5O190127TN364715T?case=intent", []Patch{
+		{Operation: "replace", Path: "/intent", Value: "AUTHORIZE"},
+		{Operation: "remove", Path: PurchaseUnitPatchPath("", "invoice_id")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	order, err := c.UpdateOrder(context.Background(), "5O190127TN364715T?case=wrapper", []PurchaseUnitRequest{
+		{ReferenceID: "PUHF", Amount: &PurchaseUnitAmount{Currency: "USD", Value: "10.00"}},
+	}, WithIdempotencyKey("update-1"), WithMockResponse(MockInternalServerError), WithAuthAssertion("MERCHANT", ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if order.ID != "5O190127TN364715T" {
+		t.Errorf("expecting the updated order, Given: %+v", order)
+	}
+}
+
+func TestTypePaymentSourceCardSCA(t *testing.T) {
+	card := &PaymentSourceCard{
+		Number: "4111111111111111",
+		Expiry: "2030-12",
+		Attributes: &CardAttributes{
+			Verification: &CardVerification{Method: CardVerificationSCAWhenRequired},
+		},
+		StoredCredential: &CardStoredCredential{
+			PaymentInitiator: PaymentInitiatorMerchant,
+			PaymentType:      StoredPaymentRecurring,
+			Usage:            StoredCredentialSubsequent,
+			PreviousNetworkTransactionReference: &NetworkTransactionReference{
+				ID:      "156GHJ654SFH543",
+				Network: "VISA",
+			},
+		},
+	}
+	expected := `{"number":"4111111111111111","expiry":"2030-12","attributes":{"verification":{"method":"SCA_WHEN_REQUIRED"}},"stored_credential":{"payment_initiator":"MERCHANT","payment_type":"RECURRING","usage":"SUBSEQUENT","previous_network_transaction_reference":{"id":"156GHJ654SFH543","network":"VISA"}}}`
+	response, _ := json.Marshal(card)
+	if string(response) != expected {
+		t.Errorf("PaymentSourceCard is incorrect,\n Given:    %s\n Expected: %s", response, expected)
+	}
+
+	capture := &CaptureAmount{}
+	err := json.Unmarshal([]byte(`{"id":"3C679366HH908993F","network_transaction_reference":{"id":"155ABC654SFH543","date":"0304","network":"VISA"}}`), capture)
+	if err != nil || capture.NetworkTransactionReference.ID != "155ABC654SFH543" {
+		t.Errorf("CaptureAmount decoded result is incorrect, Given: %+v, error: %v", capture, err)
+	}
+}
+
+func TestSetTransactionContext(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		expected := `{"additional_data":[{"key":"sender_account_id","value":"A12345N343"},{"key":"highrisk_txn_flag","value":"0"}]}`
+		if r.Method != http.MethodPut || r.URL.Path != "/v1/risk/transaction-contexts/MERCHANT/TRACKING" || string(body) != expected {
+			t.Errorf("unexpected request %s %s,\n Given:    %s\n Expected: %s", r.Method, r.URL.Path, body, expected)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	c := New(ctx, PAYPAL, &Config{
+		PayPal: PayPal{
+			ClientID: "foo",
+			SecretID: "bar",
+			APIBase:  ts.URL,
+		},
+	}).(IPayPal)
+
+	err := c.SetTransactionContext(context.Background(), "MERCHANT", "TRACKING", []TransactionContextData{
+		{Key: TransactionContextSenderAccountID, Value: "A12345N343"},
+		{Key: TransactionContextHighRiskTxnFlag, Value: "0"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestExportPayoutReport(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("page") == "1" {
+			w.Write([]byte(`{
+				"batch_header": {"payout_batch_id": "FYXMPQTX4JC9N", "batch_status": "SUCCESS"},
+				"items": [{
+					"payout_item_id": "DUCD4NHGTKQ2S",
+					"transaction_id": "5KF35149WF2312203",
+					"transaction_status": "SUCCESS",
+					"payout_item_fee": {"currency": "USD", "value": "0.25"},
+					"payout_item": {"recipient_type": "EMAIL", "receiver": "a@example.com", "sender_item_id": "14", "amount": {"currency": "USD", "value": "9.87"}},
+					"time_processed": "2018-01-01T10:00:00Z"
+				}],
+				"links": [{"href": "https://api.sandbox.paypal.com/v1/payments/payouts/FYXMPQTX4JC9N?page=2", "rel": "next", "method": "GET"}]
+			}`))
+			return
+		}
+		w.Write([]byte(`{
+			"batch_header": {"payout_batch_id": "FYXMPQTX4JC9N", "batch_status": "SUCCESS"},
+			"items": [{"payout_item_id": "ADDUIGS5XGTKG", "transaction_status": "UNCLAIMED", "payout_item": {"recipient_type": "PHONE", "receiver": "408-555-1234", "amount": {"currency": "USD", "value": "1.00"}}}]
+		}`))
+	}))
+	defer ts.Close()
+
+	c := New(ctx, PAYPAL, &Config{
+		PayPal: PayPal{
+			ClientID: "foo",
+			SecretID: "bar",
+			APIBase:  ts.URL,
+		},
+	}).(IPayPal)
+
+	var report bytes.Buffer
+	if err := c.ExportPayoutReport(context.Background(), "FYXMPQTX4JC9N", &report); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "payout_batch_id,batch_status,payout_item_id,sender_item_id,transaction_id,transaction_status,recipient_type,receiver,amount,currency,fee,fee_currency,time_processed\n" +
+		"FYXMPQTX4JC9N,SUCCESS,DUCD4NHGTKQ2S,14,5KF35149WF2312203,SUCCESS,EMAIL,a@example.com,9.87,USD,0.25,USD,2018-01-01T10:00:00Z\n" +
+		"FYXMPQTX4JC9N,SUCCESS,ADDUIGS5XGTKG,,,UNCLAIMED,PHONE,408-555-1234,1.00,USD,,,\n"
+	if report.String() != expected {
+		t.Errorf("payout report is incorrect,\n Given:    %s\n Expected: %s", report.String(), expected)
+	}
+}
+
+func TestDownloadReport(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/csv")
+		w.Write([]byte("a,b\n1,2\n"))
+	}))
+	defer ts.Close()
+
+	c := New(ctx, PAYPAL, &Config{
+		PayPal: PayPal{
+			ClientID: "foo",
+			SecretID: "bar",
+			APIBase:  ts.URL,
+		},
+	}).(IPayPal)
+
+	var report bytes.Buffer
+	if err := c.DownloadReport(context.Background(), ts.URL+"/reports/1.csv", &report); err != nil {
+		t.Fatal(err)
+	}
+	if report.String() != "a,b\n1,2\n" {
+		t.Errorf("unexpected report %q", report.String())
+	}
+
+	for _, reportURL := range []string{"https://attacker.example.com/reports/1.csv", "//attacker.example.com/reports/1.csv", strings.Replace(ts.URL, "http://", "https://", 1) + "/reports/1.csv"} {
+		if err := c.DownloadReport(context.Background(), reportURL, &report); err != ErrForeignReportURL {
+			t.Errorf("expected ErrForeignReportURL for %s, got %v", reportURL, err)
+		}
+	}
+}
+
+func TestDownloadReportTruncated(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Length", "100")
+		w.Write([]byte("a,b\n"))
+	}))
+	defer ts.Close()
+
+	c := New(ctx, PAYPAL, &Config{
+		PayPal: PayPal{
+			ClientID: "foo",
+			SecretID: "bar",
+			APIBase:  ts.URL,
+		},
+	}).(IPayPal)
+
+	var report bytes.Buffer
+	if err := c.DownloadReport(context.Background(), ts.URL+"/reports/1.csv", &report); err == nil {
+		t.Error("expected an error for a truncated report")
+	}
+}
+
+func TestSendWithAuthRefreshesRejectedToken(t *testing.T) {
+	var tokenRequests int
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/v1/oauth2/token" {
+			tokenRequests++
+			w.Write([]byte(`{"access_token":"new","token_type":"Bearer","expires_in":32400}`))
+			return
+		}
+
+		if r.Header.Get("Authorization") != "Bearer new" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"error":"invalid_token","error_description":"The token passed in was not found in the system"}`))
+			return
+		}
+
+		body, _ := ioutil.ReadAll(r.Body)
+		if string(body) != `{"amount":{"currency_code":"USD","value":"1.00"}}` {
+			t.Errorf("the replayed request has an unexpected body: %s", body)
+		}
+		w.Write([]byte(`{"id":"1JU08902781691411","status":"COMPLETED"}`))
+	}))
+	defer ts.Close()
+
+	c := New(ctx, PAYPAL, &Config{
+		PayPal: PayPal{
+			ClientID: "foo",
+			SecretID: "bar",
+			APIBase:  ts.URL,
+		},
+	}).(*PayPalClient)
+	c.Token = &TokenResponse{Token: "old"}
+	c.tokenExpiresAt = time.Now().Add(time.Hour)
+
+	refund, err := c.RefundCapture(context.Background(), "2GG279541U471931P", RefundCaptureRequest{Amount: &Money{Currency: "USD", Value: "1.00"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if refund.ID != "1JU08902781691411" || tokenRequests != 1 || c.Token.Token != "new" {
+		t.Errorf("expecting one token refresh and a replayed request, got %d refreshes and %+v", tokenRequests, refund)
+	}
+}
+
+func TestConcurrentTokenRefresh(t *testing.T) {
+	var tokenRequests int32
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/v1/oauth2/token" {
+			atomic.AddInt32(&tokenRequests, 1)
+			time.Sleep(50 * time.Millisecond)
+			w.Write([]byte(`{"access_token":"new","token_type":"Bearer","expires_in":32400}`))
+			return
+		}
+		w.Write([]byte(`{"id":"5O190127TN364715T"}`))
+	}))
+	defer ts.Close()
+
+	c := New(ctx, PAYPAL, &Config{
+		PayPal: PayPal{
+			ClientID: "foo",
+			SecretID: "bar",
+			APIBase:  ts.URL,
+		},
+	}).(*PayPalClient)
+	c.Token = &TokenResponse{Token: "old"}
+	c.tokenExpiresAt = time.Now().Add(time.Second)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := c.GetOrder(context.Background(), "5O190127TN364715T"); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if tokenRequests != 1 {
+		t.Errorf("expecting a single token request, got %d", tokenRequests)
+	}
+}
+
+func TestTokenStore(t *testing.T) {
+	var tokenRequests int32
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/v1/oauth2/token" {
+			atomic.AddInt32(&tokenRequests, 1)
+			w.Write([]byte(`{"access_token":"shared","token_type":"Bearer","expires_in":32400}`))
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer shared" {
+			t.Errorf("unexpected Authorization header %q", r.Header.Get("Authorization"))
+		}
+		w.Write([]byte(`{"id":"5O190127TN364715T"}`))
+	}))
+	defer ts.Close()
+
+	stores := map[string]TokenStore{
+		"memory": NewMemoryTokenStore(),
+		"file":   NewFileTokenStore(filepath.Join(t.TempDir(), "tokens.json")),
+		"redis":  NewRedisTokenStore(&fakeRedis{values: make(map[string]string)}),
+	}
+
+	for name, store := range stores {
+		atomic.StoreInt32(&tokenRequests, 0)
+
+		// Two replicas of the same application share the token
+		for i := 0; i < 2; i++ {
+			c, err := NewPayPalClient(&PayPal{ClientID: "foo", SecretID: "bar", APIBase: ts.URL}, WithTokenStore(store))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := c.GetOrder(context.Background(), "5O190127TN364715T"); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		if tokenRequests != 1 {
+			t.Errorf("%s store: expecting a single token request, got %d", name, tokenRequests)
+		}
+	}
+}
+
+type fakeRedis struct {
+	values map[string]string
+}
+
+func (r *fakeRedis) Get(ctx context.Context, key string) (string, error) {
+	return r.values[key], nil
+}
+
+func (r *fakeRedis) Set(ctx context.Context, key, value string, ttl time.Duration) error {
+	r.values[key] = value
+	return nil
+}
+
+func TestRequestOptions(t *testing.T) {
+	var requests []*http.Request
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r)
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v1/payment-experience/web-profiles":
+			w.Write([]byte(`[]`))
+		default:
+			w.Write([]byte(`{"products":[]}`))
+		}
+	}))
+	defer ts.Close()
+
+	c := New(ctx, PAYPAL, &Config{
+		PayPal: PayPal{
+			ClientID: "foo",
+			SecretID: "bar",
+			APIBase:  ts.URL,
+		},
+	}).(IPayPal)
+
+	if _, err := c.GetWebProfiles(context.Background(), WithHeader("Prefer", "return=representation"), WithClientMetadataID("cmid")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.ListProducts(context.Background(), &ProductListParameters{ListParams: ListParams{Page: "2"}}, WithQuery("total_required", "true"), WithIdempotencyKey("key-1")); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(requests) != 2 {
+		t.Fatalf("expected 2 requests, got %d", len(requests))
+	}
+	if h := requests[0].Header.Get("Prefer"); h != "return=representation" {
+		t.Errorf("expected Prefer header, got %q", h)
+	}
+	if h := requests[0].Header.Get("PayPal-Client-Metadata-Id"); h != "cmid" {
+		t.Errorf("expected PayPal-Client-Metadata-Id header, got %q", h)
+	}
+	if h := requests[1].Header.Get("PayPal-Request-Id"); h != "key-1" {
+		t.Errorf("expected PayPal-Request-Id header, got %q", h)
+	}
+	q := requests[1].URL.Query()
+	if q.Get("total_required") != "true" || q.Get("page") != "2" {
+		t.Errorf("unexpected query %q", requests[1].URL.RawQuery)
+	}
+}
+
+func TestAutoIdempotencyKey(t *testing.T) {
+	var keys []string
+	rejected := false
+	tokenRequests := 0
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/v1/oauth2/token" {
+			tokenRequests++
+			if key := r.Header.Get("PayPal-Request-Id"); key != "" {
+				t.Errorf("expected no key on token requests, got %q", key)
+			}
+			w.Write([]byte(`{"access_token":"new","token_type":"Bearer","expires_in":32400}`))
+			return
+		}
+
+		keys = append(keys, r.Header.Get("PayPal-Request-Id"))
+		if !rejected {
+			rejected = true
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"error":"invalid_token"}`))
+			return
+		}
+		w.Write([]byte(`{"id":"1JU08902781691411","status":"COMPLETED"}`))
+	}))
+	defer ts.Close()
+
+	client, err := NewPayPalClient(&PayPal{ClientID: "foo", SecretID: "bar", APIBase: ts.URL}, WithAutoIdempotencyKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := client.(*PayPalClient)
+	c.Token = &TokenResponse{Token: "old"}
+	c.tokenExpiresAt = time.Now().Add(time.Hour)
+
+	if _, err := c.RefundCapture(context.Background(), "2GG279541U471931P", RefundCaptureRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.VoidAuthorization(context.Background(), "0VF52814937998046", WithIdempotencyKey("void-1")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GetOrder(context.Background(), "5O190127TN364715T"); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(keys) != 4 {
+		t.Fatalf("expected 4 requests, got %d", len(keys))
+	}
+	if len(keys[0]) != 36 || keys[0] != keys[1] {
+		t.Errorf("expected the replayed refund to reuse its generated key, got %q and %q", keys[0], keys[1])
+	}
+	if keys[2] != "void-1" {
+		t.Errorf("expected the explicit key to be kept, got %q", keys[2])
+	}
+	if keys[3] != "" {
+		t.Errorf("expected no key on GET requests, got %q", keys[3])
+	}
+	if tokenRequests != 1 {
+		t.Errorf("expected the rejected token to be refreshed once, got %d token requests", tokenRequests)
+	}
+	if NewIdempotencyKey() == NewIdempotencyKey() {
+		t.Error("expected distinct idempotency keys")
+	}
+}
+
+func TestReturnRepresentation(t *testing.T) {
+	var prefers []string
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/v1/oauth2/token" {
+			w.Write([]byte(`{"access_token":"new","token_type":"Bearer","expires_in":32400}`))
+			return
+		}
+		prefers = append(prefers, r.Header.Get("Prefer"))
+		w.Write([]byte(`{"id":"5O190127TN364715T","status":"COMPLETED"}`))
+	}))
+	defer ts.Close()
+
+	config := &PayPal{ClientID: "foo", SecretID: "bar", APIBase: ts.URL}
+
+	c, err := NewPayPalClient(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.CaptureOrder(context.Background(), "5O190127TN364715T", CaptureOrderRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GetOrder(context.Background(), "5O190127TN364715T"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GetOrder(context.Background(), "5O190127TN364715T", WithReturnRepresentation()); err != nil {
+		t.Fatal(err)
+	}
+
+	verbose, err := NewPayPalClient(config, WithDefaultReturnRepresentation())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := verbose.GetOrder(context.Background(), "5O190127TN364715T"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := verbose.GetOrder(context.Background(), "5O190127TN364715T", WithHeader("Prefer", "return=minimal")); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"return=representation", "", "return=representation", "return=representation", "return=minimal"}
+	if fmt.Sprint(prefers) != fmt.Sprint(expected) {
+		t.Errorf("unexpected Prefer headers,\n Given:    %q\n Expected: %q", prefers, expected)
+	}
+}
+
+func TestNewPayPalClient(t *testing.T) {
+	if _, err := NewPayPalClient(&PayPal{ClientID: "foo", APIBase: APIBaseSandBox}); err != ErrInvalidPayPalConfig {
+		t.Errorf("expected ErrInvalidPayPalConfig, got %v", err)
+	}
+	if c := New(ctx, PAYPAL, &Config{PayPal: PayPal{ClientID: "foo"}}); c != nil {
+		t.Errorf("expected no client for an invalid configuration, got %v", c)
+	}
+
+	config := &PayPal{ClientID: "registry", SecretID: "bar", APIBase: APIBaseSandBox}
+
+	clients := make([]IPayPal, 10)
+	var wg sync.WaitGroup
+	for i := range clients {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			clients[i], _ = NewPayPalClient(config)
+		}(i)
+	}
+	wg.Wait()
+
+	for _, c := range clients {
+		if c != clients[0] {
+			t.Fatal("expected clients with the same credentials to be shared")
+		}
+	}
+
+	if c := New(ctx, PAYPAL, &Config{PayPal: *config}); c != clients[0] {
+		t.Error("expected New to return the shared client")
+	}
+
+	custom, err := NewPayPalClient(config, WithDefaultReturnRepresentation())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if custom == clients[0] {
+		t.Error("expected a client with options not to be shared")
+	}
+}
+
+func TestClientOptions(t *testing.T) {
+	var userAgent string
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/v1/oauth2/token" {
+			w.Write([]byte(`{"access_token":"new","token_type":"Bearer","expires_in":32400}`))
+			return
+		}
+		userAgent = r.Header.Get("User-Agent")
+		w.Write([]byte(`{"id":"5O190127TN364715T"}`))
+	}))
+	defer ts.Close()
+
+	httpClient := &http.Client{}
+	proxyURL, _ := url.Parse("http://proxy.example.com:3128")
+	var log bytes.Buffer
+
+	c, err := NewPayPalClient(&PayPal{ClientID: "foo", SecretID: "bar"},
+		WithAPIBase(ts.URL),
+		WithHTTPClient(httpClient),
+		WithTimeout(5*time.Second),
+		WithUserAgent("example-app/1.0"),
+		WithLogger(&log),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := c.GetOrder(context.Background(), "5O190127TN364715T"); err != nil {
+		t.Fatal(err)
+	}
+
+	client := c.(*PayPalClient)
+	if client.Client.Timeout != 5*time.Second || httpClient.Timeout != 0 {
+		t.Errorf("expected the timeout to be set on a copy of the HTTP client, got %v and %v", client.Client.Timeout, httpClient.Timeout)
+	}
+	if userAgent != "example-app/1.0" {
+		t.Errorf("unexpected User-Agent %q", userAgent)
+	}
+	if !strings.Contains(log.String(), "/v2/checkout/orders/5O190127TN364715T") {
+		t.Errorf("expected the request to be logged, got %q", log.String())
+	}
+
+	proxied, err := NewPayPalClient(&PayPal{ClientID: "foo", SecretID: "bar", APIBase: APIBaseSandBox}, WithProxy(proxyURL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, _ := http.NewRequest(http.MethodGet, APIBaseSandBox, nil)
+	if u, err := proxied.(*PayPalClient).Client.Transport.(*http.Transport).Proxy(req); err != nil || u.String() != proxyURL.String() {
+		t.Errorf("expected requests to go through %v, got %v", proxyURL, u)
+	}
+
+	if _, err := NewPayPalClient(&PayPal{ClientID: "foo", SecretID: "bar"}, WithUserAgent("example-app/1.0")); err != ErrInvalidPayPalConfig {
+		t.Errorf("expected ErrInvalidPayPalConfig without an API base, got %v", err)
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	var calls []string
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/v1/oauth2/token" {
+			w.Write([]byte(`{"access_token":"new","token_type":"Bearer","expires_in":32400}`))
+			return
+		}
+		calls = append(calls, "server "+r.Header.Get("X-Signature"))
+		w.Write([]byte(`{"id":"5O190127TN364715T"}`))
+	}))
+	defer ts.Close()
+
+	named := func(name string) Middleware {
+		return func(next RoundTripFunc) RoundTripFunc {
+			return func(req *http.Request) (*http.Response, error) {
+				calls = append(calls, "before "+name+" "+req.URL.Path)
+				resp, err := next(req)
+				calls = append(calls, fmt.Sprintf("after %s %d", name, resp.StatusCode))
+				return resp, err
+			}
+		}
+	}
+	signing := func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			req.Header.Set("X-Signature", "signed")
+			return next(req)
+		}
+	}
+
+	c, err := NewPayPalClient(&PayPal{ClientID: "foo", SecretID: "bar", APIBase: ts.URL}, WithMiddleware(named("outer"), named("inner")), WithMiddleware(signing))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := c.GetAccessToken(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GetOrder(context.Background(), "5O190127TN364715T"); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"before outer /v1/oauth2/token", "before inner /v1/oauth2/token", "after inner 200", "after outer 200",
+		"before outer /v2/checkout/orders/5O190127TN364715T", "before inner /v2/checkout/orders/5O190127TN364715T",
+		"server signed", "after inner 200", "after outer 200",
+	}
+	if fmt.Sprint(calls) != fmt.Sprint(expected) {
+		t.Errorf("unexpected middleware calls,\n Given:    %q\n Expected: %q", calls, expected)
+	}
+}
+
+func TestRetryPolicy(t *testing.T) {
+	var attempts []string
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		body, _ := ioutil.ReadAll(r.Body)
+		attempts = append(attempts, r.Method+" "+string(body))
+
+		switch len(attempts) {
+		case 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case 2:
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			w.Write([]byte(`{"id":"1JU08902781691411","status":"COMPLETED"}`))
+		}
+	}))
+	defer ts.Close()
+
+	c, err := NewPayPalClient(&PayPal{ClientID: "foo", SecretID: "bar", APIBase: ts.URL}, WithRetryPolicy(RetryPolicy{MaxAttempts: 3, MinBackoff: time.Millisecond}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	refund, err := c.RefundCapture(context.Background(), "2GG279541U471931P", RefundCaptureRequest{NoteToPayer: "retry"}, WithIdempotencyKey("refund-1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if refund.ID != "1JU08902781691411" || len(attempts) != 3 || attempts[0] != attempts[2] {
+		t.Errorf("expected the refund to succeed on the third attempt with the same body, got %q", attempts)
+	}
+
+	attempts = nil
+	if _, err := c.RefundCapture(context.Background(), "2GG279541U471931P", RefundCaptureRequest{}); err == nil {
+		t.Error("expected the 503 error to be returned")
+	}
+	if len(attempts) != 1 {
+		t.Errorf("expected a POST without idempotency key not to be retried, got %d attempts", len(attempts))
+	}
+
+	attempts = nil
+	if _, err := c.GetOrder(context.Background(), "5O190127TN364715T"); err != nil {
+		t.Fatal(err)
+	}
+	if len(attempts) != 3 {
+		t.Errorf("expected a GET to be retried, got %d attempts", len(attempts))
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	retryAfter := func(value string) *http.Response {
+		return &http.Response{Header: http.Header{"Retry-After": {value}}}
+	}
+
+	tests := []struct {
+		policy   RetryPolicy
+		attempt  int
+		resp     *http.Response
+		expected time.Duration
+	}{
+		{RetryPolicy{MinBackoff: time.Second, MaxBackoff: 10 * time.Second}, 1, nil, time.Second},
+		{RetryPolicy{MinBackoff: time.Second, MaxBackoff: 10 * time.Second}, 3, nil, 4 * time.Second},
+		{RetryPolicy{MinBackoff: time.Second, MaxBackoff: 10 * time.Second}, 5, nil, 10 * time.Second},
+		{RetryPolicy{MinBackoff: time.Second}, 100, nil, defaultMaxBackoff},
+		{RetryPolicy{MinBackoff: time.Second, MaxBackoff: 10 * time.Second}, 1, retryAfter("3"), 3 * time.Second},
+		{RetryPolicy{MinBackoff: time.Second, MaxBackoff: 10 * time.Second}, 1, retryAfter("86400"), 10 * time.Second},
+		{RetryPolicy{MinBackoff: time.Second}, 1, retryAfter("86400"), defaultMaxBackoff},
+	}
+	for _, tt := range tests {
+		if wait := tt.policy.backoff(tt.attempt, tt.resp); wait != tt.expected {
+			t.Errorf("backoff(%d) with %+v, Given: %v, Expected: %v", tt.attempt, tt.policy, wait, tt.expected)
+		}
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	var requests int
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-RateLimit-Limit", "100")
+		w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(100-requests))
+		if requests == 3 {
+			w.Header().Set("Retry-After", "2")
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"name":"RATE_LIMIT_REACHED"}`))
+			return
+		}
+		w.Write([]byte(`{"id":"5O190127TN364715T"}`))
+	}))
+	defer ts.Close()
+
+	var rateLimits []RateLimit
+	c, err := NewPayPalClient(&PayPal{ClientID: "foo", SecretID: "bar", APIBase: ts.URL},
+		WithRateLimiter(20, 1),
+		WithRateLimitCallback(func(req *http.Request, rateLimit RateLimit) {
+			rateLimits = append(rateLimits, rateLimit)
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		c.GetOrder(context.Background(), "5O190127TN364715T")
+	}
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("expected 3 requests at 20 per second to take about 100ms, took %v", elapsed)
+	}
+
+	if len(rateLimits) != 3 || rateLimits[0].Remaining != 99 || rateLimits[1].Limit != 100 {
+		t.Fatalf("unexpected rate limits %+v", rateLimits)
+	}
+	if !rateLimits[2].Limited || rateLimits[2].RetryAfter != 2*time.Second {
+		t.Errorf("expected the 429 response to be reported, got %+v", rateLimits[2])
+	}
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := c.GetOrder(canceled, "5O190127TN364715T"); err == nil {
+		t.Error("expected a canceled context to stop waiting for the limiter")
+	}
+}
+
+func TestResponseMetadata(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Paypal-Debug-Id", "b1d1f06c7246c")
+		if r.URL.Path == "/v2/checkout/orders/UNKNOWN" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"name":"RESOURCE_NOT_FOUND","debug_id":"b1d1f06c7246c"}`))
+			return
+		}
+		w.Write([]byte(`{"id":"5O190127TN364715T","status":"CREATED"}`))
+	}))
+	defer ts.Close()
+
+	c, err := NewPayPalClient(&PayPal{ClientID: "foo", SecretID: "bar", APIBase: ts.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var metadata ResponseMetadata
+	order, err := c.GetOrder(context.Background(), "5O190127TN364715T", WithResponseMetadata(&metadata))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if order.ID != "5O190127TN364715T" {
+		t.Errorf("expected the order to be decoded, got %+v", order)
+	}
+	if metadata.StatusCode != http.StatusOK || metadata.DebugID != "b1d1f06c7246c" || metadata.Duration <= 0 {
+		t.Errorf("unexpected metadata %+v", metadata)
+	}
+	if string(metadata.Body) != `{"id":"5O190127TN364715T","status":"CREATED"}` {
+		t.Errorf("unexpected raw body %s", metadata.Body)
+	}
+
+	if _, err := c.GetOrder(context.Background(), "UNKNOWN", WithResponseMetadata(&metadata)); err == nil {
+		t.Fatal("expected an error")
+	}
+	if metadata.StatusCode != http.StatusNotFound || metadata.DebugID != "b1d1f06c7246c" {
+		t.Errorf("unexpected metadata %+v", metadata)
+	}
+}
+
+func TestErrorCategories(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v2/checkout/orders/UNKNOWN":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"name":"RESOURCE_NOT_FOUND","message":"The specified resource does not exist."}`))
+		case "/v2/checkout/orders/5O190127TN364715T/capture":
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			w.Write([]byte(`{"name":"UNPROCESSABLE_ENTITY","details":[{"issue":"INSTRUMENT_DECLINED","description":"The instrument presented was either declined by the processor or bank."}]}`))
+		case "/v2/checkout/orders/8AA831015G517922L/capture":
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"id":"8AA831015G517922L","status":"COMPLETED","purchase_units":[{"reference_id":"default","payments":{"captures":[{"id":"3C679366HH908993F","status":"DECLINED","processor_response":{"avs_code":"G","cvv_code":"P","response_code":"5400"}}]}}]}`))
+		}
+	}))
+	defer ts.Close()
+
+	c, err := NewPayPalClient(&PayPal{ClientID: "foo", SecretID: "bar", APIBase: ts.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = c.GetOrder(context.Background(), "UNKNOWN")
+	var errResp *ErrorResponse
+	if !errors.Is(err, ErrNotFound) || errors.Is(err, ErrValidation) || !errors.As(err, &errResp) || errResp.Name != "RESOURCE_NOT_FOUND" {
+		t.Errorf("expected a not found ErrorResponse, got %v", err)
+	}
+
+	_, err = c.CaptureOrder(context.Background(), "5O190127TN364715T", CaptureOrderRequest{})
+	if !errors.Is(err, ErrInstrumentDeclined) || !errors.Is(err, ErrValidation) || errors.Is(err, ErrNotFound) {
+		t.Errorf("expected a declined instrument, got %v", err)
+	}
+
+	capture, err := c.CaptureOrder(context.Background(), "8AA831015G517922L", CaptureOrderRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = capture.DeclinedError()
+	var declined *CaptureDeclinedError
+	if !errors.Is(err, ErrInstrumentDeclined) || !errors.As(err, &declined) || declined.ProcessorResponse.ResponseCode != "5400" {
+		t.Errorf("expected a declined capture with its processor response, got %v", err)
+	}
+}
+
+func TestInstrumentDeclined(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v2/checkout/orders/5O190127TN364715T/capture":
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			w.Write([]byte(`{"name":"UNPROCESSABLE_ENTITY","details":[{"issue":"INSTRUMENT_DECLINED"}],"links":[{"href":"https://www.paypal.com/checkoutnow?token=5O190127TN364715T","rel":"redirect","method":"GET"}]}`))
+		case "/v2/checkout/orders/5O190127TN364715T":
+			w.Write([]byte(`{"id":"5O190127TN364715T","status":"APPROVED","links":[{"href":"https://api.paypal.com/v2/checkout/orders/5O190127TN364715T","rel":"self","method":"GET"},{"href":"https://www.paypal.com/checkoutnow?token=5O190127TN364715T","rel":"approve","method":"GET"}]}`))
+		}
+	}))
+	defer ts.Close()
+
+	c, err := NewPayPalClient(&PayPal{ClientID: "foo", SecretID: "bar", APIBase: ts.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = c.CaptureOrder(context.Background(), "5O190127TN364715T", CaptureOrderRequest{})
+	var declined *InstrumentDeclinedError
+	var errResp *ErrorResponse
+	if !errors.As(err, &declined) || !errors.Is(err, ErrInstrumentDeclined) || !errors.As(err, &errResp) {
+		t.Fatalf("expected an InstrumentDeclinedError, got %v", err)
+	}
+	if declined.OrderID != "5O190127TN364715T" || declined.ApproveURL != "https://www.paypal.com/checkoutnow?token=5O190127TN364715T" {
+		t.Errorf("unexpected error %+v", declined)
+	}
+
+	approveURL, err := c.RetryOrderApproval(context.Background(), "5O190127TN364715T")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if approveURL != "https://www.paypal.com/checkoutnow?token=5O190127TN364715T" {
+		t.Errorf("unexpected approve URL %s", approveURL)
+	}
+}
+
+func TestCatalogIterators(t *testing.T) {
+	var pageSizes []string
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		page := r.URL.Query().Get("page")
+		pageSizes = append(pageSizes, r.URL.Query().Get("page_size"))
+
+		switch r.URL.Path {
+		case "/v1/catalogs/products":
+			switch page {
+			case "1":
+				w.Write([]byte(`{"products":[{"id":"PROD-1"},{"id":"PROD-2"}],"links":[{"href":"` + "http://" + r.Host + `/v1/catalogs/products?page_size=2&page=2","rel":"next","method":"GET"}]}`))
+			case "2":
+				w.Write([]byte(`{"products":[{"id":"PROD-3"}],"links":[]}`))
+			}
+		case "/v1/billing/plans":
+			if r.URL.Query().Get("product_id") != "PROD-1" {
+				t.Errorf("expected the product filter on every page, got %q", r.URL.RawQuery)
+			}
+			switch page {
+			case "1":
+				w.Write([]byte(`{"plans":[{"id":"P-1"}],"total_pages":2}`))
+			case "2":
+				w.Write([]byte(`{"plans":[{"id":"P-2"}],"total_pages":2}`))
+			}
+		}
+	}))
+	defer ts.Close()
+
+	c, err := NewPayPalClient(&PayPal{ClientID: "foo", SecretID: "bar", APIBase: ts.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var ids []string
+	products := c.NewProductIterator(context.Background(), ProductListParameters{ListParams: ListParams{PageSize: "2"}})
+	for products.Next() {
+		ids = append(ids, products.Current().ID)
+	}
+	if products.Err() != nil {
+		t.Fatal(products.Err())
+	}
+
+	plans := c.NewPlanIterator(context.Background(), SubscriptionPlanListParameters{ProductId: "PROD-1"})
+	for plans.Next() {
+		ids = append(ids, plans.Current().ID)
+	}
+	if plans.Err() != nil {
+		t.Fatal(plans.Err())
+	}
+
+	if fmt.Sprint(ids) != "[PROD-1 PROD-2 PROD-3 P-1 P-2]" {
+		t.Errorf("unexpected items %v", ids)
+	}
+	if fmt.Sprint(pageSizes[:2]) != "[2 2]" {
+		t.Errorf("expected the page size on every page, got %q", pageSizes)
+	}
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	it := c.NewProductIterator(canceled, ProductListParameters{})
+	if it.Next() || it.Err() != context.Canceled {
+		t.Errorf("expected the iterator to stop on a canceled context, got %v", it.Err())
+	}
+}
+
+func TestPatchSubscription(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPatch || r.URL.Path != "/v1/billing/subscriptions/I-BW452GLLEP1G" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		expected := `[{"op":"replace","path":"/billing_info/outstanding_balance","value":{"currency_code":"USD","value":"50.00"}},{"op":"replace","path":"/plan/payment_preferences/auto_bill_outstanding","value":false},{"op":"replace","path":"/plan/payment_preferences/payment_failure_threshold","value":3},{"op":"replace","path":"/shipping_amount","value":{"currency_code":"USD","value":"5.00"}}]`
+		if string(body) != expected {
+			t.Errorf("unexpected patches,\n Given:    %s\n Expected: %s", body, expected)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	c, err := NewPayPalClient(&PayPal{ClientID: "foo", SecretID: "bar", APIBase: ts.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = c.PatchSubscription(context.Background(), "I-BW452GLLEP1G", []Patch{
+		SubscriptionOutstandingBalancePatch(Money{Currency: "USD", Value: "50.00"}),
+		SubscriptionAutoBillOutstandingPatch(false),
+		SubscriptionPaymentFailureThresholdPatch(3),
+		SubscriptionShippingAmountPatch(Money{Currency: "USD", Value: "5.00"}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestJSONTime(t *testing.T) {
+	expected := time.Date(2021, 7, 12, 10, 0, 0, 0, time.UTC)
+
+	for _, given := range []string{
+		`"2021-07-12T10:00:00Z"`,
+		`"2021-07-12T10:00:00.000Z"`,
+		`"2021-07-12T10:00:00+0000"`,
+		`"2021-07-12T12:00:00+02:00"`,
+	} {
+		var jt JSONTime
+		if err := json.Unmarshal([]byte(given), &jt); err != nil {
+			t.Errorf("unmarshal %s: %v", given, err)
+			continue
+		}
+		if !jt.Time().Equal(expected) {
+			t.Errorf("unmarshal %s, Given: %v, Expected: %v", given, jt.Time(), expected)
+		}
+	}
+
+	var jt JSONTime
+	if err := json.Unmarshal([]byte(`"yesterday"`), &jt); err == nil {
+		t.Error("expected an error for an invalid time")
+	}
+	if err := json.Unmarshal([]byte(`null`), &jt); err != nil || !jt.IsZero() {
+		t.Errorf("expected zero time for null, got %v, %v", jt.Time(), err)
+	}
+
+	b, err := json.Marshal(BillingAgreement{StartDate: NewJSONTime(expected.In(time.FixedZone("CEST", 7200)))})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), `"start_date":"2021-07-12T10:00:00Z"`) {
+		t.Errorf("unexpected start_date in %s", b)
+	}
+}
+
+func TestValidation(t *testing.T) {
+	var calls int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"5O190127TN364715T"}`))
+	}))
+	defer ts.Close()
+
+	c, err := NewPayPalClient(&PayPal{ClientID: "foo", SecretID: "bar", APIBase: ts.URL}, WithValidation())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = c.CreateOrder(context.Background(), "SALE", []PurchaseUnitRequest{{Amount: &PurchaseUnitAmount{Currency: "usd", Value: "7.001"}}}, nil, nil)
+	var validationErrs ValidationErrors
+	if !errors.As(err, &validationErrs) || len(validationErrs) != 3 {
+		t.Fatalf("expected 3 validation errors, got %v", err)
+	}
+	if !errors.Is(err, ErrValidation) {
+		t.Error("expected errors.Is(err, ErrValidation)")
+	}
+
+	_, err = c.CreatePayout(context.Background(), Payout{Items: []PayoutItem{{RecipientType: "IBAN", Receiver: "foo@example.com", Amount: &AmountPayout{Currency: "USD", Value: "1.00"}}}})
+	if !errors.Is(err, ErrValidation) || !strings.Contains(err.Error(), "items[0].recipient_type") {
+		t.Errorf("expected a recipient type error, got %v", err)
+	}
+
+	_, err = c.CreateWebhook(context.Background(), &CreateWebhookRequest{URL: "http://example.com/hook", EventTypes: []WebhookEventType{{Name: "PAYMENT.CAPTURE.COMPLETED"}}})
+	if !errors.Is(err, ErrValidation) || !strings.Contains(err.Error(), "HTTPS") {
+		t.Errorf("expected a webhook URL error, got %v", err)
+	}
+
+	if calls != 0 {
+		t.Errorf("expected no request to be sent, got %d", calls)
+	}
+
+	kwd, err := ParseMoneyAmount("KWD", "1.234")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, valid := range []Money{*kwd.Money(), {Currency: "JPY", Value: "1000"}, {Currency: "USD", Value: "7.5"}} {
+		if err := valid.Validate(); err != nil {
+			t.Errorf("expected %s %s to be valid, got %v", valid.Currency, valid.Value, err)
+		}
+	}
+	for _, invalid := range []Money{{Currency: "KWD", Value: "1.2345"}, {Currency: "JPY", Value: "1000.5"}, {Currency: "USD", Value: "7.001"}, {Currency: "USD", Value: "7."}} {
+		if err := invalid.Validate(); !errors.Is(err, ErrValidation) {
+			t.Errorf("expected %s %q to be invalid, got %v", invalid.Currency, invalid.Value, err)
+		}
+	}
+
+	c.GetAccessToken(context.Background())
+	_, err = c.CreateOrder(context.Background(), OrderIntentCapture, []PurchaseUnitRequest{{Amount: &PurchaseUnitAmount{Currency: "USD", Value: "7.00"}}}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMoneyAmount(t *testing.T) {
+	tests := []struct {
+		currency, value, expected string
+		minor                     int64
+	}{
+		{"USD", "10.5", "10.50", 1050},
+		{"USD", "0.07", "0.07", 7},
+		{"usd", "-3", "-3.00", -300},
+		{"JPY", "1000", "1000", 1000},
+		{"KWD", "1.234", "1.234", 1234},
+	}
+	for _, tt := range tests {
+		m, err := ParseMoneyAmount(tt.currency, tt.value)
+		if err != nil {
+			t.Errorf("ParseMoneyAmount(%s, %s): %v", tt.currency, tt.value, err)
+			continue
+		}
+		if m.Minor != tt.minor || m.String() != tt.expected {
+			t.Errorf("ParseMoneyAmount(%s, %s), Given: %d %s, Expected: %d %s", tt.currency, tt.value, m.Minor, m, tt.minor, tt.expected)
+		}
+	}
+
+	for _, invalid := range []struct{ currency, value string }{{"JPY", "10.5"}, {"USD", "1.234"}, {"USD", "1."}, {"USD", "abc"}, {"USD", ""}} {
+		if _, err := ParseMoneyAmount(invalid.currency, invalid.value); err == nil {
+			t.Errorf("expected an error for %s %q", invalid.currency, invalid.value)
+		}
+	}
+
+	price, _ := ParseMoneyAmount("USD", "19.99")
+	shipping := NewMoneyAmount("USD", 500)
+	total, err := price.Mul(3).Add(shipping)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if amount := total.PurchaseUnitAmount(); amount.Currency != "USD" || amount.Value != "64.97" {
+		t.Errorf("unexpected purchase unit amount %+v", amount)
+	}
+
+	if _, err := total.Sub(NewMoneyAmount("EUR", 1)); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("expected ErrCurrencyMismatch, got %v", err)
+	}
+}
+
+func TestOrderBuilder(t *testing.T) {
+	req, err := NewOrderBuilder(OrderIntentCapture, "USD").
+		InvoiceID("INV-001").
+		AddItem(Item{Name: "T-shirt", Quantity: "2", UnitAmount: &Money{Currency: "USD", Value: "15.00"}, Tax: &Money{Currency: "USD", Value: "1.20"}}).
+		AddItem(Item{Name: "Cap", Quantity: "1", UnitAmount: &Money{Currency: "USD", Value: "9.99"}}).
+		Shipping(NewMoneyAmount("USD", 500)).
+		Discount(NewMoneyAmount("USD", 299)).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, _ := json.Marshal(req.PurchaseUnits[0].Amount)
+	expected := `{"currency_code":"USD","value":"44.40","breakdown":{"item_total":{"currency_code":"USD","value":"39.99"},"shipping":{"currency_code":"USD","value":"5.00"},"tax_total":{"currency_code":"USD","value":"2.40"},"discount":{"currency_code":"USD","value":"2.99"}}}`
+	if string(b) != expected {
+		t.Errorf("unexpected amount,\n Given:    %s\n Expected: %s", b, expected)
+	}
+	if req.Intent != OrderIntentCapture || req.PurchaseUnits[0].InvoiceID != "INV-001" {
+		t.Errorf("unexpected request %+v", req)
+	}
+
+	_, err = NewOrderBuilder(OrderIntentCapture, "USD").
+		ReferenceID("first").
+		AddItem(Item{Name: "Cap", Quantity: "0", UnitAmount: &Money{Currency: "USD", Value: "9.99"}}).
+		NewPurchaseUnit("second").
+		AddItem(Item{Name: "Cap", Quantity: "1", UnitAmount: &Money{Currency: "EUR", Value: "9.99"}}).
+		Build()
+	var validationErrs ValidationErrors
+	if !errors.As(err, &validationErrs) || len(validationErrs) != 2 {
+		t.Errorf("expected 2 validation errors, got %v", err)
+	}
+
+	req, err = NewOrderBuilder(OrderIntentCapture, "USD").
+		ReferenceID("first").
+		AddItem(Item{Name: "Cap", Quantity: "1", UnitAmount: &Money{Currency: "USD", Value: "9.99"}}).
+		NewPurchaseUnit("second").
+		AddItem(Item{Name: "Lamp", Quantity: "1", UnitAmount: &Money{Currency: "USD", Value: "20.00"}}).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(req.PurchaseUnits) != 2 || req.PurchaseUnits[0].ReferenceID != "first" || req.PurchaseUnits[1].ReferenceID != "second" {
+		t.Errorf("unexpected purchase units %+v", req.PurchaseUnits)
+	}
+
+	_, err = NewOrderBuilder(OrderIntentCapture, "USD").
+		AddItem(Item{Name: "Cap", Quantity: "1", UnitAmount: &Money{Currency: "USD", Value: "9.99"}}).
+		NewPurchaseUnit("second").
+		AddItem(Item{Name: "Lamp", Quantity: "1", UnitAmount: &Money{Currency: "USD", Value: "20.00"}}).
+		Build()
+	if !errors.As(err, &validationErrs) || len(validationErrs) != 1 || validationErrs[0].Field != "purchase_units[0].reference_id" {
+		t.Errorf("expected a missing reference_id error, got %v", err)
+	}
+}
+
+func TestMarketplace(t *testing.T) {
+	payee := &PayeeForOrders{MerchantID: "MERCHANT-1"}
+	req, err := NewOrderBuilder(OrderIntentCapture, "USD").
+		Payee(payee).
+		AddItem(Item{Name: "Lamp", Quantity: "1", UnitAmount: &Money{Currency: "USD", Value: "100.00"}}).
+		PlatformFee(NewMoneyAmount("USD", 1000), nil).
+		DisbursementMode(DisbursementModeDelayed).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := json.Marshal(req.PurchaseUnits[0].PaymentInstruction)
+	expected := `{"platform_fees":[{"amount":{"currency_code":"USD","value":"10.00"}}],"disbursement_mode":"DELAYED"}`
+	if string(b) != expected || req.PurchaseUnits[0].Payee != payee {
+		t.Errorf("unexpected payment instruction,\n Given:    %s\n Expected: %s", b, expected)
+	}
+
+	_, err = NewOrderBuilder(OrderIntentCapture, "USD").
+		AddItem(Item{Name: "Lamp", Quantity: "1", UnitAmount: &Money{Currency: "USD", Value: "5.00"}}).
+		PlatformFee(NewMoneyAmount("USD", 1000), nil).
+		DisbursementMode("LATER").
+		Build()
+	var validationErrs ValidationErrors
+	if !errors.As(err, &validationErrs) || len(validationErrs) != 1 || validationErrs[0].Field != "purchase_units[0].payment_instruction.disbursement_mode" {
+		t.Errorf("expected a disbursement mode error, got %v", err)
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v1/payments/referenced-payouts-items" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if r.Header.Get("PayPal-Partner-Attribution-Id") != "BN-CODE" {
+			t.Errorf("missing partner attribution id")
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		if string(body) != `{"reference_id":"29N36144XH0198422","reference_type":"TRANSACTION_ID"}` {
+			t.Errorf("unexpected body %s", body)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"item_id":"ITEM-1","processing_state":{"status":"SUCCESS"},"payout_amount":{"currency_code":"USD","value":"90.00"}}`))
+	}))
+	defer ts.Close()
+
+	c, err := NewPayPalClient(&PayPal{ClientID: "foo", SecretID: "bar", APIBase: ts.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	item, err := c.DisburseCapture(context.Background(), "29N36144XH0198422", WithPartnerAttributionID("BN-CODE"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item.ItemID != "ITEM-1" || item.ProcessingState.Status != "SUCCESS" || item.PayoutAmount.Value != "90.00" {
+		t.Errorf("unexpected referenced payout item %+v", item)
+	}
+}
+
+func TestEnsureWebhook(t *testing.T) {
+	var requests []string
+	var patch string
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method {
+		case http.MethodGet:
+			w.Write([]byte(`{"webhooks":[
+				{"id":"WH-1","url":"https://example.com/hook","event_types":[{"name":"PAYMENT.CAPTURE.COMPLETED"}]},
+				{"id":"WH-2","url":"https://example.com/other","event_types":[{"name":"PAYMENT.CAPTURE.DENIED"}]},
+				{"id":"WH-3","url":"https://example.com/hook","event_types":[{"name":"PAYMENT.CAPTURE.COMPLETED"}]}
+			]}`))
+		case http.MethodPatch:
+			body, _ := ioutil.ReadAll(r.Body)
+			patch = string(body)
+			w.Write([]byte(`{"id":"WH-1","url":"https://example.com/hook","event_types":[{"name":"PAYMENT.CAPTURE.DENIED"},{"name":"PAYMENT.CAPTURE.COMPLETED"}]}`))
+		case http.MethodDelete:
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	c, err := NewPayPalClient(&PayPal{ClientID: "foo", SecretID: "bar", APIBase: ts.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	webhook, err := c.EnsureWebhook(context.Background(), "https://example.com/hook", []string{"PAYMENT.CAPTURE.DENIED", "PAYMENT.CAPTURE.COMPLETED"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if webhook.ID != "WH-1" {
+		t.Errorf("expected webhook WH-1, got %s", webhook.ID)
+	}
+
+	expected := []string{"GET /v1/notifications/webhooks", "DELETE /v1/notifications/webhooks/WH-3", "PATCH /v1/notifications/webhooks/WH-1"}
+	if strings.Join(requests, ",") != strings.Join(expected, ",") {
+		t.Errorf("unexpected requests,\n Given:    %v\n Expected: %v", requests, expected)
+	}
+	expectedPatch := `[{"op":"replace","path":"/event_types","value":[{"name":"PAYMENT.CAPTURE.DENIED","description":""},{"name":"PAYMENT.CAPTURE.COMPLETED","description":""}]}]`
+	if patch != expectedPatch {
+		t.Errorf("unexpected patch,\n Given:    %s\n Expected: %s", patch, expectedPatch)
+	}
+
+	requests = nil
+	if _, err := c.EnsureWebhook(context.Background(), "https://example.com/other", []string{"PAYMENT.CAPTURE.DENIED"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(requests) != 1 {
+		t.Errorf("expected no change for an up to date webhook, got %v", requests)
+	}
+}
+
+func TestPatchBuilder(t *testing.T) {
+	amount := PurchaseUnitAmount{Currency: "USD", Value: "7.00"}
+	patches := NewPatchBuilder().
+		Replace(PurchaseUnitPatchPath("", PurchaseUnitFieldAmount), amount).
+		Add(PurchaseUnitPatchPath("", PurchaseUnitFieldInvoiceID), "INV-001").
+		Remove(SubscriptionPatchPathShippingAmount).
+		Build()
+
+	b, err := json.Marshal(patches)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `[{"op":"replace","path":"/purchase_units/@reference_id=='default'/amount","value":{"currency_code":"USD","value":"7.00"}},{"op":"add","path":"/purchase_units/@reference_id=='default'/invoice_id","value":"INV-001"},{"op":"remove","path":"/shipping_amount"}]`
+	if string(b) != expected {
+		t.Errorf("unexpected patches,\n Given:    %s\n Expected: %s", b, expected)
+	}
+
+	fields := NewPatchBuilder().Replace(WebhookPatchPathURL, "https://example.com/hook").BuildWebhookFields()
+	if len(fields) != 1 || fields[0].Operation != PatchOperationReplace || fields[0].Path != "/url" || fields[0].Value != "https://example.com/hook" {
+		t.Errorf("unexpected webhook fields %+v", fields)
+	}
+}
+
+func TestMockResponse(t *testing.T) {
+	var mocks []string
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mocks = append(mocks, r.Header.Get("PayPal-Mock-Response"))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"5O190127TN364715T"}`))
+	}))
+	defer ts.Close()
+
+	c, err := NewPayPalClient(&PayPal{ClientID: "foo", SecretID: "bar", APIBase: ts.URL}, WithDefaultMockResponse(MockTransactionRefused))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c.GetOrder(context.Background(), "5O190127TN364715T")
+	c.CaptureOrder(context.Background(), "5O190127TN364715T", CaptureOrderRequest{}, WithMockResponse(MockInstrumentDeclined))
+
+	expected := []string{
+		`{"mock_application_codes":"TRANSACTION_REFUSED"}`,
+		`{"mock_application_codes":"INSTRUMENT_DECLINED"}`,
+	}
+	if fmt.Sprint(mocks) != fmt.Sprint(expected) {
+		t.Errorf("unexpected PayPal-Mock-Response headers,\n Given:    %q\n Expected: %q", mocks, expected)
+	}
+
+	live := &PayPalClient{APIBase: APIBaseLive}
+	req, _ := http.NewRequest(http.MethodGet, APIBaseLive, nil)
+	WithMockResponse(MockInstrumentDeclined)(live, req)
+	if h := req.Header.Get("PayPal-Mock-Response"); h != "" {
+		t.Errorf("expected no mock response header on the live API, got %q", h)
+	}
+}
+
+func TestOrderHelpers(t *testing.T) {
+	var polls int
+	var voided []string
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v2/checkout/orders/5O190127TN364715T":
+			polls++
+			status := "CREATED"
+			if polls == 3 {
+				status = "APPROVED"
+			}
+			w.Write([]byte(`{"id":"5O190127TN364715T","status":"` + status + `","links":[{"href":"https://api.paypal.com/v2/checkout/orders/5O190127TN364715T","rel":"self","method":"GET"},{"href":"https://www.paypal.com/checkoutnow?token=5O190127TN364715T","rel":"approve","method":"GET"},{"href":"https://api.paypal.com/v2/checkout/orders/5O190127TN364715T/capture","rel":"capture","method":"POST"}]}`))
+		case "/v2/checkout/orders/8AA831015G517922L":
+			w.Write([]byte(`{"id":"8AA831015G517922L","status":"COMPLETED","purchase_units":[{"reference_id":"default","payments":{"authorizations":[{"id":"0VF52814937998046","status":"CREATED"},{"id":"8AB96452CK0985221","status":"CAPTURED"}]}},{"reference_id":"second","payments":{"authorizations":[{"id":"2DX84316AW6873537","status":"CREATED"}]}}]}`))
+		default:
+			voided = append(voided, r.URL.Path)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer ts.Close()
+
+	c, err := NewPayPalClient(&PayPal{ClientID: "foo", SecretID: "bar", APIBase: ts.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	order, err := c.WaitForApproval(context.Background(), "5O190127TN364715T", PollOptions{Interval: time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if polls != 3 || order.Status != OrderStatusApproved {
+		t.Errorf("expected the order to be approved after 3 polls, got %d polls and status %s", polls, order.Status)
+	}
+	if order.ApproveURL() != "https://www.paypal.com/checkoutnow?token=5O190127TN364715T" ||
+		order.CaptureURL() != "https://api.paypal.com/v2/checkout/orders/5O190127TN364715T/capture" ||
+		order.SelfURL() != "https://api.paypal.com/v2/checkout/orders/5O190127TN364715T" {
+		t.Errorf("unexpected order links %+v", order.Links)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+	polls = 10
+	if _, err := c.WaitForApproval(ctx, "5O190127TN364715T", PollOptions{Interval: time.Millisecond}); err != context.DeadlineExceeded {
+		t.Errorf("expected the deadline to stop the poller, got %v", err)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	polls = 10
+	if _, err := c.WaitForApproval(ctx, "5O190127TN364715T", PollOptions{}); err != context.DeadlineExceeded || polls != 11 {
+		t.Errorf("expected a single poll within the default interval, got %d polls and %v", polls-10, err)
+	}
+
+	if _, err := c.VoidAuthorizedOrder(context.Background(), "8AA831015G517922L"); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"/v2/payments/authorizations/0VF52814937998046/void", "/v2/payments/authorizations/2DX84316AW6873537/void"}
+	if fmt.Sprint(voided) != fmt.Sprint(expected) {
+		t.Errorf("unexpected voided authorizations %v", voided)
+	}
+}
+
+func TestGetScopedAccessToken(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.Form.Get("grant_type") != "client_credentials" || r.Form.Get("response_type") != "id_token" ||
+			r.Form.Get("scope") != "https://uri.paypal.com/services/payments/payment openid" || r.Form.Get("target_customer_id") != "customer_4029352050" {
+			t.Errorf("unexpected token request %v", r.Form)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"scope":"https://uri.paypal.com/services/payments/payment openid","access_token":"A21AAFEpH4PsADK7qSS7pSRsgzfENtu-Q1ysgEDVDESseMHBYXVJYE8ovjj68elIDy8nF26AwPhfXTIeWAZHSLIsQkSYz9ifg","token_type":"Bearer","app_id":"APP-80W284485P519543T","expires_in":31668,"id_token":"eyJraWQiOiI1MzE..."}`))
+	}))
+	defer ts.Close()
+
+	c, err := NewPayPalClient(&PayPal{ClientID: "foo", SecretID: "bar", APIBase: ts.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := c.GetScopedAccessToken(context.Background(), TokenRequest{
+		Scopes:           []string{"https://uri.paypal.com/services/payments/payment", "openid"},
+		TargetCustomerID: "customer_4029352050",
+		ResponseType:     TokenResponseTypeIDToken,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if token.IDToken != "eyJraWQiOiI1MzE..." || token.Scope != "https://uri.paypal.com/services/payments/payment openid" || token.AppID != "APP-80W284485P519543T" {
+		t.Errorf("unexpected token %+v", token)
+	}
+	if c.(*PayPalClient).Token != nil {
+		t.Error("expected the scoped token not to replace the client token")
+	}
+}
+
+func TestConsent(t *testing.T) {
+	c := &PayPalClient{ClientID: "foo", APIBase: APIBaseSandBox}
+
+	state := NewConsentState()
+	consentURL := c.ConsentURL("https://example.com/callback", []string{"openid", "email"}, state)
+
+	u, err := url.Parse(consentURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := u.Query()
+	if u.Host != "www.sandbox.paypal.com" || u.Path != "/connect" || q.Get("client_id") != "foo" ||
+		q.Get("scope") != "openid email" || q.Get("redirect_uri") != "https://example.com/callback" || q.Get("state") != state {
+		t.Errorf("unexpected consent URL %s", consentURL)
+	}
+
+	code, err := ParseConsentCallback(url.Values{"code": {"C21AAH"}, "state": {state}}, state)
+	if err != nil || code != "C21AAH" {
+		t.Errorf("expected the authorization code, got %q and %v", code, err)
+	}
+	if _, err := ParseConsentCallback(url.Values{"code": {"C21AAH"}, "state": {"forged"}}, state); err != ErrConsentStateMismatch {
+		t.Errorf("expected ErrConsentStateMismatch, got %v", err)
+	}
+	if _, err := ParseConsentCallback(url.Values{"error": {"access_denied"}, "state": {state}}, state); err == nil {
+		t.Error("expected an error when the user denied the consent")
+	}
+	if NewConsentState() == state {
+		t.Error("expected distinct states")
+	}
+}
+
+func TestCreditCardIterator(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("external_customer_id") != "customer-1" || q.Get("start_time") != "2021-01-01T00:00:00Z" || q.Get("sort_by") != "create_time" || q.Get("sort_order") != "desc" || q.Get("page_size") != "2" {
+			t.Errorf("unexpected query %q", r.URL.RawQuery)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch q.Get("page") {
+		case "1":
+			w.Write([]byte(`{"items":[{"id":"CARD-1"},{"id":"CARD-2"}],"total_pages":2,"links":[{"href":"https://api.sandbox.paypal.com/v1/vault/credit-cards?page_size=2&page=2","rel":"next","method":"GET"}]}`))
+		default:
+			w.Write([]byte(`{"items":[{"id":"CARD-3"}],"total_pages":2,"links":[]}`))
+		}
+	}))
+	defer ts.Close()
+
+	c, err := NewPayPalClient(&PayPal{ClientID: "foo", SecretID: "bar", APIBase: ts.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	it := c.NewCreditCardIterator(context.Background(), CreditCardsFilter{
+		PageSize:           2,
+		ExternalCustomerID: "customer-1",
+		StartTime:          time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
+		SortBy:             CreditCardsSortByCreateTime,
+		SortOrder:          SortOrderDesc,
+	})
+
+	var ids []string
+	for it.Next() {
+		ids = append(ids, it.Current().ID)
+	}
+	if it.Err() != nil {
+		t.Fatal(it.Err())
+	}
+	if fmt.Sprint(ids) != "[CARD-1 CARD-2 CARD-3]" {
+		t.Errorf("unexpected credit cards %v", ids)
+	}
+}
+
+func TestCreatePayoutsBatched(t *testing.T) {
+	var mu sync.Mutex
+	batches := map[string]int{}
+	var inFlight, maxInFlight int32
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			max := atomic.LoadInt32(&maxInFlight)
+			if n <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+
+		var payout Payout
+		json.NewDecoder(r.Body).Decode(&payout)
+		mu.Lock()
+		batches[payout.SenderBatchHeader.SenderBatchID] = len(payout.Items)
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		if payout.SenderBatchHeader.SenderBatchID == "batch-3" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"name":"USER_BUSINESS_ERROR","message":"User business error.","details":[{"issue":"SENDER_BATCH_ID_ALREADY_USED"}]}`))
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"batch_header":{"payout_batch_id":"` + payout.SenderBatchHeader.SenderBatchID + `","batch_status":"PENDING"}}`))
+	}))
+	defer ts.Close()
+
+	c, err := NewPayPalClient(&PayPal{ClientID: "foo", SecretID: "bar", APIBase: ts.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	items := make([]PayoutItem, 5)
+	results, err := c.CreatePayoutsBatched(context.Background(), SenderBatchHeader{SenderBatchID: "batch"}, items, PayoutBatchOptions{BatchSize: 2, Concurrency: 2})
+	if err == nil || !errors.Is(err, ErrValidation) {
+		t.Errorf("expected the failed batch to be reported, got %v", err)
+	}
+
+	if len(results) != 3 || results[0].Response.BatchHeader.PayoutBatchID != "batch-1" || results[1].Err != nil || results[2].Err == nil {
+		t.Errorf("unexpected results %+v", results)
+	}
+	if fmt.Sprint(batches) != "map[batch-1:2 batch-2:2 batch-3:1]" {
+		t.Errorf("unexpected batches %v", batches)
+	}
+	if maxInFlight > 2 {
+		t.Errorf("expected at most 2 batches at once, got %d", maxInFlight)
+	}
+
+	if _, err := c.CreatePayoutsBatched(context.Background(), SenderBatchHeader{}, items, PayoutBatchOptions{}); err == nil {
+		t.Error("expected an error without sender batch id")
+	}
+}
+
+func TestWaitForPayoutCompletion(t *testing.T) {
+	var polls int
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Query().Get("page") {
+		case "":
+			polls++
+			status := "PROCESSING"
+			if polls == 3 {
+				status = "SUCCESS"
+			}
+			w.Write([]byte(`{"batch_header":{"payout_batch_id":"FYXMPQTX4JC9N","batch_status":"` + status + `"}}`))
+		case "1":
+			w.Write([]byte(`{"batch_header":{"payout_batch_id":"FYXMPQTX4JC9N","batch_status":"SUCCESS"},"items":[{"payout_item_id":"8AELMXH8UB2P8","transaction_status":"SUCCESS"}],"links":[{"href":"https://api.sandbox.paypal.com/v1/payments/payouts/FYXMPQTX4JC9N?page=2","rel":"next","method":"GET"}]}`))
+		case "2":
+			w.Write([]byte(`{"batch_header":{"payout_batch_id":"FYXMPQTX4JC9N","batch_status":"SUCCESS"},"items":[{"payout_item_id":"9AELMXH8UB2P9","transaction_status":"UNCLAIMED"}],"links":[]}`))
+		}
+	}))
+	defer ts.Close()
+
+	c, err := NewPayPalClient(&PayPal{ClientID: "foo", SecretID: "bar", APIBase: ts.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	payout, err := c.WaitForPayoutCompletion(context.Background(), "FYXMPQTX4JC9N", PollOptions{Interval: time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if polls != 3 || payout.BatchHeader.BatchStatus != PayoutBatchStatusSuccess || len(payout.Items) != 2 || payout.Items[1].TransactionStatus != "UNCLAIMED" {
+		t.Errorf("unexpected payout after %d polls: %+v", polls, payout)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+	polls = 10
+	if _, err := c.WaitForPayoutCompletion(ctx, "FYXMPQTX4JC9N", PollOptions{Interval: time.Millisecond}); err != context.DeadlineExceeded {
+		t.Errorf("expected the deadline to stop the poller, got %v", err)
+	}
+}
+
+func TestTypeErrorResponseDetails(t *testing.T) {
+	response := `{
+		"name": "UNPROCESSABLE_ENTITY",
+		"details": [{
+			"field": "/purchase_units/@reference_id=='default'/amount/value",
+			"value": "-10.00",
+			"location": "body",
+			"issue": "CANNOT_BE_ZERO_OR_NEGATIVE",
+			"description": "Must be greater than zero.",
+			"links": [{"href": "https://developer.paypal.com/docs/api/orders/v2/#error-CANNOT_BE_ZERO_OR_NEGATIVE", "rel": "information_link", "method": "GET"}]
+		}],
+		"links": [{"href": "https://developer.paypal.com/docs/api/orders/v2/#error-CANNOT_BE_ZERO_OR_NEGATIVE", "rel": "information_link", "method": "GET"}]
+	}`
+
+	i := &ErrorResponse{}
+	if err := json.Unmarshal([]byte(response), i); err != nil {
+		t.Fatal(err)
+	}
+
+	d := i.Details[0]
+	if d.Value != "-10.00" || d.Location != "body" || d.Description != "Must be greater than zero." || d.Issue != "CANNOT_BE_ZERO_OR_NEGATIVE" ||
+		len(d.Links) != 1 || d.Links[0].Rel != "information_link" || len(i.Links) != 1 {
+		t.Errorf("ErrorResponse decoded result is incorrect, Given: %+v", i)
+	}
+}
+
+func TestMollie(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer test_key" {
+			t.Errorf("unexpected authorization %q", r.Header.Get("Authorization"))
+		}
+		w.Header().Set("Content-Type", "application/hal+json")
+
+		switch r.Method + " " + r.URL.Path {
+		case "POST /payments":
+			body, _ := ioutil.ReadAll(r.Body)
+			expected := `{"amount":{"currency":"EUR","value":"10.00"},"description":"Order 12345","redirectUrl":"https://example.com/return","webhookUrl":"https://example.com/webhook","method":["ideal"],"issuer":"ideal_INGBNL2A"}`
+			if string(body) != expected {
+				t.Errorf("unexpected body,\n Given:    %s\n Expected: %s", body, expected)
+			}
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"id":"tr_WDqYK6vllg","status":"open","amount":{"currency":"EUR","value":"10.00"},"createdAt":"2018-03-20T13:13:37+00:00","_links":{"checkout":{"href":"https://www.mollie.com/payscreen/select-method/WDqYK6vllg","type":"text/html"}}}`))
+		case "GET /payments/tr_WDqYK6vllg":
+			w.Write([]byte(`{"id":"tr_WDqYK6vllg","status":"paid","amount":{"currency":"EUR","value":"10.00"},"_links":{}}`))
+		case "POST /payments/tr_WDqYK6vllg/refunds":
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			w.Write([]byte(`{"status":422,"title":"Unprocessable Entity","detail":"The amount is higher than the remaining amount","field":"amount"}`))
+		case "GET /methods":
+			if r.URL.Query().Get("include") != "issuers" || r.URL.Query().Get("amount[value]") != "10.00" {
+				t.Errorf("unexpected query %s", r.URL.RawQuery)
+			}
+			w.Write([]byte(`{"count":1,"_embedded":{"methods":[{"id":"ideal","description":"iDEAL","issuers":[{"id":"ideal_INGBNL2A","name":"ING"}]}]}}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	if _, err := NewMollieClient(&Mollie{}); err != ErrInvalidMollieConfig {
+		t.Errorf("expected ErrInvalidMollieConfig, got %v", err)
+	}
+	c, ok := New(ctx, MOLLIE, &Config{Mollie: Mollie{APIKey: "test_key", APIBase: ts.URL}}).(IMollie)
+	if !ok {
+		t.Fatal("expected New to return an IMollie")
+	}
+
+	methods, err := c.ListMethods(context.Background(), &MollieAmount{Currency: "EUR", Value: "10.00"}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	issuer := methods.Embedded.Methods[0].Issuers[0].ID
+
+	payment, err := c.CreatePayment(context.Background(), MolliePaymentRequest{
+		Amount:      MollieAmount{Currency: "EUR", Value: "10.00"},
+		Description: "Order 12345",
+		RedirectURL: "https://example.com/return",
+		WebhookURL:  "https://example.com/webhook",
+		Method:      []string{MollieMethodIDEAL},
+		Issuer:      issuer,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if payment.CheckoutURL() != "https://www.mollie.com/payscreen/select-method/WDqYK6vllg" || payment.CreatedAt.IsZero() {
+		t.Errorf("unexpected payment %+v", payment)
+	}
+
+	webhook := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader("id=tr_WDqYK6vllg"))
+	webhook.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	payment, err = c.HandleWebhook(webhook)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !payment.IsPaid() {
+		t.Errorf("expected a paid payment, got %s", payment.Status)
+	}
+
+	_, err = c.CreateRefund(context.Background(), "tr_WDqYK6vllg", MollieRefundRequest{Amount: MollieAmount{Currency: "EUR", Value: "20.00"}})
+	var mollieErr *MollieError
+	if !errors.As(err, &mollieErr) || mollieErr.Field != "amount" || !errors.Is(err, ErrValidation) {
+		t.Errorf("expected a validation MollieError, got %v", err)
+	}
+}
+
+func TestPayoneer(t *testing.T) {
+	var tokens int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/api/v2/oauth2/token" {
+			tokens++
+			if user, pass, _ := r.BasicAuth(); user != "foo" || pass != "bar" {
+				t.Errorf("unexpected credentials %s:%s", user, pass)
+			}
+			w.Write([]byte(`{"token_type":"Bearer","access_token":"TOKEN","expires_in":2592000,"scope":"read write"}`))
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer TOKEN" {
+			t.Errorf("unexpected authorization %q", r.Header.Get("Authorization"))
+		}
+
+		switch r.Method + " " + r.URL.Path {
+		case "POST /v4/programs/100086/payees/registration-link":
+			w.Write([]byte(`{"result":{"registration_link":"https://payouts.sandbox.payoneer.com/partners/lp.aspx?token=abc","token":"abc"}}`))
+		case "POST /v4/programs/100086/masspayouts":
+			body, _ := ioutil.ReadAll(r.Body)
+			expected := `{"Payments":[{"client_reference_id":"PO-1","payee_id":"seller-1","description":"March sales","currency":"USD","amount":1234.5}]}`
+			if string(body) != expected {
+				t.Errorf("unexpected body,\n Given:    %s\n Expected: %s", body, expected)
+			}
+			w.Write([]byte(`{"result":"Payments Created"}`))
+		case "GET /v4/programs/100086/payouts/PO-1/status":
+			w.Write([]byte(`{"result":{"payout_id":"123","status":"Transferred","amount":1234.5,"currency":"USD"}}`))
+		case "GET /v4/programs/100086/payees/unknown/status":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":"Not Found","error_description":"Payee was not found"}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	if _, err := NewPayoneerClient(&Payoneer{ClientID: "foo"}); err != ErrInvalidPayoneerConfig {
+		t.Errorf("expected ErrInvalidPayoneerConfig, got %v", err)
+	}
+	c, ok := New(ctx, PAYONEER, &Config{Payoneer: Payoneer{ClientID: "foo", SecretID: "bar", ProgramID: "100086", APIBase: ts.URL}}).(IPayoneer)
+	if !ok {
+		t.Fatal("expected New to return an IPayoneer")
+	}
+
+	link, err := c.CreateRegistrationLink(context.Background(), PayoneerRegistrationRequest{PayeeID: "seller-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if link.Token != "abc" {
+		t.Errorf("unexpected registration link %+v", link)
+	}
+
+	if _, err = c.SubmitPayouts(context.Background(), []PayoneerPayout{NewPayoneerPayout("PO-1", "seller-1", "March sales", NewMoneyAmount("USD", 123450))}); err != nil {
+		t.Fatal(err)
+	}
+
+	status, err := c.GetPayoutStatus(context.Background(), "PO-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Status != PayoneerPayoutStatusTransferred {
+		t.Errorf("unexpected status %+v", status)
+	}
+
+	if _, err = c.GetPayeeStatus(context.Background(), "unknown"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	if tokens != 1 {
+		t.Errorf("expected the access token to be requested once, got %d", tokens)
+	}
+}
+
+func TestDwolla(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"access_token":"TOKEN","token_type":"bearer","expires_in":3600}`))
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer TOKEN" || r.Header.Get("Accept") != "application/vnd.dwolla.v1.hal+json" {
+			t.Errorf("unexpected headers %v", r.Header)
+		}
+
+		switch r.Method + " " + r.URL.Path {
+		case "POST /customers/FC451A7A/funding-sources":
+			body, _ := ioutil.ReadAll(r.Body)
+			if string(body) != `{"name":"Jane Checking","plaidToken":"processor-sandbox-161c86dd"}` {
+				t.Errorf("unexpected body %s", body)
+			}
+			w.Header().Set("Location", "https://api-sandbox.dwolla.com/funding-sources/375c6781")
+			w.WriteHeader(http.StatusCreated)
+		case "POST /transfers":
+			body, _ := ioutil.ReadAll(r.Body)
+			expected := `{"_links":{"destination":{"href":"https://api-sandbox.dwolla.com/funding-sources/375c6781"},"source":{"href":"https://api-sandbox.dwolla.com/funding-sources/707177c3"}},"amount":{"currency":"USD","value":"225.00"}}`
+			if string(body) != expected {
+				t.Errorf("unexpected body,\n Given:    %s\n Expected: %s", body, expected)
+			}
+			if r.Header.Get("Idempotency-Key") != "order-1" {
+				t.Errorf("missing idempotency key")
+			}
+			w.Header().Set("Location", "https://api-sandbox.dwolla.com/transfers/d76265cd")
+			w.WriteHeader(http.StatusCreated)
+		case "POST /transfers/d76265cd":
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"code":"ValidationError","message":"Validation error(s) present.","_embedded":{"errors":[{"code":"InvalidStatus","message":"Transfer cannot be cancelled.","path":"/status"}]}}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	c, ok := New(ctx, DWOLLA, &Config{Dwolla: Dwolla{Key: "foo", Secret: "bar", APIBase: ts.URL}}).(IDwolla)
+	if !ok {
+		t.Fatal("expected New to return an IDwolla")
+	}
+
+	location, err := c.CreateFundingSource(context.Background(), "FC451A7A", DwollaFundingSourceRequest{Name: "Jane Checking", PlaidToken: "processor-sandbox-161c86dd"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if DwollaResourceID(location) != "375c6781" {
+		t.Errorf("unexpected funding source %s", location)
+	}
+
+	transfer := NewDwollaTransfer("https://api-sandbox.dwolla.com/funding-sources/707177c3", location, NewMoneyAmount("USD", 22500))
+	location, err = c.CreateTransfer(context.Background(), transfer, "order-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = c.CancelTransfer(context.Background(), DwollaResourceID(location))
+	var dwollaErr *DwollaError
+	if !errors.As(err, &dwollaErr) || len(dwollaErr.Embedded.Errors) != 1 || !errors.Is(err, ErrValidation) {
+		t.Errorf("expected a validation DwollaError, got %v", err)
+	}
+
+	body := `{"id":"80d8ff2b","resourceId":"d76265cd","topic":"customer_transfer_completed","timestamp":"2019-01-03T19:45:20.123Z"}`
+	mac := hmac.New(sha256.New, []byte("webhook-secret"))
+	mac.Write([]byte(body))
+	webhook := httptest.NewRequest(http.MethodPost, "/dwolla", strings.NewReader(body))
+	webhook.Header.Set("X-Request-Signature-SHA-256", hex.EncodeToString(mac.Sum(nil)))
+	event, err := VerifyDwollaWebhook(webhook, "webhook-secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.Topic != "customer_transfer_completed" || event.ResourceID != "d76265cd" {
+		t.Errorf("unexpected event %+v", event)
+	}
+
+	webhook = httptest.NewRequest(http.MethodPost, "/dwolla", strings.NewReader(body))
+	webhook.Header.Set("X-Request-Signature-SHA-256", hex.EncodeToString(mac.Sum(nil)))
+	if _, err = VerifyDwollaWebhook(webhook, "other-secret"); err != ErrDwollaWebhookSignature {
+		t.Errorf("expected ErrDwollaWebhookSignature, got %v", err)
+	}
+}
+
+func TestCoinbaseCommerce(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method + " " + r.URL.Path {
+		case "POST /charges":
+			if r.Header.Get("X-CC-Api-Key") != "key" || r.Header.Get("X-CC-Version") != "2018-03-22" {
+				t.Errorf("unexpected headers %v", r.Header)
+			}
+			body, _ := ioutil.ReadAll(r.Body)
+			expected := `{"name":"Invoice 42","description":"Consulting","pricing_type":"fixed_price","local_price":{"amount":"100.00","currency":"USD"}}`
+			if string(body) != expected {
+				t.Errorf("unexpected body,\n Given:    %s\n Expected: %s", body, expected)
+			}
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"data":{"id":"f765421f","code":"66BEOV2A","hosted_url":"https://commerce.coinbase.com/charges/66BEOV2A","created_at":"2017-01-31T20:49:02Z","expires_at":"2017-01-31T21:49:02Z","pricing":{"local":{"amount":"100.00","currency":"USD"},"bitcoin":{"amount":"0.00414","currency":"BTC"}},"timeline":[{"time":"2017-01-31T20:49:02Z","status":"NEW"}]}}`))
+		case "GET /charges/unknown":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":{"type":"not_found","message":"Not found"}}`))
+		case "GET /v2/exchange-rates":
+			if r.Header.Get("X-CC-Api-Key") != "" {
+				t.Error("the API key must not be sent to the public API")
+			}
+			w.Write([]byte(`{"data":{"currency":"BTC","rates":{"USD":"24150.50"}}}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	c, ok := New(ctx, COINBASE_COMMERCE, &Config{CoinbaseCommerce: CoinbaseCommerce{APIKey: "key", APIBase: ts.URL, ExchangeRatesAPIBase: ts.URL}}).(ICoinbaseCommerce)
+	if !ok {
+		t.Fatal("expected New to return an ICoinbaseCommerce")
+	}
+
+	charge, err := c.CreateCharge(context.Background(), CoinbaseChargeRequest{
+		Name:        "Invoice 42",
+		Description: "Consulting",
+		PricingType: CoinbasePricingTypeFixedPrice,
+		LocalPrice:  &CoinbaseMoney{Amount: "100.00", Currency: "USD"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if charge.Code != "66BEOV2A" || charge.Status() != CoinbaseChargeStatusNew || charge.Pricing["bitcoin"].Amount != "0.00414" {
+		t.Errorf("unexpected charge %+v", charge)
+	}
+
+	_, err = c.GetCharge(context.Background(), "unknown")
+	var coinbaseErr *CoinbaseError
+	if !errors.As(err, &coinbaseErr) || coinbaseErr.Type != "not_found" || !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected a not found CoinbaseError, got %v", err)
+	}
+
+	rates, err := c.GetExchangeRates(context.Background(), "BTC")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rates.Rates["USD"] != "24150.50" {
+		t.Errorf("unexpected rates %+v", rates)
+	}
+
+	body := `{"id":1,"scheduled_for":"2017-01-31T20:50:02Z","event":{"id":"24934862","type":"charge:confirmed","api_version":"2018-03-22","created_at":"2017-01-31T20:49:02Z","data":{"code":"66BEOV2A","timeline":[{"time":"2017-01-31T20:49:02Z","status":"COMPLETED"}]}}}`
+	mac := hmac.New(sha256.New, []byte("shared-secret"))
+	mac.Write([]byte(body))
+	webhook := httptest.NewRequest(http.MethodPost, "/coinbase", strings.NewReader(body))
+	webhook.Header.Set("X-CC-Webhook-Signature", hex.EncodeToString(mac.Sum(nil)))
+	event, err := VerifyCoinbaseWebhook(webhook, "shared-secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	charge, err = event.Charge()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.Type != CoinbaseEventChargeConfirmed || charge.Status() != CoinbaseChargeStatusCompleted {
+		t.Errorf("unexpected event %+v", event)
+	}
+
+	webhook = httptest.NewRequest(http.MethodPost, "/coinbase", strings.NewReader(body))
+	webhook.Header.Set("X-CC-Webhook-Signature", "00")
+	if _, err = VerifyCoinbaseWebhook(webhook, "shared-secret"); err != ErrCoinbaseWebhookSignature {
+		t.Errorf("expected ErrCoinbaseWebhookSignature, got %v", err)
+	}
+}
+
+type fakeBitPaySigner struct {
+	messages []string
+}
+
+func (s *fakeBitPaySigner) PublicKey() string {
+	return "02a1633cafcc01ebfb6d78e39f687a1f0995c62fc95f51ead10a02ee0be551b5dc"
+}
+
+func (s *fakeBitPaySigner) Sign(message []byte) (string, error) {
+	s.messages = append(s.messages, string(message))
+	return "3045022100", nil
+}
+
+func TestBitPay(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Accept-Version") != "2.0.0" {
+			t.Errorf("missing X-Accept-Version")
+		}
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.Method + " " + r.URL.Path {
+		case "POST /invoices":
+			body, _ := ioutil.ReadAll(r.Body)
+			expected := `{"token":"pos-token","price":10.5,"currency":"USD","orderId":"order-1","notificationURL":"https://example.com/ipn"}`
+			if string(body) != expected {
+				t.Errorf("unexpected body,\n Given:    %s\n Expected: %s", body, expected)
+			}
+			w.Write([]byte(`{"data":{"id":"G3viJEJgE8Jk2oekSdgT2A","url":"https://test.bitpay.com/invoice?id=G3viJEJgE8Jk2oekSdgT2A","status":"new","price":10.5,"currency":"USD"}}`))
+		case "GET /invoices/G3viJEJgE8Jk2oekSdgT2A":
+			w.Write([]byte(`{"data":{"id":"G3viJEJgE8Jk2oekSdgT2A","status":"confirmed","price":10.5,"currency":"USD"}}`))
+		case "POST /refunds":
+			if r.Header.Get("X-Identity") == "" || r.Header.Get("X-Signature") != "3045022100" {
+				t.Errorf("expected a signed request")
+			}
+			w.Write([]byte(`{"data":{"id":"WoE46gSLkJQS48RJEiNw3L","invoice":"G3viJEJgE8Jk2oekSdgT2A","status":"created","amount":10.5,"currency":"USD"}}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+	apiBase := ts.URL
+
+	c, ok := New(ctx, BITPAY, &Config{BitPay: BitPay{APIBase: apiBase, POSToken: "pos-token"}}).(IBitPay)
+	if !ok {
+		t.Fatal("expected New to return an IBitPay")
+	}
+
+	invoice, err := c.CreateInvoice(context.Background(), BitPayInvoiceRequest{Price: 10.5, Currency: "USD", OrderID: "order-1", NotificationURL: "https://example.com/ipn"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if invoice.Status != BitPayInvoiceStatusNew {
+		t.Errorf("unexpected invoice %+v", invoice)
+	}
+
+	invoice, err = c.WaitForInvoice(context.Background(), invoice.ID, PollOptions{Interval: time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if invoice.Status != BitPayInvoiceStatusConfirmed {
+		t.Errorf("unexpected invoice %+v", invoice)
+	}
+
+	ipn := httptest.NewRequest(http.MethodPost, "/ipn", strings.NewReader(`{"event":{"code":1005,"name":"invoice_confirmed"},"data":{"id":"G3viJEJgE8Jk2oekSdgT2A","status":"complete"}}`))
+	if _, _, err = c.HandleIPN(ipn); err != ErrBitPayIPNMismatch {
+		t.Errorf("expected ErrBitPayIPNMismatch, got %v", err)
+	}
+
+	if _, err = c.CreateRefund(context.Background(), BitPayRefundRequest{InvoiceID: invoice.ID, Amount: 10.5}); err != ErrBitPaySignerRequired {
+		t.Errorf("expected ErrBitPaySignerRequired, got %v", err)
+	}
+
+	signer := &fakeBitPaySigner{}
+	c, err = NewBitPayClient(&BitPay{APIBase: apiBase, POSToken: "pos-token", MerchantToken: "merchant-token"}, signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	refund, err := c.CreateRefund(context.Background(), BitPayRefundRequest{InvoiceID: invoice.ID, Amount: 10.5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if refund.Status != "created" {
+		t.Errorf("unexpected refund %+v", refund)
+	}
+	expected := apiBase + `/refunds{"token":"merchant-token","invoiceId":"G3viJEJgE8Jk2oekSdgT2A","amount":10.5}`
+	if len(signer.messages) != 1 || signer.messages[0] != expected {
+		t.Errorf("unexpected signed message,\n Given:    %v\n Expected: %s", signer.messages, expected)
+	}
+}
+
+func TestAlipay(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	privateDER, _ := x509.MarshalPKCS8PrivateKey(key)
+	publicDER, _ := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	privateKey := string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER}))
+	publicKey := base64.StdEncoding.EncodeToString(publicDER)
+
+	sign := func(content string) string {
+		hashed := sha256.Sum256([]byte(content))
+		signature, _ := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, hashed[:])
+		return base64.StdEncoding.EncodeToString(signature)
+	}
+	verify := func(params url.Values) {
+		hashed := sha256.Sum256([]byte(alipaySignContent(params, "sign")))
+		signature, _ := base64.StdEncoding.DecodeString(params.Get("sign"))
+		if rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, hashed[:], signature) != nil {
+			t.Errorf("invalid request signature for %s", params.Get("method"))
+		}
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		verify(r.PostForm)
+		if r.PostForm.Get("app_id") != "2021000000000000" || r.PostForm.Get("notify_url") != "https://example.com/notify" {
+			t.Errorf("unexpected common parameters %v", r.PostForm)
+		}
+
+		var node string
+		switch r.PostForm.Get("method") {
+		case "alipay.trade.query":
+			node = `{"code":"10000","msg":"Success","trade_no":"2024010122001","out_trade_no":"order-1","trade_status":"TRADE_SUCCESS","total_amount":"88.88"}`
+		case "alipay.trade.refund":
+			node = `{"code":"40004","msg":"Business Failed","sub_code":"ACQ.TRADE_NOT_EXIST","sub_msg":"trade not exist"}`
+		case "alipay.trade.close":
+			node = `{"code":"10000","msg":"Success","trade_no":"2024010122001"}`
+			fmt.Fprintf(w, `{"alipay_trade_close_response":%s,"sign":"%s"}`, node, sign(`{"tampered":true}`))
+			return
+		default:
+			t.Errorf("unexpected method %s", r.PostForm.Get("method"))
+		}
+		fmt.Fprintf(w, `{"%s_response":%s,"sign":"%s"}`, strings.Replace(r.PostForm.Get("method"), ".", "_", -1), node, sign(node))
+	}))
+	defer ts.Close()
+
+	config := &Config{Alipay: Alipay{AppID: "2021000000000000", PrivateKey: privateKey, AlipayPublicKey: publicKey, APIBase: ts.URL, NotifyURL: "https://example.com/notify"}}
+	c, ok := New(ctx, ALIPAY, config).(IAlipay)
+	if !ok {
+		t.Fatal("expected New to return an IAlipay")
+	}
+
+	payURL, err := c.PagePayURL(AlipayTradePagePay{OutTradeNo: "order-1", TotalAmount: "88.88", Subject: "Order 1", ReturnURL: "https://example.com/return"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, _ := url.Parse(payURL)
+	verify(parsed.Query())
+	if parsed.Query().Get("return_url") != "https://example.com/return" || !strings.Contains(parsed.Query().Get("biz_content"), AlipayProductCodeFastInstantTradePay) {
+		t.Errorf("unexpected page pay URL %s", payURL)
+	}
+
+	trade, err := c.TradeQuery(context.Background(), AlipayTradeQuery{OutTradeNo: "order-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if trade.TradeStatus != AlipayTradeStatusSuccess || trade.TotalAmount != "88.88" {
+		t.Errorf("unexpected trade %+v", trade)
+	}
+
+	if _, err = c.TradeRefund(context.Background(), AlipayTradeRefund{OutTradeNo: "order-2", RefundAmount: "1.00"}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+
+	if err = c.TradeClose(context.Background(), AlipayTradeQuery{TradeNo: "2024010122001"}); err != ErrAlipaySignature {
+		t.Errorf("expected ErrAlipaySignature, got %v", err)
+	}
+
+	notification := url.Values{
+		"app_id":       {"2021000000000000"},
+		"notify_id":    {"notify-1"},
+		"trade_no":     {"2024010122001"},
+		"out_trade_no": {"order-1"},
+		"trade_status": {AlipayTradeStatusSuccess},
+		"total_amount": {"88.88"},
+		"sign_type":    {"RSA2"},
+	}
+	notification.Set("sign", sign(alipaySignContent(notification, "sign", "sign_type")))
+	req := httptest.NewRequest(http.MethodPost, "/notify", strings.NewReader(notification.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	notified, err := c.VerifyNotification(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if notified.OutTradeNo != "order-1" || notified.TradeStatus != AlipayTradeStatusSuccess {
+		t.Errorf("unexpected notification %+v", notified)
+	}
+
+	notification.Set("total_amount", "0.01")
+	req = httptest.NewRequest(http.MethodPost, "/notify", strings.NewReader(notification.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if _, err = c.VerifyNotification(req); err != ErrAlipaySignature {
+		t.Errorf("expected ErrAlipaySignature, got %v", err)
+	}
+}
+
+func TestWeChatPay(t *testing.T) {
+	apiV3Key := "0123456789abcdef0123456789abcdef"
+	merchantKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	platformKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{SerialNumber: big.NewInt(1), NotBefore: time.Now().Add(-time.Hour), NotAfter: time.Now().Add(time.Hour)}
+	certificateDER, err := x509.CreateCertificate(rand.Reader, template, template, &platformKey.PublicKey, platformKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	merchantDER := x509.MarshalPKCS1PrivateKey(merchantKey)
+
+	encrypt := func(plaintext []byte, associatedData string) WeChatPayEncryptedResource {
+		block, _ := aes.NewCipher([]byte(apiV3Key))
+		gcm, _ := cipher.NewGCM(block)
+		nonce := "0123456789ab"
+		ciphertext := gcm.Seal(nil, []byte(nonce), plaintext, []byte(associatedData))
+		return WeChatPayEncryptedResource{Algorithm: "AEAD_AES_256_GCM", Ciphertext: base64.StdEncoding.EncodeToString(ciphertext), AssociatedData: associatedData, Nonce: nonce}
+	}
+	signHeaders := func(header http.Header, body []byte) {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		hashed := sha256.Sum256([]byte(timestamp + "\nnonce\n" + string(body) + "\n"))
+		signature, _ := rsa.SignPKCS1v15(rand.Reader, platformKey, crypto.SHA256, hashed[:])
+		header.Set("Wechatpay-Timestamp", timestamp)
+		header.Set("Wechatpay-Nonce", "nonce")
+		header.Set("Wechatpay-Serial", "PLATFORM1")
+		header.Set("Wechatpay-Signature", base64.StdEncoding.EncodeToString(signature))
+	}
+
+	var certificateDownloads int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		authorization := r.Header.Get("Authorization")
+		if !strings.HasPrefix(authorization, `WECHATPAY2-SHA256-RSA2048 mchid="1900000001"`) || !strings.Contains(authorization, `serial_no="MERCHANT1"`) {
+			t.Errorf("unexpected Authorization %s", authorization)
+		}
+		fields := map[string]string{}
+		for _, field := range strings.Split(strings.TrimPrefix(authorization, "WECHATPAY2-SHA256-RSA2048 "), ",") {
+			kv := strings.SplitN(field, "=", 2)
+			fields[kv[0]] = strings.Trim(kv[1], `"`)
+		}
+		message := r.Method + "\n" + r.URL.RequestURI() + "\n" + fields["timestamp"] + "\n" + fields["nonce_str"] + "\n" + string(body) + "\n"
+		hashed := sha256.Sum256([]byte(message))
+		signature, _ := base64.StdEncoding.DecodeString(fields["signature"])
+		if rsa.VerifyPKCS1v15(&merchantKey.PublicKey, crypto.SHA256, hashed[:], signature) != nil {
+			t.Errorf("invalid request signature for %s %s", r.Method, r.URL.RequestURI())
+		}
+
+		var response []byte
+		switch r.Method + " " + r.URL.Path {
+		case "GET /v3/certificates":
+			atomic.AddInt32(&certificateDownloads, 1)
+			certificate := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificateDER})
+			response, _ = json.Marshal(map[string]interface{}{"data": []interface{}{map[string]interface{}{
+				"serial_no":           "PLATFORM1",
+				"encrypt_certificate": encrypt(certificate, "certificate"),
+			}}})
+		case "POST /v3/pay/transactions/native":
+			expected := `{"appid":"wxd678efh567hg6787","mchid":"1900000001","description":"Order 1","out_trade_no":"order-1","notify_url":"https://example.com/notify","amount":{"total":100,"currency":"CNY"}}`
+			if string(body) != expected {
+				t.Errorf("unexpected body,\n Given:    %s\n Expected: %s", body, expected)
+			}
+			response = []byte(`{"code_url":"weixin://wxpay/bizpayurl?pr=p4lpSuKzz"}`)
+		case "GET /v3/pay/transactions/out-trade-no/order-2":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"code":"ORDER_NOT_EXIST","message":"order not exist"}`))
+			return
+		case "POST /v3/pay/transactions/out-trade-no/order-1/close":
+			signHeaders(w.Header(), nil)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		signHeaders(w.Header(), response)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(response)
+	}))
+	defer ts.Close()
+
+	config := &Config{WeChatPay: WeChatPay{
+		MchID:      "1900000001",
+		AppID:      "wxd678efh567hg6787",
+		SerialNo:   "MERCHANT1",
+		PrivateKey: base64.StdEncoding.EncodeToString(merchantDER),
+		APIv3Key:   apiV3Key,
+		APIBase:    ts.URL,
+		NotifyURL:  "https://example.com/notify",
+	}}
+	c, ok := New(ctx, WECHAT_PAY, config).(IWeChatPay)
+	if !ok {
+		t.Fatal("expected New to return an IWeChatPay")
+	}
+
+	native, err := c.CreateNativeTransaction(context.Background(), WeChatPayTransaction{Description: "Order 1", OutTradeNo: "order-1", Amount: WeChatPayAmount{Total: 100}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if native.CodeURL != "weixin://wxpay/bizpayurl?pr=p4lpSuKzz" {
+		t.Errorf("unexpected response %+v", native)
+	}
+
+	if err = c.CloseTransaction(context.Background(), "order-1"); err != nil {
+		t.Fatal(err)
+	}
+	if atomic.LoadInt32(&certificateDownloads) != 1 {
+		t.Errorf("expected the certificates to be downloaded once, got %d", certificateDownloads)
+	}
+
+	if _, err = c.QueryTransaction(context.Background(), "order-2"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+
+	params, err := c.JSAPIPayParams("", "wx201410272009395522657a690389285100")
+	if err != nil {
+		t.Fatal(err)
+	}
+	hashed := sha256.Sum256([]byte(params.AppID + "\n" + params.TimeStamp + "\n" + params.NonceStr + "\n" + params.Package + "\n"))
+	signature, _ := base64.StdEncoding.DecodeString(params.PaySign)
+	if params.AppID != "wxd678efh567hg6787" || rsa.VerifyPKCS1v15(&merchantKey.PublicKey, crypto.SHA256, hashed[:], signature) != nil {
+		t.Errorf("unexpected JSAPI params %+v", params)
+	}
+
+	resource := encrypt([]byte(`{"mchid":"1900000001","out_trade_no":"order-1","transaction_id":"4200000001","trade_state":"SUCCESS","amount":{"total":100,"currency":"CNY"}}`), "transaction")
+	notificationBody, _ := json.Marshal(WeChatPayNotification{ID: "EV-1", EventType: WeChatPayEventTransactionSuccess, ResourceType: "encrypt-resource", Resource: resource})
+	req := httptest.NewRequest(http.MethodPost, "/notify", bytes.NewReader(notificationBody))
+	signHeaders(req.Header, notificationBody)
+	notification, err := c.ParseNotification(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	transaction := &WeChatPayTransactionDetail{}
+	if err = c.DecryptResource(notification.Resource, transaction); err != nil {
+		t.Fatal(err)
+	}
+	if notification.EventType != WeChatPayEventTransactionSuccess || transaction.TradeState != WeChatPayTradeStateSuccess || transaction.Amount.Total != 100 {
+		t.Errorf("unexpected notification %+v %+v", notification, transaction)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/notify", bytes.NewReader(notificationBody))
+	signHeaders(req.Header, []byte(`{}`))
+	if _, err = c.ParseNotification(context.Background(), req); err != ErrWeChatPaySignature {
+		t.Errorf("expected ErrWeChatPaySignature, got %v", err)
+	}
+}
+
+func TestVNPay(t *testing.T) {
+	checksum := func(data string) string {
+		mac := hmac.New(sha512.New, []byte("SECRET"))
+		mac.Write([]byte(data))
+		return hex.EncodeToString(mac.Sum(nil))
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request := map[string]string{}
+		json.NewDecoder(r.Body).Decode(&request)
+		w.Header().Set("Content-Type", "application/json")
+
+		switch request["vnp_Command"] {
+		case "querydr":
+			data := strings.Join([]string{request["vnp_RequestId"], "2.1.0", "querydr", "DEMO0001", "order-1",
+				"20240101100000", request["vnp_CreateDate"], "127.0.0.1", "Query order-1"}, "|")
+			if request["vnp_SecureHash"] != checksum(data) {
+				t.Errorf("invalid querydr checksum")
+			}
+			response := VNPayTransaction{ResponseID: "r1", Command: "querydr", ResponseCode: "00", Message: "QueryDR Success", TmnCode: "DEMO0001",
+				TxnRef: "order-1", Amount: "10000000", BankCode: "NCB", PayDate: "20240101100500", TransactionNo: "14000001",
+				TransactionType: "01", TransactionStatus: "00", OrderInfo: "Order 1"}
+			response.SecureHash = checksum(response.hashData("querydr"))
+			json.NewEncoder(w).Encode(response)
+		case "refund":
+			if request["vnp_Amount"] != "5000000" || request["vnp_TransactionType"] != VNPayRefundPartial {
+				t.Errorf("unexpected refund %v", request)
+			}
+			w.Write([]byte(`{"vnp_ResponseId":"r2","vnp_Command":"refund","vnp_ResponseCode":"91","vnp_Message":"Transaction not found"}`))
+		default:
+			t.Errorf("unexpected command %s", request["vnp_Command"])
+		}
+	}))
+	defer ts.Close()
+
+	config := &Config{VNPay: VNPay{TmnCode: "DEMO0001", HashSecret: "SECRET", PaymentURL: VNPayPaymentURLSandBox, APIURL: ts.URL, ReturnURL: "https://example.com/return"}}
+	c, ok := New(ctx, VNPAY, config).(IVNPay)
+	if !ok {
+		t.Fatal("expected New to return an IVNPay")
+	}
+
+	if _, err := c.CreatePaymentURL(VNPayPayment{TxnRef: "order-1"}); !errors.Is(err, ErrValidation) {
+		t.Errorf("expected ErrValidation, got %v", err)
+	}
+
+	paymentURL, err := c.CreatePaymentURL(VNPayPayment{TxnRef: "order-1", Amount: 100000, OrderInfo: "Order 1", IPAddr: "127.0.0.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, _ := url.Parse(paymentURL)
+	query := parsed.Query()
+	if query.Get("vnp_Amount") != "10000000" || query.Get("vnp_ReturnUrl") != "https://example.com/return" || query.Get("vnp_Locale") != VNPayLocaleVietnamese {
+		t.Errorf("unexpected payment URL %s", paymentURL)
+	}
+	secureHash := query.Get("vnp_SecureHash")
+	query.Del("vnp_SecureHash")
+	if secureHash != checksum(query.Encode()) {
+		t.Errorf("invalid payment URL checksum")
+	}
+
+	ipn := url.Values{
+		"vnp_TmnCode":           {"DEMO0001"},
+		"vnp_TxnRef":            {"order-1"},
+		"vnp_Amount":            {"10000000"},
+		"vnp_OrderInfo":         {"Order 1"},
+		"vnp_ResponseCode":      {"00"},
+		"vnp_TransactionStatus": {"00"},
+		"vnp_TransactionNo":     {"14000001"},
+		"vnp_BankCode":          {"NCB"},
+		"vnp_PayDate":           {"20240101100500"},
+	}
+	ipn.Set("vnp_SecureHash", strings.ToUpper(checksum(ipn.Encode())))
+	ipn.Set("vnp_SecureHashType", "HmacSHA512")
+	result, err := c.VerifyReturn(ipn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Success() || result.Amount != 100000 || result.TransactionNo != "14000001" {
+		t.Errorf("unexpected result %+v", result)
+	}
+	ipn.Set("vnp_Amount", "100")
+	if _, err = c.VerifyReturn(ipn); err != ErrVNPayChecksum {
+		t.Errorf("expected ErrVNPayChecksum, got %v", err)
+	}
+
+	transaction, err := c.QueryTransaction(context.Background(), VNPayQuery{TxnRef: "order-1", TransactionDate: "20240101100000", IPAddr: "127.0.0.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if transaction.TransactionStatus != "00" || transaction.TransactionNo != "14000001" {
+		t.Errorf("unexpected transaction %+v", transaction)
+	}
+
+	_, err = c.Refund(context.Background(), VNPayRefund{TransactionType: VNPayRefundPartial, TxnRef: "order-1", Amount: 50000, TransactionDate: "20240101100000", CreateBy: "admin", IPAddr: "127.0.0.1"})
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestMoMo(t *testing.T) {
+	sign := func(data string) string {
+		mac := hmac.New(sha256.New, []byte("SECRET"))
+		mac.Write([]byte(data))
+		return hex.EncodeToString(mac.Sum(nil))
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request := map[string]interface{}{}
+		json.NewDecoder(r.Body).Decode(&request)
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.URL.Path {
+		case "/v2/gateway/api/create":
+			data := fmt.Sprintf("accessKey=ACCESS&amount=50000&extraData=&ipnUrl=https://example.com/ipn&orderId=order-1&orderInfo=Order 1&partnerCode=MOMO&redirectUrl=https://example.com/return&requestId=%s&requestType=captureWallet", request["requestId"])
+			if request["signature"] != sign(data) {
+				t.Errorf("invalid create signature")
+			}
+			w.Write([]byte(`{"partnerCode":"MOMO","orderId":"order-1","amount":50000,"resultCode":0,"message":"Successful.","payUrl":"https://test-payment.momo.vn/v2/gateway/pay?t=token"}`))
+		case "/v2/gateway/api/query":
+			if request["signature"] != sign(fmt.Sprintf("accessKey=ACCESS&orderId=order-1&partnerCode=MOMO&requestId=%s", request["requestId"])) {
+				t.Errorf("invalid query signature")
+			}
+			w.Write([]byte(`{"partnerCode":"MOMO","orderId":"order-1","amount":50000,"resultCode":1000,"message":"Transaction initiated"}`))
+		case "/v2/gateway/api/refund":
+			w.Write([]byte(`{"partnerCode":"MOMO","orderId":"refund-1","resultCode":42,"message":"Invalid orderId or orderId is not found."}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	config := &Config{MoMo: MoMo{PartnerCode: "MOMO", AccessKey: "ACCESS", SecretKey: "SECRET", APIBase: ts.URL, RedirectURL: "https://example.com/return", IPNURL: "https://example.com/ipn"}}
+	c, ok := New(ctx, MOMO, config).(IMoMo)
+	if !ok {
+		t.Fatal("expected New to return an IMoMo")
+	}
+
+	payment, err := c.CreatePayment(context.Background(), MoMoPaymentRequest{Amount: 50000, OrderID: "order-1", OrderInfo: "Order 1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if payment.PayURL == "" || payment.Amount != 50000 {
+		t.Errorf("unexpected payment %+v", payment)
+	}
+
+	status, err := c.QueryPayment(context.Background(), "order-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.ResultCode != MoMoResultInitiated {
+		t.Errorf("unexpected status %+v", status)
+	}
+
+	if _, err = c.Refund(context.Background(), MoMoRefundRequest{OrderID: "refund-1", Amount: 50000, TransID: 2588659987}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+
+	ipn := MoMoIPN{PartnerCode: "MOMO", OrderID: "order-1", RequestID: "req-1", Amount: 50000, OrderInfo: "Order 1", OrderType: "momo_wallet",
+		TransID: 2588659987, ResultCode: 0, Message: "Successful.", PayType: "qr", ResponseTime: 1704078000000}
+	ipn.Signature = sign("accessKey=ACCESS&amount=50000&extraData=&message=Successful.&orderId=order-1&orderInfo=Order 1&orderType=momo_wallet" +
+		"&partnerCode=MOMO&payType=qr&requestId=req-1&responseTime=1704078000000&resultCode=0&transId=2588659987")
+	body, _ := json.Marshal(ipn)
+	notified, err := c.VerifyIPN(httptest.NewRequest(http.MethodPost, "/ipn", bytes.NewReader(body)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if notified.TransID != 2588659987 || notified.ResultCode != MoMoResultSuccess {
+		t.Errorf("unexpected IPN %+v", notified)
+	}
+
+	ipn.Amount = 1000
+	body, _ = json.Marshal(ipn)
+	if _, err = c.VerifyIPN(httptest.NewRequest(http.MethodPost, "/ipn", bytes.NewReader(body))); err != ErrMoMoSignature {
+		t.Errorf("expected ErrMoMoSignature, got %v", err)
+	}
+}
+
+func TestZaloPay(t *testing.T) {
+	mac := func(key string, fields ...string) string {
+		h := hmac.New(sha256.New, []byte(key))
+		h.Write([]byte(strings.Join(fields, "|")))
+		return hex.EncodeToString(h.Sum(nil))
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		form := r.PostForm
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.URL.Path {
+		case "/v2/create":
+			expected := mac("KEY1", "2553", "240101_order-1", "user-1", "50000", form.Get("app_time"), `{"redirecturl":"https://example.com/return"}`, "[]")
+			if form.Get("mac") != expected || form.Get("callback_url") != "https://example.com/callback" {
+				t.Errorf("unexpected create form %v", form)
+			}
+			w.Write([]byte(`{"return_code":1,"return_message":"Giao dịch thành công","order_url":"https://qcgateway.zalopay.vn/openinapp?order=token","zp_trans_token":"token"}`))
+		case "/v2/query":
+			if form.Get("mac") != mac("KEY1", "2553", "240101_order-1", "KEY1") {
+				t.Errorf("invalid query mac")
+			}
+			w.Write([]byte(`{"return_code":3,"return_message":"Giao dịch đang xử lý","is_processing":true,"amount":50000}`))
+		case "/v2/refund":
+			if form.Get("mac") != mac("KEY1", "2553", "240101000000001", "50000", "Refund order-1", form.Get("timestamp")) {
+				t.Errorf("invalid refund mac")
+			}
+			w.Write([]byte(`{"return_code":2,"return_message":"Giao dịch thất bại","sub_return_code":-402,"sub_return_message":"Mac không hợp lệ"}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	config := &Config{ZaloPay: ZaloPay{AppID: "2553", Key1: "KEY1", Key2: "KEY2", APIBase: ts.URL, CallbackURL: "https://example.com/callback"}}
+	c, ok := New(ctx, ZALOPAY, config).(IZaloPay)
+	if !ok {
+		t.Fatal("expected New to return an IZaloPay")
+	}
+
+	appTransID := NewZaloPayTransID(time.Date(2023, 12, 31, 20, 0, 0, 0, time.UTC), "order-1")
+	if appTransID != "240101_order-1" {
+		t.Errorf("unexpected app_trans_id %s", appTransID)
+	}
+
+	order, err := c.CreateOrder(context.Background(), ZaloPayOrder{AppTransID: appTransID, AppUser: "user-1", Amount: 50000, Description: "Order 1",
+		EmbedData: map[string]interface{}{"redirecturl": "https://example.com/return"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if order.ZPTransToken != "token" {
+		t.Errorf("unexpected order %+v", order)
+	}
+
+	status, err := c.QueryOrder(context.Background(), appTransID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.ReturnCode != ZaloPayReturnCodeProcessing || !status.IsProcessing {
+		t.Errorf("unexpected status %+v", status)
+	}
+
+	_, err = c.Refund(context.Background(), ZaloPayRefund{ZPTransID: "240101000000001", Amount: 50000, Description: "Refund order-1"})
+	if !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("expected ErrUnauthorized, got %v", err)
+	}
+
+	data := `{"app_id":2553,"app_trans_id":"240101_order-1","app_user":"user-1","amount":50000,"zp_trans_id":240101000000001,"channel":38}`
+	body, _ := json.Marshal(ZaloPayCallback{Data: data, MAC: mac("KEY2", data), Type: 1})
+	callback, err := c.VerifyCallback(httptest.NewRequest(http.MethodPost, "/callback", bytes.NewReader(body)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if callback.AppTransID != "240101_order-1" || callback.ZPTransID != 240101000000001 {
+		t.Errorf("unexpected callback %+v", callback)
+	}
+
+	body, _ = json.Marshal(ZaloPayCallback{Data: data, MAC: mac("KEY1", data), Type: 1})
+	if _, err = c.VerifyCallback(httptest.NewRequest(http.MethodPost, "/callback", bytes.NewReader(body))); err != ErrZaloPayMAC {
+		t.Errorf("expected ErrZaloPayMAC, got %v", err)
+	}
+}
+
+func TestAmazonPay(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	snsKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{SerialNumber: big.NewInt(1), NotBefore: time.Now().Add(-time.Hour), NotAfter: time.Now().Add(time.Hour)}
+	snsCertificate, err := x509.CreateCertificate(rand.Reader, template, template, &snsKey.PublicKey, snsKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	privateKey := string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}))
+
+	var certificateDownloads int32
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/SimpleNotificationService-1.pem" {
+			atomic.AddInt32(&certificateDownloads, 1)
+			pem.Encode(w, &pem.Block{Type: "CERTIFICATE", Bytes: snsCertificate})
+			return
+		}
+
+		authorization := r.Header.Get("Authorization")
+		if !strings.HasPrefix(authorization, "AMZN-PAY-RSASSA-PSS-V2 PublicKeyId=SANDBOX-AHEGSJCM2QJ3D, SignedHeaders=accept;content-type;x-amz-pay-date;x-amz-pay-host;") ||
+			!strings.Contains(authorization, "x-amz-pay-region, Signature=") {
+			t.Errorf("unexpected Authorization %s", authorization)
+		}
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.Method + " " + r.URL.Path {
+		case "POST /sandbox/v2/checkoutSessions":
+			if r.Header.Get("X-Amz-Pay-Idempotency-Key") == "" || !strings.Contains(authorization, "x-amz-pay-idempotency-key") {
+				t.Errorf("expected a signed idempotency key")
+			}
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"checkoutSessionId":"bd504926-f659-4ad7-a1a9-9a747aaf5275","statusDetails":{"state":"Open"},"releaseEnvironment":"Sandbox"}`))
+		case "POST /sandbox/v2/refunds":
+			body, _ := ioutil.ReadAll(r.Body)
+			expected := `{"chargeId":"S01-5105180-3221187-C056351","refundAmount":{"amount":"14.00","currencyCode":"USD"}}`
+			if string(body) != expected || r.Header.Get("X-Amz-Pay-Idempotency-Key") != "refund-1" {
+				t.Errorf("unexpected body,\n Given:    %s\n Expected: %s", body, expected)
+			}
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"refundId":"S01-5105180-3221187-R022311","chargeId":"S01-5105180-3221187-C056351","refundAmount":{"amount":"14.00","currencyCode":"USD"},"statusDetails":{"state":"RefundInitiated"}}`))
+		case "GET /sandbox/v2/charges/unknown":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"reasonCode":"ResourceNotFound","message":"Charge not found"}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	config := &Config{AmazonPay: AmazonPay{PublicKeyID: "SANDBOX-AHEGSJCM2QJ3D", PrivateKey: privateKey, Region: AmazonPayRegionNA, APIBase: ts.URL + "/sandbox/v2"}}
+	i, ok := New(ctx, AMAZON_PAY, config).(IAmazonPay)
+	if !ok {
+		t.Fatal("expected New to return an IAmazonPay")
+	}
+	c := i.(*AmazonPayClient)
+	c.Client = ts.Client()
+	c.snsCertHost = regexp.MustCompile(`^127\.0\.0\.1$`)
+
+	payload := `{"webCheckoutDetails":{"checkoutReviewReturnUrl":"https://example.com/review"},"storeId":"amzn1.application-oa2-client.8b5e45312b5248b69eeaStoreId"}`
+	signature, err := c.GenerateButtonSignature(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	payloadHash := sha256.Sum256([]byte(payload))
+	hashed := sha256.Sum256([]byte("AMZN-PAY-RSASSA-PSS-V2\n" + hex.EncodeToString(payloadHash[:])))
+	decoded, _ := base64.StdEncoding.DecodeString(signature)
+	if rsa.VerifyPSS(&key.PublicKey, crypto.SHA256, hashed[:], decoded, &rsa.PSSOptions{SaltLength: 32}) != nil {
+		t.Errorf("invalid button signature")
+	}
+
+	session, err := c.CreateCheckoutSession(context.Background(), AmazonPayCheckoutSessionRequest{
+		WebCheckoutDetails: &AmazonPayWebCheckoutDetails{CheckoutReviewReturnURL: "https://example.com/review"},
+		StoreID:            "amzn1.application-oa2-client.8b5e45312b5248b69eeaStoreId",
+	}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if session.CheckoutSessionID != "bd504926-f659-4ad7-a1a9-9a747aaf5275" || session.StatusDetails.State != AmazonPayStateOpen {
+		t.Errorf("unexpected checkout session %+v", session)
+	}
+
+	refund, err := c.CreateRefund(context.Background(), AmazonPayRefundRequest{ChargeID: "S01-5105180-3221187-C056351", RefundAmount: AmazonPayPrice{Amount: "14.00", CurrencyCode: "USD"}}, "refund-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if refund.StatusDetails.State != AmazonPayStateRefundInitiated {
+		t.Errorf("unexpected refund %+v", refund)
+	}
+
+	if _, err = c.GetCharge(context.Background(), "unknown"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+
+	message := AmazonPaySNSMessage{
+		Type:             AmazonSNSTypeNotification,
+		MessageID:        "cf5543af-dd65-5f74-8ccf-0a410ff3e5e0",
+		TopicArn:         "arn:aws:sns:us-east-1:291180941288:A01494295CPDNDM2S8LQ",
+		Message:          `{"ObjectType":"CHARGE","ObjectId":"S01-5105180-3221187-C056351","ChargePermissionId":"S01-5105180-3221187","NotificationType":"STATE_CHANGE","NotificationVersion":"V1"}`,
+		Timestamp:        "2024-01-01T10:00:00.000Z",
+		SignatureVersion: "2",
+		SigningCertURL:   ts.URL + "/SimpleNotificationService-1.pem",
+	}
+	snsHashed := sha256.Sum256([]byte(message.stringToSign()))
+	snsSignature, _ := rsa.SignPKCS1v15(rand.Reader, snsKey, crypto.SHA256, snsHashed[:])
+	message.Signature = base64.StdEncoding.EncodeToString(snsSignature)
+
+	for i := 0; i < 2; i++ {
+		body, _ := json.Marshal(message)
+		verified, err := c.VerifySNSMessage(context.Background(), httptest.NewRequest(http.MethodPost, "/ipn", bytes.NewReader(body)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		notification, err := verified.Notification()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if notification.ObjectType != "CHARGE" || notification.ObjectID != "S01-5105180-3221187-C056351" {
+			t.Errorf("unexpected notification %+v", notification)
+		}
+	}
+	if atomic.LoadInt32(&certificateDownloads) != 1 {
+		t.Errorf("expected the certificate to be downloaded once, got %d", certificateDownloads)
+	}
+
+	message.Message = strings.Replace(message.Message, "CHARGE", "REFUND", 1)
+	body, _ := json.Marshal(message)
+	if _, err = c.VerifySNSMessage(context.Background(), httptest.NewRequest(http.MethodPost, "/ipn", bytes.NewReader(body))); err != ErrAmazonPaySNSSignature {
+		t.Errorf("expected ErrAmazonPaySNSSignature, got %v", err)
+	}
+
+	message.SigningCertURL = "https://attacker.example.com/SimpleNotificationService-1.pem"
+	body, _ = json.Marshal(message)
+	if _, err = c.VerifySNSMessage(context.Background(), httptest.NewRequest(http.MethodPost, "/ipn", bytes.NewReader(body))); err != ErrAmazonPaySNSSignature {
+		t.Errorf("expected ErrAmazonPaySNSSignature, got %v", err)
+	}
+}
+
+// applePayTestCA issues ECDSA certificates for the Apple Pay tests
+func applePayTestCA(t *testing.T, parent *x509.Certificate, parentKey *ecdsa.PrivateKey, serial int64, isCA bool, extension asn1.ObjectIdentifier) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(serial),
+		Subject:               pkix.Name{CommonName: fmt.Sprintf("Apple Pay Test %d", serial)},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  isCA,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	if extension != nil {
+		template.ExtraExtensions = []pkix.Extension{{Id: extension, Value: []byte{0x05, 0x00}}}
+	}
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certificate, _ := x509.ParseCertificate(der)
+	return certificate, key
+}
+
+// applePayTestSignature returns a detached PKCS#7 signature of content made by leaf
+func applePayTestSignature(t *testing.T, leaf, intermediate *x509.Certificate, leafKey *ecdsa.PrivateKey, content []byte, signingTime time.Time) string {
+	oidData := asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 1}
+	set := func(value interface{}) asn1.RawValue {
+		b, err := asn1.Marshal(value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return asn1.RawValue{Tag: asn1.TagSet, IsCompound: true, Bytes: b}
+	}
+
+	digest := sha256.Sum256(content)
+	var attrs []byte
+	for _, attribute := range []pkcs7Attribute{
+		{Type: asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 3}, Values: set(oidData)},
+		{Type: oidPKCS9SigningTime, Values: set(signingTime.UTC())},
+		{Type: oidPKCS9MessageDigest, Values: set(digest[:])},
+	} {
+		b, _ := asn1.Marshal(attribute)
+		attrs = append(attrs, b...)
+	}
+	signedAttrs, _ := asn1.Marshal(asn1.RawValue{Tag: asn1.TagSet, IsCompound: true, Bytes: attrs})
+	hashed := sha256.Sum256(signedAttrs)
+	signature, err := ecdsa.SignASN1(rand.Reader, leafKey, hashed[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sid, _ := asn1.Marshal(struct {
+		Issuer       asn1.RawValue
+		SerialNumber *big.Int
+	}{asn1.RawValue{FullBytes: leaf.RawIssuer}, leaf.SerialNumber})
+	sha256Algorithm := pkix.AlgorithmIdentifier{Algorithm: asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}}
+	signedData := pkcs7SignedData{
+		Version:          1,
+		DigestAlgorithms: set(sha256Algorithm),
+		Certificates:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: append(append([]byte{}, leaf.Raw...), intermediate.Raw...)},
+		SignerInfos: []pkcs7SignerInfo{{
+			Version:            1,
+			SID:                asn1.RawValue{FullBytes: sid},
+			DigestAlgorithm:    sha256Algorithm,
+			SignedAttrs:        asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: attrs},
+			SignatureAlgorithm: pkix.AlgorithmIdentifier{Algorithm: asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 3, 2}},
+			Signature:          signature,
+		}},
+	}
+	signedData.EncapContentInfo.ContentType = oidData
+	signedDataDER, err := asn1.Marshal(signedData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	contentInfo, err := asn1.Marshal(pkcs7ContentInfo{
+		ContentType: oidPKCS7SignedData,
+		Content:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: signedDataDER},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return base64.StdEncoding.EncodeToString(contentInfo)
+}
+
+func TestApplePay(t *testing.T) {
+	root, rootKey := applePayTestCA(t, nil, nil, 1, true, nil)
+	intermediate, intermediateKey := applePayTestCA(t, root, rootKey, 2, true, oidApplePayIntermediate)
+	leaf, leafKey := applePayTestCA(t, intermediate, intermediateKey, 3, false, oidApplePayLeaf)
+	identity, identityKey := applePayTestCA(t, nil, nil, 4, false, nil)
+	processingKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	identityKeyDER, _ := x509.MarshalECPrivateKey(identityKey)
+	processingKeyDER, _ := x509.MarshalPKCS8PrivateKey(processingKey)
+	config := &Config{ApplePay: ApplePay{
+		MerchantID:                  "merchant.com.example",
+		DomainName:                  "shop.example.com",
+		DisplayName:                 "Example Shop",
+		MerchantIdentityCertificate: string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: identity.Raw})),
+		MerchantIdentityKey:         string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: identityKeyDER})),
+		PaymentProcessingKey:        string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: processingKeyDER})),
+		RootCertificate:             string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: root.Raw})),
+	}}
+	i, ok := New(ctx, APPLE_PAY, config).(IApplePay)
+	if !ok {
+		t.Fatal("expected New to return an IApplePay")
+	}
+	c := i.(*ApplePayClient)
+
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.TLS.PeerCertificates) != 1 || !r.TLS.PeerCertificates[0].Equal(identity) {
+			t.Errorf("expected the merchant identity certificate")
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		expected := `{"merchantIdentifier":"merchant.com.example","displayName":"Example Shop","initiative":"web","initiativeContext":"shop.example.com"}`
+		if string(body) != expected {
+			t.Errorf("unexpected body,\n Given:    %s\n Expected: %s", body, expected)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"epochTimestamp":1704067200000,"merchantSessionIdentifier":"SSH1","signature":"308006"}`))
+	}))
+	ts.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	ts.StartTLS()
+	defer ts.Close()
+	c.Client.Transport.(*http.Transport).TLSClientConfig.RootCAs = ts.Client().Transport.(*http.Transport).TLSClientConfig.RootCAs
+	c.validationHost = regexp.MustCompile(`^127\.0\.0\.1$`)
+
+	session, err := c.ValidateMerchant(context.Background(), ts.URL+"/paymentservices/startSession")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(session), `"merchantSessionIdentifier":"SSH1"`) {
+		t.Errorf("unexpected merchant session %s", session)
+	}
+	if _, err = c.ValidateMerchant(context.Background(), "https://attacker.example.com/paymentservices/startSession"); err != ErrApplePayValidationURL {
+		t.Errorf("expected ErrApplePayValidationURL, got %v", err)
+	}
+
+	ephemeralKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	ephemeralDER, _ := x509.MarshalPKIXPublicKey(&ephemeralKey.PublicKey)
+	x, _ := elliptic.P256().ScalarMult(processingKey.X, processingKey.Y, ephemeralKey.D.Bytes())
+	sharedSecret := make([]byte, 32)
+	x.FillBytes(sharedSecret)
+	block, _ := aes.NewCipher(applePaySymmetricKey(sharedSecret, "merchant.com.example"))
+	gcm, _ := cipher.NewGCMWithNonceSize(block, 16)
+	plaintext := `{"applicationPrimaryAccountNumber":"4109370251004320","applicationExpirationDate":"281231","currencyCode":"840","transactionAmount":1999,` +
+		`"deviceManufacturerIdentifier":"040010030273","paymentDataType":"3DSecure","paymentData":{"onlinePaymentCryptogram":"Af9x/QwAA/DjmU65oyc1MAABAAA=","eciIndicator":"5"}}`
+	data := gcm.Seal(nil, make([]byte, 16), []byte(plaintext), nil)
+	publicKeyDER, _ := x509.MarshalPKIXPublicKey(&processingKey.PublicKey)
+	publicKeyHash := sha256.Sum256(publicKeyDER)
+	transactionID := "c1caf5ae72f0039a82bad92b828363734f85bf2f9cadf193d1bad9ddcb60a795"
+	transactionIDBytes, _ := hex.DecodeString(transactionID)
+
+	token := ApplePayPaymentToken{PaymentMethod: ApplePayPaymentMethod{DisplayName: "Visa 4320", Network: "Visa", Type: "debit"}}
+	token.PaymentData.Version = "EC_v1"
+	token.PaymentData.Data = base64.StdEncoding.EncodeToString(data)
+	token.PaymentData.Header.EphemeralPublicKey = base64.StdEncoding.EncodeToString(ephemeralDER)
+	token.PaymentData.Header.PublicKeyHash = base64.StdEncoding.EncodeToString(publicKeyHash[:])
+	token.PaymentData.Header.TransactionID = transactionID
+	signed := bytes.Join([][]byte{ephemeralDER, data, transactionIDBytes}, nil)
+	token.PaymentData.Signature = applePayTestSignature(t, leaf, intermediate, leafKey, signed, time.Now())
+
+	payment, err := c.DecryptToken(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if payment.ApplicationPrimaryAccountNumber != "4109370251004320" || payment.PaymentData.ECIIndicator != "5" {
+		t.Errorf("unexpected payment %+v", payment)
+	}
+
+	source := payment.PayPalPaymentSource("USD").ApplePay.DecryptedToken
+	if source.TokenizedCard.Expiry != "2028-12" || source.TokenizedCard.Brand != "VISA" || source.TransactionAmount.Value != "19.99" ||
+		source.PaymentDataType != "3DSECURE" || source.PaymentData.Cryptogram != "Af9x/QwAA/DjmU65oyc1MAABAAA=" {
+		t.Errorf("unexpected PayPal decrypted token %+v", source)
+	}
+
+	stale := token
+	stale.PaymentData.Signature = applePayTestSignature(t, leaf, intermediate, leafKey, signed, time.Now().Add(-time.Hour))
+	if _, err = c.DecryptToken(stale); err != ErrApplePayTokenSignature {
+		t.Errorf("expected ErrApplePayTokenSignature, got %v", err)
+	}
+
+	forged := token
+	forgedLeaf, forgedKey := applePayTestCA(t, nil, nil, 5, false, oidApplePayLeaf)
+	forged.PaymentData.Signature = applePayTestSignature(t, forgedLeaf, intermediate, forgedKey, signed, time.Now())
+	if _, err = c.DecryptToken(forged); err != ErrApplePayTokenSignature {
+		t.Errorf("expected ErrApplePayTokenSignature, got %v", err)
+	}
+
+	tampered := token
+	tampered.PaymentData.Header.TransactionID = strings.Repeat("00", 32)
+	if _, err = c.DecryptToken(tampered); err != ErrApplePayTokenSignature {
+		t.Errorf("expected ErrApplePayTokenSignature, got %v", err)
+	}
+}
+
+func TestGooglePay(t *testing.T) {
+	rootKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	intermediateKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	merchantKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	oldMerchantKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	publicKey := func(key *ecdsa.PrivateKey) string {
+		der, _ := x509.MarshalPKIXPublicKey(&key.PublicKey)
+		return base64.StdEncoding.EncodeToString(der)
+	}
+	sign := func(key *ecdsa.PrivateKey, content []byte) string {
+		hashed := sha256.Sum256(content)
+		signature, _ := ecdsa.SignASN1(rand.Reader, key, hashed[:])
+		return base64.StdEncoding.EncodeToString(signature)
+	}
+	expiration := strconv.FormatInt(time.Now().Add(time.Hour).UnixNano()/int64(time.Millisecond), 10)
+
+	var rootKeysDownloads int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&rootKeysDownloads, 1)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"keys":[{"keyValue":"%s","protocolVersion":"ECv1"},{"keyValue":"%s","protocolVersion":"ECv2","keyExpiration":"%s"}]}`,
+			publicKey(intermediateKey), publicKey(rootKey), expiration)
+	}))
+	defer ts.Close()
+
+	newToken := func(recipientID, plaintext string) string {
+		ephemeralKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		ephemeralPublicKey := elliptic.Marshal(elliptic.P256(), ephemeralKey.X, ephemeralKey.Y)
+		x, _ := elliptic.P256().ScalarMult(merchantKey.X, merchantKey.Y, ephemeralKey.D.Bytes())
+		sharedSecret := make([]byte, 32)
+		x.FillBytes(sharedSecret)
+		keys := googlePayHKDF(append(append([]byte{}, ephemeralPublicKey...), sharedSecret...))
+
+		block, _ := aes.NewCipher(keys[:32])
+		encryptedMessage := make([]byte, len(plaintext))
+		cipher.NewCTR(block, make([]byte, aes.BlockSize)).XORKeyStream(encryptedMessage, []byte(plaintext))
+		mac := hmac.New(sha256.New, keys[32:])
+		mac.Write(encryptedMessage)
+
+		signedMessage, _ := json.Marshal(GooglePaySignedMessage{
+			EncryptedMessage:   base64.StdEncoding.EncodeToString(encryptedMessage),
+			EphemeralPublicKey: base64.StdEncoding.EncodeToString(ephemeralPublicKey),
+			Tag:                base64.StdEncoding.EncodeToString(mac.Sum(nil)),
+		})
+		signedKey, _ := json.Marshal(GooglePaySignedKey{KeyValue: publicKey(intermediateKey), KeyExpiration: expiration})
+
+		token := GooglePayPaymentMethodToken{ProtocolVersion: "ECv2", SignedMessage: string(signedMessage)}
+		token.IntermediateSigningKey.SignedKey = string(signedKey)
+		token.IntermediateSigningKey.Signatures = []string{sign(rootKey, googlePaySignedString("Google", "ECv2", string(signedKey)))}
+		token.Signature = sign(intermediateKey, googlePaySignedString("Google", recipientID, "ECv2", string(signedMessage)))
+		b, _ := json.Marshal(token)
+		return string(b)
+	}
+
+	oldKeyDER, _ := x509.MarshalECPrivateKey(oldMerchantKey)
+	merchantKeyDER, _ := x509.MarshalPKCS8PrivateKey(merchantKey)
+	config := &Config{GooglePay: GooglePay{
+		RecipientID: "merchant:12345678901234567890",
+		PrivateKeys: []string{base64.StdEncoding.EncodeToString(oldKeyDER), base64.StdEncoding.EncodeToString(merchantKeyDER)},
+		RootKeysURL: ts.URL,
+	}}
+	d, ok := New(ctx, GOOGLE_PAY, config).(IGooglePayDecryptor)
+	if !ok {
+		t.Fatal("expected New to return an IGooglePayDecryptor")
+	}
+
+	plaintext := fmt.Sprintf(`{"messageExpiration":"%s","messageId":"AH2Ejtc","paymentMethod":"CARD","paymentMethodDetails":`+
+		`{"pan":"4111111111111111","expirationMonth":3,"expirationYear":2029,"authMethod":"CRYPTOGRAM_3DS","cryptogram":"AAAAAA==","eciIndicator":"05"}}`, expiration)
+	data, err := d.Decrypt(context.Background(), newToken("merchant:12345678901234567890", plaintext))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data.PaymentMethodDetails.AuthMethod != GooglePayAuthMethodCryptogram3DS || data.PaymentMethodDetails.Cryptogram != "AAAAAA==" {
+		t.Errorf("unexpected payment data %+v", data)
+	}
+
+	card := data.Card()
+	if card.Number != "4111111111111111" || card.Expiry != "2029-03" {
+		t.Errorf("unexpected card %+v", card)
+	}
+	if source := data.PayPalPaymentSource().GooglePay.DecryptedToken; source.ECIIndicator != "05" || source.Card.Expiry != "2029-03" {
+		t.Errorf("unexpected PayPal decrypted token %+v", source)
+	}
+
+	if _, err = d.Decrypt(context.Background(), newToken("merchant:other", plaintext)); err != ErrGooglePaySignature {
+		t.Errorf("expected ErrGooglePaySignature, got %v", err)
+	}
+
+	expired := strings.Replace(plaintext, expiration, "1000", 1)
+	if _, err = d.Decrypt(context.Background(), newToken("merchant:12345678901234567890", expired)); err != ErrGooglePayExpired {
+		t.Errorf("expected ErrGooglePayExpired, got %v", err)
+	}
+
+	if atomic.LoadInt32(&rootKeysDownloads) != 1 {
+		t.Errorf("expected the root keys to be downloaded once, got %d", rootKeysDownloads)
+	}
+}
+
+func TestPaddle(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Header.Get("Authorization") != "Bearer pdl_key" {
+			t.Errorf("unexpected authorization %q", r.Header.Get("Authorization"))
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		switch r.Method + " " + r.URL.Path {
+		case "POST /transactions":
+			expected := `{"items":[{"price_id":"pri_01","quantity":2}],"customer_id":"ctm_01","custom_data":{"account":"42"}}`
+			if string(body) != expected {
+				t.Errorf("unexpected body,\n Given:    %s\n Expected: %s", body, expected)
+			}
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"data":{"id":"txn_01","status":"ready","customer_id":"ctm_01","checkout":{"url":"https://pay.example.com/?_ptxn=txn_01"},"details":{"totals":{"grand_total":"2000","currency_code":"USD"}}}}`))
+		case "GET /transactions":
+			if r.URL.Query().Get("subscription_id") != "sub_01" {
+				t.Errorf("unexpected query %s", r.URL.RawQuery)
+			}
+			w.Write([]byte(`{"data":[{"id":"txn_02","status":"completed","subscription_id":"sub_01","invoice_number":"325-10566"}],"meta":{"pagination":{"per_page":50,"next":"https://api.paddle.com/transactions?after=txn_02","has_more":false,"estimated_total":1}}}`))
+		case "GET /transactions/txn_02/invoice":
+			w.Write([]byte(`{"data":{"url":"https://storage.example.com/invoice.pdf"}}`))
+		case "GET /transactions/unknown":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":{"type":"request_error","code":"not_found","detail":"Entity unknown not found"}}`))
+		case "PATCH /subscriptions/sub_01":
+			expected := `{"items":[{"price_id":"pri_02","quantity":1}],"proration_billing_mode":"prorated_immediately"}`
+			if string(body) != expected {
+				t.Errorf("unexpected body,\n Given:    %s\n Expected: %s", body, expected)
+			}
+			w.Write([]byte(`{"data":{"id":"sub_01","status":"active","items":[{"quantity":1,"price":{"id":"pri_02"}}]}}`))
+		case "POST /subscriptions/sub_01/pause":
+			if string(body) != `{"effective_from":"next_billing_period"}` {
+				t.Errorf("unexpected body %s", body)
+			}
+			w.Write([]byte(`{"data":{"id":"sub_01","status":"active","scheduled_change":{"action":"pause","effective_at":"2024-05-01T00:00:00Z"}}}`))
+		case "POST /subscriptions/sub_01/cancel":
+			if string(body) != `{"effective_from":"immediately"}` {
+				t.Errorf("unexpected body %s", body)
+			}
+			w.Write([]byte(`{"data":{"id":"sub_01","status":"canceled"}}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	c, ok := New(ctx, PADDLE, &Config{Paddle: Paddle{APIKey: "pdl_key", APIBase: ts.URL}}).(IPaddle)
+	if !ok {
+		t.Fatal("expected New to return an IPaddle")
+	}
+
+	checkout, err := c.CreateCheckout(context.Background(), PaddleTransactionRequest{
+		Items:      []PaddleItem{{PriceID: "pri_01", Quantity: 2}},
+		CustomerID: "ctm_01",
+		CustomData: map[string]interface{}{"account": "42"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if checkout.Checkout == nil || checkout.Checkout.URL != "https://pay.example.com/?_ptxn=txn_01" || checkout.Details.Totals.GrandTotal != "2000" {
+		t.Errorf("unexpected transaction %+v", checkout)
+	}
+
+	transactions, pagination, err := c.ListTransactions(context.Background(), url.Values{"subscription_id": {"sub_01"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(transactions) != 1 || transactions[0].InvoiceNumber != "325-10566" || pagination.PerPage != 50 || pagination.EstimatedTotal != 1 {
+		t.Errorf("unexpected transactions %+v %+v", transactions, pagination)
+	}
+
+	invoiceURL, err := c.GetInvoicePDF(context.Background(), "txn_02")
+	if err != nil || invoiceURL != "https://storage.example.com/invoice.pdf" {
+		t.Errorf("unexpected invoice %q, %v", invoiceURL, err)
+	}
+
+	_, err = c.GetTransaction(context.Background(), "unknown")
+	var paddleErr *PaddleError
+	if !errors.As(err, &paddleErr) || paddleErr.Code != "not_found" || !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected a not found PaddleError, got %v", err)
+	}
+
+	subscription, err := c.UpdateSubscription(context.Background(), "sub_01", PaddleSubscriptionUpdate{
+		Items:                []PaddleItem{{PriceID: "pri_02", Quantity: 1}},
+		ProrationBillingMode: PaddleProrationProratedImmediately,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(subscription.Items) != 1 || subscription.Items[0].Price.ID != "pri_02" {
+		t.Errorf("unexpected subscription %+v", subscription)
+	}
+
+	subscription, err = c.PauseSubscription(context.Background(), "sub_01", PaddleEffectiveFromNextBillingPeriod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if subscription.ScheduledChange == nil || subscription.ScheduledChange.Action != "pause" {
+		t.Errorf("unexpected subscription %+v", subscription)
+	}
+
+	subscription, err = c.CancelSubscription(context.Background(), "sub_01", PaddleEffectiveFromImmediately)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if subscription.Status != PaddleSubscriptionStatusCanceled {
+		t.Errorf("unexpected subscription %+v", subscription)
+	}
+
+	if _, err = NewPaddleClient(&Paddle{}); err != ErrInvalidPaddleConfig {
+		t.Errorf("expected ErrInvalidPaddleConfig, got %v", err)
+	}
+}
+
+func TestPaddleWebhook(t *testing.T) {
+	payload := `{"event_id":"evt_01","event_type":"subscription.canceled","occurred_at":"2024-04-12T10:18:49Z","notification_id":"ntf_01","data":{"id":"sub_01","status":"canceled"}}`
+	sign := func(ts string) string {
+		mac := hmac.New(sha256.New, []byte("pdl_ntfset_secret"))
+		mac.Write([]byte(ts + ":" + payload))
+		return hex.EncodeToString(mac.Sum(nil))
+	}
+	newWebhook := func(signature string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/webhooks/paddle", strings.NewReader(payload))
+		req.Header.Set("Paddle-Signature", signature)
+		return req
+	}
+
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	event, err := VerifyPaddleWebhook(newWebhook("ts="+ts+";h1=00ff;h1="+sign(ts)), "pdl_ntfset_secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.EventType != PaddleEventSubscriptionCanceled {
+		t.Errorf("unexpected event %+v", event)
+	}
+	subscription, err := event.Subscription()
+	if err != nil || subscription.ID != "sub_01" {
+		t.Errorf("unexpected subscription %+v, %v", subscription, err)
+	}
+
+	if _, err = VerifyPaddleWebhook(newWebhook("ts="+ts+";h1="+sign(ts)), "other"); err != ErrPaddleWebhookSignature {
+		t.Errorf("expected ErrPaddleWebhookSignature, got %v", err)
+	}
+
+	old := strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10)
+	if _, err = VerifyPaddleWebhook(newWebhook("ts="+old+";h1="+sign(old)), "pdl_ntfset_secret"); err != ErrPaddleWebhookSignature {
+		t.Errorf("expected ErrPaddleWebhookSignature for an old signature, got %v", err)
+	}
+}
+
+func TestTwoCheckout(t *testing.T) {
+	authRegexp := regexp.MustCompile(`^code="MERCHANT" date="([0-9: -]+)" hash="([0-9a-f]{64})" algo="sha256"$`)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		match := authRegexp.FindStringSubmatch(r.Header.Get("X-Avangate-Authentication"))
+		if match == nil {
+			t.Errorf("unexpected authentication %q", r.Header.Get("X-Avangate-Authentication"))
+		} else {
+			mac := hmac.New(sha256.New, []byte("secret-key"))
+			mac.Write([]byte("8MERCHANT19" + match[1]))
+			if match[2] != hex.EncodeToString(mac.Sum(nil)) {
+				t.Errorf("unexpected authentication hash %s", match[2])
+			}
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		switch r.Method + " " + r.URL.Path {
+		case "POST /orders/":
+			expected := `{"Currency":"USD","Country":"US","ExternalReference":"order-42","Items":[{"Code":"PRO","Quantity":1}],"BillingDetails":{"FirstName":"John","LastName":"Doe","Email":"john@example.com","CountryCode":"US"},"PaymentDetails":{"Type":"TEST","Currency":"USD","PaymentMethod":{"EesToken":"tok_42","RecurringEnabled":true}}}`
+			if string(body) != expected {
+				t.Errorf("unexpected body,\n Given:    %s\n Expected: %s", body, expected)
+			}
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"RefNo":"79885823","ExternalReference":"order-42","Status":"AUTHRECEIVED","ApproveStatus":"WAITING","Currency":"usd","GrossPrice":49.99,"Items":[{"Code":"PRO","Quantity":1,"ProductDetails":{"Name":"Pro plan","Subscriptions":[{"SubscriptionReference":"SUB42"}]}}],"PaymentDetails":{"Type":"TEST","Currency":"usd","PaymentMethod":{"RecurringEnabled":true}}}`))
+		case "GET /orders/00000000/":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error_code":"RESOURCE_NOT_FOUND","message":"Order not found"}`))
+		case "POST /orders/79885823/refund/":
+			if string(body) != `{"amount":49.99,"reason":"Other"}` {
+				t.Errorf("unexpected body %s", body)
+			}
+			w.Write([]byte(`true`))
+		case "GET /subscriptions/SUB42/":
+			w.Write([]byte(`{"SubscriptionReference":"SUB42","Status":"ACTIVE","SubscriptionEnabled":true,"RecurringEnabled":true,"ExpirationDate":"2025-01-01 00:00:00","NextRenewalPrice":49.99,"Product":{"ProductCode":"PRO","ProductQuantity":1}}`))
+		case "DELETE /subscriptions/SUB42/renewal/", "DELETE /subscriptions/SUB42/":
+			w.Write([]byte(`true`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	c, ok := New(ctx, TWO_CHECKOUT, &Config{TwoCheckout: TwoCheckout{MerchantCode: "MERCHANT", SecretKey: "secret-key", APIBase: ts.URL}}).(ITwoCheckout)
+	if !ok {
+		t.Fatal("expected New to return an ITwoCheckout")
+	}
+
+	order, err := c.CreateSale(context.Background(), TwoCheckoutOrderRequest{
+		Currency:          "USD",
+		Country:           "US",
+		ExternalReference: "order-42",
+		Items:             []TwoCheckoutItem{{Code: "PRO", Quantity: 1}},
+		BillingDetails:    TwoCheckoutBillingDetails{FirstName: "John", LastName: "Doe", Email: "john@example.com", CountryCode: "US"},
+		PaymentDetails: TwoCheckoutPaymentDetails{
+			Type:          TwoCheckoutPaymentTypeTest,
+			Currency:      "USD",
+			PaymentMethod: TwoCheckoutPaymentMethod{EesToken: "tok_42", RecurringEnabled: true},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if order.RefNo != "79885823" || order.Status != TwoCheckoutOrderStatusAuthReceived || len(order.Items) != 1 || order.Items[0].ProductDetails.Subscriptions[0].SubscriptionReference != "SUB42" {
+		t.Errorf("unexpected order %+v", order)
+	}
+
+	_, err = c.GetSale(context.Background(), "00000000")
+	var twoCheckoutErr *TwoCheckoutError
+	if !errors.As(err, &twoCheckoutErr) || twoCheckoutErr.ErrorCode != "RESOURCE_NOT_FOUND" || !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected a not found TwoCheckoutError, got %v", err)
+	}
+
+	if err = c.RefundSale(context.Background(), order.RefNo, TwoCheckoutRefundRequest{Amount: order.GrossPrice, Reason: "Other"}); err != nil {
+		t.Error(err)
+	}
+
+	subscription, err := c.GetSubscription(context.Background(), "SUB42")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !subscription.RecurringEnabled || subscription.NextRenewalPrice != 49.99 || subscription.Product.ProductCode != "PRO" {
+		t.Errorf("unexpected subscription %+v", subscription)
+	}
+
+	if err = c.DisableRecurring(context.Background(), "SUB42"); err != nil {
+		t.Error(err)
+	}
+	if err = c.DisableSubscription(context.Background(), "SUB42"); err != nil {
+		t.Error(err)
+	}
+
+	if _, err = NewTwoCheckoutClient(&TwoCheckout{MerchantCode: "MERCHANT"}); err != ErrInvalidTwoCheckoutConfig {
+		t.Errorf("expected ErrInvalidTwoCheckoutConfig, got %v", err)
+	}
+}
+
+func TestTwoCheckoutINS(t *testing.T) {
+	newINS := func(hash string) *http.Request {
+		form := url.Values{
+			"message_type": {TwoCheckoutINSRecurringInstallment},
+			"sale_id":      {"4834917619"},
+			"vendor_id":    {"1817037"},
+			"invoice_id":   {"4834917628"},
+			"md5_hash":     {hash},
+		}
+		req := httptest.NewRequest(http.MethodPost, "/ins/2checkout", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		return req
+	}
+	sum := md5.Sum([]byte("4834917619" + "1817037" + "4834917628" + "secret-word"))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+
+	ins, err := VerifyTwoCheckoutINS(newINS(hash), "1817037", "secret-word")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ins.MessageType != TwoCheckoutINSRecurringInstallment || ins.SaleID != "4834917619" || ins.Values.Get("invoice_id") != "4834917628" {
+		t.Errorf("unexpected INS %+v", ins)
+	}
+
+	if _, err = VerifyTwoCheckoutINS(newINS(hash), "1817037", "other"); err != ErrTwoCheckoutINSSignature {
+		t.Errorf("expected ErrTwoCheckoutINSSignature, got %v", err)
+	}
+	if _, err = VerifyTwoCheckoutINS(newINS(hash), "901234", "secret-word"); err != ErrTwoCheckoutINSSignature {
+		t.Errorf("expected ErrTwoCheckoutINSSignature for another seller, got %v", err)
+	}
+}
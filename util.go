@@ -1,7 +1,12 @@
 package payment
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
 )
 
 // SetContext set new context
@@ -13,3 +18,81 @@ func SetContext(context context.Context) {
 func GetContext() context.Context {
 	return ctx
 }
+
+// newJSONRequest builds a request of which the body is payload encoded to JSON, when payload is not nil
+func newJSONRequest(ctx context.Context, method, url string, payload interface{}) (*http.Request, error) {
+	var buf io.Reader
+	if payload != nil {
+		b, err := json.Marshal(payload)
+		if err != nil {
+			return nil, err
+		}
+		buf = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, buf)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Accept", "application/json")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	return req, nil
+}
+
+// doRequest sends req and returns the response of a 2xx status, of which the caller closes the body.
+// Other responses are turned into an error by newErr
+func doRequest(client *http.Client, req *http.Request, newErr func(resp *http.Response, body []byte) error) (*http.Response, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		defer resp.Body.Close()
+		body, _ := ioutil.ReadAll(resp.Body)
+		return nil, newErr(resp, body)
+	}
+
+	return resp, nil
+}
+
+// sendJSON sends req and decodes the JSON body of a 2xx response into v, when v is not nil.
+// Other responses are turned into an error by newErr
+func sendJSON(client *http.Client, req *http.Request, v interface{}, newErr func(resp *http.Response, body []byte) error) error {
+	resp, err := doRequest(client, req, newErr)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if v == nil {
+		return nil
+	}
+
+	if err = json.NewDecoder(resp.Body).Decode(v); err != nil && err != io.EOF {
+		return err
+	}
+
+	return nil
+}
+
+// statusErrorIs reports whether an HTTP status belongs to the error category target,
+// so that the errors of every provider match ErrUnauthorized, ErrNotFound, ErrValidation and ErrRateLimited
+func statusErrorIs(status int, target error) bool {
+	switch target {
+	case ErrUnauthorized:
+		return status == http.StatusUnauthorized
+	case ErrNotFound:
+		return status == http.StatusNotFound
+	case ErrValidation:
+		return status == http.StatusBadRequest || status == http.StatusUnprocessableEntity
+	case ErrRateLimited:
+		return status == http.StatusTooManyRequests
+	}
+
+	return false
+}
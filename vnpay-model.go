@@ -0,0 +1,189 @@
+package payment
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// VNPay locales
+const (
+	VNPayLocaleVietnamese = "vn"
+	VNPayLocaleEnglish    = "en"
+)
+
+// VNPay refund transaction types
+const (
+	VNPayRefundFull    = "02"
+	VNPayRefundPartial = "03"
+)
+
+// VNPay response codes
+// Doc: https://sandbox.vnpayment.vn/apis/docs/bang-ma-loi/
+const (
+	VNPayResponseCodeSuccess          = "00"
+	VNPayResponseCodeInvalidMerchant  = "02"
+	VNPayResponseCodeInvalidFormat    = "03"
+	VNPayResponseCodeNotFound         = "91"
+	VNPayResponseCodeDuplicateRequest = "94"
+	VNPayResponseCodeInvalidChecksum  = "97"
+)
+
+// VNPay IPN answers
+const (
+	VNPayIPNConfirmSuccess   = "00"
+	VNPayIPNOrderNotFound    = "01"
+	VNPayIPNAlreadyConfirmed = "02"
+	VNPayIPNInvalidAmount    = "04"
+	VNPayIPNInvalidChecksum  = "97"
+	VNPayIPNUnknownError     = "99"
+)
+
+// VNPayError is returned for a non 2xx response or a response code other than 00
+type VNPayError struct {
+	Response     *http.Response
+	ResponseCode string
+	Message      string
+}
+
+func (e *VNPayError) Error() string {
+	if e.Response != nil {
+		return fmt.Sprintf("%v %v: %d %s", e.Response.Request.Method, e.Response.Request.URL, e.Response.StatusCode, e.Message)
+	}
+	return fmt.Sprintf("vnpay: %s %s", e.ResponseCode, e.Message)
+}
+
+// Is reports whether the error belongs to the target error category
+func (e *VNPayError) Is(target error) bool {
+	switch target {
+	case ErrUnauthorized:
+		if e.ResponseCode == VNPayResponseCodeInvalidMerchant || e.ResponseCode == VNPayResponseCodeInvalidChecksum {
+			return true
+		}
+	case ErrNotFound:
+		if e.ResponseCode == VNPayResponseCodeNotFound {
+			return true
+		}
+	case ErrValidation:
+		if e.ResponseCode == VNPayResponseCodeInvalidFormat {
+			return true
+		}
+	}
+	if e.Response != nil {
+		return statusErrorIs(e.Response.StatusCode, target)
+	}
+	return false
+}
+
+// VNPayPayment is a payment to redirect the buyer for, Amount in VND
+type VNPayPayment struct {
+	TxnRef     string
+	Amount     int64
+	OrderInfo  string
+	OrderType  string // Defaults to "other"
+	Locale     string // Defaults to VNPayLocaleVietnamese
+	BankCode   string // Skips the bank selection, e.g. "VNPAYQR", "VNBANK" or "INTCARD"
+	IPAddr     string
+	ReturnURL  string // Defaults to the ReturnURL of the client
+	CreateDate time.Time
+	ExpireDate time.Time
+}
+
+// Validate checks the fields required by the payment page
+func (p VNPayPayment) Validate() error {
+	var errs ValidationErrors
+	if p.TxnRef == "" {
+		errs = append(errs, ValidationError{Field: "vnp_TxnRef", Message: "is required"})
+	}
+	if p.Amount <= 0 {
+		errs = append(errs, ValidationError{Field: "vnp_Amount", Message: "must be positive"})
+	}
+	if p.IPAddr == "" {
+		errs = append(errs, ValidationError{Field: "vnp_IpAddr", Message: "is required"})
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// VNPayResult is the result of a payment, as sent to the return URL and the IPN URL, Amount in VND
+type VNPayResult struct {
+	TxnRef            string
+	Amount            int64
+	OrderInfo         string
+	ResponseCode      string
+	TransactionStatus string
+	TransactionNo     string
+	BankCode          string
+	BankTranNo        string
+	CardType          string
+	PayDate           string
+}
+
+// Success reports whether the payment succeeded
+func (r *VNPayResult) Success() bool {
+	return r.ResponseCode == VNPayResponseCodeSuccess && r.TransactionStatus == VNPayResponseCodeSuccess
+}
+
+// VNPayIPNResponse is the JSON answer to an IPN call
+type VNPayIPNResponse struct {
+	RspCode string `json:"RspCode"`
+	Message string `json:"Message"`
+}
+
+// VNPayQuery identifies a payment, TransactionDate is the vnp_CreateDate of the payment
+type VNPayQuery struct {
+	RequestID       string // Unique per request, generated when empty
+	TxnRef          string
+	TransactionNo   string
+	TransactionDate string
+	OrderInfo       string
+	IPAddr          string
+}
+
+// VNPayRefund is a refund of a payment, Amount in VND
+type VNPayRefund struct {
+	RequestID       string
+	TransactionType string // VNPayRefundFull or VNPayRefundPartial
+	TxnRef          string
+	Amount          int64
+	TransactionNo   string
+	TransactionDate string
+	CreateBy        string
+	OrderInfo       string
+	IPAddr          string
+}
+
+// VNPayTransaction is the response of querydr and refund
+type VNPayTransaction struct {
+	ResponseID        string `json:"vnp_ResponseId"`
+	Command           string `json:"vnp_Command"`
+	ResponseCode      string `json:"vnp_ResponseCode"`
+	Message           string `json:"vnp_Message"`
+	TmnCode           string `json:"vnp_TmnCode"`
+	TxnRef            string `json:"vnp_TxnRef"`
+	Amount            string `json:"vnp_Amount"` // In VND × 100
+	BankCode          string `json:"vnp_BankCode"`
+	PayDate           string `json:"vnp_PayDate"`
+	TransactionNo     string `json:"vnp_TransactionNo"`
+	TransactionType   string `json:"vnp_TransactionType"`
+	TransactionStatus string `json:"vnp_TransactionStatus"`
+	OrderInfo         string `json:"vnp_OrderInfo"`
+	PromotionCode     string `json:"vnp_PromotionCode,omitempty"`
+	PromotionAmount   string `json:"vnp_PromotionAmount,omitempty"`
+	SecureHash        string `json:"vnp_SecureHash"`
+}
+
+// hashData returns the data of which the checksum of a response of command is computed
+func (t *VNPayTransaction) hashData(command string) string {
+	fields := []string{
+		t.ResponseID, t.Command, t.ResponseCode, t.Message, t.TmnCode, t.TxnRef, t.Amount, t.BankCode,
+		t.PayDate, t.TransactionNo, t.TransactionType, t.TransactionStatus, t.OrderInfo,
+	}
+	if command == "querydr" {
+		fields = append(fields, t.PromotionCode, t.PromotionAmount)
+	}
+	return strings.Join(fields, "|")
+}
@@ -0,0 +1,275 @@
+package payment
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// VNPayPaymentURLSandBox is the sandbox payment page
+	VNPayPaymentURLSandBox = "https://sandbox.vnpayment.vn/paymentv2/vpcpay.html"
+
+	// VNPayPaymentURLLive is the live payment page
+	VNPayPaymentURLLive = "https://pay.vnpay.vn/vpcpay.html"
+
+	// VNPayAPIURLSandBox is the sandbox merchant API
+	VNPayAPIURLSandBox = "https://sandbox.vnpayment.vn/merchant_webapi/api/transaction"
+
+	// VNPayAPIURLLive is the live merchant API
+	VNPayAPIURLLive = "https://merchant.vnpay.vn/merchant_webapi/api/transaction"
+
+	// vnpayVersion is the version of the payment gateway
+	vnpayVersion = "2.1.0"
+
+	// vnpayDateLayout is the layout of the dates, in GMT+7
+	vnpayDateLayout = "20060102150405"
+)
+
+var (
+	// ErrInvalidVNPayConfig is returned when a VNPay client is created without TmnCode, HashSecret, PaymentURL or APIURL
+	ErrInvalidVNPayConfig = errors.New("vnpay: TmnCode, HashSecret, PaymentURL and APIURL are required to create a client")
+
+	// ErrVNPayChecksum is returned when the vnp_SecureHash of a return URL, an IPN or a response is invalid
+	ErrVNPayChecksum = errors.New("vnpay: invalid checksum")
+)
+
+// indochinaLocation is the GMT+7 time zone of the Vietnamese gateways
+var indochinaLocation = time.FixedZone("ICT", 7*60*60)
+
+// IVNPay is the VNPay client interface
+type IVNPay interface {
+	CreatePaymentURL(payment VNPayPayment) (string, error)
+	VerifyReturn(query url.Values) (*VNPayResult, error)
+	QueryTransaction(ctx context.Context, query VNPayQuery) (*VNPayTransaction, error)
+	Refund(ctx context.Context, refund VNPayRefund) (*VNPayTransaction, error)
+}
+
+// VNPayClient represents a VNPay gateway client
+type VNPayClient struct {
+	Client     *http.Client
+	TmnCode    string
+	HashSecret string
+	PaymentURL string
+	APIURL     string
+	ReturnURL  string
+}
+
+// NewVNPayClient returns a VNPay client for config
+func NewVNPayClient(config *VNPay) (IVNPay, error) {
+	if config == nil || config.TmnCode == "" || config.HashSecret == "" || config.PaymentURL == "" || config.APIURL == "" {
+		return nil, ErrInvalidVNPayConfig
+	}
+
+	return &VNPayClient{
+		Client:     &http.Client{},
+		TmnCode:    config.TmnCode,
+		HashSecret: config.HashSecret,
+		PaymentURL: config.PaymentURL,
+		APIURL:     config.APIURL,
+		ReturnURL:  config.ReturnURL,
+	}, nil
+}
+
+// newVNPay returns a VNPay client, or nil when config is invalid
+func newVNPay(config *VNPay) IVNPay {
+	client, err := NewVNPayClient(config)
+	if err != nil {
+		log.Println("Unable to init VNPay client: ", err)
+		return nil
+	}
+
+	return client
+}
+
+// VNPayDate formats t as a VNPay date
+func VNPayDate(t time.Time) string {
+	return t.In(indochinaLocation).Format(vnpayDateLayout)
+}
+
+// checksum returns the hex HMAC-SHA512 of data with the hash secret
+func (c *VNPayClient) checksum(data string) string {
+	mac := hmac.New(sha512.New, []byte(c.HashSecret))
+	mac.Write([]byte(data))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// CreatePaymentURL returns the signed URL of the payment page to redirect the buyer to.
+// The buyer is sent back to ReturnURL, of which the query is checked by VerifyReturn
+// Doc: https://sandbox.vnpayment.vn/apis/docs/thanh-toan-pay/pay.html
+func (c *VNPayClient) CreatePaymentURL(payment VNPayPayment) (string, error) {
+	if err := payment.Validate(); err != nil {
+		return "", err
+	}
+
+	returnURL := payment.ReturnURL
+	if returnURL == "" {
+		returnURL = c.ReturnURL
+	}
+	createDate := payment.CreateDate
+	if createDate.IsZero() {
+		createDate = time.Now()
+	}
+
+	params := url.Values{
+		"vnp_Version":    {vnpayVersion},
+		"vnp_Command":    {"pay"},
+		"vnp_TmnCode":    {c.TmnCode},
+		"vnp_Amount":     {strconv.FormatInt(payment.Amount*100, 10)},
+		"vnp_CurrCode":   {"VND"},
+		"vnp_TxnRef":     {payment.TxnRef},
+		"vnp_OrderInfo":  {payment.OrderInfo},
+		"vnp_OrderType":  {payment.OrderType},
+		"vnp_Locale":     {payment.Locale},
+		"vnp_ReturnUrl":  {returnURL},
+		"vnp_IpAddr":     {payment.IPAddr},
+		"vnp_CreateDate": {VNPayDate(createDate)},
+	}
+	if params.Get("vnp_OrderType") == "" {
+		params.Set("vnp_OrderType", "other")
+	}
+	if params.Get("vnp_Locale") == "" {
+		params.Set("vnp_Locale", VNPayLocaleVietnamese)
+	}
+	if payment.BankCode != "" {
+		params.Set("vnp_BankCode", payment.BankCode)
+	}
+	if !payment.ExpireDate.IsZero() {
+		params.Set("vnp_ExpireDate", VNPayDate(payment.ExpireDate))
+	}
+
+	query := params.Encode()
+	return c.PaymentURL + "?" + query + "&vnp_SecureHash=" + c.checksum(query), nil
+}
+
+// VerifyReturn checks the checksum of the query of the return URL or of an IPN call and decodes it.
+// Answer an IPN with a VNPayIPNResponse, VNPay retries otherwise
+// Doc: https://sandbox.vnpayment.vn/apis/docs/thanh-toan-pay/pay.html#code-ipn-url
+func (c *VNPayClient) VerifyReturn(query url.Values) (*VNPayResult, error) {
+	params := url.Values{}
+	for key, values := range query {
+		if strings.HasPrefix(key, "vnp_") && key != "vnp_SecureHash" && key != "vnp_SecureHashType" {
+			params[key] = values
+		}
+	}
+
+	if !hmac.Equal([]byte(c.checksum(params.Encode())), []byte(strings.ToLower(query.Get("vnp_SecureHash")))) {
+		return nil, ErrVNPayChecksum
+	}
+	if params.Get("vnp_TmnCode") != c.TmnCode {
+		return nil, ErrVNPayChecksum
+	}
+
+	amount, _ := strconv.ParseInt(params.Get("vnp_Amount"), 10, 64)
+	return &VNPayResult{
+		TxnRef:            params.Get("vnp_TxnRef"),
+		Amount:            amount / 100,
+		OrderInfo:         params.Get("vnp_OrderInfo"),
+		ResponseCode:      params.Get("vnp_ResponseCode"),
+		TransactionStatus: params.Get("vnp_TransactionStatus"),
+		TransactionNo:     params.Get("vnp_TransactionNo"),
+		BankCode:          params.Get("vnp_BankCode"),
+		BankTranNo:        params.Get("vnp_BankTranNo"),
+		CardType:          params.Get("vnp_CardType"),
+		PayDate:           params.Get("vnp_PayDate"),
+	}, nil
+}
+
+// send posts a request to the merchant API and checks the checksum of the response
+func (c *VNPayClient) send(ctx context.Context, request map[string]string, hashFields []string, response *VNPayTransaction) error {
+	request["vnp_Version"] = vnpayVersion
+	request["vnp_TmnCode"] = c.TmnCode
+	request["vnp_CreateDate"] = VNPayDate(time.Now())
+	if request["vnp_RequestId"] == "" {
+		request["vnp_RequestId"] = strconv.FormatInt(time.Now().UnixNano(), 10)
+	}
+	values := make([]string, len(hashFields))
+	for i, field := range hashFields {
+		values[i] = request[field]
+	}
+	request["vnp_SecureHash"] = c.checksum(strings.Join(values, "|"))
+
+	req, err := newJSONRequest(ctx, http.MethodPost, c.APIURL, request)
+	if err != nil {
+		return err
+	}
+
+	err = sendJSON(c.Client, req, response, func(resp *http.Response, body []byte) error {
+		return &VNPayError{Response: resp, Message: string(body)}
+	})
+	if err != nil {
+		return err
+	}
+
+	if response.ResponseCode != VNPayResponseCodeSuccess {
+		return &VNPayError{ResponseCode: response.ResponseCode, Message: response.Message}
+	}
+	if !hmac.Equal([]byte(c.checksum(response.hashData(request["vnp_Command"]))), []byte(strings.ToLower(response.SecureHash))) {
+		return ErrVNPayChecksum
+	}
+
+	return nil
+}
+
+// QueryTransaction returns the status of a payment
+// Command: querydr
+// Doc: https://sandbox.vnpayment.vn/apis/docs/truy-van-hoan-tien/querydr&refund.html
+func (c *VNPayClient) QueryTransaction(ctx context.Context, query VNPayQuery) (*VNPayTransaction, error) {
+	request := map[string]string{
+		"vnp_RequestId":       query.RequestID,
+		"vnp_Command":         "querydr",
+		"vnp_TxnRef":          query.TxnRef,
+		"vnp_OrderInfo":       query.OrderInfo,
+		"vnp_TransactionNo":   query.TransactionNo,
+		"vnp_TransactionDate": query.TransactionDate,
+		"vnp_IpAddr":          query.IPAddr,
+	}
+	if request["vnp_OrderInfo"] == "" {
+		request["vnp_OrderInfo"] = "Query " + query.TxnRef
+	}
+
+	response := &VNPayTransaction{}
+	err := c.send(ctx, request, []string{
+		"vnp_RequestId", "vnp_Version", "vnp_Command", "vnp_TmnCode", "vnp_TxnRef",
+		"vnp_TransactionDate", "vnp_CreateDate", "vnp_IpAddr", "vnp_OrderInfo",
+	}, response)
+	return response, err
+}
+
+// Refund refunds a payment, partially when Amount is less than the amount of the payment
+// Command: refund
+func (c *VNPayClient) Refund(ctx context.Context, refund VNPayRefund) (*VNPayTransaction, error) {
+	request := map[string]string{
+		"vnp_RequestId":       refund.RequestID,
+		"vnp_Command":         "refund",
+		"vnp_TransactionType": refund.TransactionType,
+		"vnp_TxnRef":          refund.TxnRef,
+		"vnp_Amount":          strconv.FormatInt(refund.Amount*100, 10),
+		"vnp_OrderInfo":       refund.OrderInfo,
+		"vnp_TransactionNo":   refund.TransactionNo,
+		"vnp_TransactionDate": refund.TransactionDate,
+		"vnp_CreateBy":        refund.CreateBy,
+		"vnp_IpAddr":          refund.IPAddr,
+	}
+	if request["vnp_TransactionType"] == "" {
+		request["vnp_TransactionType"] = VNPayRefundFull
+	}
+	if request["vnp_OrderInfo"] == "" {
+		request["vnp_OrderInfo"] = "Refund " + refund.TxnRef
+	}
+
+	response := &VNPayTransaction{}
+	err := c.send(ctx, request, []string{
+		"vnp_RequestId", "vnp_Version", "vnp_Command", "vnp_TmnCode", "vnp_TransactionType", "vnp_TxnRef", "vnp_Amount",
+		"vnp_TransactionNo", "vnp_TransactionDate", "vnp_CreateBy", "vnp_CreateDate", "vnp_IpAddr", "vnp_OrderInfo",
+	}, response)
+	return response, err
+}
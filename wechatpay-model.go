@@ -0,0 +1,209 @@
+package payment
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// WeChat Pay trade states
+// Doc: https://pay.weixin.qq.com/wiki/doc/apiv3/apis/chapter3_4_2.shtml
+const (
+	WeChatPayTradeStateSuccess    = "SUCCESS"
+	WeChatPayTradeStateRefund     = "REFUND"
+	WeChatPayTradeStateNotPay     = "NOTPAY"
+	WeChatPayTradeStateClosed     = "CLOSED"
+	WeChatPayTradeStateUserPaying = "USERPAYING"
+	WeChatPayTradeStatePayError   = "PAYERROR"
+)
+
+// WeChat Pay refund statuses
+const (
+	WeChatPayRefundStatusSuccess    = "SUCCESS"
+	WeChatPayRefundStatusClosed     = "CLOSED"
+	WeChatPayRefundStatusProcessing = "PROCESSING"
+	WeChatPayRefundStatusAbnormal   = "ABNORMAL"
+)
+
+// WeChat Pay callback event types
+const (
+	WeChatPayEventTransactionSuccess = "TRANSACTION.SUCCESS"
+	WeChatPayEventRefundSuccess      = "REFUND.SUCCESS"
+	WeChatPayEventRefundAbnormal     = "REFUND.ABNORMAL"
+	WeChatPayEventRefundClosed       = "REFUND.CLOSED"
+)
+
+// WeChatPayError is returned for a non 2xx response
+// Doc: https://pay.weixin.qq.com/wiki/doc/apiv3/wechatpay/wechatpay2_0.shtml
+type WeChatPayError struct {
+	Response *http.Response `json:"-"`
+	Code     string         `json:"code"`
+	Message  string         `json:"message"`
+}
+
+func (e *WeChatPayError) Error() string {
+	return fmt.Sprintf("%v %v: %d %s %s", e.Response.Request.Method, e.Response.Request.URL, e.Response.StatusCode, e.Code, e.Message)
+}
+
+// Is reports whether the error belongs to the target error category
+func (e *WeChatPayError) Is(target error) bool {
+	switch target {
+	case ErrUnauthorized:
+		if e.Code == "SIGN_ERROR" {
+			return true
+		}
+	case ErrNotFound:
+		if e.Code == "ORDER_NOT_EXIST" || e.Code == "RESOURCE_NOT_EXISTS" {
+			return true
+		}
+	case ErrValidation:
+		if e.Code == "PARAM_ERROR" || e.Code == "INVALID_REQUEST" {
+			return true
+		}
+	case ErrRateLimited:
+		if e.Code == "FREQUENCY_LIMITED" {
+			return true
+		}
+	}
+	return statusErrorIs(e.Response.StatusCode, target)
+}
+
+// WeChatPayEncryptedResource is an AEAD_AES_256_GCM ciphertext encrypted with the APIv3 key
+type WeChatPayEncryptedResource struct {
+	Algorithm      string `json:"algorithm"`
+	Ciphertext     string `json:"ciphertext"`
+	AssociatedData string `json:"associated_data,omitempty"`
+	OriginalType   string `json:"original_type,omitempty"`
+	Nonce          string `json:"nonce"`
+}
+
+// WeChatPayCertificatesResponse struct
+type WeChatPayCertificatesResponse struct {
+	Data []struct {
+		SerialNo           string                     `json:"serial_no"`
+		EffectiveTime      string                     `json:"effective_time"`
+		ExpireTime         string                     `json:"expire_time"`
+		EncryptCertificate WeChatPayEncryptedResource `json:"encrypt_certificate"`
+	} `json:"data"`
+}
+
+// WeChatPayAmount is an amount in the minor unit of Currency, fen for CNY
+type WeChatPayAmount struct {
+	Total         int64  `json:"total"`
+	Currency      string `json:"currency,omitempty"`
+	PayerTotal    int64  `json:"payer_total,omitempty"`
+	PayerCurrency string `json:"payer_currency,omitempty"`
+}
+
+// WeChatPayPayer struct
+type WeChatPayPayer struct {
+	OpenID string `json:"openid"`
+}
+
+// WeChatPaySceneInfo struct, H5Info is required by H5 transactions
+type WeChatPaySceneInfo struct {
+	PayerClientIP string `json:"payer_client_ip"`
+	DeviceID      string `json:"device_id,omitempty"`
+	H5Info        *struct {
+		Type string `json:"type"` // Wap, iOS or Android
+	} `json:"h5_info,omitempty"`
+}
+
+// WeChatPayTransaction is the request of the native, JSAPI and H5 transactions.
+// MchID is set by the client, AppID and NotifyURL when empty
+type WeChatPayTransaction struct {
+	AppID       string              `json:"appid"`
+	MchID       string              `json:"mchid"`
+	Description string              `json:"description"`
+	OutTradeNo  string              `json:"out_trade_no"`
+	TimeExpire  string              `json:"time_expire,omitempty"` // RFC3339
+	Attach      string              `json:"attach,omitempty"`
+	NotifyURL   string              `json:"notify_url"`
+	Amount      WeChatPayAmount     `json:"amount"`
+	Payer       *WeChatPayPayer     `json:"payer,omitempty"` // JSAPI only
+	SceneInfo   *WeChatPaySceneInfo `json:"scene_info,omitempty"`
+}
+
+// WeChatPayNativeResponse struct
+type WeChatPayNativeResponse struct {
+	CodeURL string `json:"code_url"`
+}
+
+// WeChatPayJSAPIResponse struct
+type WeChatPayJSAPIResponse struct {
+	PrepayID string `json:"prepay_id"`
+}
+
+// WeChatPayH5Response struct
+type WeChatPayH5Response struct {
+	H5URL string `json:"h5_url"`
+}
+
+// WeChatPayJSAPIParams are the parameters of WeixinJSBridge getBrandWCPayRequest
+type WeChatPayJSAPIParams struct {
+	AppID     string `json:"appId"`
+	TimeStamp string `json:"timeStamp"`
+	NonceStr  string `json:"nonceStr"`
+	Package   string `json:"package"`
+	SignType  string `json:"signType"`
+	PaySign   string `json:"paySign"`
+}
+
+// WeChatPayTransactionDetail is a transaction, as queried or decrypted from a callback
+type WeChatPayTransactionDetail struct {
+	AppID          string          `json:"appid"`
+	MchID          string          `json:"mchid"`
+	OutTradeNo     string          `json:"out_trade_no"`
+	TransactionID  string          `json:"transaction_id,omitempty"`
+	TradeType      string          `json:"trade_type,omitempty"`
+	TradeState     string          `json:"trade_state"`
+	TradeStateDesc string          `json:"trade_state_desc,omitempty"`
+	BankType       string          `json:"bank_type,omitempty"`
+	Attach         string          `json:"attach,omitempty"`
+	SuccessTime    string          `json:"success_time,omitempty"`
+	Payer          *WeChatPayPayer `json:"payer,omitempty"`
+	Amount         WeChatPayAmount `json:"amount"`
+}
+
+// WeChatPayRefundAmount struct, in the minor unit of Currency
+type WeChatPayRefundAmount struct {
+	Refund      int64  `json:"refund"`
+	Total       int64  `json:"total"`
+	Currency    string `json:"currency"`
+	PayerTotal  int64  `json:"payer_total,omitempty"`
+	PayerRefund int64  `json:"payer_refund,omitempty"`
+}
+
+// WeChatPayRefundRequest identifies the transaction by TransactionID or OutTradeNo
+type WeChatPayRefundRequest struct {
+	TransactionID string                `json:"transaction_id,omitempty"`
+	OutTradeNo    string                `json:"out_trade_no,omitempty"`
+	OutRefundNo   string                `json:"out_refund_no"`
+	Reason        string                `json:"reason,omitempty"`
+	NotifyURL     string                `json:"notify_url,omitempty"`
+	Amount        WeChatPayRefundAmount `json:"amount"`
+}
+
+// WeChatPayRefund is a refund, as queried or decrypted from a callback
+type WeChatPayRefund struct {
+	RefundID            string                `json:"refund_id"`
+	OutRefundNo         string                `json:"out_refund_no"`
+	TransactionID       string                `json:"transaction_id"`
+	OutTradeNo          string                `json:"out_trade_no"`
+	Channel             string                `json:"channel,omitempty"`
+	UserReceivedAccount string                `json:"user_received_account,omitempty"`
+	SuccessTime         string                `json:"success_time,omitempty"`
+	CreateTime          string                `json:"create_time,omitempty"`
+	Status              string                `json:"status,omitempty"`
+	RefundStatus        string                `json:"refund_status,omitempty"` // Set in callbacks instead of Status
+	Amount              WeChatPayRefundAmount `json:"amount"`
+}
+
+// WeChatPayNotification is a payment or refund callback, of which Resource is encrypted
+type WeChatPayNotification struct {
+	ID           string                     `json:"id"`
+	CreateTime   string                     `json:"create_time"`
+	EventType    string                     `json:"event_type"`
+	ResourceType string                     `json:"resource_type"`
+	Summary      string                     `json:"summary"`
+	Resource     WeChatPayEncryptedResource `json:"resource"`
+}
@@ -0,0 +1,423 @@
+package payment
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// WeChatPayAPIBaseLive is the base of the WeChat Pay v3 API, which has no sandbox
+	WeChatPayAPIBaseLive = "https://api.mch.weixin.qq.com"
+
+	// wechatPayAuthSchema is the schema of the Authorization header
+	wechatPayAuthSchema = "WECHATPAY2-SHA256-RSA2048"
+
+	// wechatPayTimestampSkew is how old a signed response or callback may be
+	wechatPayTimestampSkew = 5 * time.Minute
+)
+
+var (
+	// ErrInvalidWeChatPayConfig is returned when a WeChat Pay client is created without MchID, SerialNo, PrivateKey, APIv3Key or APIBase
+	ErrInvalidWeChatPayConfig = errors.New("wechatpay: MchID, SerialNo, PrivateKey, a 32 bytes APIv3Key and APIBase are required to create a client")
+
+	// ErrWeChatPaySignature is returned when a response or a callback is not signed by a WeChat Pay platform certificate
+	ErrWeChatPaySignature = errors.New("wechatpay: invalid signature")
+)
+
+// IWeChatPay is the WeChat Pay client interface
+type IWeChatPay interface {
+	CreateNativeTransaction(ctx context.Context, transaction WeChatPayTransaction) (*WeChatPayNativeResponse, error)
+	CreateJSAPITransaction(ctx context.Context, transaction WeChatPayTransaction) (*WeChatPayJSAPIResponse, error)
+	CreateH5Transaction(ctx context.Context, transaction WeChatPayTransaction) (*WeChatPayH5Response, error)
+	JSAPIPayParams(appID, prepayID string) (*WeChatPayJSAPIParams, error)
+	QueryTransaction(ctx context.Context, outTradeNo string) (*WeChatPayTransactionDetail, error)
+	CloseTransaction(ctx context.Context, outTradeNo string) error
+	CreateRefund(ctx context.Context, refund WeChatPayRefundRequest) (*WeChatPayRefund, error)
+	QueryRefund(ctx context.Context, outRefundNo string) (*WeChatPayRefund, error)
+	DownloadCertificates(ctx context.Context) ([]*x509.Certificate, error)
+	ParseNotification(ctx context.Context, req *http.Request) (*WeChatPayNotification, error)
+	DecryptResource(resource WeChatPayEncryptedResource, v interface{}) error
+}
+
+// WeChatPayClient represents a WeChat Pay v3 API client
+type WeChatPayClient struct {
+	sync.Mutex
+	Client       *http.Client
+	MchID        string
+	AppID        string
+	SerialNo     string
+	APIBase      string
+	NotifyURL    string
+	privateKey   *rsa.PrivateKey
+	apiV3Key     []byte
+	certificates map[string]*x509.Certificate
+}
+
+// NewWeChatPayClient returns a WeChat Pay client for config.
+// The platform certificates are downloaded when the first response is verified
+func NewWeChatPayClient(config *WeChatPay) (IWeChatPay, error) {
+	if config == nil || config.MchID == "" || config.SerialNo == "" || config.PrivateKey == "" || len(config.APIv3Key) != 32 || config.APIBase == "" {
+		return nil, ErrInvalidWeChatPayConfig
+	}
+
+	privateKey, err := parseRSAPrivateKey(config.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("wechatpay: private key: %w", err)
+	}
+
+	return &WeChatPayClient{
+		Client:       &http.Client{},
+		MchID:        config.MchID,
+		AppID:        config.AppID,
+		SerialNo:     config.SerialNo,
+		APIBase:      config.APIBase,
+		NotifyURL:    config.NotifyURL,
+		privateKey:   privateKey,
+		apiV3Key:     []byte(config.APIv3Key),
+		certificates: map[string]*x509.Certificate{},
+	}, nil
+}
+
+// newWeChatPay returns a WeChat Pay client, or nil when config is invalid
+func newWeChatPay(config *WeChatPay) IWeChatPay {
+	client, err := NewWeChatPayClient(config)
+	if err != nil {
+		log.Println("Unable to init WeChat Pay client: ", err)
+		return nil
+	}
+
+	return client
+}
+
+// sign returns the base64 RSA-SHA256 signature of the lines, each followed by "\n"
+func (c *WeChatPayClient) sign(lines ...string) (string, error) {
+	var buf bytes.Buffer
+	for _, line := range lines {
+		buf.WriteString(line)
+		buf.WriteByte('\n')
+	}
+
+	hashed := sha256.Sum256(buf.Bytes())
+	signature, err := rsa.SignPKCS1v15(rand.Reader, c.privateKey, crypto.SHA256, hashed[:])
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(signature), nil
+}
+
+// newNonce returns a random nonce string
+func newNonce() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// decrypt opens an AEAD_AES_256_GCM ciphertext with the APIv3 key
+func (c *WeChatPayClient) decrypt(resource WeChatPayEncryptedResource) ([]byte, error) {
+	ciphertext, err := base64.StdEncoding.DecodeString(resource.Ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(c.apiV3Key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCMWithNonceSize(block, len(resource.Nonce))
+	if err != nil {
+		return nil, err
+	}
+	return gcm.Open(nil, []byte(resource.Nonce), ciphertext, []byte(resource.AssociatedData))
+}
+
+// DecryptResource decrypts the resource of a callback or a certificate into v
+func (c *WeChatPayClient) DecryptResource(resource WeChatPayEncryptedResource, v interface{}) error {
+	plaintext, err := c.decrypt(resource)
+	if err != nil {
+		return fmt.Errorf("wechatpay: decrypt resource: %w", err)
+	}
+	return json.Unmarshal(plaintext, v)
+}
+
+// verifySignature checks the Wechatpay-* headers against body with the given platform certificates
+func verifyWeChatPaySignature(header http.Header, body []byte, certificates map[string]*x509.Certificate) error {
+	timestamp, err := strconv.ParseInt(header.Get("Wechatpay-Timestamp"), 10, 64)
+	if err != nil {
+		return ErrWeChatPaySignature
+	}
+	if age := time.Since(time.Unix(timestamp, 0)); age > wechatPayTimestampSkew || age < -wechatPayTimestampSkew {
+		return ErrWeChatPaySignature
+	}
+
+	certificate := certificates[header.Get("Wechatpay-Serial")]
+	if certificate == nil {
+		return ErrWeChatPaySignature
+	}
+	publicKey, ok := certificate.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return ErrWeChatPaySignature
+	}
+	signature, err := base64.StdEncoding.DecodeString(header.Get("Wechatpay-Signature"))
+	if err != nil {
+		return ErrWeChatPaySignature
+	}
+
+	message := header.Get("Wechatpay-Timestamp") + "\n" + header.Get("Wechatpay-Nonce") + "\n" + string(body) + "\n"
+	hashed := sha256.Sum256([]byte(message))
+	if rsa.VerifyPKCS1v15(publicKey, crypto.SHA256, hashed[:], signature) != nil {
+		return ErrWeChatPaySignature
+	}
+	return nil
+}
+
+// verify checks a signed response or callback, downloading the platform certificates
+// when the serial in the header is unknown
+func (c *WeChatPayClient) verify(ctx context.Context, header http.Header, body []byte) error {
+	c.Lock()
+	_, known := c.certificates[header.Get("Wechatpay-Serial")]
+	c.Unlock()
+	if !known {
+		if _, err := c.DownloadCertificates(ctx); err != nil {
+			return err
+		}
+	}
+
+	c.Lock()
+	defer c.Unlock()
+	return verifyWeChatPaySignature(header, body, c.certificates)
+}
+
+// send signs and sends a request to path, returning the body and the headers of a 2xx response
+func (c *WeChatPayClient) send(ctx context.Context, method, path string, payload interface{}) ([]byte, http.Header, error) {
+	req, err := newJSONRequest(ctx, method, c.APIBase+path, payload)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var body []byte
+	if payload != nil {
+		if body, err = json.Marshal(payload); err != nil {
+			return nil, nil, err
+		}
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	nonce := newNonce()
+	signature, err := c.sign(method, path, timestamp, nonce, string(body))
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("Authorization", fmt.Sprintf(`%s mchid="%s",nonce_str="%s",signature="%s",timestamp="%s",serial_no="%s"`,
+		wechatPayAuthSchema, c.MchID, nonce, signature, timestamp, c.SerialNo))
+
+	resp, err := doRequest(c.Client, req, func(resp *http.Response, body []byte) error {
+		e := &WeChatPayError{Response: resp}
+		json.Unmarshal(body, e)
+		return e
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err = ioutil.ReadAll(resp.Body)
+	return body, resp.Header, err
+}
+
+// execute sends a request and decodes the verified response into v, when v is not nil
+func (c *WeChatPayClient) execute(ctx context.Context, method, path string, payload, v interface{}) error {
+	body, header, err := c.send(ctx, method, path, payload)
+	if err != nil {
+		return err
+	}
+	if err = c.verify(ctx, header, body); err != nil {
+		return err
+	}
+
+	if v == nil || len(body) == 0 {
+		return nil
+	}
+	return json.Unmarshal(body, v)
+}
+
+// DownloadCertificates downloads, decrypts and stores the platform certificates which sign the responses and callbacks.
+// The response itself is verified with the certificates it carries
+// Endpoint: GET /v3/certificates
+func (c *WeChatPayClient) DownloadCertificates(ctx context.Context) ([]*x509.Certificate, error) {
+	body, header, err := c.send(ctx, http.MethodGet, "/v3/certificates", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &WeChatPayCertificatesResponse{}
+	if err = json.Unmarshal(body, response); err != nil {
+		return nil, err
+	}
+
+	downloaded := map[string]*x509.Certificate{}
+	certificates := make([]*x509.Certificate, 0, len(response.Data))
+	for _, data := range response.Data {
+		plaintext, err := c.decrypt(data.EncryptCertificate)
+		if err != nil {
+			return nil, fmt.Errorf("wechatpay: decrypt certificate %s: %w", data.SerialNo, err)
+		}
+		block, _ := pem.Decode(plaintext)
+		if block == nil {
+			return nil, fmt.Errorf("wechatpay: certificate %s is not PEM encoded", data.SerialNo)
+		}
+		certificate, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		downloaded[data.SerialNo] = certificate
+		certificates = append(certificates, certificate)
+	}
+
+	if err = verifyWeChatPaySignature(header, body, downloaded); err != nil {
+		return nil, err
+	}
+
+	c.Lock()
+	for serialNo, certificate := range downloaded {
+		c.certificates[serialNo] = certificate
+	}
+	c.Unlock()
+
+	return certificates, nil
+}
+
+// fill sets the merchant fields of transaction from the client when they are empty
+func (c *WeChatPayClient) fill(transaction *WeChatPayTransaction) {
+	transaction.MchID = c.MchID
+	if transaction.AppID == "" {
+		transaction.AppID = c.AppID
+	}
+	if transaction.NotifyURL == "" {
+		transaction.NotifyURL = c.NotifyURL
+	}
+	if transaction.Amount.Currency == "" {
+		transaction.Amount.Currency = "CNY"
+	}
+}
+
+// CreateNativeTransaction creates a transaction paid by scanning the returned code URL
+// Endpoint: POST /v3/pay/transactions/native
+func (c *WeChatPayClient) CreateNativeTransaction(ctx context.Context, transaction WeChatPayTransaction) (*WeChatPayNativeResponse, error) {
+	c.fill(&transaction)
+	response := &WeChatPayNativeResponse{}
+	err := c.execute(ctx, http.MethodPost, "/v3/pay/transactions/native", transaction, response)
+	return response, err
+}
+
+// CreateJSAPITransaction creates a transaction paid inside WeChat by Payer.OpenID, see JSAPIPayParams
+// Endpoint: POST /v3/pay/transactions/jsapi
+func (c *WeChatPayClient) CreateJSAPITransaction(ctx context.Context, transaction WeChatPayTransaction) (*WeChatPayJSAPIResponse, error) {
+	c.fill(&transaction)
+	response := &WeChatPayJSAPIResponse{}
+	err := c.execute(ctx, http.MethodPost, "/v3/pay/transactions/jsapi", transaction, response)
+	return response, err
+}
+
+// CreateH5Transaction creates a transaction paid from a mobile browser at the returned URL
+// Endpoint: POST /v3/pay/transactions/h5
+func (c *WeChatPayClient) CreateH5Transaction(ctx context.Context, transaction WeChatPayTransaction) (*WeChatPayH5Response, error) {
+	c.fill(&transaction)
+	response := &WeChatPayH5Response{}
+	err := c.execute(ctx, http.MethodPost, "/v3/pay/transactions/h5", transaction, response)
+	return response, err
+}
+
+// JSAPIPayParams returns the signed parameters of WeixinJSBridge getBrandWCPayRequest for a prepay ID
+// Doc: https://pay.weixin.qq.com/wiki/doc/apiv3/apis/chapter3_1_4.shtml
+func (c *WeChatPayClient) JSAPIPayParams(appID, prepayID string) (*WeChatPayJSAPIParams, error) {
+	if appID == "" {
+		appID = c.AppID
+	}
+	params := &WeChatPayJSAPIParams{
+		AppID:     appID,
+		TimeStamp: strconv.FormatInt(time.Now().Unix(), 10),
+		NonceStr:  newNonce(),
+		Package:   "prepay_id=" + prepayID,
+		SignType:  "RSA",
+	}
+
+	signature, err := c.sign(params.AppID, params.TimeStamp, params.NonceStr, params.Package)
+	if err != nil {
+		return nil, err
+	}
+	params.PaySign = signature
+
+	return params, nil
+}
+
+// QueryTransaction returns a transaction by the merchant order number
+// Endpoint: GET /v3/pay/transactions/out-trade-no/{out_trade_no}
+func (c *WeChatPayClient) QueryTransaction(ctx context.Context, outTradeNo string) (*WeChatPayTransactionDetail, error) {
+	response := &WeChatPayTransactionDetail{}
+	path := "/v3/pay/transactions/out-trade-no/" + url.PathEscape(outTradeNo) + "?mchid=" + url.QueryEscape(c.MchID)
+	err := c.execute(ctx, http.MethodGet, path, nil, response)
+	return response, err
+}
+
+// CloseTransaction closes an unpaid transaction
+// Endpoint: POST /v3/pay/transactions/out-trade-no/{out_trade_no}/close
+func (c *WeChatPayClient) CloseTransaction(ctx context.Context, outTradeNo string) error {
+	path := "/v3/pay/transactions/out-trade-no/" + url.PathEscape(outTradeNo) + "/close"
+	return c.execute(ctx, http.MethodPost, path, map[string]string{"mchid": c.MchID}, nil)
+}
+
+// CreateRefund refunds a transaction, partially when Amount.Refund is less than Amount.Total
+// Endpoint: POST /v3/refund/domestic/refunds
+func (c *WeChatPayClient) CreateRefund(ctx context.Context, refund WeChatPayRefundRequest) (*WeChatPayRefund, error) {
+	if refund.Amount.Currency == "" {
+		refund.Amount.Currency = "CNY"
+	}
+	response := &WeChatPayRefund{}
+	err := c.execute(ctx, http.MethodPost, "/v3/refund/domestic/refunds", refund, response)
+	return response, err
+}
+
+// QueryRefund returns a refund by the merchant refund number
+// Endpoint: GET /v3/refund/domestic/refunds/{out_refund_no}
+func (c *WeChatPayClient) QueryRefund(ctx context.Context, outRefundNo string) (*WeChatPayRefund, error) {
+	response := &WeChatPayRefund{}
+	err := c.execute(ctx, http.MethodGet, "/v3/refund/domestic/refunds/"+url.PathEscape(outRefundNo), nil, response)
+	return response, err
+}
+
+// ParseNotification verifies the signature of a payment or refund callback and decodes it.
+// Decrypt the resource with DecryptResource into a WeChatPayTransactionDetail or a WeChatPayRefund
+// Doc: https://pay.weixin.qq.com/wiki/doc/apiv3/apis/chapter3_1_5.shtml
+func (c *WeChatPayClient) ParseNotification(ctx context.Context, req *http.Request) (*WeChatPayNotification, error) {
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	if err = c.verify(ctx, req.Header, body); err != nil {
+		return nil, err
+	}
+
+	notification := &WeChatPayNotification{}
+	if err = json.Unmarshal(body, notification); err != nil {
+		return nil, err
+	}
+	return notification, nil
+}
@@ -0,0 +1,148 @@
+package payment
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// ZaloPay return codes
+const (
+	ZaloPayReturnCodeSuccess    = 1
+	ZaloPayReturnCodeFailed     = 2
+	ZaloPayReturnCodeProcessing = 3
+)
+
+// ZaloPay sub return codes
+const (
+	ZaloPaySubReturnCodeInvalidParams = -401
+	ZaloPaySubReturnCodeInvalidMAC    = -402
+)
+
+// ZaloPayError is returned for a non 2xx response or a failed return code
+type ZaloPayError struct {
+	Response         *http.Response
+	ReturnCode       int
+	ReturnMessage    string
+	SubReturnCode    int
+	SubReturnMessage string
+}
+
+func (e *ZaloPayError) Error() string {
+	if e.Response != nil {
+		return fmt.Sprintf("%v %v: %d %s", e.Response.Request.Method, e.Response.Request.URL, e.Response.StatusCode, e.ReturnMessage)
+	}
+	return fmt.Sprintf("zalopay: %d %s: %d %s", e.ReturnCode, e.ReturnMessage, e.SubReturnCode, e.SubReturnMessage)
+}
+
+// Is reports whether the error belongs to the target error category
+func (e *ZaloPayError) Is(target error) bool {
+	switch target {
+	case ErrUnauthorized:
+		if e.SubReturnCode == ZaloPaySubReturnCodeInvalidMAC {
+			return true
+		}
+	case ErrValidation:
+		if e.SubReturnCode == ZaloPaySubReturnCodeInvalidParams {
+			return true
+		}
+	}
+	if e.Response != nil {
+		return statusErrorIs(e.Response.StatusCode, target)
+	}
+	return false
+}
+
+// ZaloPayResult are the fields common to the responses
+type ZaloPayResult struct {
+	ReturnCode       int    `json:"return_code"`
+	ReturnMessage    string `json:"return_message"`
+	SubReturnCode    int    `json:"sub_return_code"`
+	SubReturnMessage string `json:"sub_return_message"`
+}
+
+func (r *ZaloPayResult) err() error {
+	return &ZaloPayError{
+		ReturnCode:       r.ReturnCode,
+		ReturnMessage:    r.ReturnMessage,
+		SubReturnCode:    r.SubReturnCode,
+		SubReturnMessage: r.SubReturnMessage,
+	}
+}
+
+// ZaloPayItem struct
+type ZaloPayItem struct {
+	ItemID       string `json:"itemid"`
+	ItemName     string `json:"itemname"`
+	ItemPrice    int64  `json:"itemprice"`
+	ItemQuantity int    `json:"itemquantity"`
+}
+
+// ZaloPayOrder is an order in VND. AppTime, AppUser and CallbackURL are set when empty
+type ZaloPayOrder struct {
+	AppTransID  string // See NewZaloPayTransID
+	AppUser     string
+	AppTime     int64 // Unix milliseconds
+	Amount      int64
+	Description string
+	BankCode    string
+	Items       []ZaloPayItem
+	EmbedData   map[string]interface{} // e.g. {"redirecturl": "https://example.com/return"}
+	CallbackURL string
+}
+
+// ZaloPayOrderResponse struct
+type ZaloPayOrderResponse struct {
+	ZaloPayResult
+	OrderURL     string `json:"order_url"`
+	ZPTransToken string `json:"zp_trans_token"`
+	OrderToken   string `json:"order_token"`
+	QRCode       string `json:"qr_code"`
+}
+
+// ZaloPayCallback is the body of a payment callback, of which Data is signed
+type ZaloPayCallback struct {
+	Data string `json:"data"`
+	MAC  string `json:"mac"`
+	Type int    `json:"type"`
+}
+
+// ZaloPayCallbackData is the data of a successful payment
+type ZaloPayCallbackData struct {
+	AppID          int64  `json:"app_id"`
+	AppTransID     string `json:"app_trans_id"`
+	AppTime        int64  `json:"app_time"`
+	AppUser        string `json:"app_user"`
+	Amount         int64  `json:"amount"`
+	EmbedData      string `json:"embed_data"`
+	Item           string `json:"item"`
+	ZPTransID      int64  `json:"zp_trans_id"`
+	ServerTime     int64  `json:"server_time"`
+	Channel        int    `json:"channel"`
+	MerchantUserID string `json:"merchant_user_id"`
+	UserFeeAmount  int64  `json:"user_fee_amount"`
+	DiscountAmount int64  `json:"discount_amount"`
+}
+
+// ZaloPayQueryResponse struct, ReturnCode is the status of the payment
+type ZaloPayQueryResponse struct {
+	ZaloPayResult
+	IsProcessing   bool  `json:"is_processing"`
+	Amount         int64 `json:"amount"`
+	DiscountAmount int64 `json:"discount_amount"`
+	ZPTransID      int64 `json:"zp_trans_id"`
+}
+
+// ZaloPayRefund is a refund in VND, MRefundID is generated when empty
+type ZaloPayRefund struct {
+	MRefundID   string
+	ZPTransID   string
+	Amount      int64
+	Description string
+}
+
+// ZaloPayRefundResponse struct, ReturnCode is the status of the refund
+type ZaloPayRefundResponse struct {
+	ZaloPayResult
+	MRefundID string `json:"-"`
+	RefundID  int64  `json:"refund_id"`
+}
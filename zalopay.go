@@ -0,0 +1,252 @@
+package payment
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// ZaloPayAPIBaseSandBox points to the sandbox of the ZaloPay open API
+	ZaloPayAPIBaseSandBox = "https://sb-openapi.zalopay.vn"
+
+	// ZaloPayAPIBaseLive points to the live ZaloPay open API
+	ZaloPayAPIBaseLive = "https://openapi.zalopay.vn"
+)
+
+var (
+	// ErrInvalidZaloPayConfig is returned when a ZaloPay client is created without AppID, Key1, Key2 or APIBase
+	ErrInvalidZaloPayConfig = errors.New("zalopay: AppID, Key1, Key2 and APIBase are required to create a client")
+
+	// ErrZaloPayMAC is returned when the MAC of a callback is invalid
+	ErrZaloPayMAC = errors.New("zalopay: invalid mac")
+)
+
+// IZaloPay is the ZaloPay client interface
+type IZaloPay interface {
+	CreateOrder(ctx context.Context, order ZaloPayOrder) (*ZaloPayOrderResponse, error)
+	VerifyCallback(req *http.Request) (*ZaloPayCallbackData, error)
+	QueryOrder(ctx context.Context, appTransID string) (*ZaloPayQueryResponse, error)
+	Refund(ctx context.Context, refund ZaloPayRefund) (*ZaloPayRefundResponse, error)
+	QueryRefund(ctx context.Context, mRefundID string) (*ZaloPayRefundResponse, error)
+}
+
+// ZaloPayClient represents a ZaloPay open API client
+type ZaloPayClient struct {
+	Client      *http.Client
+	AppID       string
+	Key1        string
+	Key2        string
+	APIBase     string
+	CallbackURL string
+}
+
+// NewZaloPayClient returns a ZaloPay client for config
+func NewZaloPayClient(config *ZaloPay) (IZaloPay, error) {
+	if config == nil || config.AppID == "" || config.Key1 == "" || config.Key2 == "" || config.APIBase == "" {
+		return nil, ErrInvalidZaloPayConfig
+	}
+
+	return &ZaloPayClient{
+		Client:      &http.Client{},
+		AppID:       config.AppID,
+		Key1:        config.Key1,
+		Key2:        config.Key2,
+		APIBase:     config.APIBase,
+		CallbackURL: config.CallbackURL,
+	}, nil
+}
+
+// newZaloPay returns a ZaloPay client, or nil when config is invalid
+func newZaloPay(config *ZaloPay) IZaloPay {
+	client, err := NewZaloPayClient(config)
+	if err != nil {
+		log.Println("Unable to init ZaloPay client: ", err)
+		return nil
+	}
+
+	return client
+}
+
+// NewZaloPayTransID returns an app_trans_id or a m_refund_id, which ZaloPay requires to start with the date in GMT+7
+func NewZaloPayTransID(t time.Time, suffix string) string {
+	return t.In(indochinaLocation).Format("060102") + "_" + suffix
+}
+
+// zaloPayMAC returns the hex HMAC-SHA256 of the fields joined by "|"
+func zaloPayMAC(key string, fields ...string) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(strings.Join(fields, "|")))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// send posts form to path and decodes the response into v
+func (c *ZaloPayClient) send(ctx context.Context, path string, form url.Values, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.APIBase+path, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	return sendJSON(c.Client, req, v, func(resp *http.Response, body []byte) error {
+		return &ZaloPayError{Response: resp, ReturnMessage: string(body)}
+	})
+}
+
+// CreateOrder creates an order, of which the buyer opens OrderURL or scans QRCode.
+// The result is posted to the callback URL, see VerifyCallback
+// Endpoint: POST /v2/create
+// Doc: https://docs.zalopay.vn/v2/general/overview.html#tao-don-hang
+func (c *ZaloPayClient) CreateOrder(ctx context.Context, order ZaloPayOrder) (*ZaloPayOrderResponse, error) {
+	if order.AppTime == 0 {
+		order.AppTime = time.Now().UnixNano() / int64(time.Millisecond)
+	}
+	if order.AppUser == "" {
+		order.AppUser = "user"
+	}
+	if order.CallbackURL == "" {
+		order.CallbackURL = c.CallbackURL
+	}
+	item, err := json.Marshal(order.Items)
+	if err != nil {
+		return nil, err
+	}
+	if order.Items == nil {
+		item = []byte("[]")
+	}
+	embedData, err := json.Marshal(order.EmbedData)
+	if err != nil {
+		return nil, err
+	}
+	if order.EmbedData == nil {
+		embedData = []byte("{}")
+	}
+
+	amount := strconv.FormatInt(order.Amount, 10)
+	appTime := strconv.FormatInt(order.AppTime, 10)
+	form := url.Values{
+		"app_id":       {c.AppID},
+		"app_trans_id": {order.AppTransID},
+		"app_user":     {order.AppUser},
+		"app_time":     {appTime},
+		"amount":       {amount},
+		"item":         {string(item)},
+		"embed_data":   {string(embedData)},
+		"description":  {order.Description},
+		"bank_code":    {order.BankCode},
+		"callback_url": {order.CallbackURL},
+		"mac":          {zaloPayMAC(c.Key1, c.AppID, order.AppTransID, order.AppUser, amount, appTime, string(embedData), string(item))},
+	}
+
+	response := &ZaloPayOrderResponse{}
+	if err = c.send(ctx, "/v2/create", form, response); err != nil {
+		return nil, err
+	}
+	if response.ReturnCode != ZaloPayReturnCodeSuccess {
+		return response, response.err()
+	}
+	return response, nil
+}
+
+// VerifyCallback checks the MAC of a payment callback, made with Key2, and decodes its data.
+// Answer the callback with {"return_code":1,"return_message":"success"}, ZaloPay retries otherwise
+// Doc: https://docs.zalopay.vn/v2/general/overview.html#callback
+func (c *ZaloPayClient) VerifyCallback(req *http.Request) (*ZaloPayCallbackData, error) {
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	callback := &ZaloPayCallback{}
+	if err = json.Unmarshal(body, callback); err != nil {
+		return nil, err
+	}
+	if !hmac.Equal([]byte(zaloPayMAC(c.Key2, callback.Data)), []byte(callback.MAC)) {
+		return nil, ErrZaloPayMAC
+	}
+
+	data := &ZaloPayCallbackData{}
+	if err = json.Unmarshal([]byte(callback.Data), data); err != nil {
+		return nil, err
+	}
+	if strconv.FormatInt(data.AppID, 10) != c.AppID {
+		return nil, ErrZaloPayMAC
+	}
+
+	return data, nil
+}
+
+// QueryOrder returns the status of an order. A failed or processing payment is not an error,
+// check ReturnCode and IsProcessing
+// Endpoint: POST /v2/query
+func (c *ZaloPayClient) QueryOrder(ctx context.Context, appTransID string) (*ZaloPayQueryResponse, error) {
+	form := url.Values{
+		"app_id":       {c.AppID},
+		"app_trans_id": {appTransID},
+		"mac":          {zaloPayMAC(c.Key1, c.AppID, appTransID, c.Key1)},
+	}
+
+	response := &ZaloPayQueryResponse{}
+	if err := c.send(ctx, "/v2/query", form, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// Refund refunds a payment, partially when Amount is less than the amount of the payment.
+// The refund may still be processing, see QueryRefund
+// Endpoint: POST /v2/refund
+func (c *ZaloPayClient) Refund(ctx context.Context, refund ZaloPayRefund) (*ZaloPayRefundResponse, error) {
+	if refund.MRefundID == "" {
+		refund.MRefundID = NewZaloPayTransID(time.Now(), c.AppID+"_"+strconv.FormatInt(time.Now().UnixNano(), 10))
+	}
+	amount := strconv.FormatInt(refund.Amount, 10)
+	timestamp := strconv.FormatInt(time.Now().UnixNano()/int64(time.Millisecond), 10)
+	form := url.Values{
+		"m_refund_id": {refund.MRefundID},
+		"app_id":      {c.AppID},
+		"zp_trans_id": {refund.ZPTransID},
+		"amount":      {amount},
+		"timestamp":   {timestamp},
+		"description": {refund.Description},
+		"mac":         {zaloPayMAC(c.Key1, c.AppID, refund.ZPTransID, amount, refund.Description, timestamp)},
+	}
+
+	response := &ZaloPayRefundResponse{MRefundID: refund.MRefundID}
+	if err := c.send(ctx, "/v2/refund", form, response); err != nil {
+		return nil, err
+	}
+	if response.ReturnCode == ZaloPayReturnCodeFailed {
+		return response, response.err()
+	}
+	return response, nil
+}
+
+// QueryRefund returns the status of a refund
+// Endpoint: POST /v2/query_refund
+func (c *ZaloPayClient) QueryRefund(ctx context.Context, mRefundID string) (*ZaloPayRefundResponse, error) {
+	timestamp := strconv.FormatInt(time.Now().UnixNano()/int64(time.Millisecond), 10)
+	form := url.Values{
+		"app_id":      {c.AppID},
+		"m_refund_id": {mRefundID},
+		"timestamp":   {timestamp},
+		"mac":         {zaloPayMAC(c.Key1, c.AppID, mRefundID, timestamp)},
+	}
+
+	response := &ZaloPayRefundResponse{MRefundID: mRefundID}
+	if err := c.send(ctx, "/v2/query_refund", form, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}